@@ -0,0 +1,52 @@
+package deposit
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+)
+
+type Controller struct {
+	DepositService depositPort.Service
+}
+
+func New(DepositService depositPort.Service) *Controller {
+	return &Controller{DepositService}
+}
+
+var (
+	// refer to middleware
+	PartnerCodeContextKey = "partnercode"
+
+	ErrAccountNotFound = "Deposit account not found"
+)
+
+// Balance godoc
+// @Summary Deposit balance
+// @Description Get the prepaid deposit balance of the partner, held is taken by orders still in progress
+// @Tags Deposit
+// @Accept  json
+// @Param partner-code header string true "fill with partner code value" default(partner001)
+// @Param Authorization header string true "Authentication Bearer Token, token format ===> b64(unixTime:hmacSHA256(unixTime:JSONminify(body)))" default(Bearer token)
+// @Produce  json
+// @Success 200 {object} ResponseBalance
+// @Failure 401
+// @Failure 404
+// @Failure 422
+// @Router /deposit/balance [get]
+func (controller *Controller) Balance(c echo.Context) error {
+	// get Partner code form header (signature authentication)
+	partnerCode := c.Get(PartnerCodeContextKey).(string)
+
+	balance, err := controller.DepositService.Balance(partnerCode)
+	if err != nil {
+		if err.Error() == ErrAccountNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrAccountNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, ResponseBalance(balance))
+}
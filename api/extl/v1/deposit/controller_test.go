@@ -0,0 +1,52 @@
+package deposit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	depositController "github.com/sepulsa/teleco/api/extl/v1/deposit"
+	depositService "github.com/sepulsa/teleco/business/deposit/mock"
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	TestPartnerCode = "partner001"
+)
+
+func TestBalance(t *testing.T) {
+	e := echo.New()
+
+	service := depositService.New()
+	deposit := depositController.New(service)
+	endpoint := `/api/v1/deposit/balance`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(depositController.PartnerCodeContextKey, TestPartnerCode)
+	service.On("Balance", TestPartnerCode).Return(depositPort.Balance{PartnerCode: TestPartnerCode, Balance: 50000, Held: 10500}, nil).Once()
+	if assert.NoError(t, deposit.Balance(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response depositController.ResponseBalance
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, int64(50000), response.Balance)
+			assert.Equal(t, int64(10500), response.Held)
+		}
+	}
+
+	// 404 postpaid partner
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(depositController.PartnerCodeContextKey, TestPartnerCode)
+	service.On("Balance", TestPartnerCode).Return(depositPort.Balance{}, errors.New(depositController.ErrAccountNotFound)).Once()
+	if assert.NoError(t, deposit.Balance(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
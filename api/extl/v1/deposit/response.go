@@ -0,0 +1,10 @@
+package deposit
+
+import "time"
+
+type ResponseBalance struct {
+	PartnerCode string    `json:"partner_code"`
+	Balance     int64     `json:"balance"`
+	Held        int64     `json:"held"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -53,17 +53,19 @@ func (controller *Controller) Purchase(c echo.Context) error {
 
 	data := orderPort.OrderService{
 		TransactionId:   reqData.TransactionId,
+		ProductCode:     reqData.ProductCode,
 		IssuerProductId: reqData.IssuerProductId,
 		CustomerNumber:  reqData.CustomerNumber,
 		PartnerCode:     partnerCode,
 		IssuerCode:      reqData.IssuerCode,
 	}
-	result, err := controller.OrderService.Purchase(data)
+	result, err := controller.OrderService.Purchase(c.Request().Context(), data)
 	if err != nil {
 		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, ResponseOrder{
+		IssuerCode:          result.IssuerCode,
 		IssuerTransactionId: result.IssuerTransactionId,
 		SerialNumber:        result.SerialNumber,
 		IssuerRescode:       result.IssuerRescode,
@@ -103,12 +105,13 @@ func (controller *Controller) Advise(c echo.Context) error {
 		PartnerCode:         partnerCode,
 		IssuerCode:          reqData.IssuerCode,
 	}
-	result, err := controller.OrderService.Advise(data)
+	result, err := controller.OrderService.Advise(c.Request().Context(), data)
 	if err != nil {
 		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, ResponseOrder{
+		IssuerCode:          result.IssuerCode,
 		IssuerTransactionId: result.IssuerTransactionId,
 		SerialNumber:        result.SerialNumber,
 		IssuerRescode:       result.IssuerRescode,
@@ -148,12 +151,13 @@ func (controller *Controller) Reversal(c echo.Context) error {
 		PartnerCode:         partnerCode,
 		IssuerCode:          reqData.IssuerCode,
 	}
-	result, err := controller.OrderService.Reversal(data)
+	result, err := controller.OrderService.Reversal(c.Request().Context(), data)
 	if err != nil {
 		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusOK, ResponseOrder{
+		IssuerCode:          result.IssuerCode,
 		IssuerTransactionId: result.IssuerTransactionId,
 		SerialNumber:        result.SerialNumber,
 		IssuerRescode:       result.IssuerRescode,
@@ -34,7 +34,7 @@ func TestPurchase(t *testing.T) {
 
 	result := orderPort.OrderServiceResult{}
 
-	service.On("Purchase", mock.Anything).Return(result, nil).Once()
+	service.On("Purchase", mock.Anything, mock.Anything).Return(result, nil).Once()
 	if assert.NoError(t, order.Purchase(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	}
@@ -58,6 +58,31 @@ func TestPurchase(t *testing.T) {
 	if assert.NoError(t, order.Purchase(c)) {
 		assert.Equal(t, http.StatusBadRequest, rec.Code)
 	}
+
+	// 200 routed by product code
+	purchaseData = `{"transaction_id": "1002", "product_code":"TSEL10", "customer_number":"08123456789"}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(purchaseData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(orderController.PartnerCodeContextKey, TestPartnerCode)
+	service.On("Purchase", mock.Anything, mock.MatchedBy(func(order orderPort.OrderService) bool {
+		return order.ProductCode == "TSEL10"
+	})).Return(orderPort.OrderServiceResult{IssuerCode: "issuer"}, nil).Once()
+	if assert.NoError(t, order.Purchase(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"issuer_code":"issuer"`)
+	}
+
+	// 400 neither product code nor issuer product id
+	purchaseData = `{"transaction_id": "1003", "customer_number":"08123456789", "issuer_code":"issuer"}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(purchaseData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, order.Purchase(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
 }
 
 func TestAdvice(t *testing.T) {
@@ -76,7 +101,7 @@ func TestAdvice(t *testing.T) {
 
 	result := orderPort.OrderServiceResult{}
 
-	service.On("Advise", mock.Anything).Return(result, nil).Once()
+	service.On("Advise", mock.Anything, mock.Anything).Return(result, nil).Once()
 	if assert.NoError(t, order.Advise(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	}
@@ -118,7 +143,7 @@ func TestReversal(t *testing.T) {
 
 	result := orderPort.OrderServiceResult{}
 
-	service.On("Reversal", mock.Anything).Return(result, nil).Once()
+	service.On("Reversal", mock.Anything, mock.Anything).Return(result, nil).Once()
 	if assert.NoError(t, order.Reversal(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
 	}
@@ -1,10 +1,12 @@
 package order
 
+// PurchaseRequestOrder either product_code, routed to an issuer by teleco, or issuer_code with issuer_product_id
 type PurchaseRequestOrder struct {
 	TransactionId   string `json:"transaction_id" validate:"required"`
-	IssuerProductId string `json:"issuer_product_id" validate:"required"`
+	ProductCode     string `json:"product_code" validate:"required_without=IssuerCode"`
+	IssuerProductId string `json:"issuer_product_id" validate:"required_without=ProductCode"`
 	CustomerNumber  string `json:"customer_number" validate:"required"`
-	IssuerCode      string `json:"issuer_code" validate:"required"`
+	IssuerCode      string `json:"issuer_code" validate:"required_without=ProductCode"`
 }
 
 type AdviseRequestOrder struct {
@@ -1,6 +1,7 @@
 package order
 
 type ResponseOrder struct {
+	IssuerCode          string `json:"issuer_code"`
 	IssuerTransactionId string `json:"issuer_transaction_id"`
 	SerialNumber        string `json:"serial_number"`
 	IssuerRescode       string `json:"issuer_rescode"`
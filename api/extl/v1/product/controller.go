@@ -0,0 +1,57 @@
+package product
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	productPort "github.com/sepulsa/teleco/business/product/port"
+)
+
+type Controller struct {
+	ProductService productPort.Service
+}
+
+func New(ProductService productPort.Service) *Controller {
+	return &Controller{ProductService}
+}
+
+var (
+	// refer to middleware
+	PartnerCodeContextKey = "partnercode"
+)
+
+// ListData godoc
+// @Summary List products
+// @Description List the products enabled for the partner, purchase them with the product code
+// @Tags Product
+// @Accept  json
+// @Param partner-code header string true "fill with partner code value" default(partner001)
+// @Param Authorization header string true "Authentication Bearer Token, token format ===> b64(unixTime:hmacSHA256(unixTime:JSONminify(body)))" default(Bearer token)
+// @Produce  json
+// @Success 200
+// @Failure 401
+// @Failure 422
+// @Router /product [get]
+func (controller *Controller) ListData(c echo.Context) error {
+	// get Partner code form header (signature authentication)
+	partnerCode := c.Get(PartnerCodeContextKey).(string)
+
+	datas, err := controller.ProductService.ListPartnerProducts(partnerCode)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	products := make([]ResponseProduct, 0, len(datas))
+	for _, data := range datas {
+		products = append(products, ResponseProduct{
+			Code:         data.Code,
+			Name:         data.Name,
+			Operator:     data.Operator,
+			Type:         data.Type,
+			Denomination: data.Denomination,
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ResponseProduct{"data": products})
+}
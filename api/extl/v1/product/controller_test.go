@@ -0,0 +1,58 @@
+package product_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	productController "github.com/sepulsa/teleco/api/extl/v1/product"
+	productService "github.com/sepulsa/teleco/business/product/mock"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	TestPartnerCode = "partner001"
+)
+
+func TestListData(t *testing.T) {
+	e := echo.New()
+
+	service := productService.New()
+	product := productController.New(service)
+	endpoint := `/api/v1/product`
+
+	// 200 without issuer mapping
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set(productController.PartnerCodeContextKey, TestPartnerCode)
+	service.On("ListPartnerProducts", TestPartnerCode).Return([]productPort.ProductService{{
+		Code:         "TSEL10",
+		Type:         productPort.TypePulsa,
+		Denomination: 10000,
+		Issuers:      []productPort.IssuerProduct{{IssuerCode: "dummy", IssuerProductId: "S10"}},
+	}}, nil).Once()
+	if assert.NoError(t, product.ListData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "S10")
+		var response map[string][]productController.ResponseProduct
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, "TSEL10", response["data"][0].Code)
+			assert.Equal(t, int64(10000), response["data"][0].Denomination)
+		}
+	}
+
+	// 422
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set(productController.PartnerCodeContextKey, TestPartnerCode)
+	service.On("ListPartnerProducts", TestPartnerCode).Return([]productPort.ProductService{}, errors.New("Partner not found")).Once()
+	if assert.NoError(t, product.ListData(c)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	}
+}
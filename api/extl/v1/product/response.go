@@ -0,0 +1,9 @@
+package product
+
+type ResponseProduct struct {
+	Code         string `json:"code"`
+	Name         string `json:"name"`
+	Operator     string `json:"operator"`
+	Type         string `json:"type"`
+	Denomination int64  `json:"denomination"`
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	log "github.com/sepulsa/teleco/utils/logger"
+)
+
+type RateLimit struct {
+	ratelimitService ratelimitPort.Service
+}
+
+func NewRateLimit(ratelimitService ratelimitPort.Service) *RateLimit {
+	return &RateLimit{
+		ratelimitService,
+	}
+}
+
+var (
+	ErrTooManyRequests = "too many requests"
+
+	HeaderRetryAfterKeyName = "Retry-After"
+)
+
+// Limit must run after the signature validator, it relies on the verified partner code. Product orders name
+// no issuer, the order service checks their partner+issuer limit once the product is routed
+func (handler *RateLimit) Limit(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		partnerCode, _ := c.Get("partnercode").(string)
+
+		payload, err := ioutil.ReadAll(c.Request().Body)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrReadBody})
+		}
+		c.Request().Body = ioutil.NopCloser(bytes.NewBuffer(payload))
+
+		var reqData struct {
+			IssuerCode string `json:"issuer_code"`
+		}
+		json.Unmarshal(payload, &reqData)
+
+		result, err := handler.ratelimitService.Allow(partnerCode, reqData.IssuerCode)
+		if apperror.KindOf(err) == apperror.Conflict {
+			// the bucket is contended by concurrent requests of the partner, a flood is exactly what must be limited
+			result = ratelimitPort.Result{RetryAfter: time.Second}
+		} else if err != nil {
+			// do not reject orders because the limiter store is unavailable
+			log.Error().Str("event", "ratelimit.error").Msgf("partner %s: %s", partnerCode, err.Error())
+			return next(c)
+		}
+		if !result.Allowed {
+			retryAfter := int(math.Max(math.Ceil(result.RetryAfter.Seconds()), 1))
+			c.Response().Header().Set(HeaderRetryAfterKeyName, strconv.Itoa(retryAfter))
+			return c.JSON(http.StatusTooManyRequests, echo.HTTPError{Message: ErrTooManyRequests})
+		}
+
+		return next(c)
+	}
+}
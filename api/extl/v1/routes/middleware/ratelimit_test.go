@@ -0,0 +1,78 @@
+package middleware_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	extlMiddleware "github.com/sepulsa/teleco/api/extl/v1/routes/middleware"
+	ratelimitService "github.com/sepulsa/teleco/business/ratelimit/mock"
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	endpoint := `/api/v1/order/purchase`
+	purchaseData := `{"transaction_id":"1","issuer_code":"dummy"}`
+
+	e := echo.New()
+	ratelimitService := ratelimitService.New()
+	middleware := extlMiddleware.NewRateLimit(ratelimitService)
+
+	var body string
+	handler := middleware.Limit(func(c echo.Context) error {
+		b, _ := ioutil.ReadAll(c.Request().Body)
+		body = string(b)
+		return c.NoContent(http.StatusOK)
+	})
+
+	ratelimitService.On("Allow", "p1", "dummy").Return(ratelimitPort.Result{Allowed: true}, nil).Once()
+	ratelimitService.On("Allow", "p1", "dummy").Return(ratelimitPort.Result{RetryAfter: 1500 * time.Millisecond}, nil).Once()
+	ratelimitService.On("Allow", "p1", "dummy").Return(ratelimitPort.Result{}, errors.New("store down")).Once()
+	ratelimitService.On("Allow", "p1", "dummy").Return(ratelimitPort.Result{}, apperror.NewConflict("Rate limit bucket update conflict")).Once()
+
+	// allowed, body still readable by the controller
+	req := httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(purchaseData))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("partnercode", "p1")
+	if assert.NoError(t, handler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, purchaseData, body)
+	}
+
+	// limited
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(purchaseData))
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set("partnercode", "p1")
+	if assert.NoError(t, handler(c)) {
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get(extlMiddleware.HeaderRetryAfterKeyName))
+	}
+
+	// limiter store error fails open
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(purchaseData))
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set("partnercode", "p1")
+	if assert.NoError(t, handler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// contended bucket is limited
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(purchaseData))
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.Set("partnercode", "p1")
+	if assert.NoError(t, handler(c)) {
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get(extlMiddleware.HeaderRetryAfterKeyName))
+	}
+}
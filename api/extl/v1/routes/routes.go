@@ -1,15 +1,23 @@
 package routes
 
 import (
+	depositController "github.com/sepulsa/teleco/api/extl/v1/deposit"
 	orderController "github.com/sepulsa/teleco/api/extl/v1/order"
+	productController "github.com/sepulsa/teleco/api/extl/v1/product"
 	extlMiddleware "github.com/sepulsa/teleco/api/extl/v1/routes/middleware"
+	statementController "github.com/sepulsa/teleco/api/extl/v1/statement"
 	authService "github.com/sepulsa/teleco/business/auth"
+	depositService "github.com/sepulsa/teleco/business/deposit"
+	issuerService "github.com/sepulsa/teleco/business/issuer"
 	orderService "github.com/sepulsa/teleco/business/order"
+	priceService "github.com/sepulsa/teleco/business/price"
+	productService "github.com/sepulsa/teleco/business/product"
+	ratelimitService "github.com/sepulsa/teleco/business/ratelimit"
+	routeService "github.com/sepulsa/teleco/business/route"
+	statementService "github.com/sepulsa/teleco/business/statement"
 	issuerApi "github.com/sepulsa/teleco/modules/issuerapi"
-	issuerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/issuer"
-	orderRepository "github.com/sepulsa/teleco/modules/repository/mongodb/order"
-	partnerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/partner"
-	partnerIssuerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/partner/issuer"
+	"github.com/sepulsa/teleco/modules/notifier"
+	"github.com/sepulsa/teleco/modules/repository"
 	"github.com/sepulsa/teleco/utils/config"
 
 	"github.com/labstack/echo/v4"
@@ -17,21 +25,44 @@ import (
 )
 
 func API(e *echo.Echo) {
-	db := config.Mgo
-
-	issuerRepo := issuerRepository.New(db)
-	partnerRepo := partnerRepository.New(db)
-	partnerIssuerRepo := partnerIssuerRepository.New(db)
-	orderRepo := orderRepository.New(db)
-	issuerApi := issuerApi.New()
-	orderServiceHandler := orderService.New(issuerRepo, partnerRepo, partnerIssuerRepo, orderRepo, issuerApi)
+	issuerRepo := repository.NewIssuer()
+	partnerRepo := repository.NewPartner()
+	partnerIssuerRepo := repository.NewPartnerIssuer()
+	orderRepo := repository.NewOrder()
+	productRepo := repository.NewProduct()
+	depositServ := depositService.New(repository.NewDeposit(), partnerRepo)
+	issuerBalanceServ := issuerService.NewBalance(issuerRepo, repository.NewIssuerBalance(), notifier.New())
+	issuerApi := issuerApi.New(repository.NewIssuerCircuit(), depositServ, issuerBalanceServ)
+	routeRepo := repository.NewRoute()
+	routeServ := routeService.New(routeRepo)
+	priceServ := priceService.New(repository.NewPrice(), partnerRepo, productRepo)
+	ratelimitServ := ratelimitService.New(partnerRepo, repository.NewRateLimit())
+	orderServiceHandler := orderService.New(issuerRepo, partnerRepo, partnerIssuerRepo, orderRepo, issuerApi, productRepo, routeServ, priceServ, depositServ, ratelimitServ)
 	orderHandler := orderController.New(orderServiceHandler)
+	depositHandler := depositController.New(depositServ)
+	statementHandler := statementController.New(statementService.New(orderRepo, partnerRepo, config.GetStatementDownloadUrl(), config.GetStatementLinkExpired()))
+	productHandler := productController.New(productService.New(productRepo, issuerRepo, partnerRepo, partnerIssuerRepo, routeRepo))
 	authService := authService.New(nil, nil, partnerRepo)
 	authMiddleware := extlMiddleware.NewAuth(authService)
-	order := e.Group("/api/v1/order", middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+
+	rateLimitMiddleware := extlMiddleware.NewRateLimit(ratelimitServ)
+
+	partnerAuth := middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
 		Validator: authMiddleware.PartnerSignatureValidator,
-	}))
+	})
+
+	order := e.Group("/api/v1/order", partnerAuth, rateLimitMiddleware.Limit)
 	order.POST("/purchase", orderHandler.Purchase)
 	order.POST("/advise", orderHandler.Advise)
 	order.POST("/reversal", orderHandler.Reversal)
+
+	product := e.Group("/api/v1/product", partnerAuth, rateLimitMiddleware.Limit)
+	product.GET("", productHandler.ListData)
+
+	deposit := e.Group("/api/v1/deposit", partnerAuth, rateLimitMiddleware.Limit)
+	deposit.GET("/balance", depositHandler.Balance)
+
+	// the signed link is the credential, a partner can hand it over to its finance team
+	statement := e.Group("/api/v1/statement")
+	statement.GET("/download", statementHandler.Download)
 }
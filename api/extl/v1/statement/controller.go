@@ -0,0 +1,102 @@
+package statement
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	statementPort "github.com/sepulsa/teleco/business/statement/port"
+)
+
+type Controller struct {
+	StatementService statementPort.Service
+}
+
+func New(StatementService statementPort.Service) *Controller {
+	return &Controller{StatementService}
+}
+
+var (
+	ErrInvalidDate      = "invalid %s value"
+	ErrPartnerNotFound  = "Partner not found"
+	ErrLinkExpired      = "Download link expired"
+	ErrInvalidSignature = "Invalid download link signature"
+
+	DateLayout = "2006-01-02"
+)
+
+// Download godoc
+// @Summary Download statement
+// @Description Download the statement of a partner through a link made on the internal API, the link signature replaces the request signature
+// @Tags Statement
+// @Accept  json
+// @Param partner_code query string true "Partner code"
+// @Param from query string true "First day, 2006-01-02"
+// @Param until query string true "Last day, 2006-01-02"
+// @Param format query string true "csv or json"
+// @Param expires query int true "Unix time the link expires at"
+// @Param signature query string true "Link signature"
+// @Produce  json,text/csv
+// @Success 200 {object} ResponseStatement
+// @Failure 400
+// @Failure 403
+// @Failure 410
+// @Failure 422
+// @Router /statement/download [get]
+func (controller *Controller) Download(c echo.Context) error {
+	reqData := new(RequestDownload)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+	from, err := time.ParseInLocation(DateLayout, reqData.From, time.Local)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: fmt.Sprintf(ErrInvalidDate, "from")})
+	}
+	until, err := time.ParseInLocation(DateLayout, reqData.Until, time.Local)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: fmt.Sprintf(ErrInvalidDate, "until")})
+	}
+	until = until.AddDate(0, 0, 1)
+
+	params := statementPort.LinkParams{
+		PartnerCode: reqData.PartnerCode,
+		From:        from,
+		Until:       until,
+		Format:      reqData.Format,
+		Expires:     reqData.Expires,
+		Signature:   reqData.Signature,
+	}
+	if err := controller.StatementService.VerifyLink(params); err != nil {
+		if err.Error() == ErrLinkExpired {
+			return c.JSON(http.StatusGone, echo.HTTPError{Message: ErrLinkExpired})
+		}
+		return c.JSON(http.StatusForbidden, echo.HTTPError{Message: err.Error()})
+	}
+
+	data, err := controller.StatementService.Generate(reqData.PartnerCode, from, until)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	if reqData.Format == statementPort.FormatCSV {
+		filename := fmt.Sprintf("statement_%s_%s_%s.csv", data.PartnerCode, reqData.From, reqData.Until)
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv")
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Response().WriteHeader(http.StatusOK)
+		return controller.StatementService.WriteCSV(c.Response(), data)
+	}
+
+	var statement ResponseStatement
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &statement)
+
+	return c.JSON(http.StatusOK, statement)
+}
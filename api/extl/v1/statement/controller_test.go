@@ -0,0 +1,82 @@
+package statement_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	statementController "github.com/sepulsa/teleco/api/extl/v1/statement"
+	statementService "github.com/sepulsa/teleco/business/statement/mock"
+	statementPort "github.com/sepulsa/teleco/business/statement/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	TestPartnerCode = "partner001"
+	TestFrom        = time.Date(2021, 9, 1, 0, 0, 0, 0, time.Local)
+	TestUntil       = time.Date(2021, 10, 1, 0, 0, 0, 0, time.Local)
+	TestQuery       = "?partner_code=partner001&from=2021-09-01&until=2021-09-30&format=json&expires=1633046400&signature=abc"
+)
+
+func TestDownload(t *testing.T) {
+	e := echo.New()
+
+	service := statementService.New()
+	statement := statementController.New(service)
+	endpoint := `/api/v1/statement/download`
+	params := statementPort.LinkParams{
+		PartnerCode: TestPartnerCode,
+		From:        TestFrom,
+		Until:       TestUntil,
+		Format:      statementPort.FormatJSON,
+		Expires:     1633046400,
+		Signature:   "abc",
+	}
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint+TestQuery, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("VerifyLink", params).Return(nil).Once()
+	service.On("Generate", TestPartnerCode, TestFrom, TestUntil).Return(statementPort.Statement{PartnerCode: TestPartnerCode, Count: 2}, nil).Once()
+	if assert.NoError(t, statement.Download(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response statementController.ResponseStatement
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, 2, response.Count)
+		}
+	}
+
+	// 403 invalid signature
+	req = httptest.NewRequest(http.MethodGet, endpoint+TestQuery, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("VerifyLink", params).Return(errors.New(statementController.ErrInvalidSignature)).Once()
+	if assert.NoError(t, statement.Download(c)) {
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+
+	// 410 expired
+	req = httptest.NewRequest(http.MethodGet, endpoint+TestQuery, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("VerifyLink", params).Return(errors.New(statementController.ErrLinkExpired)).Once()
+	if assert.NoError(t, statement.Download(c)) {
+		assert.Equal(t, http.StatusGone, rec.Code)
+	}
+
+	// 400 signature is required
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?partner_code=partner001&from=2021-09-01&until=2021-09-30&format=json&expires=1633046400", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, statement.Download(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	service.AssertExpectations(t)
+	service.AssertNotCalled(t, "WriteCSV", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,11 @@
+package statement
+
+// RequestDownload query of a download link made on the internal API
+type RequestDownload struct {
+	PartnerCode string `query:"partner_code" validate:"required"`
+	From        string `query:"from" validate:"required"`
+	Until       string `query:"until" validate:"required"`
+	Format      string `query:"format" validate:"required,oneof=csv json"`
+	Expires     int64  `query:"expires" validate:"required"`
+	Signature   string `query:"signature" validate:"required"`
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
@@ -11,6 +12,11 @@ import (
 	"github.com/sepulsa/teleco/utils/validator"
 )
 
+var (
+	ErrRequiredTokenID = "token id is required"
+	ErrSessionNotFound = "session not found"
+)
+
 type Controller struct {
 	authService authPort.Service
 }
@@ -76,6 +82,8 @@ func (controller *Controller) UserLogin(c echo.Context) error {
 	userAuth.Email = reqData.Email
 	userAuth.Password = reqData.Password
 	userAuth.KeepLogin = reqData.KeepLogin
+	userAuth.IPAddress = c.RealIP()
+	userAuth.UserAgent = c.Request().UserAgent()
 	if err := controller.authService.UserLogin(userAuth); err != nil {
 		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
 	}
@@ -139,3 +147,101 @@ func (controller *Controller) UserRefreshToken(c echo.Context) error {
 
 	return c.JSON(http.StatusOK, response)
 }
+
+// UserSessions godoc
+// @Summary List user sessions
+// @Description list active sessions of the logged in user
+// @Tags UserAuthentication
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Success 200 {object} UserResponseSession
+// @Failure 401
+// @Failure 422
+// @Router /auth/session [get]
+func (controller *Controller) UserSessions(c echo.Context) error {
+	user := c.Get("user").(*jwt.Token)
+	tokenClaims := user.Claims.(*auth.JWTClaims)
+
+	datas, err := controller.authService.UserSessions(tokenClaims.ID)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	var session UserResponseSession
+	sessions := make([]UserResponseSession, 0, len(datas))
+	for i := range datas {
+		session.TokenID = datas[i].TokenID
+		session.IPAddress = datas[i].IPAddress
+		session.UserAgent = datas[i].UserAgent
+		session.Current = datas[i].Current
+		session.CreatedAt = datas[i].CreatedAt
+		session.LastUsedAt = datas[i].LastUsedAt
+		session.ExpiredAt = datas[i].ExpiredAt
+
+		sessions = append(sessions, session)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]UserResponseSession{"data": sessions})
+}
+
+// UserRevokeSession godoc
+// @Summary Revoke user session
+// @Description revoke one of the logged in user sessions
+// @Tags UserAuthentication
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param token_id path string true "Session Token ID"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /auth/session/{token_id} [delete]
+func (controller *Controller) UserRevokeSession(c echo.Context) error {
+	sessionTokenID := c.Param("token_id")
+	if strings.TrimSpace(sessionTokenID) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredTokenID})
+	}
+
+	user := c.Get("user").(*jwt.Token)
+	tokenClaims := user.Claims.(*auth.JWTClaims)
+
+	if err := controller.authService.UserRevokeSession(tokenClaims.ID, sessionTokenID); err != nil {
+		if err.Error() == ErrSessionNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrSessionNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
+}
+
+// JWKS godoc
+// @Summary JSON Web Key Set
+// @Description public keys for verifying user tokens, includes retired keys still accepted
+// @Tags UserAuthentication
+// @Produce  json
+// @Success 200 {object} ResponseJSONWebKeySet
+// @Router /.well-known/jwks.json [get]
+func (controller *Controller) JWKS(c echo.Context) error {
+	datas := controller.authService.PublicKeys()
+
+	var key ResponseJSONWebKey
+	response := ResponseJSONWebKeySet{Keys: make([]ResponseJSONWebKey, 0, len(datas))}
+	for i := range datas {
+		key.KeyType = datas[i].KeyType
+		key.KeyID = datas[i].KeyID
+		key.Use = datas[i].Use
+		key.Algorithm = datas[i].Algorithm
+		key.N = datas[i].N
+		key.E = datas[i].E
+		key.Curve = datas[i].Curve
+		key.X = datas[i].X
+		key.Y = datas[i].Y
+
+		response.Keys = append(response.Keys, key)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
@@ -2,7 +2,7 @@ package auth
 
 type UserRequestLogin struct {
 	Email     string `json:"email" validate:"required,email"`
-	Password  string `json:"password" validate:"required,min=4,max=20,alphanum"`
+	Password  string `json:"password" validate:"required,max=72"`
 	KeepLogin bool   `json:"keep_login"`
 }
 
@@ -12,6 +12,6 @@ type UserRefreshToken struct {
 
 type UserRegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=4,max=20,alphanum"`
+	Password string `json:"password" validate:"required,max=72"`
 	Fullname string `json:"fullname" validate:"required"`
 }
@@ -0,0 +1,117 @@
+package deposit
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+)
+
+var (
+	ErrRequiredPartnerCode = "Partner code can't be empty"
+	ErrAccountNotFound     = "Deposit account not found"
+)
+
+type Controller struct {
+	depositService depositPort.Service
+}
+
+func New(depositService depositPort.Service) *Controller {
+	return &Controller{
+		depositService,
+	}
+}
+
+// Topup godoc
+// @Summary Topup a partner deposit
+// @Description add a transfer received from the partner to its prepaid deposit, the deposit is created on the first topup, admin only
+// @Tags Deposit
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param body body RequestTopup true "please refer to deposit.RequestTopup models below"
+// @Success 201
+// @Failure 400
+// @Failure 403
+// @Failure 422
+// @Router /deposit/topup [post]
+func (controller *Controller) Topup(c echo.Context) error {
+	reqData := new(RequestTopup)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	if err := controller.depositService.Topup(reqData.PartnerCode, reqData.Amount, reqData.Reference); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, "")
+}
+
+// Balance godoc
+// @Summary Get a partner deposit balance
+// @Description get a partner deposit balance, held is taken by orders still in progress
+// @Tags Deposit
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param partner_code path string true "Partner code"
+// @Success 200 {object} ResponseBalance
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /deposit/{partner_code} [get]
+func (controller *Controller) Balance(c echo.Context) error {
+	partnerCode := c.Param("partner_code")
+	if strings.TrimSpace(partnerCode) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredPartnerCode})
+	}
+
+	balance, err := controller.depositService.Balance(partnerCode)
+	if err != nil {
+		if err.Error() == ErrAccountNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrAccountNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, ResponseBalance(balance))
+}
+
+// Ledger godoc
+// @Summary List a partner deposit ledger
+// @Description list the ledger entries of a partner deposit, newest first, every entry moves the amount from its debit account to its credit account
+// @Tags Deposit
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param partner_code path string true "Partner code"
+// @Success 200
+// @Failure 400
+// @Failure 422
+// @Router /deposit/{partner_code}/ledger [get]
+func (controller *Controller) Ledger(c echo.Context) error {
+	partnerCode := c.Param("partner_code")
+	if strings.TrimSpace(partnerCode) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredPartnerCode})
+	}
+
+	datas, err := controller.depositService.Ledger(partnerCode)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	entries := make([]ResponseEntry, 0, len(datas))
+	for _, data := range datas {
+		entries = append(entries, ResponseEntry(data))
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ResponseEntry{"data": entries})
+}
@@ -0,0 +1,119 @@
+package deposit_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	depositController "github.com/sepulsa/teleco/api/intl/v1/deposit"
+	depositService "github.com/sepulsa/teleco/business/deposit/mock"
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	TestPartnerCode = "partner001"
+
+	ErrRequiredReference = "reference is required"
+)
+
+func TestTopup(t *testing.T) {
+	e := echo.New()
+
+	service := depositService.New()
+	deposit := depositController.New(service)
+	endpoint := `/api/v1/deposit/topup`
+
+	// 201
+	reqData := `{"partner_code":"partner001","amount":100000,"reference":"TRF-1"}`
+	req := httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("Topup", TestPartnerCode, int64(100000), "TRF-1").Return(nil).Once()
+	if assert.NoError(t, deposit.Topup(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	// 400 validate
+	reqData = `{"partner_code":"partner001","amount":100000}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, deposit.Topup(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), ErrRequiredReference)
+	}
+
+	// 422 err service
+	reqData = `{"partner_code":"unknown","amount":100000,"reference":"TRF-1"}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("Topup", "unknown", int64(100000), "TRF-1").Return(errors.New("Partner not found")).Once()
+	if assert.NoError(t, deposit.Topup(c)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	}
+}
+
+func TestBalance(t *testing.T) {
+	e := echo.New()
+
+	service := depositService.New()
+	deposit := depositController.New(service)
+	endpoint := `/api/v1/deposit`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	service.On("Balance", TestPartnerCode).Return(depositPort.Balance{PartnerCode: TestPartnerCode, Balance: 50000}, nil).Once()
+	if assert.NoError(t, deposit.Balance(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"balance":50000`)
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	service.On("Balance", TestPartnerCode).Return(depositPort.Balance{}, errors.New(depositController.ErrAccountNotFound)).Once()
+	if assert.NoError(t, deposit.Balance(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestLedger(t *testing.T) {
+	e := echo.New()
+
+	service := depositService.New()
+	deposit := depositController.New(service)
+	endpoint := `/api/v1/deposit`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	service.On("Ledger", TestPartnerCode).Return([]depositPort.Entry{
+		{Type: depositPort.EntryTopup, DebitAccount: depositPort.AccountCash, CreditAccount: depositPort.AccountAvailable, Amount: 100000},
+	}, nil).Once()
+	if assert.NoError(t, deposit.Ledger(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response map[string][]depositController.ResponseEntry
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) && assert.Len(t, response["data"], 1) {
+			assert.Equal(t, depositPort.EntryTopup, response["data"][0].Type)
+		}
+	}
+}
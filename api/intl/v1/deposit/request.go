@@ -0,0 +1,8 @@
+package deposit
+
+// RequestTopup reference of the transfer received by finance
+type RequestTopup struct {
+	PartnerCode string `json:"partner_code" validate:"required"`
+	Amount      int64  `json:"amount" validate:"required,gte=1"`
+	Reference   string `json:"reference" validate:"required"`
+}
@@ -0,0 +1,21 @@
+package deposit
+
+import "time"
+
+type ResponseBalance struct {
+	PartnerCode string    `json:"partner_code"`
+	Balance     int64     `json:"balance"`
+	Held        int64     `json:"held"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ResponseEntry struct {
+	ID            string    `json:"id"`
+	PartnerCode   string    `json:"partner_code"`
+	Type          string    `json:"type"`
+	DebitAccount  string    `json:"debit_account"`
+	CreditAccount string    `json:"credit_account"`
+	Amount        int64     `json:"amount"`
+	Reference     string    `json:"reference"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,97 @@
+package balance
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+)
+
+var (
+	ErrRequiredID      = "ID can't be empty"
+	ErrIssuerNotFound  = "Issuer not found"
+	ErrBalanceNotFound = "Issuer balance not reported yet"
+)
+
+type Controller struct {
+	balanceService issuerPort.BalanceService
+}
+
+func New(balanceService issuerPort.BalanceService) *Controller {
+	return &Controller{
+		balanceService,
+	}
+}
+
+// ReadData godoc
+// @Summary Get the balance of an issuer
+// @Description get the deposit left at an issuer as last reported by the issuer, set by ops or estimated from successful purchases
+// @Tags Issuer
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Issuer ID"
+// @Success 200 {object} ResponseBalance
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /issuer/{id}/balance [get]
+func (controller *Controller) ReadData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	data, err := controller.balanceService.ReadData(id)
+	if err != nil {
+		switch err.Error() {
+		case ErrIssuerNotFound, ErrBalanceNotFound:
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: err.Error()})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, ResponseBalance(data))
+}
+
+// UpdateData godoc
+// @Summary Set the balance of an issuer
+// @Description set the deposit left at an issuer, usually after a top up, purchases are deducted from it until the issuer reports its balance
+// @Tags Issuer
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Issuer ID"
+// @Param body body RequestBalance true "please refer to balance.RequestBalance models below"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /issuer/{id}/balance [put]
+func (controller *Controller) UpdateData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	reqData := new(RequestBalance)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	if err := controller.balanceService.UpdateData(id, *reqData.Balance); err != nil {
+		if err.Error() == ErrIssuerNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrIssuerNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
+}
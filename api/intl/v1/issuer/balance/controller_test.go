@@ -0,0 +1,102 @@
+package balance_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	balanceController "github.com/sepulsa/teleco/api/intl/v1/issuer/balance"
+	issuerService "github.com/sepulsa/teleco/business/issuer/mock"
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	TestID   = "6138813fb95630b0b528b160"
+	TestCode = "issuer"
+)
+
+func TestReadData(t *testing.T) {
+	e := echo.New()
+
+	service := issuerService.NewBalance()
+	balance := balanceController.New(service)
+	endpoint := `/api/v1/issuer/:id/balance`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	data := issuerPort.IssuerBalance{IssuerCode: TestCode, Balance: 50000, Source: issuerPort.BalanceSourceEstimate, Threshold: 100000, Low: true}
+	service.On("ReadData", TestID).Return(data, nil).Once()
+	if assert.NoError(t, balance.ReadData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response balanceController.ResponseBalance
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, int64(50000), response.Balance)
+			assert.True(t, response.Low)
+		}
+	}
+
+	// 404 never reported
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(issuerPort.IssuerBalance{}, errors.New(balanceController.ErrBalanceNotFound)).Once()
+	if assert.NoError(t, balance.ReadData(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+	service.AssertExpectations(t)
+}
+
+func TestUpdateData(t *testing.T) {
+	e := echo.New()
+
+	service := issuerService.NewBalance()
+	balance := balanceController.New(service)
+	endpoint := `/api/v1/issuer/:id/balance`
+
+	// 200, zero is a valid balance
+	req := httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(`{"balance": 0}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("UpdateData", TestID, int64(0)).Return(nil).Once()
+	if assert.NoError(t, balance.UpdateData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// 400 balance is required
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	if assert.NoError(t, balance.UpdateData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(`{"balance": 1000}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("UpdateData", TestID, int64(1000)).Return(errors.New(balanceController.ErrIssuerNotFound)).Once()
+	if assert.NoError(t, balance.UpdateData(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,5 @@
+package balance
+
+type RequestBalance struct {
+	Balance *int64 `json:"balance" validate:"required"`
+}
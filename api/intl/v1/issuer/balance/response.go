@@ -0,0 +1,12 @@
+package balance
+
+import "time"
+
+type ResponseBalance struct {
+	IssuerCode string    `json:"issuer_code"`
+	Balance    int64     `json:"balance"`
+	Source     string    `json:"source"`
+	Threshold  int64     `json:"threshold"`
+	Low        bool      `json:"low"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
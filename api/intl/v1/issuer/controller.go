@@ -6,7 +6,9 @@ import (
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/etag"
 	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/query"
 	"github.com/sepulsa/teleco/utils/validator"
 
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
@@ -38,6 +40,7 @@ func New(issuerService issuerPort.Service) *Controller {
 // @Param body body RequestIssuer true "please refer to issuer.RequestIssuer models below"
 // @Success 201
 // @Failure 400
+// @Failure 409
 // @Failure 422
 // @Router /issuer [post]
 func (controller *Controller) CreateData(c echo.Context) error {
@@ -56,9 +59,14 @@ func (controller *Controller) CreateData(c echo.Context) error {
 		ThreadNum:        reqData.ThreadNum,
 		ThreadTimeout:    reqData.ThreadTimeout,
 		QueueWorkerLimit: reqData.QueueWorkerLimit,
+		Status:           reqData.Status,
+		CircuitBreaker:   issuerPort.CircuitBreaker(reqData.CircuitBreaker),
+
+		LowBalanceThreshold: reqData.LowBalanceThreshold,
+		SettlementMapping:   issuerPort.SettlementMapping(reqData.SettlementMapping),
 	}
 	if err := controller.issuerService.CreateData(data); err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, "")
@@ -73,6 +81,7 @@ func (controller *Controller) CreateData(c echo.Context) error {
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "Issuer ID"
 // @Success 200 {object} ResponseIssuer
+// @Header 200 {string} ETag "Version of the issuer, sent back as If-Match on update"
 // @Failure 400
 // @Failure 404
 // @Failure 422
@@ -98,8 +107,18 @@ func (controller *Controller) ReadData(c echo.Context) error {
 		ThreadNum:        data.ThreadNum,
 		ThreadTimeout:    data.ThreadTimeout,
 		QueueWorkerLimit: data.QueueWorkerLimit,
+		Status:           data.Status,
+
+		CircuitBreaker:    ResponseCircuitBreaker(data.CircuitBreaker),
+		CircuitForcedOpen: data.CircuitForcedOpen,
+
+		LowBalanceThreshold: data.LowBalanceThreshold,
+		SettlementMapping:   ResponseSettlementMapping(data.SettlementMapping),
+
+		Version: data.Version,
 	}
 
+	etag.Set(c, data.Version)
 	return c.JSON(http.StatusOK, issuer)
 }
 
@@ -111,11 +130,15 @@ func (controller *Controller) ReadData(c echo.Context) error {
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "Issuer ID"
+// @Param If-Match header string false "ETag of the issuer read, instead of version"
 // @Param body body RequestIssuer true "please refer to issuer.RequestIssuer models below"
 // @Success 200
+// @Header 200 {string} ETag "Version of the updated issuer"
 // @Failure 400
 // @Failure 404
+// @Failure 409
 // @Failure 422
+// @Failure 428
 // @Router /issuer/{id} [put]
 func (controller *Controller) UpdateData(c echo.Context) error {
 	id := c.Param("id")
@@ -130,6 +153,10 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 	if err := validator.GetValidator().Struct(reqData); err != nil {
 		return httperror.NewValidationError(c, http.StatusBadRequest, err)
 	}
+	version, err := etag.Version(c, reqData.Version)
+	if err != nil {
+		return c.JSON(http.StatusPreconditionRequired, echo.HTTPError{Message: err.Error()})
+	}
 
 	data := issuerPort.IssuerService{
 		ID:               id,
@@ -139,14 +166,22 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 		ThreadNum:        reqData.ThreadNum,
 		ThreadTimeout:    reqData.ThreadTimeout,
 		QueueWorkerLimit: reqData.QueueWorkerLimit,
+		Status:           reqData.Status,
+		CircuitBreaker:   issuerPort.CircuitBreaker(reqData.CircuitBreaker),
+
+		LowBalanceThreshold: reqData.LowBalanceThreshold,
+		SettlementMapping:   issuerPort.SettlementMapping(reqData.SettlementMapping),
+
+		Version: version,
 	}
 	if err := controller.issuerService.UpdateData(data); err != nil {
 		if err.Error() == ErrIssuerNotFound {
 			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrIssuerNotFound})
 		}
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 
+	etag.Set(c, version+1)
 	return c.JSON(http.StatusOK, "")
 }
 
@@ -161,6 +196,7 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 // @Success 200
 // @Failure 400
 // @Failure 404
+// @Failure 409
 // @Failure 422
 // @Router /issuer/{id} [delete]
 func (controller *Controller) DeleteData(c echo.Context) error {
@@ -173,24 +209,52 @@ func (controller *Controller) DeleteData(c echo.Context) error {
 		if err.Error() == ErrIssuerNotFound {
 			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrIssuerNotFound})
 		}
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 	return c.JSON(http.StatusOK, "")
 }
 
 // ListData godoc
 // @Summary List issuers
-// @Description list issuers
+// @Description list issuers, a page with the total count
 // @Tags Issuer
 // @Accept  json
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
-// @Success 200
+// @Param page query int false "Page, 1 by default"
+// @Param limit query int false "Page size, 20 by default, up to 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of page"
+// @Param sort query string false "Sort field, descending with a - prefix: code, label, status, created_at, updated_at"
+// @Param search query string false "Text searched in code or label"
+// @Param status query string false "active or inactive"
+// @Success 200 {object} ResponseList
+// @Failure 400
 // @Failure 422
 // @Router /issuer [get]
 func (controller *Controller) ListData(c echo.Context) error {
-	datas, err := controller.issuerService.ListData()
+	reqData := new(RequestList)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	datas, page, err := controller.issuerService.SearchData(query.Query{
+		Page:   reqData.Page,
+		Limit:  reqData.Limit,
+		Cursor: reqData.Cursor,
+		Sort:   reqData.Sort,
+		Search: reqData.Search,
+		Filters: map[string]string{
+			"status": reqData.Status,
+		},
+	})
 	if err != nil {
+		switch err.Error() {
+		case query.ErrInvalidCursor, query.ErrInvalidSort, query.ErrInvalidFilter:
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+		}
 		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
@@ -200,5 +264,73 @@ func (controller *Controller) ListData(c echo.Context) error {
 		json.Unmarshal(d, &issuers)
 	}
 
-	return c.JSON(http.StatusOK, map[string][]ResponseIssuer{"data": issuers})
+	return c.JSON(http.StatusOK, ResponseList{Data: issuers, Pagination: page})
+}
+
+// CircuitState godoc
+// @Summary Get circuit breaker state of an issuer
+// @Description get circuit breaker state of an issuer as last reported by an instance, forced_open while kept open for maintenance
+// @Tags Issuer
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Issuer ID"
+// @Success 200 {object} ResponseCircuitState
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /issuer/{id}/circuit [get]
+func (controller *Controller) CircuitState(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	data, err := controller.issuerService.CircuitState(id)
+	if err != nil {
+		if err.Error() == ErrIssuerNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrIssuerNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, ResponseCircuitState(data))
+}
+
+// ForceOpenCircuit godoc
+// @Summary Force open the circuit of an issuer
+// @Description keep the issuer circuit open for maintenance, orders fail fast until released with forced_open false
+// @Tags Issuer
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Issuer ID"
+// @Param body body RequestCircuit true "please refer to issuer.RequestCircuit models below"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /issuer/{id}/circuit [put]
+func (controller *Controller) ForceOpenCircuit(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	reqData := new(RequestCircuit)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	if err := controller.issuerService.ForceOpenCircuit(id, *reqData.ForcedOpen); err != nil {
+		if err.Error() == ErrIssuerNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrIssuerNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
 }
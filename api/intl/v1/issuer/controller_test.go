@@ -13,6 +13,9 @@ import (
 	issuerController "github.com/sepulsa/teleco/api/intl/v1/issuer"
 	issuerService "github.com/sepulsa/teleco/business/issuer/mock"
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/net/etag"
+	"github.com/sepulsa/teleco/utils/query"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -91,6 +94,8 @@ func TestReadData(t *testing.T) {
 		ID:    TestID,
 		Code:  TestCode,
 		Label: TestLabel,
+
+		Version: 2,
 	}
 
 	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
@@ -107,7 +112,9 @@ func TestReadData(t *testing.T) {
 			assert.Equal(t, TestID, response.ID)
 			assert.Equal(t, TestCode, response.Code)
 			assert.Equal(t, TestLabel, response.Label)
+			assert.Equal(t, 2, response.Version)
 		}
+		assert.Equal(t, `"2"`, rec.Header().Get(etag.HeaderETag))
 	}
 
 	// 400 empty ID
@@ -156,16 +163,35 @@ func TestUpdateData(t *testing.T) {
 	endpoint := `/api/v1/issuer/`
 
 	// 200
-	updDataSuccess := fmt.Sprintf(`{"code":"%s","label":"%s"}`, TestCode, TestLabel)
+	updDataSuccess := fmt.Sprintf(`{"code":"%s","label":"%s","version":2}`, TestCode, TestLabel)
 	req := httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataSuccess))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
 	c.SetParamValues(TestID)
-	service.On("UpdateData", mock.Anything).Return(nil).Once()
+	service.On("UpdateData", mock.MatchedBy(func(data issuerPort.IssuerService) bool {
+		return data.Version == 2
+	})).Return(nil).Once()
 	if assert.NoError(t, issuer.UpdateData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"3"`, rec.Header().Get(etag.HeaderETag))
+	}
+
+	// 200 If-Match takes precedence over version
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataSuccess))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(etag.HeaderIfMatch, `"5"`)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("UpdateData", mock.MatchedBy(func(data issuerPort.IssuerService) bool {
+		return data.Version == 5
+	})).Return(nil).Once()
+	if assert.NoError(t, issuer.UpdateData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"6"`, rec.Header().Get(etag.HeaderETag))
 	}
 
 	// 400 empty ID
@@ -216,6 +242,31 @@ func TestUpdateData(t *testing.T) {
 	if assert.NoError(t, issuer.UpdateData(c)) {
 		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 	}
+
+	// 409 - updated since it was read
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataSuccess))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("UpdateData", mock.Anything).Return(apperror.NewConflict("Issuer was updated since it was read, reload it and retry")).Once()
+	if assert.NoError(t, issuer.UpdateData(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	// 428 - version missing
+	updData = fmt.Sprintf(`{"code":"%s","label":"%s"}`, TestCode, TestLabel)
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	if assert.NoError(t, issuer.UpdateData(c)) {
+		assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+		assert.Contains(t, rec.Body.String(), etag.ErrVersionRequired)
+	}
 }
 
 func TestDeleteData(t *testing.T) {
@@ -294,14 +345,15 @@ func TestListData(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	service.On("ListData").Return(issuers, nil).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"status": ""}}).Return(issuers, query.Page{Total: 1, Page: 1, Limit: 20}, nil).Once()
 	if assert.NoError(t, issuer.ListData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		var response map[string][]issuerController.ResponseIssuer
+		var response issuerController.ResponseList
 		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
-			assert.Equal(t, TestID, response["data"][0].ID)
-			assert.Equal(t, TestCode, response["data"][0].Code)
-			assert.Equal(t, TestLabel, response["data"][0].Label)
+			assert.Equal(t, 1, response.Pagination.Total)
+			assert.Equal(t, TestID, response.Data[0].ID)
+			assert.Equal(t, TestCode, response.Data[0].Code)
+			assert.Equal(t, TestLabel, response.Data[0].Label)
 		}
 	}
 
@@ -310,22 +362,121 @@ func TestListData(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec = httptest.NewRecorder()
 	c = e.NewContext(req, rec)
-	service.On("ListData").Return([]issuerPort.IssuerService{}, nil).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"status": ""}}).Return([]issuerPort.IssuerService{}, query.Page{Page: 1, Limit: 20}, nil).Once()
+	if assert.NoError(t, issuer.ListData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response issuerController.ResponseList
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, 0, len(response.Data))
+		}
+	}
+
+	// 200 query
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?page=2&limit=5&sort=-code&search=dum&status=active", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	listQuery := query.Query{Page: 2, Limit: 5, Sort: "-code", Search: "dum", Filters: map[string]string{"status": issuerPort.StatusActive}}
+	service.On("SearchData", listQuery).Return(issuers, query.Page{Total: 6, Page: 2, Limit: 5}, nil).Once()
 	if assert.NoError(t, issuer.ListData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		var response map[string][]issuerController.ResponseIssuer
+		var response issuerController.ResponseList
 		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
-			assert.Equal(t, 0, len(response["data"]))
+			assert.Equal(t, 6, response.Pagination.Total)
+			assert.Equal(t, 2, response.Pagination.Page)
 		}
 	}
 
+	// 400 invalid sort field
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?sort=config", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, issuer.ListData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 400 invalid cursor
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?cursor=x", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("SearchData", query.Query{Cursor: "x", Filters: map[string]string{"status": ""}}).Return([]issuerPort.IssuerService{}, query.Page{}, errors.New(query.ErrInvalidCursor)).Once()
+	if assert.NoError(t, issuer.ListData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
 	// 422 err service
 	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec = httptest.NewRecorder()
 	c = e.NewContext(req, rec)
-	service.On("ListData").Return([]issuerPort.IssuerService{}, errors.New("")).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"status": ""}}).Return([]issuerPort.IssuerService{}, query.Page{}, errors.New("")).Once()
 	if assert.NoError(t, issuer.ListData(c)) {
 		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 	}
 }
+
+func TestCircuitState(t *testing.T) {
+	e := echo.New()
+
+	service := issuerService.New()
+	issuer := issuerController.New(service)
+	endpoint := `/api/v1/issuer/:id/circuit`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("CircuitState", TestID).Return(issuerPort.CircuitState{IssuerCode: TestCode, State: issuerPort.CircuitForcedOpen}, nil).Once()
+	if assert.NoError(t, issuer.CircuitState(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response issuerController.ResponseCircuitState
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, TestCode, response.IssuerCode)
+			assert.Equal(t, issuerPort.CircuitForcedOpen, response.State)
+		}
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("CircuitState", TestID).Return(issuerPort.CircuitState{}, errors.New(issuerController.ErrIssuerNotFound)).Once()
+	if assert.NoError(t, issuer.CircuitState(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestForceOpenCircuit(t *testing.T) {
+	e := echo.New()
+
+	service := issuerService.New()
+	issuer := issuerController.New(service)
+	endpoint := `/api/v1/issuer/:id/circuit`
+
+	// 200
+	req := httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(`{"forced_open": true}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ForceOpenCircuit", TestID, true).Return(nil).Once()
+	if assert.NoError(t, issuer.ForceOpenCircuit(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// 400 forced_open is required
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	if assert.NoError(t, issuer.ForceOpenCircuit(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	service.AssertExpectations(t)
+}
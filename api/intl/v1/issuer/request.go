@@ -6,5 +6,53 @@ type RequestIssuer struct {
 	Config           string `json:"config"`
 	ThreadNum        int    `json:"thread_num"`
 	ThreadTimeout    int    `json:"thread_timeout"`
-	QueueWorkerLimit int    `json:"queue_worker_limit"`
+	QueueWorkerLimit int    `json:"queue_worker_limit" validate:"gte=0"`
+	Status           string `json:"status" validate:"omitempty,oneof=active inactive"`
+
+	CircuitBreaker RequestCircuitBreaker `json:"circuit_breaker"`
+
+	// LowBalanceThreshold alert once the issuer balance goes below it, zero disables the alert
+	LowBalanceThreshold int64 `json:"low_balance_threshold" validate:"gte=0"`
+
+	SettlementMapping RequestSettlementMapping `json:"settlement_mapping"`
+
+	// Version of the issuer read, only on update and ignored when the If-Match header is set
+	Version *int `json:"version"`
+}
+
+// RequestSettlementMapping header names of the issuer settlement file columns, a transaction id or
+// issuer transaction id column is needed to reconcile the issuer
+type RequestSettlementMapping struct {
+	TransactionId       string   `json:"transaction_id"`
+	IssuerTransactionId string   `json:"issuer_transaction_id"`
+	Amount              string   `json:"amount"`
+	Status              string   `json:"status"`
+	SuccessStatus       []string `json:"success_status"`
+	Delimiter           string   `json:"delimiter" validate:"omitempty,max=1"`
+}
+
+// RequestCircuitBreaker thresholds, rates are ratios between 0 and 1 and zero disables the check,
+// slow_call_duration is in milliseconds, window and open_duration in seconds
+type RequestCircuitBreaker struct {
+	ErrorRate        float64 `json:"error_rate" validate:"gte=0,lte=1"`
+	SlowCallRate     float64 `json:"slow_call_rate" validate:"gte=0,lte=1"`
+	SlowCallDuration int     `json:"slow_call_duration" validate:"gte=0"`
+	MinimumCalls     int     `json:"minimum_calls" validate:"gte=0"`
+	Window           int     `json:"window" validate:"gte=0"`
+	OpenDuration     int     `json:"open_duration" validate:"gte=0"`
+	HalfOpenCalls    int     `json:"half_open_calls" validate:"gte=0"`
+}
+
+type RequestCircuit struct {
+	ForcedOpen *bool `json:"forced_open" validate:"required"`
+}
+
+// RequestList page and limit, or the next_cursor of the previous page
+type RequestList struct {
+	Page   int    `query:"page" validate:"gte=0"`
+	Limit  int    `query:"limit" validate:"gte=0,lte=100"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort" validate:"omitempty,oneof=code -code label -label status -status created_at -created_at updated_at -updated_at"`
+	Search string `query:"search"`
+	Status string `query:"status" validate:"omitempty,oneof=active inactive"`
 }
@@ -1,5 +1,11 @@
 package issuer
 
+import (
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+)
+
 type ResponseIssuer struct {
 	ID               string `json:"id"`
 	Code             string `json:"code"`
@@ -8,4 +14,47 @@ type ResponseIssuer struct {
 	ThreadNum        int    `json:"thread_num"`
 	ThreadTimeout    int    `json:"thread_timeout"`
 	QueueWorkerLimit int    `json:"queue_worker_limit"`
+	Status           string `json:"status"`
+
+	CircuitBreaker    ResponseCircuitBreaker `json:"circuit_breaker"`
+	CircuitForcedOpen bool                   `json:"circuit_forced_open"`
+
+	LowBalanceThreshold int64                     `json:"low_balance_threshold"`
+	SettlementMapping   ResponseSettlementMapping `json:"settlement_mapping"`
+
+	Version int `json:"version"`
+}
+
+type ResponseSettlementMapping struct {
+	TransactionId       string   `json:"transaction_id"`
+	IssuerTransactionId string   `json:"issuer_transaction_id"`
+	Amount              string   `json:"amount"`
+	Status              string   `json:"status"`
+	SuccessStatus       []string `json:"success_status"`
+	Delimiter           string   `json:"delimiter"`
+}
+
+type ResponseCircuitBreaker struct {
+	ErrorRate        float64 `json:"error_rate"`
+	SlowCallRate     float64 `json:"slow_call_rate"`
+	SlowCallDuration int     `json:"slow_call_duration"`
+	MinimumCalls     int     `json:"minimum_calls"`
+	Window           int     `json:"window"`
+	OpenDuration     int     `json:"open_duration"`
+	HalfOpenCalls    int     `json:"half_open_calls"`
+}
+
+type ResponseCircuitState struct {
+	IssuerCode string    `json:"issuer_code"`
+	State      string    `json:"state"`
+	Calls      int       `json:"calls"`
+	Failures   int       `json:"failures"`
+	SlowCalls  int       `json:"slow_calls"`
+	OpenedAt   time.Time `json:"opened_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ResponseList struct {
+	Data       []ResponseIssuer `json:"data"`
+	Pagination query.Page       `json:"pagination"`
 }
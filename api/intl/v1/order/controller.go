@@ -0,0 +1,165 @@
+package order
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+)
+
+var (
+	ErrRequiredID    = "ID can't be empty"
+	ErrInvalidDate   = "invalid %s value"
+	ErrInvalidID     = "Invalid ID"
+	ErrInvalidCursor = "Invalid cursor"
+	ErrOrderNotFound = "Order not found"
+
+	DateLayout = "2006-01-02"
+)
+
+type Controller struct {
+	queryService orderPort.QueryService
+}
+
+func New(queryService orderPort.QueryService) *Controller {
+	return &Controller{
+		queryService,
+	}
+}
+
+// SearchData godoc
+// @Summary Search orders
+// @Description search the order log, a page holds limit records and next_cursor reads the following page, empty on the last one
+// @Tags Order
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param partner_code query string false "Partner code"
+// @Param issuer_code query string false "Issuer code"
+// @Param command_type query string false "purchase, advise or reversal"
+// @Param status query string false "success, failed or pending"
+// @Param transaction_id query string false "Partner transaction ID"
+// @Param issuer_transaction_id query string false "Issuer transaction ID"
+// @Param customer_number query string false "Customer number"
+// @Param from query string false "From, RFC3339 time or 2006-01-02 day"
+// @Param until query string false "Until, RFC3339 time exclusive or 2006-01-02 day inclusive"
+// @Param sort query string false "-created_at (default) or created_at"
+// @Param cursor query string false "next_cursor of the previous page"
+// @Param limit query int false "Page size, 20 by default, up to 100"
+// @Success 200 {object} ResponseList
+// @Failure 400
+// @Failure 422
+// @Router /order [get]
+func (controller *Controller) SearchData(c echo.Context) error {
+	reqData := new(RequestSearch)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+	from, err := parseTime(reqData.From, false)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: fmt.Sprintf(ErrInvalidDate, "from")})
+	}
+	until, err := parseTime(reqData.Until, true)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: fmt.Sprintf(ErrInvalidDate, "until")})
+	}
+
+	data, err := controller.queryService.SearchData(orderPort.OrderQuery{
+		PartnerCode:         reqData.PartnerCode,
+		IssuerCode:          reqData.IssuerCode,
+		CommandType:         reqData.CommandType,
+		Status:              reqData.Status,
+		TransactionId:       reqData.TransactionId,
+		IssuerTransactionId: reqData.IssuerTransactionId,
+		CustomerNumber:      reqData.CustomerNumber,
+		From:                from,
+		Until:               until,
+		Sort:                reqData.Sort,
+		Cursor:              reqData.Cursor,
+		Limit:               reqData.Limit,
+	})
+	if err != nil {
+		if err.Error() == ErrInvalidCursor {
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrInvalidCursor})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	response := ResponseList{
+		Data:       make([]ResponseOrder, 0, len(data.Orders)),
+		NextCursor: data.NextCursor,
+	}
+	for _, record := range data.Orders {
+		response.Data = append(response.Data, toResponseOrder(record))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// ReadData godoc
+// @Summary Get an order
+// @Description get an order record with the timeline of its transaction, every issuer attempt, advise, reversal and callback, credentials are redacted from the raw data
+// @Tags Order
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Order ID"
+// @Success 200 {object} ResponseDetail
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /order/{id} [get]
+func (controller *Controller) ReadData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	data, err := controller.queryService.ReadData(id)
+	if err != nil {
+		switch err.Error() {
+		case ErrOrderNotFound:
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrOrderNotFound})
+		case ErrInvalidID:
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrInvalidID})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	response := ResponseDetail{
+		ResponseOrder: toResponseOrder(data.OrderRecord),
+		FinalStatus:   data.FinalStatus,
+		Timeline:      make([]ResponseRecord, 0, len(data.Timeline)),
+	}
+	for _, record := range data.Timeline {
+		response.Timeline = append(response.Timeline, toResponseRecord(record))
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// parseTime reads a RFC3339 time or a day, the day after is returned for an inclusive until day
+func parseTime(value string, until bool) (t time.Time, err error) {
+	if value == "" {
+		return
+	}
+	if t, err = time.Parse(time.RFC3339, value); err == nil {
+		return
+	}
+	if t, err = time.ParseInLocation(DateLayout, value, time.Local); err != nil {
+		return
+	}
+	if until {
+		t = t.AddDate(0, 0, 1)
+	}
+	return
+}
@@ -0,0 +1,120 @@
+package order_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	orderController "github.com/sepulsa/teleco/api/intl/v1/order"
+	orderService "github.com/sepulsa/teleco/business/order/mock"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	TestID = "6138813fb95630b0b528b160"
+)
+
+func TestSearchData(t *testing.T) {
+	e := echo.New()
+
+	service := orderService.NewQuery()
+	order := orderController.New(service)
+
+	// 200
+	query := orderPort.OrderQuery{
+		PartnerCode: "partner",
+		Status:      orderPort.StatusSuccess,
+		From:        time.Date(2021, 9, 8, 0, 0, 0, 0, time.Local),
+		Until:       time.Date(2021, 9, 9, 0, 0, 0, 0, time.Local),
+		Limit:       10,
+	}
+	req := httptest.NewRequest(http.MethodGet, `/api/v1/order?partner_code=partner&status=success&from=2021-09-08&until=2021-09-08&limit=10`, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("SearchData", query).Return(orderPort.OrderList{Orders: []orderPort.OrderRecord{{ID: TestID, Status: orderPort.StatusSuccess}}, NextCursor: "next"}, nil).Once()
+	if assert.NoError(t, order.SearchData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response orderController.ResponseList
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Len(t, response.Data, 1)
+			assert.Equal(t, "next", response.NextCursor)
+		}
+	}
+
+	// 400 invalid status
+	req = httptest.NewRequest(http.MethodGet, `/api/v1/order?status=unknown`, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, order.SearchData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 400 invalid date
+	req = httptest.NewRequest(http.MethodGet, `/api/v1/order?from=yesterday`, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, order.SearchData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 400 invalid cursor
+	req = httptest.NewRequest(http.MethodGet, `/api/v1/order?cursor=x`, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("SearchData", orderPort.OrderQuery{Cursor: "x"}).Return(orderPort.OrderList{}, errors.New(orderController.ErrInvalidCursor)).Once()
+	if assert.NoError(t, order.SearchData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestReadData(t *testing.T) {
+	e := echo.New()
+
+	service := orderService.NewQuery()
+	order := orderController.New(service)
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, `/`, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetPath("/api/v1/order/:id")
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	detail := orderPort.OrderDetail{
+		OrderRecord: orderPort.OrderRecord{ID: TestID},
+		FinalStatus: orderPort.StatusSuccess,
+		Timeline:    []orderPort.OrderRecord{{ID: TestID, RequestData: "{}"}},
+	}
+	service.On("ReadData", TestID).Return(detail, nil).Once()
+	if assert.NoError(t, order.ReadData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response orderController.ResponseDetail
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, orderPort.StatusSuccess, response.FinalStatus)
+			assert.Equal(t, "{}", response.Timeline[0].RequestData)
+		}
+	}
+
+	// 404
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetPath("/api/v1/order/:id")
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(orderPort.OrderDetail{}, errors.New(orderController.ErrOrderNotFound)).Once()
+	if assert.NoError(t, order.ReadData(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+
+	// 400
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, order.ReadData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,17 @@
+package order
+
+// RequestSearch from and until are RFC3339 times or 2006-01-02 days, until is exclusive for a time and inclusive for a day
+type RequestSearch struct {
+	PartnerCode         string `query:"partner_code"`
+	IssuerCode          string `query:"issuer_code"`
+	CommandType         string `query:"command_type" validate:"omitempty,oneof=purchase advise reversal"`
+	Status              string `query:"status" validate:"omitempty,oneof=success failed pending"`
+	TransactionId       string `query:"transaction_id"`
+	IssuerTransactionId string `query:"issuer_transaction_id"`
+	CustomerNumber      string `query:"customer_number"`
+	From                string `query:"from"`
+	Until               string `query:"until"`
+	Sort                string `query:"sort" validate:"omitempty,oneof=created_at -created_at"`
+	Cursor              string `query:"cursor"`
+	Limit               int    `query:"limit" validate:"gte=0,lte=100"`
+}
@@ -0,0 +1,75 @@
+package order
+
+import (
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+)
+
+type ResponseOrder struct {
+	ID                  string               `json:"id"`
+	CommandType         string               `json:"command_type"`
+	TransactionId       string               `json:"transaction_id"`
+	IssuerProductId     string               `json:"issuer_product_id"`
+	ProductCode         string               `json:"product_code"`
+	CustomerNumber      string               `json:"customer_number"`
+	PartnerCode         string               `json:"partner_code"`
+	IssuerCode          string               `json:"issuer_code"`
+	IssuerTransactionId string               `json:"issuer_transaction_id"`
+	IssuerRescode       string               `json:"issuer_rescode"`
+	Status              string               `json:"status"`
+	SerialNumber        string               `json:"serial_number"`
+	Route               int                  `json:"route"`
+	Price               orderPort.OrderPrice `json:"price"`
+	CreatedAt           time.Time            `json:"created_at"`
+}
+
+type ResponseList struct {
+	Data       []ResponseOrder `json:"data"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+// ResponseRecord one issuer attempt or callback, credentials are redacted from the raw data
+type ResponseRecord struct {
+	ResponseOrder
+	RequestData          string `json:"request_data"`
+	ResponseData         string `json:"response_data"`
+	CallbackRequestData  string `json:"callback_request_data"`
+	CallbackResponseData string `json:"callback_response_data"`
+}
+
+type ResponseDetail struct {
+	ResponseOrder
+	FinalStatus string           `json:"final_status"`
+	Timeline    []ResponseRecord `json:"timeline"`
+}
+
+func toResponseOrder(record orderPort.OrderRecord) ResponseOrder {
+	return ResponseOrder{
+		ID:                  record.ID,
+		CommandType:         record.CommandType,
+		TransactionId:       record.TransactionId,
+		IssuerProductId:     record.IssuerProductId,
+		ProductCode:         record.ProductCode,
+		CustomerNumber:      record.CustomerNumber,
+		PartnerCode:         record.PartnerCode,
+		IssuerCode:          record.IssuerCode,
+		IssuerTransactionId: record.IssuerTransactionId,
+		IssuerRescode:       record.IssuerRescode,
+		Status:              record.Status,
+		SerialNumber:        record.SerialNumber,
+		Route:               record.Route,
+		Price:               record.Price,
+		CreatedAt:           record.CreatedAt,
+	}
+}
+
+func toResponseRecord(record orderPort.OrderRecord) ResponseRecord {
+	return ResponseRecord{
+		ResponseOrder:        toResponseOrder(record),
+		RequestData:          record.RequestData,
+		ResponseData:         record.ResponseData,
+		CallbackRequestData:  record.CallbackRequestData,
+		CallbackResponseData: record.CallbackResponseData,
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/etag"
 	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/query"
 	"github.com/sepulsa/teleco/utils/validator"
 
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
@@ -38,6 +40,7 @@ func New(partnerService partnerPort.Service) *Controller {
 // @Param body body RequestPartner true "please refer to partner.RequestPartner models below"
 // @Success 200
 // @Failure 400
+// @Failure 409
 // @Failure 422
 // @Router /partner [post]
 func (controller *Controller) CreateData(c echo.Context) error {
@@ -58,10 +61,13 @@ func (controller *Controller) CreateData(c echo.Context) error {
 		IpWhitelist: reqData.IpWhitelist,
 		Status:      reqData.Status,
 		SecretKey:   reqData.SecretKey,
+
+		RateLimit:       partnerPort.RateLimit(reqData.RateLimit),
+		IssuerRateLimit: toRateLimits(reqData.IssuerRateLimit),
 	}
 
 	if err := controller.partnerService.CreateData(data); err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, "")
@@ -76,6 +82,7 @@ func (controller *Controller) CreateData(c echo.Context) error {
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "id"
 // @Success 200
+// @Header 200 {string} ETag "Version of the partner, sent back as If-Match on update"
 // @Failure 400
 // @Failure 422
 // @Router /partner/{id} [get]
@@ -102,8 +109,14 @@ func (controller *Controller) ReadData(c echo.Context) error {
 		IpWhitelist: data.IpWhitelist,
 		Status:      data.Status,
 		SecretKey:   data.SecretKey,
+
+		RateLimit:       ResponseRateLimit(data.RateLimit),
+		IssuerRateLimit: toResponseRateLimits(data.IssuerRateLimit),
+
+		Version: data.Version,
 	}
 
+	etag.Set(c, data.Version)
 	return c.JSON(http.StatusOK, partner)
 }
 
@@ -115,10 +128,14 @@ func (controller *Controller) ReadData(c echo.Context) error {
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "id"
+// @Param If-Match header string false "ETag of the partner read, instead of version"
 // @Param body body RequestPartner true "please refer to partner.RequestPartner models below"
 // @Success 200
+// @Header 200 {string} ETag "Version of the updated partner"
 // @Failure 400
+// @Failure 409
 // @Failure 422
+// @Failure 428
 // @Router /partner/{id} [put]
 func (controller *Controller) UpdateData(c echo.Context) error {
 	id := c.Param("id")
@@ -133,6 +150,10 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 	if err := validator.GetValidator().Struct(reqData); err != nil {
 		return httperror.NewValidationError(c, http.StatusBadRequest, err)
 	}
+	version, err := etag.Version(c, reqData.Version)
+	if err != nil {
+		return c.JSON(http.StatusPreconditionRequired, echo.HTTPError{Message: err.Error()})
+	}
 
 	data := partnerPort.PartnerService{
 		ID:          id,
@@ -144,14 +165,20 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 		IpWhitelist: reqData.IpWhitelist,
 		Status:      reqData.Status,
 		SecretKey:   reqData.SecretKey,
+
+		RateLimit:       partnerPort.RateLimit(reqData.RateLimit),
+		IssuerRateLimit: toRateLimits(reqData.IssuerRateLimit),
+
+		Version: version,
 	}
 	if err := controller.partnerService.UpdateData(data); err != nil {
 		if err.Error() == ErrPartnerNotFound {
 			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPartnerNotFound})
 		}
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 
+	etag.Set(c, version+1)
 	return c.JSON(http.StatusOK, "")
 }
 
@@ -165,6 +192,7 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 // @Param id path string true "id"
 // @Success 200
 // @Failure 400
+// @Failure 409
 // @Failure 422
 // @Router /partner/{id} [delete]
 func (controller *Controller) DeleteData(c echo.Context) error {
@@ -177,25 +205,52 @@ func (controller *Controller) DeleteData(c echo.Context) error {
 		if err.Error() == ErrPartnerNotFound {
 			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPartnerNotFound})
 		}
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 	return c.JSON(http.StatusOK, "")
 }
 
-// CreateData godoc
+// ListData godoc
 // @Summary Get List an partner
-// @Description get list an partner
+// @Description get list an partner, a page with the total count
 // @Tags Partner
 // @Accept  json
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
-// @Success 200
+// @Param page query int false "Page, 1 by default"
+// @Param limit query int false "Page size, 20 by default, up to 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of page"
+// @Param sort query string false "Sort field, descending with a - prefix: code, name, status, created_at, updated_at"
+// @Param search query string false "Text searched in code, name or pic"
+// @Param status query string false "Status"
+// @Success 200 {object} ResponseList
 // @Failure 400
 // @Failure 422
 // @Router /partner [get]
 func (controller *Controller) ListData(c echo.Context) error {
-	datas, err := controller.partnerService.ListData()
+	reqData := new(RequestList)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	datas, page, err := controller.partnerService.SearchData(query.Query{
+		Page:   reqData.Page,
+		Limit:  reqData.Limit,
+		Cursor: reqData.Cursor,
+		Sort:   reqData.Sort,
+		Search: reqData.Search,
+		Filters: map[string]string{
+			"status": reqData.Status,
+		},
+	})
 	if err != nil {
+		switch err.Error() {
+		case query.ErrInvalidCursor, query.ErrInvalidSort, query.ErrInvalidFilter:
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+		}
 		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
@@ -205,5 +260,27 @@ func (controller *Controller) ListData(c echo.Context) error {
 		json.Unmarshal(d, &partners)
 	}
 
-	return c.JSON(http.StatusOK, map[string][]ResponsePartner{"data": partners})
+	return c.JSON(http.StatusOK, ResponseList{Data: partners, Pagination: page})
+}
+
+func toRateLimits(limits map[string]RequestRateLimit) map[string]partnerPort.RateLimit {
+	if len(limits) == 0 {
+		return nil
+	}
+	data := make(map[string]partnerPort.RateLimit, len(limits))
+	for issuerCode, limit := range limits {
+		data[issuerCode] = partnerPort.RateLimit(limit)
+	}
+	return data
+}
+
+func toResponseRateLimits(limits map[string]partnerPort.RateLimit) map[string]ResponseRateLimit {
+	if len(limits) == 0 {
+		return nil
+	}
+	data := make(map[string]ResponseRateLimit, len(limits))
+	for issuerCode, limit := range limits {
+		data[issuerCode] = ResponseRateLimit(limit)
+	}
+	return data
 }
@@ -13,6 +13,9 @@ import (
 	partnerController "github.com/sepulsa/teleco/api/intl/v1/partner"
 	partnerService "github.com/sepulsa/teleco/business/partner/mock"
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/net/etag"
+	"github.com/sepulsa/teleco/utils/query"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -63,6 +66,17 @@ func TestCreate(t *testing.T) {
 			assert.Equal(t, http.StatusCreated, rec.Code)
 		}
 
+		// 409 duplicate code
+		req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec = httptest.NewRecorder()
+		c = e.NewContext(req, rec)
+		service.On("CreateData", mock.Anything).Return(apperror.NewConflict("Code already in use")).Once()
+		if assert.NoError(t, partner.CreateData(c)) {
+			assert.Equal(t, http.StatusConflict, rec.Code)
+			assert.Contains(t, rec.Body.String(), "Code already in use")
+		}
+
 		// 400 bind
 		reqData = fmt.Sprintf(`{"partner_id":"%s","partner_name":"%s"}`, TestPartnerID1, TestPartnerName1)
 		req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
@@ -103,6 +117,8 @@ func TestRead(t *testing.T) {
 		CallbackUrl: TestPartnerCallbackUrl1,
 		IpWhitelist: TestPartnerIpwhitelist1,
 		Status:      TestPartnerStatus1,
+
+		Version: 1,
 	}
 
 	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
@@ -123,7 +139,9 @@ func TestRead(t *testing.T) {
 			assert.Equal(t, TestPartnerCallbackUrl1, response.CallbackUrl)
 			assert.Equal(t, TestPartnerIpwhitelist1, response.IpWhitelist)
 			assert.Equal(t, TestPartnerStatus1, response.Status)
+			assert.Equal(t, 1, response.Version)
 		}
+		assert.Equal(t, `"1"`, rec.Header().Get(etag.HeaderETag))
 	}
 
 	// 400 empty ID
@@ -173,16 +191,36 @@ func TestUpdate(t *testing.T) {
 	b, _ := json.Marshal(TestPartnerIpwhitelist1)
 
 	// 200
-	updDataSuccess := fmt.Sprintf(`{"id":"%s","code":"%s","name":"%s","pic":"%s","address":"%s","callback_url":"%s","ip_whitelist":%s,"status":"%s","secret_key":"%s"}`, TestPartnerID1, TestPartnerCode1, TestPartnerName1, TestPartnerPic1, TestPartnerAddress1, TestPartnerCallbackUrl1, string(b), TestPartnerStatus1, TestPartnerSecretKey1)
+	updDataNoVersion := fmt.Sprintf(`{"id":"%s","code":"%s","name":"%s","pic":"%s","address":"%s","callback_url":"%s","ip_whitelist":%s,"status":"%s","secret_key":"%s"}`, TestPartnerID1, TestPartnerCode1, TestPartnerName1, TestPartnerPic1, TestPartnerAddress1, TestPartnerCallbackUrl1, string(b), TestPartnerStatus1, TestPartnerSecretKey1)
+	updDataSuccess := strings.TrimSuffix(updDataNoVersion, "}") + `,"version":1}`
 	req := httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataSuccess))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
 	c.SetParamValues(TestPartnerID1)
-	service.On("UpdateData", mock.Anything).Return(nil).Once()
+	service.On("UpdateData", mock.MatchedBy(func(data partnerPort.PartnerService) bool {
+		return data.Version == 1
+	})).Return(nil).Once()
+	if assert.NoError(t, partner.UpdateData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get(etag.HeaderETag))
+	}
+
+	// 200 - version of If-Match
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataNoVersion))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(etag.HeaderIfMatch, `"4"`)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestPartnerID1)
+	service.On("UpdateData", mock.MatchedBy(func(data partnerPort.PartnerService) bool {
+		return data.Version == 4
+	})).Return(nil).Once()
 	if assert.NoError(t, partner.UpdateData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"5"`, rec.Header().Get(etag.HeaderETag))
 	}
 
 	// 400 empty ID
@@ -245,6 +283,43 @@ func TestUpdate(t *testing.T) {
 	if assert.NoError(t, partner.UpdateData(c)) {
 		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 	}
+
+	// 409 - updated since it was read
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataSuccess))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestPartnerID1)
+	service.On("UpdateData", mock.Anything).Return(apperror.NewConflict("Partner was updated since it was read, reload it and retry")).Once()
+	if assert.NoError(t, partner.UpdateData(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	// 428 - version missing
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataNoVersion))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestPartnerID1)
+	if assert.NoError(t, partner.UpdateData(c)) {
+		assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+		assert.Contains(t, rec.Body.String(), etag.ErrVersionRequired)
+	}
+
+	// 428 - If-Match not an ETag of the partner
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataNoVersion))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(etag.HeaderIfMatch, "*")
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestPartnerID1)
+	if assert.NoError(t, partner.UpdateData(c)) {
+		assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+		assert.Contains(t, rec.Body.String(), etag.ErrInvalidIfMatch)
+	}
 }
 
 func TestDelete(t *testing.T) {
@@ -291,6 +366,18 @@ func TestDelete(t *testing.T) {
 		assert.Contains(t, rec.Body.String(), partnerController.ErrPartnerNotFound)
 	}
 
+	// 409 partner still mapped
+	req = httptest.NewRequest(http.MethodDelete, endpoint, nil)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestPartnerID1)
+	service.On("DeleteData", mock.Anything).Return(apperror.NewConflict("Partner still mapped to issuers")).Once()
+	if assert.NoError(t, partner.DeleteData(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
 	// 422 error service
 	req = httptest.NewRequest(http.MethodDelete, endpoint, nil)
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
@@ -327,18 +414,19 @@ func TestList(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	service.On("ListData").Return(partners, nil).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"status": ""}}).Return(partners, query.Page{Total: 1, Page: 1, Limit: 20}, nil).Once()
 	if assert.NoError(t, partner.ListData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		var response map[string][]partnerController.ResponsePartner
+		var response partnerController.ResponseList
 		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
-			assert.Equal(t, TestPartnerID1, response["data"][0].ID)
-			assert.Equal(t, TestPartnerName1, response["data"][0].Name)
-			assert.Equal(t, TestPartnerPic1, response["data"][0].Pic)
-			assert.Equal(t, TestPartnerAddress1, response["data"][0].Address)
-			assert.Equal(t, TestPartnerCallbackUrl1, response["data"][0].CallbackUrl)
-			assert.Equal(t, TestPartnerIpwhitelist1, response["data"][0].IpWhitelist)
-			assert.Equal(t, TestPartnerStatus1, response["data"][0].Status)
+			assert.Equal(t, 1, response.Pagination.Total)
+			assert.Equal(t, TestPartnerID1, response.Data[0].ID)
+			assert.Equal(t, TestPartnerName1, response.Data[0].Name)
+			assert.Equal(t, TestPartnerPic1, response.Data[0].Pic)
+			assert.Equal(t, TestPartnerAddress1, response.Data[0].Address)
+			assert.Equal(t, TestPartnerCallbackUrl1, response.Data[0].CallbackUrl)
+			assert.Equal(t, TestPartnerIpwhitelist1, response.Data[0].IpWhitelist)
+			assert.Equal(t, TestPartnerStatus1, response.Data[0].Status)
 		}
 	}
 
@@ -347,12 +435,12 @@ func TestList(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec = httptest.NewRecorder()
 	c = e.NewContext(req, rec)
-	service.On("ListData").Return([]partnerPort.PartnerService{}, nil).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"status": ""}}).Return([]partnerPort.PartnerService{}, query.Page{Page: 1, Limit: 20}, nil).Once()
 	if assert.NoError(t, partner.ListData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		var response map[string][]partnerController.ResponsePartner
+		var response partnerController.ResponseList
 		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
-			assert.Equal(t, 0, len(response["data"]))
+			assert.Equal(t, 0, len(response.Data))
 		}
 	}
 
@@ -361,7 +449,7 @@ func TestList(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec = httptest.NewRecorder()
 	c = e.NewContext(req, rec)
-	service.On("ListData").Return([]partnerPort.PartnerService{}, errors.New("")).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"status": ""}}).Return([]partnerPort.PartnerService{}, query.Page{}, errors.New("")).Once()
 	if assert.NoError(t, partner.ListData(c)) {
 		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 	}
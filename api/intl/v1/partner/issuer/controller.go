@@ -6,7 +6,9 @@ import (
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/etag"
 	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/query"
 	"github.com/sepulsa/teleco/utils/validator"
 
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
@@ -14,7 +16,7 @@ import (
 
 var (
 	ErrRequiredID            = "ID can't be empty"
-	ErrPartnerIssuerNotFound = "PartnerIssuer not found"
+	ErrPartnerIssuerNotFound = "Partner Issuer not found"
 )
 
 type Controller struct {
@@ -37,6 +39,7 @@ func New(partnerIssuerService partnerIssuerPort.Service) *Controller {
 // @Param body body RequestPartnerIssuer true "please refer to partnerIssuer.RequestPartnerIssuer models below"
 // @Success 201
 // @Failure 400
+// @Failure 409
 // @Failure 422
 // @Router /partner/issuer [post]
 func (controller *Controller) CreateData(c echo.Context) error {
@@ -52,9 +55,12 @@ func (controller *Controller) CreateData(c echo.Context) error {
 		PartnerId: reqData.PartnerId,
 		IssuerId:  reqData.IssuerId,
 		Config:    reqData.Config,
+
+		ReservedThread: reqData.ReservedThread,
+		MaxThread:      reqData.MaxThread,
 	}
 	if err := controller.partnerIssuerService.CreateData(data); err != nil {
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 
 	return c.JSON(http.StatusCreated, "")
@@ -69,6 +75,7 @@ func (controller *Controller) CreateData(c echo.Context) error {
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "Partner Issuer Mapping ID"
 // @Success 200 {object} ResponsePartnerIssuer
+// @Header 200 {string} ETag "Version of the mapping, sent back as If-Match on update"
 // @Failure 400
 // @Failure 404
 // @Failure 422
@@ -91,8 +98,14 @@ func (controller *Controller) ReadData(c echo.Context) error {
 		PartnerId: data.PartnerId,
 		IssuerId:  data.IssuerId,
 		Config:    data.Config,
+
+		ReservedThread: data.ReservedThread,
+		MaxThread:      data.MaxThread,
+
+		Version: data.Version,
 	}
 
+	etag.Set(c, data.Version)
 	return c.JSON(http.StatusOK, partnerIssuer)
 }
 
@@ -104,11 +117,15 @@ func (controller *Controller) ReadData(c echo.Context) error {
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "Partner Issuer Mapping ID"
+// @Param If-Match header string false "ETag of the mapping read, instead of version"
 // @Param body body RequestPartnerIssuer true "please refer to partnerIssuer.RequestPartnerIssuer models below"
 // @Success 200
+// @Header 200 {string} ETag "Version of the updated mapping"
 // @Failure 400
 // @Failure 404
+// @Failure 409
 // @Failure 422
+// @Failure 428
 // @Router /partner/issuer/{id} [put]
 func (controller *Controller) UpdateData(c echo.Context) error {
 	id := c.Param("id")
@@ -123,20 +140,30 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 	if err := validator.GetValidator().Struct(reqData); err != nil {
 		return httperror.NewValidationError(c, http.StatusBadRequest, err)
 	}
+	version, err := etag.Version(c, reqData.Version)
+	if err != nil {
+		return c.JSON(http.StatusPreconditionRequired, echo.HTTPError{Message: err.Error()})
+	}
 
 	data := partnerIssuerPort.PartnerIssuerService{
 		ID:        id,
 		PartnerId: reqData.PartnerId,
 		IssuerId:  reqData.IssuerId,
 		Config:    reqData.Config,
+
+		ReservedThread: reqData.ReservedThread,
+		MaxThread:      reqData.MaxThread,
+
+		Version: version,
 	}
 	if err := controller.partnerIssuerService.UpdateData(data); err != nil {
 		if err.Error() == ErrPartnerIssuerNotFound {
 			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPartnerIssuerNotFound})
 		}
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 
+	etag.Set(c, version+1)
 	return c.JSON(http.StatusOK, "")
 }
 
@@ -151,6 +178,7 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 // @Success 200
 // @Failure 400
 // @Failure 404
+// @Failure 409
 // @Failure 422
 // @Router /partner/issuer/{id} [delete]
 func (controller *Controller) DeleteData(c echo.Context) error {
@@ -163,24 +191,52 @@ func (controller *Controller) DeleteData(c echo.Context) error {
 		if err.Error() == ErrPartnerIssuerNotFound {
 			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPartnerIssuerNotFound})
 		}
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 	return c.JSON(http.StatusOK, "")
 }
 
 // ListData godoc
 // @Summary List partner issuer mapping
-// @Description list partner issuer mapping
+// @Description list partner issuer mapping, a page with the total count
 // @Tags PartnerIssuerMapping
 // @Accept  json
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
-// @Success 200
+// @Param page query int false "Page, 1 by default"
+// @Param limit query int false "Page size, 20 by default, up to 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of page"
+// @Param sort query string false "Sort field, descending with a - prefix: created_at, updated_at"
+// @Param partner_id query string false "Partner ID"
+// @Param issuer_id query string false "Issuer ID"
+// @Success 200 {object} ResponseList
+// @Failure 400
 // @Failure 422
 // @Router /partner/issuer [get]
 func (controller *Controller) ListData(c echo.Context) error {
-	datas, err := controller.partnerIssuerService.ListData()
+	reqData := new(RequestList)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	datas, page, err := controller.partnerIssuerService.SearchData(query.Query{
+		Page:   reqData.Page,
+		Limit:  reqData.Limit,
+		Cursor: reqData.Cursor,
+		Sort:   reqData.Sort,
+		Filters: map[string]string{
+			"partner_id": reqData.PartnerId,
+			"issuer_id":  reqData.IssuerId,
+		},
+	})
 	if err != nil {
+		switch err.Error() {
+		case query.ErrInvalidCursor, query.ErrInvalidSort, query.ErrInvalidFilter:
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+		}
 		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
@@ -190,5 +246,5 @@ func (controller *Controller) ListData(c echo.Context) error {
 		json.Unmarshal(d, &partnerIssuers)
 	}
 
-	return c.JSON(http.StatusOK, map[string][]ResponsePartnerIssuer{"data": partnerIssuers})
+	return c.JSON(http.StatusOK, ResponseList{Data: partnerIssuers, Pagination: page})
 }
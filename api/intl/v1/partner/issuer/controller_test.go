@@ -13,6 +13,9 @@ import (
 	partnerIssuerController "github.com/sepulsa/teleco/api/intl/v1/partner/issuer"
 	partnerIssuerService "github.com/sepulsa/teleco/business/partner/issuer/mock"
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/net/etag"
+	"github.com/sepulsa/teleco/utils/query"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -44,6 +47,27 @@ func TestCreateData(t *testing.T) {
 		assert.Equal(t, http.StatusCreated, rec.Code)
 	}
 
+	// 409 pair already mapped
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("CreateData", mock.Anything).Return(apperror.NewConflict("Partner already mapped to the issuer")).Once()
+	if assert.NoError(t, partnerIssuer.CreateData(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	// 422 partner not found
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("CreateData", mock.Anything).Return(apperror.NewInvalid("Partner not found")).Once()
+	if assert.NoError(t, partnerIssuer.CreateData(c)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Partner not found")
+	}
+
 	// 400 bind
 	reqData = fmt.Sprintf(`{"issuer_id":"%s","config":"%s"}`, TestIssuerID, TestConfig)
 	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
@@ -79,6 +103,8 @@ func TestReadData(t *testing.T) {
 		PartnerId: TestPartnerID,
 		IssuerId:  TestIssuerID,
 		Config:    TestConfig,
+
+		Version: 3,
 	}
 
 	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
@@ -96,7 +122,9 @@ func TestReadData(t *testing.T) {
 			assert.Equal(t, TestPartnerID, response.PartnerId)
 			assert.Equal(t, TestIssuerID, response.IssuerId)
 			assert.Equal(t, TestConfig, response.Config)
+			assert.Equal(t, 3, response.Version)
 		}
+		assert.Equal(t, `"3"`, rec.Header().Get(etag.HeaderETag))
 	}
 
 	// 400 empty ID
@@ -145,16 +173,19 @@ func TestUpdateData(t *testing.T) {
 	endpoint := `/api/v1/partnerIssuer/`
 
 	// 200
-	updDataSuccess := fmt.Sprintf(`{"partner_id":"%s","issuer_id":"%s","config":"%s"}`, TestPartnerID, TestIssuerID, TestConfig)
+	updDataSuccess := fmt.Sprintf(`{"partner_id":"%s","issuer_id":"%s","config":"%s","version":3}`, TestPartnerID, TestIssuerID, TestConfig)
 	req := httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataSuccess))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 	c.SetParamNames("id")
 	c.SetParamValues(TestID)
-	service.On("UpdateData", mock.Anything).Return(nil).Once()
+	service.On("UpdateData", mock.MatchedBy(func(data partnerIssuerPort.PartnerIssuerService) bool {
+		return data.Version == 3
+	})).Return(nil).Once()
 	if assert.NoError(t, partnerIssuer.UpdateData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"4"`, rec.Header().Get(etag.HeaderETag))
 	}
 
 	// 400 empty ID
@@ -217,6 +248,31 @@ func TestUpdateData(t *testing.T) {
 	if assert.NoError(t, partnerIssuer.UpdateData(c)) {
 		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 	}
+
+	// 409 - updated since it was read
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updDataSuccess))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("UpdateData", mock.Anything).Return(apperror.NewConflict("Partner Issuer was updated since it was read, reload it and retry")).Once()
+	if assert.NoError(t, partnerIssuer.UpdateData(c)) {
+		assert.Equal(t, http.StatusConflict, rec.Code)
+	}
+
+	// 428 - version missing
+	updData = fmt.Sprintf(`{"partner_id":"%s","issuer_id":"%s","config":"%s"}`, TestPartnerID, TestIssuerID, TestConfig)
+	req = httptest.NewRequest(http.MethodPut, endpoint, strings.NewReader(updData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	if assert.NoError(t, partnerIssuer.UpdateData(c)) {
+		assert.Equal(t, http.StatusPreconditionRequired, rec.Code)
+		assert.Contains(t, rec.Body.String(), etag.ErrVersionRequired)
+	}
 }
 
 func TestDeleteData(t *testing.T) {
@@ -296,15 +352,16 @@ func TestListData(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
-	service.On("ListData").Return(partnerIssuers, nil).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"partner_id": "", "issuer_id": ""}}).Return(partnerIssuers, query.Page{Total: 1, Page: 1, Limit: 20}, nil).Once()
 	if assert.NoError(t, partnerIssuer.ListData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		var response map[string][]partnerIssuerController.ResponsePartnerIssuer
+		var response partnerIssuerController.ResponseList
 		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
-			assert.Equal(t, TestID, response["data"][0].ID)
-			assert.Equal(t, TestPartnerID, response["data"][0].PartnerId)
-			assert.Equal(t, TestIssuerID, response["data"][0].IssuerId)
-			assert.Equal(t, TestPartnerID, response["data"][0].PartnerId)
+			assert.Equal(t, 1, response.Pagination.Total)
+			assert.Equal(t, TestID, response.Data[0].ID)
+			assert.Equal(t, TestPartnerID, response.Data[0].PartnerId)
+			assert.Equal(t, TestIssuerID, response.Data[0].IssuerId)
+			assert.Equal(t, TestPartnerID, response.Data[0].PartnerId)
 		}
 	}
 
@@ -313,12 +370,12 @@ func TestListData(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec = httptest.NewRecorder()
 	c = e.NewContext(req, rec)
-	service.On("ListData").Return([]partnerIssuerPort.PartnerIssuerService{}, nil).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"partner_id": "", "issuer_id": ""}}).Return([]partnerIssuerPort.PartnerIssuerService{}, query.Page{Page: 1, Limit: 20}, nil).Once()
 	if assert.NoError(t, partnerIssuer.ListData(c)) {
 		assert.Equal(t, http.StatusOK, rec.Code)
-		var response map[string][]partnerIssuerController.ResponsePartnerIssuer
+		var response partnerIssuerController.ResponseList
 		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
-			assert.Equal(t, 0, len(response["data"]))
+			assert.Equal(t, 0, len(response.Data))
 		}
 	}
 
@@ -327,7 +384,7 @@ func TestListData(t *testing.T) {
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec = httptest.NewRecorder()
 	c = e.NewContext(req, rec)
-	service.On("ListData").Return([]partnerIssuerPort.PartnerIssuerService{}, errors.New("")).Once()
+	service.On("SearchData", query.Query{Filters: map[string]string{"partner_id": "", "issuer_id": ""}}).Return([]partnerIssuerPort.PartnerIssuerService{}, query.Page{}, errors.New("")).Once()
 	if assert.NoError(t, partnerIssuer.ListData(c)) {
 		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
 	}
@@ -4,4 +4,20 @@ type RequestPartnerIssuer struct {
 	PartnerId string `json:"partner_id" validate:"required"`
 	IssuerId  string `json:"issuer_id" validate:"required"`
 	Config    string `json:"config" validate:"required"`
+
+	ReservedThread int `json:"reserved_thread" validate:"gte=0"`
+	MaxThread      int `json:"max_thread" validate:"omitempty,gtefield=ReservedThread"`
+
+	// Version of the partner issuer mapping read, only on update and ignored when the If-Match header is set
+	Version *int `json:"version"`
+}
+
+// RequestList page and limit, or the next_cursor of the previous page
+type RequestList struct {
+	Page      int    `query:"page" validate:"gte=0"`
+	Limit     int    `query:"limit" validate:"gte=0,lte=100"`
+	Cursor    string `query:"cursor"`
+	Sort      string `query:"sort" validate:"omitempty,oneof=created_at -created_at updated_at -updated_at"`
+	PartnerId string `query:"partner_id"`
+	IssuerId  string `query:"issuer_id"`
 }
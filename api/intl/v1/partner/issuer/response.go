@@ -1,8 +1,20 @@
 package issuer
 
+import "github.com/sepulsa/teleco/utils/query"
+
 type ResponsePartnerIssuer struct {
 	ID        string `json:"id"`
 	PartnerId string `json:"partner_id"`
 	IssuerId  string `json:"issuer_id"`
 	Config    string `json:"config"`
+
+	ReservedThread int `json:"reserved_thread"`
+	MaxThread      int `json:"max_thread"`
+
+	Version int `json:"version"`
+}
+
+type ResponseList struct {
+	Data       []ResponsePartnerIssuer `json:"data"`
+	Pagination query.Page              `json:"pagination"`
 }
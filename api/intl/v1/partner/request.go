@@ -9,4 +9,25 @@ type RequestPartner struct {
 	IpWhitelist []string `json:"ip_whitelist"`
 	Status      string   `json:"status"`
 	SecretKey   string   `json:"secret_key"`
+
+	RateLimit       RequestRateLimit            `json:"rate_limit"`
+	IssuerRateLimit map[string]RequestRateLimit `json:"issuer_rate_limit" validate:"omitempty,dive,keys,required,endkeys"`
+
+	// Version of the partner read, only on update and ignored when the If-Match header is set
+	Version *int `json:"version"`
+}
+
+type RequestRateLimit struct {
+	Rate  float64 `json:"rate" validate:"gte=0"`
+	Burst int     `json:"burst" validate:"gte=0"`
+}
+
+// RequestList page and limit, or the next_cursor of the previous page
+type RequestList struct {
+	Page   int    `query:"page" validate:"gte=0"`
+	Limit  int    `query:"limit" validate:"gte=0,lte=100"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort" validate:"omitempty,oneof=code -code name -name status -status created_at -created_at updated_at -updated_at"`
+	Search string `query:"search"`
+	Status string `query:"status"`
 }
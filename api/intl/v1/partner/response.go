@@ -1,5 +1,7 @@
 package partner
 
+import "github.com/sepulsa/teleco/utils/query"
+
 type ResponsePartner struct {
 	ID          string   `json:"id"`
 	Code        string   `json:"code"`
@@ -10,4 +12,19 @@ type ResponsePartner struct {
 	IpWhitelist []string `json:"ip_whitelist"`
 	Status      string   `json:"status"`
 	SecretKey   string   `json:"secret_key"`
+
+	RateLimit       ResponseRateLimit            `json:"rate_limit"`
+	IssuerRateLimit map[string]ResponseRateLimit `json:"issuer_rate_limit"`
+
+	Version int `json:"version"`
+}
+
+type ResponseRateLimit struct {
+	Rate  float64 `json:"rate"`
+	Burst int     `json:"burst"`
+}
+
+type ResponseList struct {
+	Data       []ResponsePartner `json:"data"`
+	Pagination query.Page        `json:"pagination"`
 }
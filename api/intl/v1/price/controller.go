@@ -0,0 +1,244 @@
+package price
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+)
+
+var (
+	ErrRequiredID    = "ID can't be empty"
+	ErrRequiredFile  = "file is required"
+	ErrPriceNotFound = "Price not found"
+)
+
+type Controller struct {
+	priceService pricePort.Service
+}
+
+func New(priceService pricePort.Service) *Controller {
+	return &Controller{
+		priceService,
+	}
+}
+
+func toService(reqData RequestPrice) pricePort.PriceService {
+	return pricePort.PriceService{
+		PartnerCode:    reqData.PartnerCode,
+		ProductCode:    reqData.ProductCode,
+		BasePrice:      reqData.BasePrice,
+		SellingPrice:   reqData.SellingPrice,
+		Margin:         reqData.Margin,
+		MarginPercent:  reqData.MarginPercent,
+		Fee:            reqData.Fee,
+		EffectiveFrom:  reqData.EffectiveFrom,
+		EffectiveUntil: reqData.EffectiveUntil,
+	}
+}
+
+// CreateData godoc
+// @Summary Add a partner price
+// @Description add the price a partner pays for a product over an effective range
+// @Tags Price
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param body body RequestPrice true "please refer to price.RequestPrice models below"
+// @Success 201
+// @Failure 400
+// @Failure 422
+// @Router /price [post]
+func (controller *Controller) CreateData(c echo.Context) error {
+	reqData := new(RequestPrice)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	if err := controller.priceService.CreateData(toService(*reqData)); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, "")
+}
+
+// ReadData godoc
+// @Summary Get detail a partner price
+// @Description get detail a partner price
+// @Tags Price
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Price ID"
+// @Success 200 {object} ResponsePrice
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /price/{id} [get]
+func (controller *Controller) ReadData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	data, err := controller.priceService.ReadData(id)
+	if err != nil {
+		if err.Error() == ErrPriceNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPriceNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, ResponsePrice(data))
+}
+
+// UpdateData godoc
+// @Summary Update a partner price
+// @Description update a partner price
+// @Tags Price
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Price ID"
+// @Param body body RequestPrice true "please refer to price.RequestPrice models below"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /price/{id} [put]
+func (controller *Controller) UpdateData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	reqData := new(RequestPrice)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	data := toService(*reqData)
+	data.ID = id
+	if err := controller.priceService.UpdateData(data); err != nil {
+		if err.Error() == ErrPriceNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPriceNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
+}
+
+// DeleteData godoc
+// @Summary Remove a partner price
+// @Description remove a partner price
+// @Tags Price
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Price ID"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /price/{id} [delete]
+func (controller *Controller) DeleteData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	if err := controller.priceService.DeleteData(id); err != nil {
+		if err.Error() == ErrPriceNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPriceNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, "")
+}
+
+// ListData godoc
+// @Summary List partner prices
+// @Description list partner prices
+// @Tags Price
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Success 200
+// @Failure 422
+// @Router /price [get]
+func (controller *Controller) ListData(c echo.Context) error {
+	datas, err := controller.priceService.ListData()
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	prices := make([]ResponsePrice, 0)
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &prices)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ResponsePrice{"data": prices})
+}
+
+// ImportData godoc
+// @Summary Import partner prices
+// @Description import partner prices from a CSV file with the header partner_code,product_code,base_price,selling_price,margin,margin_percent,fee,effective_from,effective_until, nothing is imported when a row is rejected
+// @Tags Price
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param file formData file true "CSV file"
+// @Success 201 {object} ResponseImport
+// @Failure 400 {object} ResponseImport
+// @Failure 422 {object} ResponseImport
+// @Router /price/import [post]
+func (controller *Controller) ImportData(c echo.Context) error {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredFile})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	defer file.Close()
+
+	reqDatas, rowErrors, err := parseCSV(file)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if len(rowErrors) > 0 {
+		return c.JSON(http.StatusBadRequest, ResponseImport{Errors: rowErrors})
+	}
+
+	datas := make([]pricePort.PriceService, 0, len(reqDatas))
+	for _, reqData := range reqDatas {
+		datas = append(datas, toService(reqData))
+	}
+	importErrors, err := controller.priceService.ImportData(datas)
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	if len(importErrors) > 0 {
+		response := ResponseImport{Errors: make([]ResponseImportError, 0, len(importErrors))}
+		for _, importError := range importErrors {
+			response.Errors = append(response.Errors, ResponseImportError(importError))
+		}
+		return c.JSON(http.StatusUnprocessableEntity, response)
+	}
+
+	return c.JSON(http.StatusCreated, ResponseImport{Imported: len(datas), Errors: []ResponseImportError{}})
+}
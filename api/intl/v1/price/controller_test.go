@@ -0,0 +1,179 @@
+package price_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	priceController "github.com/sepulsa/teleco/api/intl/v1/price"
+	priceService "github.com/sepulsa/teleco/business/price/mock"
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	TestID          = "6138813fb95630b0b528b160"
+	TestPartnerCode = "partner001"
+	TestProductCode = "TSEL10"
+
+	ErrRequiredEffectiveFrom = "effective_from is required"
+)
+
+func newImportRequest(t *testing.T, content string) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile("file", "prices.csv")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	part.Write([]byte(content))
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, `/api/v1/price/import`, body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestCreateData(t *testing.T) {
+	e := echo.New()
+
+	service := priceService.New()
+	price := priceController.New(service)
+	endpoint := `/api/v1/price`
+
+	// 201
+	reqData := `{"partner_code":"partner001","product_code":"TSEL10","base_price":10000,"margin":500,"effective_from":"2021-01-01T00:00:00Z"}`
+	req := httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("CreateData", pricePort.PriceService{
+		PartnerCode:   TestPartnerCode,
+		ProductCode:   TestProductCode,
+		BasePrice:     10000,
+		Margin:        500,
+		EffectiveFrom: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}).Return(nil).Once()
+	if assert.NoError(t, price.CreateData(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	// 400 validate
+	reqData = `{"partner_code":"partner001","product_code":"TSEL10","base_price":10000}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, price.CreateData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), ErrRequiredEffectiveFrom)
+	}
+
+	// 422 err service
+	reqData = `{"partner_code":"partner001","product_code":"TSEL10","base_price":10000,"effective_from":"2021-01-01T00:00:00Z"}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("CreateData", mock.Anything).Return(errors.New("")).Once()
+	if assert.NoError(t, price.CreateData(c)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	}
+}
+
+func TestReadData(t *testing.T) {
+	e := echo.New()
+
+	service := priceService.New()
+	price := priceController.New(service)
+	endpoint := `/api/v1/price`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(pricePort.PriceService{ID: TestID, PartnerCode: TestPartnerCode, BasePrice: 10000}, nil).Once()
+	if assert.NoError(t, price.ReadData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response priceController.ResponsePrice
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, int64(10000), response.BasePrice)
+		}
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(pricePort.PriceService{}, errors.New(priceController.ErrPriceNotFound)).Once()
+	if assert.NoError(t, price.ReadData(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestImportData(t *testing.T) {
+	e := echo.New()
+
+	service := priceService.New()
+	price := priceController.New(service)
+
+	// 201
+	content := "partner_code,product_code,base_price,margin_percent,effective_from,effective_until\n" +
+		"partner001,TSEL10,10000,2.5,2021-01-01,2021-02-01\n" +
+		"partner001,TSEL10,10100,2.5,2021-02-01T00:00:00+07:00,\n"
+	rec := httptest.NewRecorder()
+	c := e.NewContext(newImportRequest(t, content), rec)
+	service.On("ImportData", mock.MatchedBy(func(prices []pricePort.PriceService) bool {
+		return len(prices) == 2 && prices[0].MarginPercent == 2.5 && prices[1].EffectiveUntil.IsZero()
+	})).Return([]pricePort.ImportError(nil), nil).Once()
+	if assert.NoError(t, price.ImportData(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"imported":2`)
+	}
+
+	// 400 malformed row
+	content = "partner_code,product_code,base_price,effective_from\n" +
+		"partner001,TSEL10,ten,2021-01-01\n" +
+		"partner001,TSEL10,10000,\n"
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newImportRequest(t, content), rec)
+	if assert.NoError(t, price.ImportData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var response priceController.ResponseImport
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) && assert.Len(t, response.Errors, 2) {
+			assert.Equal(t, 1, response.Errors[0].Row)
+			assert.Equal(t, "invalid base_price value", response.Errors[0].Message)
+			assert.Equal(t, ErrRequiredEffectiveFrom, response.Errors[1].Message)
+		}
+	}
+
+	// 400 missing column
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newImportRequest(t, "partner_code,base_price\n"), rec)
+	if assert.NoError(t, price.ImportData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "missing column product_code")
+	}
+
+	// 422 rows rejected by the service
+	content = "partner_code,product_code,effective_from\npartner001,TSEL10,2021-01-01\n"
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newImportRequest(t, content), rec)
+	service.On("ImportData", mock.Anything).Return([]pricePort.ImportError{{Row: 1, Message: "Partner not found"}}, nil).Once()
+	if assert.NoError(t, price.ImportData(c)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Contains(t, rec.Body.String(), "Partner not found")
+	}
+}
@@ -0,0 +1,138 @@
+package price
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+)
+
+// RequestPrice selling price wins over the margins, effective until is exclusive and may be omitted
+type RequestPrice struct {
+	PartnerCode    string    `json:"partner_code" validate:"required"`
+	ProductCode    string    `json:"product_code" validate:"required"`
+	BasePrice      int64     `json:"base_price" validate:"gte=0"`
+	SellingPrice   int64     `json:"selling_price" validate:"gte=0"`
+	Margin         int64     `json:"margin"`
+	MarginPercent  float64   `json:"margin_percent"`
+	Fee            int64     `json:"fee" validate:"gte=0"`
+	EffectiveFrom  time.Time `json:"effective_from" validate:"required"`
+	EffectiveUntil time.Time `json:"effective_until"`
+}
+
+var (
+	// CSVHeader columns of a price import, partner_code, product_code and effective_from are required
+	CSVHeader = []string{"partner_code", "product_code", "base_price", "selling_price", "margin", "margin_percent", "fee", "effective_from", "effective_until"}
+
+	ErrCSVEmpty         = "file is empty"
+	ErrCSVMissingColumn = "missing column %s"
+	ErrCSVInvalidValue  = "invalid %s value"
+)
+
+// parseCSV read prices of a CSV file with a header line, rows start at one after the header
+func parseCSV(r io.Reader) ([]RequestPrice, []ResponseImportError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New(ErrCSVEmpty)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	for _, name := range []string{"partner_code", "product_code", "effective_from"} {
+		if _, found := columns[name]; !found {
+			return nil, nil, fmt.Errorf(ErrCSVMissingColumn, name)
+		}
+	}
+
+	prices := make([]RequestPrice, 0)
+	rowErrors := make([]ResponseImportError, 0)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		price, err := parseRecord(record, columns)
+		if err == nil {
+			if errValidate := validator.GetValidator().Struct(price); errValidate != nil {
+				err = errors.New(httperror.ValidationMessage(errValidate))
+			}
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, ResponseImportError{Row: row, Message: err.Error()})
+			continue
+		}
+		prices = append(prices, price)
+	}
+
+	return prices, rowErrors, nil
+}
+
+func parseRecord(record []string, columns map[string]int) (price RequestPrice, err error) {
+	value := func(name string) string {
+		if i, found := columns[name]; found && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	parseInt := func(name string) int64 {
+		if err != nil || value(name) == "" {
+			return 0
+		}
+		n, errParse := strconv.ParseInt(value(name), 10, 64)
+		if errParse != nil {
+			err = fmt.Errorf(ErrCSVInvalidValue, name)
+		}
+		return n
+	}
+	parseTime := func(name string) time.Time {
+		if err != nil || value(name) == "" {
+			return time.Time{}
+		}
+		t, errParse := parseDate(value(name))
+		if errParse != nil {
+			err = fmt.Errorf(ErrCSVInvalidValue, name)
+		}
+		return t
+	}
+
+	price = RequestPrice{
+		PartnerCode:    value("partner_code"),
+		ProductCode:    value("product_code"),
+		BasePrice:      parseInt("base_price"),
+		SellingPrice:   parseInt("selling_price"),
+		Margin:         parseInt("margin"),
+		Fee:            parseInt("fee"),
+		EffectiveFrom:  parseTime("effective_from"),
+		EffectiveUntil: parseTime("effective_until"),
+	}
+	if err == nil && value("margin_percent") != "" {
+		if price.MarginPercent, err = strconv.ParseFloat(value("margin_percent"), 64); err != nil {
+			err = fmt.Errorf(ErrCSVInvalidValue, "margin_percent")
+		}
+	}
+	return
+}
+
+// parseDate accept RFC3339 or a plain date at midnight UTC
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
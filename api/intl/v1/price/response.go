@@ -0,0 +1,28 @@
+package price
+
+import "time"
+
+type ResponsePrice struct {
+	ID             string    `json:"id"`
+	PartnerCode    string    `json:"partner_code"`
+	ProductCode    string    `json:"product_code"`
+	BasePrice      int64     `json:"base_price"`
+	SellingPrice   int64     `json:"selling_price"`
+	Margin         int64     `json:"margin"`
+	MarginPercent  float64   `json:"margin_percent"`
+	Fee            int64     `json:"fee"`
+	EffectiveFrom  time.Time `json:"effective_from"`
+	EffectiveUntil time.Time `json:"effective_until"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+type ResponseImport struct {
+	Imported int                   `json:"imported"`
+	Errors   []ResponseImportError `json:"errors"`
+}
+
+type ResponseImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,224 @@
+package product
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	productPort "github.com/sepulsa/teleco/business/product/port"
+)
+
+var (
+	ErrRequiredID      = "ID can't be empty"
+	ErrProductNotFound = "Product not found"
+)
+
+type Controller struct {
+	productService productPort.Service
+}
+
+func New(productService productPort.Service) *Controller {
+	return &Controller{
+		productService,
+	}
+}
+
+func toIssuerProducts(issuers []RequestIssuerProduct) []productPort.IssuerProduct {
+	data := make([]productPort.IssuerProduct, 0, len(issuers))
+	for _, issuer := range issuers {
+		data = append(data, productPort.IssuerProduct(issuer))
+	}
+	return data
+}
+
+func toResponseIssuerProducts(issuers []productPort.IssuerProduct) []ResponseIssuerProduct {
+	data := make([]ResponseIssuerProduct, 0, len(issuers))
+	for _, issuer := range issuers {
+		data = append(data, ResponseIssuerProduct(issuer))
+	}
+	return data
+}
+
+// CreateData godoc
+// @Summary Add a product
+// @Description add a teleco product and its product id at every issuer serving it
+// @Tags Product
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param body body RequestProduct true "please refer to product.RequestProduct models below"
+// @Success 201
+// @Failure 400
+// @Failure 422
+// @Router /product [post]
+func (controller *Controller) CreateData(c echo.Context) error {
+	reqData := new(RequestProduct)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	data := productPort.ProductService{
+		Code:         reqData.Code,
+		Name:         reqData.Name,
+		Operator:     reqData.Operator,
+		Type:         reqData.Type,
+		Denomination: reqData.Denomination,
+		Active:       reqData.Active,
+		Issuers:      toIssuerProducts(reqData.Issuers),
+	}
+	if err := controller.productService.CreateData(data); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, "")
+}
+
+// ReadData godoc
+// @Summary Get detail a product
+// @Description get detail a product
+// @Tags Product
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Product ID"
+// @Success 200 {object} ResponseProduct
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /product/{id} [get]
+func (controller *Controller) ReadData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	data, err := controller.productService.ReadData(id)
+	if err != nil {
+		if err.Error() == ErrProductNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrProductNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	product := ResponseProduct{
+		ID:           data.ID,
+		Code:         data.Code,
+		Name:         data.Name,
+		Operator:     data.Operator,
+		Type:         data.Type,
+		Denomination: data.Denomination,
+		Active:       data.Active,
+		Issuers:      toResponseIssuerProducts(data.Issuers),
+		CreatedAt:    data.CreatedAt,
+		UpdatedAt:    data.UpdatedAt,
+	}
+
+	return c.JSON(http.StatusOK, product)
+}
+
+// UpdateData godoc
+// @Summary Update a product
+// @Description update a product
+// @Tags Product
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Product ID"
+// @Param body body RequestProduct true "please refer to product.RequestProduct models below"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /product/{id} [put]
+func (controller *Controller) UpdateData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	reqData := new(RequestProduct)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	data := productPort.ProductService{
+		ID:           id,
+		Code:         reqData.Code,
+		Name:         reqData.Name,
+		Operator:     reqData.Operator,
+		Type:         reqData.Type,
+		Denomination: reqData.Denomination,
+		Active:       reqData.Active,
+		Issuers:      toIssuerProducts(reqData.Issuers),
+	}
+	if err := controller.productService.UpdateData(data); err != nil {
+		if err.Error() == ErrProductNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrProductNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
+}
+
+// DeleteData godoc
+// @Summary Remove a product
+// @Description remove a product
+// @Tags Product
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Product ID"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /product/{id} [delete]
+func (controller *Controller) DeleteData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	if err := controller.productService.DeleteData(id); err != nil {
+		if err.Error() == ErrProductNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrProductNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, "")
+}
+
+// ListData godoc
+// @Summary List products
+// @Description list products
+// @Tags Product
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Success 200
+// @Failure 422
+// @Router /product [get]
+func (controller *Controller) ListData(c echo.Context) error {
+	datas, err := controller.productService.ListData()
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	products := make([]ResponseProduct, 0)
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &products)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ResponseProduct{"data": products})
+}
@@ -0,0 +1,134 @@
+package product_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	productController "github.com/sepulsa/teleco/api/intl/v1/product"
+	productService "github.com/sepulsa/teleco/business/product/mock"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	TestID   = "6138813fb95630b0b528b160"
+	TestCode = "TSEL10"
+
+	ErrOneofType = "type must be one of"
+)
+
+func TestCreateData(t *testing.T) {
+	e := echo.New()
+
+	service := productService.New()
+	product := productController.New(service)
+	endpoint := `/api/v1/product`
+
+	// 201
+	reqData := `{"code":"TSEL10","name":"Telkomsel 10.000","operator":"telkomsel","type":"pulsa","denomination":10000,"active":true,"issuers":[{"issuer_code":"dummy","issuer_product_id":"S10"}]}`
+	req := httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("CreateData", productPort.ProductService{
+		Code:         TestCode,
+		Name:         "Telkomsel 10.000",
+		Operator:     "telkomsel",
+		Type:         productPort.TypePulsa,
+		Denomination: 10000,
+		Active:       true,
+		Issuers:      []productPort.IssuerProduct{{IssuerCode: "dummy", IssuerProductId: "S10"}},
+	}).Return(nil).Once()
+	if assert.NoError(t, product.CreateData(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	// 400 validate
+	reqData = `{"code":"TSEL10","name":"Telkomsel 10.000","operator":"telkomsel","type":"voucher"}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, product.CreateData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), ErrOneofType)
+	}
+
+	// 422 err service
+	reqData = `{"code":"TSEL10","name":"Telkomsel 10.000","operator":"telkomsel","type":"pulsa"}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("CreateData", mock.Anything).Return(errors.New("")).Once()
+	if assert.NoError(t, product.CreateData(c)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	}
+}
+
+func TestReadData(t *testing.T) {
+	e := echo.New()
+
+	service := productService.New()
+	product := productController.New(service)
+	endpoint := `/api/v1/product`
+
+	// 200
+	dataService := productPort.ProductService{
+		ID:      TestID,
+		Code:    TestCode,
+		Issuers: []productPort.IssuerProduct{{IssuerCode: "dummy", IssuerProductId: "S10"}},
+	}
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(dataService, nil).Once()
+	if assert.NoError(t, product.ReadData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response productController.ResponseProduct
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, TestCode, response.Code)
+			assert.Equal(t, "S10", response.Issuers[0].IssuerProductId)
+		}
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(productPort.ProductService{}, errors.New(productController.ErrProductNotFound)).Once()
+	if assert.NoError(t, product.ReadData(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestListData(t *testing.T) {
+	e := echo.New()
+
+	service := productService.New()
+	product := productController.New(service)
+	endpoint := `/api/v1/product`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("ListData").Return([]productPort.ProductService{{ID: TestID, Code: TestCode}}, nil).Once()
+	if assert.NoError(t, product.ListData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response map[string][]productController.ResponseProduct
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, TestCode, response["data"][0].Code)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package product
+
+type RequestProduct struct {
+	Code         string                 `json:"code" validate:"required"`
+	Name         string                 `json:"name" validate:"required"`
+	Operator     string                 `json:"operator" validate:"required"`
+	Type         string                 `json:"type" validate:"required,oneof=pulsa data pln_token"`
+	Denomination int64                  `json:"denomination" validate:"gte=0"`
+	Active       bool                   `json:"active"`
+	Issuers      []RequestIssuerProduct `json:"issuers" validate:"dive"`
+}
+
+// RequestIssuerProduct product id of the teleco product at the issuer
+type RequestIssuerProduct struct {
+	IssuerCode      string `json:"issuer_code" validate:"required"`
+	IssuerProductId string `json:"issuer_product_id" validate:"required"`
+}
@@ -0,0 +1,21 @@
+package product
+
+import "time"
+
+type ResponseProduct struct {
+	ID           string                  `json:"id"`
+	Code         string                  `json:"code"`
+	Name         string                  `json:"name"`
+	Operator     string                  `json:"operator"`
+	Type         string                  `json:"type"`
+	Denomination int64                   `json:"denomination"`
+	Active       bool                    `json:"active"`
+	Issuers      []ResponseIssuerProduct `json:"issuers"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+}
+
+type ResponseIssuerProduct struct {
+	IssuerCode      string `json:"issuer_code"`
+	IssuerProductId string `json:"issuer_product_id"`
+}
@@ -0,0 +1,191 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+)
+
+var (
+	ErrRequiredID             = "ID can't be empty"
+	ErrRequiredFile           = "file is required"
+	ErrInvalidDate            = "invalid date value"
+	ErrIssuerNotFound         = "Issuer not found"
+	ErrReconciliationNotFound = "Reconciliation not found"
+	ErrInvalidSettlement      = "Invalid settlement file"
+)
+
+type Controller struct {
+	reconciliationService reconciliationPort.Service
+}
+
+func New(reconciliationService reconciliationPort.Service) *Controller {
+	return &Controller{
+		reconciliationService,
+	}
+}
+
+// Reconcile godoc
+// @Summary Reconcile an issuer settlement file
+// @Description match the rows of an issuer settlement file, read with the settlement mapping of the issuer, against the purchases of the day, nothing is stored when a row is rejected
+// @Tags Reconciliation
+// @Accept  multipart/form-data
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param issuer_code formData string true "Issuer code"
+// @Param date formData string true "Day of the purchases, 2006-01-02"
+// @Param file formData file true "Settlement CSV file"
+// @Success 201 {object} ResponseRun
+// @Failure 400 {object} ResponseRowErrors
+// @Failure 404
+// @Failure 422
+// @Router /reconciliation [post]
+func (controller *Controller) Reconcile(c echo.Context) error {
+	reqData := new(RequestReconcile)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+	date, err := time.ParseInLocation("2006-01-02", reqData.Date, time.Local)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrInvalidDate})
+	}
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredFile})
+	}
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	defer file.Close()
+
+	data, rowErrors, err := controller.reconciliationService.Reconcile(reqData.IssuerCode, date, fileHeader.Filename, file)
+	if err != nil {
+		switch {
+		case err.Error() == ErrIssuerNotFound:
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrIssuerNotFound})
+		case strings.HasPrefix(err.Error(), ErrInvalidSettlement):
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	if len(rowErrors) > 0 {
+		response := ResponseRowErrors{Errors: make([]ResponseRowError, 0, len(rowErrors))}
+		for _, rowError := range rowErrors {
+			response.Errors = append(response.Errors, ResponseRowError(rowError))
+		}
+		return c.JSON(http.StatusBadRequest, response)
+	}
+
+	return c.JSON(http.StatusCreated, ResponseRun(data))
+}
+
+// ReadRun godoc
+// @Summary Get a reconciliation
+// @Description get the summary of a reconciliation run
+// @Tags Reconciliation
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Reconciliation ID"
+// @Success 200 {object} ResponseRun
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /reconciliation/{id} [get]
+func (controller *Controller) ReadRun(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	data, err := controller.reconciliationService.ReadRun(id)
+	if err != nil {
+		if err.Error() == ErrReconciliationNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrReconciliationNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, ResponseRun(data))
+}
+
+// ListRuns godoc
+// @Summary List reconciliations
+// @Description list reconciliation runs, newest first
+// @Tags Reconciliation
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param issuer_code query string false "Issuer code"
+// @Success 200
+// @Failure 422
+// @Router /reconciliation [get]
+func (controller *Controller) ListRuns(c echo.Context) error {
+	datas, err := controller.reconciliationService.ListRuns(c.QueryParam("issuer_code"))
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	runs := make([]ResponseRun, 0)
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &runs)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ResponseRun{"data": runs})
+}
+
+// ListItems godoc
+// @Summary List reconciliation items
+// @Description list the rows and missing purchases of a reconciliation run
+// @Tags Reconciliation
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Reconciliation ID"
+// @Param class query string false "matched, missing_ours, missing_issuer, amount_mismatch, status_mismatch or duplicate"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /reconciliation/{id}/item [get]
+func (controller *Controller) ListItems(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+	reqData := new(RequestItems)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	datas, err := controller.reconciliationService.ListItems(id, reqData.Class)
+	if err != nil {
+		if err.Error() == ErrReconciliationNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrReconciliationNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	items := make([]ResponseItem, 0)
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &items)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ResponseItem{"data": items})
+}
@@ -0,0 +1,154 @@
+package reconciliation_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	reconciliationController "github.com/sepulsa/teleco/api/intl/v1/reconciliation"
+	reconciliationService "github.com/sepulsa/teleco/business/reconciliation/mock"
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	TestID         = "6138813fb95630b0b528b160"
+	TestIssuerCode = "dummy"
+)
+
+func newReconcileRequest(t *testing.T, fields map[string]string, content string) *http.Request {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+	for name, value := range fields {
+		writer.WriteField(name, value)
+	}
+	if content != "" {
+		part, err := writer.CreateFormFile("file", "settlement.csv")
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		part.Write([]byte(content))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, `/api/v1/reconciliation`, body)
+	req.Header.Set(echo.HeaderContentType, writer.FormDataContentType())
+	return req
+}
+
+func TestReconcile(t *testing.T) {
+	e := echo.New()
+
+	service := reconciliationService.New()
+	reconciliation := reconciliationController.New(service)
+	fields := map[string]string{"issuer_code": TestIssuerCode, "date": "2021-09-08"}
+	date := time.Date(2021, 9, 8, 0, 0, 0, 0, time.Local)
+
+	// 201
+	rec := httptest.NewRecorder()
+	c := e.NewContext(newReconcileRequest(t, fields, "IdTrx\ntrx1\n"), rec)
+	service.On("Reconcile", TestIssuerCode, date, "settlement.csv", mock.Anything).Return(reconciliationPort.RunService{ID: TestID, Rows: 1, Matched: 1}, []reconciliationPort.RowError{}, nil).Once()
+	if assert.NoError(t, reconciliation.Reconcile(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var response reconciliationController.ResponseRun
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, TestID, response.ID)
+			assert.Equal(t, 1, response.Matched)
+		}
+	}
+
+	// 400 row errors
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newReconcileRequest(t, fields, "IdTrx\n\n"), rec)
+	service.On("Reconcile", TestIssuerCode, date, "settlement.csv", mock.Anything).Return(reconciliationPort.RunService{}, []reconciliationPort.RowError{{Row: 1, Message: "transaction id is required"}}, nil).Once()
+	if assert.NoError(t, reconciliation.Reconcile(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		var response reconciliationController.ResponseRowErrors
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Len(t, response.Errors, 1)
+		}
+	}
+
+	// 400 invalid file
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newReconcileRequest(t, fields, "Other\n"), rec)
+	service.On("Reconcile", TestIssuerCode, date, "settlement.csv", mock.Anything).Return(reconciliationPort.RunService{}, []reconciliationPort.RowError{}, errors.New(reconciliationController.ErrInvalidSettlement+": no IdTrx column")).Once()
+	if assert.NoError(t, reconciliation.Reconcile(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 404
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newReconcileRequest(t, fields, "IdTrx\n"), rec)
+	service.On("Reconcile", TestIssuerCode, date, "settlement.csv", mock.Anything).Return(reconciliationPort.RunService{}, []reconciliationPort.RowError{}, errors.New(reconciliationController.ErrIssuerNotFound)).Once()
+	if assert.NoError(t, reconciliation.Reconcile(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+
+	// 400 invalid date
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newReconcileRequest(t, map[string]string{"issuer_code": TestIssuerCode, "date": "08-09-2021"}, "IdTrx\n"), rec)
+	if assert.NoError(t, reconciliation.Reconcile(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 400 file is required
+	rec = httptest.NewRecorder()
+	c = e.NewContext(newReconcileRequest(t, fields, ""), rec)
+	if assert.NoError(t, reconciliation.Reconcile(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	service.AssertExpectations(t)
+}
+
+func TestListItems(t *testing.T) {
+	e := echo.New()
+
+	service := reconciliationService.New()
+	reconciliation := reconciliationController.New(service)
+	endpoint := `/api/v1/reconciliation/:id/item`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint+"?class=missing_issuer", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ListItems", TestID, reconciliationPort.ClassMissingIssuer).Return([]reconciliationPort.ItemService{{Class: reconciliationPort.ClassMissingIssuer, TransactionId: "trx4"}}, nil).Once()
+	if assert.NoError(t, reconciliation.ListItems(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response map[string][]reconciliationController.ResponseItem
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) && assert.Len(t, response["data"], 1) {
+			assert.Equal(t, "trx4", response["data"][0].TransactionId)
+		}
+	}
+
+	// 400 unknown class
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?class=other", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	if assert.NoError(t, reconciliation.ListItems(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ListItems", TestID, "").Return([]reconciliationPort.ItemService{}, errors.New(reconciliationController.ErrReconciliationNotFound)).Once()
+	if assert.NoError(t, reconciliation.ListItems(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+	service.AssertExpectations(t)
+}
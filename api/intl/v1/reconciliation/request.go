@@ -0,0 +1,11 @@
+package reconciliation
+
+type RequestReconcile struct {
+	IssuerCode string `form:"issuer_code" validate:"required"`
+	// Date day of the purchases settled by the file, 2006-01-02
+	Date string `form:"date" validate:"required"`
+}
+
+type RequestItems struct {
+	Class string `query:"class" validate:"omitempty,oneof=matched missing_ours missing_issuer amount_mismatch status_mismatch duplicate"`
+}
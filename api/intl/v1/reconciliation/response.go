@@ -0,0 +1,39 @@
+package reconciliation
+
+import "time"
+
+type ResponseRun struct {
+	ID             string    `json:"id"`
+	IssuerCode     string    `json:"issuer_code"`
+	FileName       string    `json:"file_name"`
+	Date           time.Time `json:"date"`
+	Rows           int       `json:"rows"`
+	Matched        int       `json:"matched"`
+	MissingOurs    int       `json:"missing_ours"`
+	MissingIssuer  int       `json:"missing_issuer"`
+	AmountMismatch int       `json:"amount_mismatch"`
+	StatusMismatch int       `json:"status_mismatch"`
+	Duplicate      int       `json:"duplicate"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ResponseItem struct {
+	ID                  string `json:"id"`
+	Class               string `json:"class"`
+	Row                 int    `json:"row"`
+	TransactionId       string `json:"transaction_id"`
+	IssuerTransactionId string `json:"issuer_transaction_id"`
+	Amount              int64  `json:"amount"`
+	IssuerAmount        int64  `json:"issuer_amount"`
+	Status              string `json:"status"`
+	IssuerStatus        string `json:"issuer_status"`
+}
+
+type ResponseRowErrors struct {
+	Errors []ResponseRowError `json:"errors"`
+}
+
+type ResponseRowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
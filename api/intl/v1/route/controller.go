@@ -0,0 +1,210 @@
+package route
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	routePort "github.com/sepulsa/teleco/business/route/port"
+)
+
+var (
+	ErrRequiredID    = "ID can't be empty"
+	ErrRouteNotFound = "Route not found"
+)
+
+type Controller struct {
+	routeService routePort.Service
+}
+
+func New(routeService routePort.Service) *Controller {
+	return &Controller{
+		routeService,
+	}
+}
+
+func toCandidates(candidates []RequestCandidate) []routePort.Candidate {
+	data := make([]routePort.Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		data = append(data, routePort.Candidate(candidate))
+	}
+	return data
+}
+
+func toResponseCandidates(candidates []routePort.Candidate) []ResponseCandidate {
+	data := make([]ResponseCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		data = append(data, ResponseCandidate(candidate))
+	}
+	return data
+}
+
+// CreateData godoc
+// @Summary Add a product route
+// @Description add a product route, the issuer candidates serving a teleco product code
+// @Tags Route
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param body body RequestRoute true "please refer to route.RequestRoute models below"
+// @Success 201
+// @Failure 400
+// @Failure 422
+// @Router /route [post]
+func (controller *Controller) CreateData(c echo.Context) error {
+	reqData := new(RequestRoute)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	data := routePort.RouteService{
+		ProductCode:      reqData.ProductCode,
+		Candidates:       toCandidates(reqData.Candidates),
+		FailoverRescodes: reqData.FailoverRescodes,
+	}
+	if err := controller.routeService.CreateData(data); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, "")
+}
+
+// ReadData godoc
+// @Summary Get detail a product route
+// @Description get detail a product route
+// @Tags Route
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Route ID"
+// @Success 200 {object} ResponseRoute
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /route/{id} [get]
+func (controller *Controller) ReadData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	data, err := controller.routeService.ReadData(id)
+	if err != nil {
+		if err.Error() == ErrRouteNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrRouteNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	route := ResponseRoute{
+		ID:               data.ID,
+		ProductCode:      data.ProductCode,
+		Candidates:       toResponseCandidates(data.Candidates),
+		FailoverRescodes: data.FailoverRescodes,
+	}
+
+	return c.JSON(http.StatusOK, route)
+}
+
+// UpdateData godoc
+// @Summary Update a product route
+// @Description update a product route
+// @Tags Route
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Route ID"
+// @Param body body RequestRoute true "please refer to route.RequestRoute models below"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /route/{id} [put]
+func (controller *Controller) UpdateData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	reqData := new(RequestRoute)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	data := routePort.RouteService{
+		ID:               id,
+		ProductCode:      reqData.ProductCode,
+		Candidates:       toCandidates(reqData.Candidates),
+		FailoverRescodes: reqData.FailoverRescodes,
+	}
+	if err := controller.routeService.UpdateData(data); err != nil {
+		if err.Error() == ErrRouteNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrRouteNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
+}
+
+// DeleteData godoc
+// @Summary Remove a product route
+// @Description remove a product route
+// @Tags Route
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "Route ID"
+// @Success 200
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /route/{id} [delete]
+func (controller *Controller) DeleteData(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	if err := controller.routeService.DeleteData(id); err != nil {
+		if err.Error() == ErrRouteNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrRouteNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, "")
+}
+
+// ListData godoc
+// @Summary List product routes
+// @Description list product routes
+// @Tags Route
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Success 200
+// @Failure 422
+// @Router /route [get]
+func (controller *Controller) ListData(c echo.Context) error {
+	datas, err := controller.routeService.ListData()
+	if err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	routes := make([]ResponseRoute, 0)
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &routes)
+	}
+
+	return c.JSON(http.StatusOK, map[string][]ResponseRoute{"data": routes})
+}
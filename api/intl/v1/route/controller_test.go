@@ -0,0 +1,129 @@
+package route_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	routeController "github.com/sepulsa/teleco/api/intl/v1/route"
+	routeService "github.com/sepulsa/teleco/business/route/mock"
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	TestID          = "6138813fb95630b0b528b160"
+	TestProductCode = "TSEL10"
+
+	ErrRequiredCandidates = "candidates is required"
+)
+
+func TestCreateData(t *testing.T) {
+	e := echo.New()
+
+	service := routeService.New()
+	route := routeController.New(service)
+	endpoint := `/api/v1/route`
+
+	// 201
+	reqData := `{"product_code":"TSEL10","candidates":[{"issuer_code":"dummy","issuer_product_id":"S10","priority":1,"weight":2}]}`
+	req := httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("CreateData", routePort.RouteService{
+		ProductCode: TestProductCode,
+		Candidates:  []routePort.Candidate{{IssuerCode: "dummy", IssuerProductId: "S10", Priority: 1, Weight: 2}},
+	}).Return(nil).Once()
+	if assert.NoError(t, route.CreateData(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	}
+
+	// 400 validate
+	reqData = `{"product_code":"TSEL10"}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	if assert.NoError(t, route.CreateData(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), ErrRequiredCandidates)
+	}
+
+	// 422 err service
+	reqData = `{"product_code":"TSEL10","candidates":[{"issuer_code":"dummy","issuer_product_id":"S10"}]}`
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(reqData))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	service.On("CreateData", mock.Anything).Return(errors.New("")).Once()
+	if assert.NoError(t, route.CreateData(c)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	}
+}
+
+func TestReadData(t *testing.T) {
+	e := echo.New()
+
+	service := routeService.New()
+	route := routeController.New(service)
+	endpoint := `/api/v1/route`
+
+	// 200
+	dataService := routePort.RouteService{
+		ID:          TestID,
+		ProductCode: TestProductCode,
+		Candidates:  []routePort.Candidate{{IssuerCode: "dummy", IssuerProductId: "S10"}},
+	}
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(dataService, nil).Once()
+	if assert.NoError(t, route.ReadData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response routeController.ResponseRoute
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, TestProductCode, response.ProductCode)
+			assert.Equal(t, "dummy", response.Candidates[0].IssuerCode)
+		}
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(TestID)
+	service.On("ReadData", TestID).Return(routePort.RouteService{}, errors.New(routeController.ErrRouteNotFound)).Once()
+	if assert.NoError(t, route.ReadData(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestListData(t *testing.T) {
+	e := echo.New()
+
+	service := routeService.New()
+	route := routeController.New(service)
+	endpoint := `/api/v1/route`
+
+	// 200
+	req := httptest.NewRequest(http.MethodGet, endpoint, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	service.On("ListData").Return([]routePort.RouteService{{ID: TestID, ProductCode: TestProductCode}}, nil).Once()
+	if assert.NoError(t, route.ListData(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response map[string][]routeController.ResponseRoute
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, TestProductCode, response["data"][0].ProductCode)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package route
+
+type RequestRoute struct {
+	ProductCode      string             `json:"product_code" validate:"required"`
+	Candidates       []RequestCandidate `json:"candidates" validate:"required,min=1,dive"`
+	FailoverRescodes []string           `json:"failover_rescodes"`
+}
+
+// RequestCandidate lower priority is tried first, weight shares the orders between candidates of the same priority
+type RequestCandidate struct {
+	IssuerCode      string `json:"issuer_code" validate:"required"`
+	IssuerProductId string `json:"issuer_product_id" validate:"required"`
+	Priority        int    `json:"priority" validate:"gte=0"`
+	Weight          int    `json:"weight" validate:"gte=0"`
+}
@@ -0,0 +1,15 @@
+package route
+
+type ResponseRoute struct {
+	ID               string              `json:"id"`
+	ProductCode      string              `json:"product_code"`
+	Candidates       []ResponseCandidate `json:"candidates"`
+	FailoverRescodes []string            `json:"failover_rescodes"`
+}
+
+type ResponseCandidate struct {
+	IssuerCode      string `json:"issuer_code"`
+	IssuerProductId string `json:"issuer_product_id"`
+	Priority        int    `json:"priority"`
+	Weight          int    `json:"weight"`
+}
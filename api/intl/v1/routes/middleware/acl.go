@@ -1,7 +1,17 @@
 package middleware
 
 import (
+	"net/http"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
+
+	"github.com/sepulsa/teleco/utils/auth"
+)
+
+var (
+	ErrAdminRequired = "Only an admin can do this action"
 )
 
 //ACL is method for checking user permisson
@@ -12,3 +22,21 @@ func ACL(permission string) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// Admin allows the request only when the logged in user email is one of emails
+func Admin(emails []string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if token, ok := c.Get("user").(*jwt.Token); ok {
+				if claims, ok := token.Claims.(*auth.JWTClaims); ok {
+					for i := range emails {
+						if strings.EqualFold(emails[i], claims.Email) {
+							return next(c)
+						}
+					}
+				}
+			}
+			return c.JSON(http.StatusForbidden, echo.HTTPError{Message: ErrAdminRequired})
+		}
+	}
+}
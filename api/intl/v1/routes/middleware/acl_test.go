@@ -0,0 +1,49 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+
+	intlMiddleware "github.com/sepulsa/teleco/api/intl/v1/routes/middleware"
+	"github.com/sepulsa/teleco/utils/auth"
+)
+
+func TestAdmin(t *testing.T) {
+	e := echo.New()
+	admin := intlMiddleware.Admin([]string{"admin@teleco.id"})
+	handler := admin(func(c echo.Context) error {
+		return c.JSON(http.StatusOK, "")
+	})
+	context := func(email string) (echo.Context, *httptest.ResponseRecorder) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/", nil), rec)
+		if email != "" {
+			c.Set("user", &jwt.Token{Claims: &auth.JWTClaims{Email: email}})
+		}
+		return c, rec
+	}
+
+	// 200 admin, email case ignored
+	c, rec := context("Admin@teleco.id")
+	if assert.NoError(t, handler(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+	}
+
+	// 403 other user
+	c, rec = context("user@teleco.id")
+	if assert.NoError(t, handler(c)) {
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), intlMiddleware.ErrAdminRequired)
+	}
+
+	// 403 without token
+	c, rec = context("")
+	if assert.NoError(t, handler(c)) {
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+	}
+}
@@ -26,8 +26,10 @@ func AuthAPISkipper(c echo.Context) bool {
 		"/api/v1/auth/login",
 		"/api/v1/auth/refresh",
 		"/api/v1/auth/user",
+		"/api/v1/auth/password/reset",
 		"/api/v1/swagger",
 		"/health",
+		"/.well-known/jwks.json",
 	}
 
 	requestURI := c.Request().RequestURI
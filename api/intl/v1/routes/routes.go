@@ -5,36 +5,53 @@ import (
 
 	partnerController "github.com/sepulsa/teleco/api/intl/v1/partner"
 	partnerService "github.com/sepulsa/teleco/business/partner"
-	partnerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/partner"
 
 	partnerIssuerController "github.com/sepulsa/teleco/api/intl/v1/partner/issuer"
 	partnerIssuerService "github.com/sepulsa/teleco/business/partner/issuer"
-	partnerIssuerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/partner/issuer"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 	authController "github.com/sepulsa/teleco/api/intl/v1/auth"
 	authService "github.com/sepulsa/teleco/business/auth"
-	userTokenRepository "github.com/sepulsa/teleco/modules/repository/mongodb/usertoken"
+
+	depositController "github.com/sepulsa/teleco/api/intl/v1/deposit"
+	depositService "github.com/sepulsa/teleco/business/deposit"
 
 	issuerController "github.com/sepulsa/teleco/api/intl/v1/issuer"
+	issuerBalanceController "github.com/sepulsa/teleco/api/intl/v1/issuer/balance"
 	issuerService "github.com/sepulsa/teleco/business/issuer"
-	issuerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/issuer"
+	"github.com/sepulsa/teleco/modules/notifier"
+
+	priceController "github.com/sepulsa/teleco/api/intl/v1/price"
+	priceService "github.com/sepulsa/teleco/business/price"
+
+	orderController "github.com/sepulsa/teleco/api/intl/v1/order"
+	orderService "github.com/sepulsa/teleco/business/order"
+
+	reconciliationController "github.com/sepulsa/teleco/api/intl/v1/reconciliation"
+	reconciliationService "github.com/sepulsa/teleco/business/reconciliation"
+
+	statementController "github.com/sepulsa/teleco/api/intl/v1/statement"
+	statementService "github.com/sepulsa/teleco/business/statement"
+
+	productController "github.com/sepulsa/teleco/api/intl/v1/product"
+	productService "github.com/sepulsa/teleco/business/product"
+
+	routeController "github.com/sepulsa/teleco/api/intl/v1/route"
+	routeService "github.com/sepulsa/teleco/business/route"
 
 	userController "github.com/sepulsa/teleco/api/intl/v1/user"
 	userService "github.com/sepulsa/teleco/business/user"
-	userRepository "github.com/sepulsa/teleco/modules/repository/mongodb/user"
+	"github.com/sepulsa/teleco/modules/repository"
 
 	"github.com/sepulsa/teleco/utils/config"
 )
 
 func API(e *echo.Echo) {
-	db := config.Mgo
-
 	// User && Auth
-	userRepo := userRepository.New(db)
-	userTokenRepo := userTokenRepository.New(db)
-	userServ := userService.New(userRepo, userTokenRepo)
+	userRepo := repository.NewUser()
+	userTokenRepo := repository.NewUserToken()
+	userServ := userService.New(userRepo, userTokenRepo, notifier.NewPasswordReset(), config.GetPasswordPolicy(), config.GetPasswordResetExpired())
 	authServ := authService.New(userRepo, userTokenRepo, nil)
 
 	authMiddleware := intlMiddleware.NewAuth(authServ)
@@ -46,23 +63,34 @@ func API(e *echo.Echo) {
 	e.Use(middleware.JWTWithConfig(JWTCustomConfig))
 
 	authHandler := authController.New(authServ)
+	e.GET("/.well-known/jwks.json", authHandler.JWKS)
 	auth := e.Group("/api/v1/auth")
 	auth.POST("/user", authHandler.UserRegister)
 	auth.POST("/login", authHandler.UserLogin)
 	auth.POST("/refresh", authHandler.UserRefreshToken)
 	auth.POST("/logout", authHandler.UserLogout)
+	auth.GET("/session", authHandler.UserSessions)
+	auth.DELETE("/session/:token_id", authHandler.UserRevokeSession)
 
 	userHandler := userController.New(userServ)
+	auth.POST("/password", userHandler.ChangePassword)
+	auth.POST("/password/reset", userHandler.ConfirmResetPassword)
 	user := e.Group("/api/v1/user")
 	user.GET("", userHandler.ListData)
 	user.POST("", userHandler.CreateData)
 	user.GET("/:id", userHandler.ReadData)
 	user.PUT("/:id", userHandler.UpdateData)
 	user.DELETE("/:id", userHandler.DeleteData)
+	admin := intlMiddleware.Admin(config.GetAdminEmails())
+	user.DELETE("/:id/session", userHandler.RevokeSessions, admin)
+	user.POST("/:id/password/reset", userHandler.ResetPassword, admin)
 
 	// Issuer
-	issuerRepo := issuerRepository.New(db)
-	issuerServ := issuerService.New(issuerRepo)
+	issuerRepo := repository.NewIssuer()
+	partnerRepository := repository.NewPartner()
+	partnerIssuerRepository := repository.NewPartnerIssuer()
+	issuerCircuitRepo := repository.NewIssuerCircuit()
+	issuerServ := issuerService.New(issuerRepo, issuerCircuitRepo, partnerIssuerRepository)
 	issuerHandler := issuerController.New(issuerServ)
 	issuer := e.Group("/api/v1/issuer")
 	issuer.POST("", issuerHandler.CreateData)
@@ -70,10 +98,15 @@ func API(e *echo.Echo) {
 	issuer.PUT("/:id", issuerHandler.UpdateData)
 	issuer.DELETE("/:id", issuerHandler.DeleteData)
 	issuer.GET("", issuerHandler.ListData)
+	issuer.GET("/:id/circuit", issuerHandler.CircuitState)
+	issuer.PUT("/:id/circuit", issuerHandler.ForceOpenCircuit)
+	issuerBalanceServ := issuerService.NewBalance(issuerRepo, repository.NewIssuerBalance(), notifier.New())
+	issuerBalanceHandler := issuerBalanceController.New(issuerBalanceServ)
+	issuer.GET("/:id/balance", issuerBalanceHandler.ReadData)
+	issuer.PUT("/:id/balance", issuerBalanceHandler.UpdateData)
 
 	// Partner Mapping
-	partnerRepository := partnerRepository.New(db)
-	partnerService := partnerService.New(partnerRepository)
+	partnerService := partnerService.New(partnerRepository, partnerIssuerRepository)
 	partnerController := partnerController.New(partnerService)
 	partner := e.Group("/api/v1/partner")
 	partner.POST("", partnerController.CreateData)
@@ -83,8 +116,7 @@ func API(e *echo.Echo) {
 	partner.GET("", partnerController.ListData)
 
 	// Partner Issuer Mapping
-	partnerIssuerRepository := partnerIssuerRepository.New(db)
-	partnerIssuerService := partnerIssuerService.New(partnerIssuerRepository)
+	partnerIssuerService := partnerIssuerService.New(partnerIssuerRepository, partnerRepository, issuerRepo)
 	partnerIssuerController := partnerIssuerController.New(partnerIssuerService)
 	partnerIssuer := e.Group("/api/v1/partner/issuer")
 	partnerIssuer.POST("", partnerIssuerController.CreateData)
@@ -92,4 +124,66 @@ func API(e *echo.Echo) {
 	partnerIssuer.PUT("/:id", partnerIssuerController.UpdateData)
 	partnerIssuer.DELETE("/:id", partnerIssuerController.DeleteData)
 	partnerIssuer.GET("", partnerIssuerController.ListData)
+
+	// Product Route
+	routeRepo := repository.NewRoute()
+	routeServ := routeService.New(routeRepo)
+	routeHandler := routeController.New(routeServ)
+	route := e.Group("/api/v1/route")
+	route.POST("", routeHandler.CreateData)
+	route.GET("/:id", routeHandler.ReadData)
+	route.PUT("/:id", routeHandler.UpdateData)
+	route.DELETE("/:id", routeHandler.DeleteData)
+	route.GET("", routeHandler.ListData)
+
+	// Product
+	productRepo := repository.NewProduct()
+	productServ := productService.New(productRepo, issuerRepo, partnerRepository, partnerIssuerRepository, routeRepo)
+	productHandler := productController.New(productServ)
+	product := e.Group("/api/v1/product")
+	product.POST("", productHandler.CreateData)
+	product.GET("/:id", productHandler.ReadData)
+	product.PUT("/:id", productHandler.UpdateData)
+	product.DELETE("/:id", productHandler.DeleteData)
+	product.GET("", productHandler.ListData)
+
+	// Partner Price
+	priceServ := priceService.New(repository.NewPrice(), partnerRepository, productRepo)
+	priceHandler := priceController.New(priceServ)
+	price := e.Group("/api/v1/price")
+	price.POST("", priceHandler.CreateData)
+	price.POST("/import", priceHandler.ImportData)
+	price.GET("/:id", priceHandler.ReadData)
+	price.PUT("/:id", priceHandler.UpdateData)
+	price.DELETE("/:id", priceHandler.DeleteData)
+	price.GET("", priceHandler.ListData)
+
+	// Partner Deposit
+	depositServ := depositService.New(repository.NewDeposit(), partnerRepository)
+	depositHandler := depositController.New(depositServ)
+	deposit := e.Group("/api/v1/deposit")
+	deposit.POST("/topup", depositHandler.Topup, admin)
+	deposit.GET("/:partner_code", depositHandler.Balance)
+	deposit.GET("/:partner_code/ledger", depositHandler.Ledger)
+
+	// Order Log
+	orderHandler := orderController.New(orderService.NewQuery(repository.NewOrder(), partnerRepository, issuerRepo))
+	order := e.Group("/api/v1/order")
+	order.GET("", orderHandler.SearchData)
+	order.GET("/:id", orderHandler.ReadData)
+
+	// Issuer Reconciliation
+	reconciliationServ := reconciliationService.New(repository.NewReconciliation(), issuerRepo, repository.NewOrder())
+	reconciliationHandler := reconciliationController.New(reconciliationServ)
+	reconciliation := e.Group("/api/v1/reconciliation")
+	reconciliation.POST("", reconciliationHandler.Reconcile)
+	reconciliation.GET("", reconciliationHandler.ListRuns)
+	reconciliation.GET("/:id", reconciliationHandler.ReadRun)
+	reconciliation.GET("/:id/item", reconciliationHandler.ListItems)
+
+	// Partner Statement
+	statementHandler := statementController.New(statementService.New(repository.NewOrder(), partnerRepository, config.GetStatementDownloadUrl(), config.GetStatementLinkExpired()))
+	statement := e.Group("/api/v1/statement")
+	statement.GET("/:partner_code", statementHandler.Generate)
+	statement.POST("/:partner_code/link", statementHandler.SignLink)
 }
@@ -0,0 +1,148 @@
+package statement
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/validator"
+
+	statementPort "github.com/sepulsa/teleco/business/statement/port"
+)
+
+var (
+	ErrRequiredPartnerCode = "partner code can't be empty"
+	ErrInvalidDate         = "invalid %s value"
+	ErrPartnerNotFound     = "Partner not found"
+
+	DateLayout = "2006-01-02"
+)
+
+type Controller struct {
+	statementService statementPort.Service
+}
+
+func New(statementService statementPort.Service) *Controller {
+	return &Controller{
+		statementService,
+	}
+}
+
+// Generate godoc
+// @Summary Get the statement of a partner
+// @Description get the purchases of a partner between two days with their final status, price and serial number, and the totals by product and status
+// @Tags Statement
+// @Accept  json
+// @Produce  json,text/csv
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param partner_code path string true "Partner code"
+// @Param from query string true "First day, 2006-01-02"
+// @Param until query string true "Last day, 2006-01-02"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} ResponseStatement
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /statement/{partner_code} [get]
+func (controller *Controller) Generate(c echo.Context) error {
+	partnerCode := c.Param("partner_code")
+	if strings.TrimSpace(partnerCode) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredPartnerCode})
+	}
+	reqData := new(RequestStatement)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+	from, until, err := parseRange(reqData)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+
+	data, err := controller.statementService.Generate(partnerCode, from, until)
+	if err != nil {
+		if err.Error() == ErrPartnerNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPartnerNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	if reqData.Format == statementPort.FormatCSV {
+		filename := fmt.Sprintf("statement_%s_%s_%s.csv", data.PartnerCode, reqData.From, reqData.Until)
+		c.Response().Header().Set(echo.HeaderContentType, "text/csv")
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+		c.Response().WriteHeader(http.StatusOK)
+		return controller.statementService.WriteCSV(c.Response(), data)
+	}
+
+	var statement ResponseStatement
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &statement)
+
+	return c.JSON(http.StatusOK, statement)
+}
+
+// SignLink godoc
+// @Summary Create a statement download link
+// @Description create a link the partner can download its statement from on the external API without signing the request, the link is signed with the partner secret key
+// @Tags Statement
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param partner_code path string true "Partner code"
+// @Param body body RequestStatement true "please refer to statement.RequestStatement models below"
+// @Success 201 {object} ResponseLink
+// @Failure 400
+// @Failure 404
+// @Failure 422
+// @Router /statement/{partner_code}/link [post]
+func (controller *Controller) SignLink(c echo.Context) error {
+	partnerCode := c.Param("partner_code")
+	if strings.TrimSpace(partnerCode) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredPartnerCode})
+	}
+	reqData := new(RequestStatement)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+	from, until, err := parseRange(reqData)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if reqData.Format == "" {
+		reqData.Format = statementPort.FormatCSV
+	}
+
+	link, err := controller.statementService.SignLink(partnerCode, from, until, reqData.Format)
+	if err != nil {
+		if err.Error() == ErrPartnerNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrPartnerNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusCreated, ResponseLink(link))
+}
+
+// parseRange from and until days, until is returned as the start of the next day
+func parseRange(reqData *RequestStatement) (from time.Time, until time.Time, err error) {
+	if from, err = time.ParseInLocation(DateLayout, reqData.From, time.Local); err != nil {
+		err = fmt.Errorf(ErrInvalidDate, "from")
+		return
+	}
+	if until, err = time.ParseInLocation(DateLayout, reqData.Until, time.Local); err != nil {
+		err = fmt.Errorf(ErrInvalidDate, "until")
+		return
+	}
+	until = until.AddDate(0, 0, 1)
+	return
+}
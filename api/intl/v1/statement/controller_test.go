@@ -0,0 +1,135 @@
+package statement_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	statementController "github.com/sepulsa/teleco/api/intl/v1/statement"
+	statementService "github.com/sepulsa/teleco/business/statement/mock"
+	statementPort "github.com/sepulsa/teleco/business/statement/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	TestPartnerCode = "partner001"
+	TestFrom        = time.Date(2021, 9, 1, 0, 0, 0, 0, time.Local)
+	TestUntil       = time.Date(2021, 10, 1, 0, 0, 0, 0, time.Local)
+)
+
+func TestGenerate(t *testing.T) {
+	e := echo.New()
+
+	service := statementService.New()
+	statement := statementController.New(service)
+	endpoint := `/api/v1/statement/:partner_code`
+	data := statementPort.Statement{PartnerCode: TestPartnerCode, Count: 1, Amount: 11000, Lines: []statementPort.StatementLine{{TransactionId: "trx1"}}}
+
+	// 200 json
+	req := httptest.NewRequest(http.MethodGet, endpoint+"?from=2021-09-01&until=2021-09-30", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	service.On("Generate", TestPartnerCode, TestFrom, TestUntil).Return(data, nil).Once()
+	if assert.NoError(t, statement.Generate(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		var response statementController.ResponseStatement
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, int64(11000), response.Amount)
+			assert.Len(t, response.Lines, 1)
+		}
+	}
+
+	// 200 csv
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?from=2021-09-01&until=2021-09-30&format=csv", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	service.On("Generate", TestPartnerCode, TestFrom, TestUntil).Return(data, nil).Once()
+	service.On("WriteCSV", mock.Anything, data).Run(func(args mock.Arguments) {
+		io.WriteString(args.Get(0).(io.Writer), "transaction_id\ntrx1\n")
+	}).Return(nil).Once()
+	if assert.NoError(t, statement.Generate(c)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), "statement_partner001_2021-09-01_2021-09-30.csv")
+		assert.Equal(t, "transaction_id\ntrx1\n", rec.Body.String())
+	}
+
+	// 400 invalid date
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?from=2021-09-01&until=30-09-2021", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	if assert.NoError(t, statement.Generate(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 400 unknown format
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?from=2021-09-01&until=2021-09-30&format=xlsx", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	if assert.NoError(t, statement.Generate(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+
+	// 404
+	req = httptest.NewRequest(http.MethodGet, endpoint+"?from=2021-09-01&until=2021-09-30", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	service.On("Generate", TestPartnerCode, TestFrom, TestUntil).Return(statementPort.Statement{}, errors.New(statementController.ErrPartnerNotFound)).Once()
+	if assert.NoError(t, statement.Generate(c)) {
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+	service.AssertExpectations(t)
+}
+
+func TestSignLink(t *testing.T) {
+	e := echo.New()
+
+	service := statementService.New()
+	statement := statementController.New(service)
+	endpoint := `/api/v1/statement/:partner_code/link`
+
+	// 201, csv by default
+	req := httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(`{"from":"2021-09-01","until":"2021-09-30"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	service.On("SignLink", TestPartnerCode, TestFrom, TestUntil, statementPort.FormatCSV).Return(statementPort.Link{Url: "/api/v1/statement/download?signature=x"}, nil).Once()
+	if assert.NoError(t, statement.SignLink(c)) {
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		var response statementController.ResponseLink
+		if assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response)) {
+			assert.Equal(t, "/api/v1/statement/download?signature=x", response.Url)
+		}
+	}
+
+	// 400 from is required
+	req = httptest.NewRequest(http.MethodPost, endpoint, strings.NewReader(`{"until":"2021-09-30"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("partner_code")
+	c.SetParamValues(TestPartnerCode)
+	if assert.NoError(t, statement.SignLink(c)) {
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	service.AssertExpectations(t)
+}
@@ -0,0 +1,8 @@
+package statement
+
+// RequestStatement dates are 2006-01-02, until is inclusive
+type RequestStatement struct {
+	From   string `query:"from" json:"from" validate:"required"`
+	Until  string `query:"until" json:"until" validate:"required"`
+	Format string `query:"format" json:"format" validate:"omitempty,oneof=csv json"`
+}
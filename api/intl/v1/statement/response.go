@@ -0,0 +1,40 @@
+package statement
+
+import "time"
+
+type ResponseStatement struct {
+	PartnerCode string                   `json:"partner_code"`
+	From        time.Time                `json:"from"`
+	Until       time.Time                `json:"until"`
+	Lines       []ResponseStatementLine  `json:"lines"`
+	Totals      []ResponseStatementTotal `json:"totals"`
+	Count       int                      `json:"count"`
+	Amount      int64                    `json:"amount"`
+	GeneratedAt time.Time                `json:"generated_at"`
+}
+
+type ResponseStatementLine struct {
+	TransactionId       string    `json:"transaction_id"`
+	IssuerTransactionId string    `json:"issuer_transaction_id"`
+	ProductCode         string    `json:"product_code"`
+	IssuerProductId     string    `json:"issuer_product_id"`
+	CustomerNumber      string    `json:"customer_number"`
+	SerialNumber        string    `json:"serial_number"`
+	Status              string    `json:"status"`
+	SellingPrice        int64     `json:"selling_price"`
+	Fee                 int64     `json:"fee"`
+	Amount              int64     `json:"amount"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+type ResponseStatementTotal struct {
+	ProductCode string `json:"product_code"`
+	Status      string `json:"status"`
+	Count       int    `json:"count"`
+	Amount      int64  `json:"amount"`
+}
+
+type ResponseLink struct {
+	Url       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -4,10 +4,14 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/golang-jwt/jwt/v4"
 	"github.com/labstack/echo/v4"
 
 	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/auth"
+	"github.com/sepulsa/teleco/utils/net/etag"
 	"github.com/sepulsa/teleco/utils/net/httperror"
+	"github.com/sepulsa/teleco/utils/query"
 	"github.com/sepulsa/teleco/utils/validator"
 )
 
@@ -69,6 +73,7 @@ func (controller *Controller) CreateData(c echo.Context) error {
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "User ID"
 // @Success 200 {object} ResponseUser
+// @Header 200 {string} ETag "Version of the user, sent back as If-Match on update"
 // @Failure 400
 // @Failure 404
 // @Failure 422
@@ -91,7 +96,9 @@ func (controller *Controller) ReadData(c echo.Context) error {
 	user.ID = data.ID
 	user.Email = data.Email
 	user.Fullname = data.Fullname
+	user.Version = data.Version
 
+	etag.Set(c, data.Version)
 	return c.JSON(http.StatusOK, user)
 }
 
@@ -103,11 +110,15 @@ func (controller *Controller) ReadData(c echo.Context) error {
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
 // @Param id path string true "User ID"
+// @Param If-Match header string false "ETag of the user read, instead of version"
 // @Param body body RequestUser true "please refer to user.RequestUser models below"
 // @Success 200
+// @Header 200 {string} ETag "Version of the updated user"
 // @Failure 400
 // @Failure 404
+// @Failure 409
 // @Failure 422
+// @Failure 428
 // @Router /user/{id} [put]
 func (controller *Controller) UpdateData(c echo.Context) error {
 	id := c.Param("id")
@@ -122,20 +133,26 @@ func (controller *Controller) UpdateData(c echo.Context) error {
 	if err := validator.GetValidator().Struct(reqData); err != nil {
 		return httperror.NewValidationError(c, http.StatusBadRequest, err)
 	}
+	version, err := etag.Version(c, reqData.Version)
+	if err != nil {
+		return c.JSON(http.StatusPreconditionRequired, echo.HTTPError{Message: err.Error()})
+	}
 
 	var data userPort.UserService
 	data.ID = id
 	data.Email = reqData.Email
 	data.Password = reqData.Password
 	data.Fullname = reqData.Fullname
+	data.Version = version
 
 	if err := controller.userService.UpdateData(data); err != nil {
 		if err.Error() == ErrUserNotFound {
 			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrUserNotFound})
 		}
-		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+		return c.JSON(httperror.Status(err), echo.HTTPError{Message: err.Error()})
 	}
 
+	etag.Set(c, version+1)
 	return c.JSON(http.StatusOK, "")
 }
 
@@ -169,29 +186,170 @@ func (controller *Controller) DeleteData(c echo.Context) error {
 
 // ListData godoc
 // @Summary List users
-// @Description list users
+// @Description list users, a page with the total count
 // @Tags User
 // @Accept  json
 // @Produce  json
 // @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
-// @Success 200
+// @Param page query int false "Page, 1 by default"
+// @Param limit query int false "Page size, 20 by default, up to 100"
+// @Param cursor query string false "next_cursor of the previous page, instead of page"
+// @Param sort query string false "Sort field, descending with a - prefix: email, fullname, created_at, updated_at"
+// @Param search query string false "Text searched in email or fullname"
+// @Success 200 {object} ResponseList
+// @Failure 400
 // @Failure 422
 // @Router /user [get]
 func (controller *Controller) ListData(c echo.Context) error {
-	datas, err := controller.userService.ListData()
+	reqData := new(RequestList)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	datas, page, err := controller.userService.SearchData(query.Query{
+		Page:   reqData.Page,
+		Limit:  reqData.Limit,
+		Cursor: reqData.Cursor,
+		Sort:   reqData.Sort,
+		Search: reqData.Search,
+	})
 	if err != nil {
+		switch err.Error() {
+		case query.ErrInvalidCursor, query.ErrInvalidSort, query.ErrInvalidFilter:
+			return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+		}
 		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
-	var user ResponseUser
 	users := make([]ResponseUser, 0, len(datas))
 	for i := range datas {
-		user.ID = datas[i].ID
-		user.Email = datas[i].Email
-		user.Fullname = datas[i].Fullname
+		users = append(users, ResponseUser{
+			ID:       datas[i].ID,
+			Email:    datas[i].Email,
+			Fullname: datas[i].Fullname,
+			Version:  datas[i].Version,
+		})
+	}
+
+	return c.JSON(http.StatusOK, ResponseList{Data: users, Pagination: page})
+}
+
+// RevokeSessions godoc
+// @Summary Revoke user sessions
+// @Description revoke all active sessions of an user, admin only
+// @Tags User
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "User ID"
+// @Success 200
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Router /user/{id}/session [delete]
+func (controller *Controller) RevokeSessions(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	if err := controller.userService.RevokeSessions(id); err != nil {
+		if err.Error() == ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrUserNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+	return c.JSON(http.StatusOK, "")
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description change password of the logged in user, all sessions are revoked afterward
+// @Tags UserAuthentication
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param body body RequestChangePassword true "please refer to user.RequestChangePassword models below"
+// @Success 200
+// @Failure 400
+// @Failure 422
+// @Router /auth/password [post]
+func (controller *Controller) ChangePassword(c echo.Context) error {
+	reqData := new(RequestChangePassword)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
+
+	user := c.Get("user").(*jwt.Token)
+	tokenClaims := user.Claims.(*auth.JWTClaims)
+
+	if err := controller.userService.ChangePassword(tokenClaims.ID, reqData.OldPassword, reqData.NewPassword); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
+}
+
+// ResetPassword godoc
+// @Summary Reset user password
+// @Description mail a one-time password reset token to an user, admin only
+// @Tags User
+// @Accept  json
+// @Produce  json
+// @Param Authorization header string true "Authentication Bearer Token (JWT)" default(Bearer token)
+// @Param id path string true "User ID"
+// @Success 200
+// @Failure 400
+// @Failure 403
+// @Failure 404
+// @Failure 422
+// @Router /user/{id}/password/reset [post]
+func (controller *Controller) ResetPassword(c echo.Context) error {
+	id := c.Param("id")
+	if strings.TrimSpace(id) == "" {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: ErrRequiredID})
+	}
+
+	if err := controller.userService.ResetPassword(id); err != nil {
+		if err.Error() == ErrUserNotFound {
+			return c.JSON(http.StatusNotFound, echo.HTTPError{Message: ErrUserNotFound})
+		}
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
+	}
+
+	return c.JSON(http.StatusOK, "")
+}
+
+// ConfirmResetPassword godoc
+// @Summary Confirm password reset
+// @Description set new password using a one-time reset token
+// @Tags UserAuthentication
+// @Accept  json
+// @Produce  json
+// @Param body body RequestConfirmResetPassword true "please refer to user.RequestConfirmResetPassword models below"
+// @Success 200
+// @Failure 400
+// @Failure 422
+// @Router /auth/password/reset [post]
+func (controller *Controller) ConfirmResetPassword(c echo.Context) error {
+	reqData := new(RequestConfirmResetPassword)
+	if err := c.Bind(reqData); err != nil {
+		return c.JSON(http.StatusBadRequest, echo.HTTPError{Message: err.Error()})
+	}
+	if err := validator.GetValidator().Struct(reqData); err != nil {
+		return httperror.NewValidationError(c, http.StatusBadRequest, err)
+	}
 
-		users = append(users, user)
+	if err := controller.userService.ConfirmResetPassword(reqData.ResetToken, reqData.NewPassword); err != nil {
+		return c.JSON(http.StatusUnprocessableEntity, echo.HTTPError{Message: err.Error()})
 	}
 
-	return c.JSON(http.StatusOK, map[string][]ResponseUser{"data": users})
+	return c.JSON(http.StatusOK, "")
 }
@@ -2,6 +2,28 @@ package user
 
 type RequestUser struct {
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"omitempty,min=4,max=20,alphanum"`
+	Password string `json:"password" validate:"omitempty,max=72"`
 	Fullname string `json:"fullname" validate:"required"`
+
+	// Version of the user read, only on update and ignored when the If-Match header is set
+	Version *int `json:"version"`
+}
+
+type RequestChangePassword struct {
+	OldPassword string `json:"old_password" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,max=72"`
+}
+
+type RequestConfirmResetPassword struct {
+	ResetToken  string `json:"reset_token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,max=72"`
+}
+
+// RequestList page and limit, or the next_cursor of the previous page
+type RequestList struct {
+	Page   int    `query:"page" validate:"gte=0"`
+	Limit  int    `query:"limit" validate:"gte=0,lte=100"`
+	Cursor string `query:"cursor"`
+	Sort   string `query:"sort" validate:"omitempty,oneof=email -email fullname -fullname created_at -created_at updated_at -updated_at"`
+	Search string `query:"search"`
 }
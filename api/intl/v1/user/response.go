@@ -1,7 +1,15 @@
 package user
 
+import "github.com/sepulsa/teleco/utils/query"
+
 type ResponseUser struct {
 	ID       string `json:"id"`
 	Email    string `json:"email"`
 	Fullname string `json:"fullname"`
+	Version  int    `json:"version"`
+}
+
+type ResponseList struct {
+	Data       []ResponseUser `json:"data"`
+	Pagination query.Page     `json:"pagination"`
 }
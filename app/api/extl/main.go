@@ -5,10 +5,16 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/sepulsa/teleco/api/extl/v1/routes"
+	intlMiddleware "github.com/sepulsa/teleco/api/intl/v1/routes/middleware"
+	authService "github.com/sepulsa/teleco/business/auth"
+	"github.com/sepulsa/teleco/modules/repository"
+	"github.com/sepulsa/teleco/utils/config"
 	"github.com/sepulsa/teleco/utils/logger"
+	"github.com/sepulsa/teleco/utils/threadpool"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -35,6 +41,10 @@ import (
 // @BasePath /api/v1
 func main() {
 
+	repository.MigrateMongoDBOnStartup()
+	threadpool.CallTimeout = config.GetThreadPoolCallTimeout()
+	threadpool.Logger = logger.Logger
+
 	e := echo.New()
 	e.Pre(middleware.RemoveTrailingSlash())
 	e.Use(
@@ -71,6 +81,12 @@ func main() {
 		return c.NoContent(200)
 	})
 
+	// issuer threadpool stats of this instance, for the internal admins only since the pools live in this process
+	userAuth := intlMiddleware.NewAuth(authService.New(repository.NewUser(), repository.NewUserToken(), nil))
+	e.GET("/health/threadpool", func(c echo.Context) error {
+		return c.JSON(http.StatusOK, map[string][]threadpool.Stats{"data": threadpool.GetStats()})
+	}, middleware.JWTWithConfig(middleware.JWTConfig{ParseTokenFunc: userAuth.CustomParseToken}), intlMiddleware.Admin(config.GetAdminEmails()))
+
 	// swagger
 	e.GET("/api/v1/swagger/*", echoSwagger.EchoWrapHandler(func(c *echoSwagger.Config) { c.URL = "./doc.json" }))
 
@@ -81,16 +97,21 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 10 seconds.
-	quit := make(chan os.Signal)
-	signal.Notify(quit, os.Interrupt)
+	// Wait for interrupt signal to gracefully shutdown the server, new requests are
+	// refused while in-flight requests get the drain timeout to finish.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.GetShutdownDrainTimeout())*time.Second)
 	defer cancel()
 	if err := e.Shutdown(ctx); err != nil {
-		e.Logger.Fatal(err)
+		logger.Error().Msgf("[ERROR] -- Shutdown server: %s", err.Error())
+	}
+	// issuer calls still running in the threadpool store their order log and callback
+	if err := threadpool.Drain(ctx); err != nil {
+		logger.Error().Msgf("[ERROR] -- Drain threadpool: %s", err.Error())
 	}
+	logger.Close()
 }
 
 // ServiceRequestTime middleware adds a `Server` header to the response.
@@ -5,10 +5,14 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/sepulsa/teleco/api/intl/v1/routes"
+	"github.com/sepulsa/teleco/modules/repository"
+	"github.com/sepulsa/teleco/utils/config"
 	"github.com/sepulsa/teleco/utils/logger"
+	"github.com/sepulsa/teleco/utils/net/etag"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
@@ -35,6 +39,8 @@ import (
 // @BasePath /api/v1
 func main() {
 
+	repository.MigrateMongoDBOnStartup()
+
 	e := echo.New()
 	e.Pre(middleware.RemoveTrailingSlash())
 	e.Use(
@@ -47,7 +53,8 @@ func main() {
 		e.DefaultHTTPErrorHandler(err, c)
 	}
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowHeaders: []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowHeaders:  []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, etag.HeaderIfMatch},
+		ExposeHeaders: []string{etag.HeaderETag},
 	}))
 	// Handler for hooking any request in routers registered and log it
 	e.Use(middleware.BodyDumpWithConfig(middleware.BodyDumpConfig{
@@ -81,17 +88,17 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 10 seconds.
-	quit := make(chan os.Signal)
-	signal.Notify(quit, os.Interrupt)
+	// Wait for interrupt signal to gracefully shutdown the server, new requests are
+	// refused while in-flight requests get the drain timeout to finish.
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
 	<-quit
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.GetShutdownDrainTimeout())*time.Second)
 	defer cancel()
 	if err := e.Shutdown(ctx); err != nil {
-		e.Logger.Fatal(err)
+		logger.Error().Msgf("[ERROR] -- Shutdown server: %s", err.Error())
 	}
-
+	logger.Close()
 }
 
 // ServiceRequestTime middleware adds a `Server` header to the response.
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sepulsa/teleco/modules/repository"
+)
+
+// main applies the MongoDB indexes and backfills not applied yet, the applied versions are kept in schema_migrations
+func main() {
+	if err := repository.MigrateMongoDB(); err != nil {
+		fmt.Println("Migration failed:", err)
+		os.Exit(1)
+	}
+	fmt.Println("Migrations applied")
+}
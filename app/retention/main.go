@@ -0,0 +1,30 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	orderService "github.com/sepulsa/teleco/business/order"
+	"github.com/sepulsa/teleco/modules/archive"
+	"github.com/sepulsa/teleco/modules/repository"
+	"github.com/sepulsa/teleco/utils/config"
+)
+
+// main archive then remove the order records older than database.mongo.max_age and print the report,
+// -dry-run only counts them
+func main() {
+	dryRun := flag.Bool("dry-run", false, "count the records past the max age without archiving nor removing them")
+	flag.Parse()
+
+	retentionServ := orderService.NewRetention(repository.NewOrder(), archive.New(), config.GetMongoMaxAge())
+	report, err := retentionServ.Run(*dryRun)
+
+	b, _ := json.MarshalIndent(report, "", "  ")
+	fmt.Println(string(b))
+	if err != nil {
+		fmt.Println("Retention failed:", err)
+		os.Exit(1)
+	}
+}
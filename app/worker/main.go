@@ -1,33 +1,177 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
+	"time"
 
+	depositService "github.com/sepulsa/teleco/business/deposit"
 	issuerService "github.com/sepulsa/teleco/business/issuer"
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	orderService "github.com/sepulsa/teleco/business/order"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/sepulsa/teleco/modules/archive"
+	"github.com/sepulsa/teleco/modules/issuerapi"
 	"github.com/sepulsa/teleco/modules/issuerapi/task"
-	issuerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/issuer"
+	"github.com/sepulsa/teleco/modules/notifier"
+	"github.com/sepulsa/teleco/modules/repository"
 	"github.com/sepulsa/teleco/utils/config"
+	log "github.com/sepulsa/teleco/utils/logger"
 	"github.com/sepulsa/teleco/utils/queue/consumer"
+	"github.com/sepulsa/teleco/utils/threadpool"
+)
+
+var (
+	packageLog = "teleco/app/worker"
+
+	// retaining set while a retention run is in progress, a tick during a long run is skipped
+	retaining int32
 )
 
 func main() {
 
-	db := config.Mgo
-	issuerRepo := issuerRepository.New(db)
-	issuerServ := issuerService.New(issuerRepo)
-	issuerList, _ := issuerServ.ListData()
+	repository.MigrateMongoDBOnStartup()
+	threadpool.CallTimeout = config.GetThreadPoolCallTimeout()
+	threadpool.Logger = log.Logger
 
-	for _, issuer := range issuerList {
-		consumer.Consumer.StartWorker("teleco_"+issuer.Code, issuer.QueueWorkerLimit, &task.WorkerTask{})
+	issuerRepo := repository.NewIssuer()
+	issuerCircuitRepo := repository.NewIssuerCircuit()
+	issuerServ := issuerService.New(issuerRepo, issuerCircuitRepo, repository.NewPartnerIssuer())
+	depositServ := depositService.New(repository.NewDeposit(), repository.NewPartner())
+	issuerBalanceServ := issuerService.NewBalance(issuerRepo, repository.NewIssuerBalance(), notifier.New())
+	// queued orders go through the same circuit breaker as the API ones
+	workerTask := &task.WorkerTask{
+		IssuerApi:      issuerapi.New(issuerCircuitRepo, depositServ, issuerBalanceServ),
+		DepositService: depositServ,
 	}
 
+	reconcile(issuerServ, workerTask)
+
 	fmt.Println("Worker Started")
 
+	// issuers added, changed or disabled through the internal API are picked up on the next tick,
+	// SIGHUP forces an immediate sync
+	ticker := time.NewTicker(time.Duration(config.GetWorkerReconcileInterval()) * time.Second)
+	defer ticker.Stop()
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+
+	// issuer balances are also kept from purchase responses, the inquiry only runs when configured
+	var inquiry <-chan time.Time
+	if interval := config.GetWorkerBalanceInquiryInterval(); interval > 0 {
+		inquiryTicker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer inquiryTicker.Stop()
+		inquiry = inquiryTicker.C
+	}
+
+	// order records past database.mongo.max_age are archived then removed, only when configured
+	var retention <-chan time.Time
+	retentionServ := orderService.NewRetention(repository.NewOrder(), archive.New(), config.GetMongoMaxAge())
+	if interval := config.GetWorkerRetentionInterval(); interval > 0 {
+		retentionTicker := time.NewTicker(time.Duration(interval) * time.Second)
+		defer retentionTicker.Stop()
+		retention = retentionTicker.C
+	}
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 	// When you push CTRL+C close worker gracefully
-	<-sig
+	for running := true; running; {
+		select {
+		case <-ticker.C:
+			reconcile(issuerServ, workerTask)
+		case <-reload:
+			reconcile(issuerServ, workerTask)
+		case <-inquiry:
+			go inquiryBalance(issuerServ, issuerBalanceServ)
+		case <-retention:
+			go retain(retentionServ)
+		case <-sig:
+			running = false
+		}
+	}
+	shutdown()
+}
+
+// shutdown stop consuming and wait, bounded by the drain timeout, for in-flight messages,
+// unacknowledged messages go back to their queue
+func shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.GetShutdownDrainTimeout())*time.Second)
+	defer cancel()
+	if err := consumer.Consumer.Shutdown(ctx); err != nil {
+		log.Error().Str("event", "shutdown.error").Str("package", packageLog).Msgf("Error Stop Consumer: %s", err.Error())
+	}
+	// issuer calls still running in the threadpool settle their hold and send their callback
+	if err := threadpool.Drain(ctx); err != nil {
+		log.Error().Str("event", "shutdown.error").Str("package", packageLog).Msgf("Error Drain Threadpool: %s", err.Error())
+	}
+	fmt.Println("Worker Stopped")
+	log.Close()
+}
+
+// reconcile run one consumer per active issuer with its queue worker limit,
+// running consumers are left untouched when the issuer list can not be read
+func reconcile(issuerServ issuerPort.Service, workerTask consumer.Runnable) {
+	issuerList, err := issuerServ.ListData()
+	if err != nil {
+		log.Error().Str("event", "reconcile.error").Str("package", packageLog).Msgf("Error List Issuer: %s", err.Error())
+		return
+	}
+
+	desired := make(map[string]int)
+	for _, issuer := range issuerList {
+		if issuer.Status == issuerPort.StatusInactive {
+			continue
+		}
+		desired["teleco_"+issuer.Code] = issuer.QueueWorkerLimit
+	}
+	consumer.Consumer.Reconcile(desired, workerTask)
+}
+
+// inquiryBalance ask every active issuer its balance, a failed inquiry keeps the last known balance
+func inquiryBalance(issuerServ issuerPort.Service, balanceServ issuerPort.BalanceService) {
+	issuerList, err := issuerServ.ListData()
+	if err != nil {
+		log.Error().Str("event", "inquiry.error").Str("package", packageLog).Msgf("Error List Issuer: %s", err.Error())
+		return
+	}
+
+	for _, issuer := range issuerList {
+		if issuer.Status == issuerPort.StatusInactive {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(config.GetWorkerBalanceInquiryInterval())*time.Second)
+		if err := task.InquiryBalance(ctx, balanceServ, issuer); err != nil {
+			log.Error().Str("event", "inquiry.error").Str("package", packageLog).Msgf("Error Inquiry Balance %s: %s", issuer.Code, err.Error())
+		}
+		cancel()
+	}
+}
+
+// retain archive the order records past the max age and log the report of the run
+func retain(retentionServ orderPort.RetentionService) {
+	if !atomic.CompareAndSwapInt32(&retaining, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&retaining, 0)
+
+	report, err := retentionServ.Run(false)
+	if err != nil {
+		log.Error().Str("event", "retention.error").Str("package", packageLog).Msgf("Error Order Retention after %d archived: %s", report.Archived, err.Error())
+		return
+	}
+	log.Info().
+		Str("event", "retention.report").
+		Str("package", packageLog).
+		Time("cutoff", report.Cutoff).
+		Int("matched", report.Matched).
+		Int("archived", report.Archived).
+		Int("deleted", report.Deleted).
+		Str("location", report.Location).
+		Dur("elapsed", report.FinishedAt.Sub(report.StartedAt)).
+		Msg("Order Retention")
 }
@@ -43,3 +43,18 @@ func (s *service) UserRefreshToken(refreshTokenString string) (authPort.UserAuth
 	result := s.Called(refreshTokenString)
 	return result.Get(0).(authPort.UserAuthService), result.Error(1)
 }
+
+func (s *service) UserSessions(tokenID string) ([]authPort.UserSession, error) {
+	result := s.Called(tokenID)
+	return result.Get(0).([]authPort.UserSession), result.Error(1)
+}
+
+func (s *service) UserRevokeSession(tokenID string, sessionTokenID string) error {
+	result := s.Called(tokenID, sessionTokenID)
+	return result.Error(0)
+}
+
+func (s *service) PublicKeys() []authPort.PublicKey {
+	result := s.Called()
+	return result.Get(0).([]authPort.PublicKey)
+}
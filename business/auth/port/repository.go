@@ -1,9 +1,16 @@
 package port
 
+import "time"
+
 type (
 	UserTokenRepo struct {
-		UserID  string `json:"user_id"`
-		TokenID string `json:"token_id"`
+		UserID     string    `json:"user_id"`
+		TokenID    string    `json:"token_id"`
+		IPAddress  string    `json:"ip_address"`
+		UserAgent  string    `json:"user_agent"`
+		LastUsedAt time.Time `json:"last_used_at"`
+		ExpiredAt  time.Time `json:"expired_at"`
+		CreatedAt  time.Time `json:"created_at"`
 	}
 )
 
@@ -12,9 +19,15 @@ type Repository interface {
 	// FindByCode find issuer by code
 	FindByTokenID(tokenID string) UserTokenRepo
 
+	// ListDataByUserID get active tokens owned by userID
+	ListDataByUserID(userID string) ([]UserTokenRepo, error)
+
 	// CreateData insert new data
 	CreateData(userToken UserTokenRepo) error
 
+	// UpdateLastUsed mark token as used now
+	UpdateLastUsed(tokenID string) error
+
 	// DeleteData revoke user token
 	DeleteData(tokenID string) error
 
@@ -1,5 +1,7 @@
 package port
 
+import "time"
+
 type (
 	Signature struct {
 		Payload     []byte
@@ -17,6 +19,30 @@ type (
 		KeepLogin    bool
 		Token        string
 		RefreshToken string
+		IPAddress    string
+		UserAgent    string
+	}
+
+	UserSession struct {
+		TokenID    string
+		IPAddress  string
+		UserAgent  string
+		Current    bool
+		CreatedAt  time.Time
+		LastUsedAt time.Time
+		ExpiredAt  time.Time
+	}
+
+	PublicKey struct {
+		KeyType   string
+		KeyID     string
+		Use       string
+		Algorithm string
+		N         string
+		E         string
+		Curve     string
+		X         string
+		Y         string
 	}
 )
 
@@ -39,4 +65,13 @@ type Service interface {
 
 	// UserRefreshToken refresh jwt
 	UserRefreshToken(refreshTokenString string) (UserAuthService, error)
+
+	// UserSessions list active sessions owned by the user of tokenID
+	UserSessions(tokenID string) ([]UserSession, error)
+
+	// UserRevokeSession revoke one of the sessions owned by the user of tokenID
+	UserRevokeSession(tokenID string, sessionTokenID string) error
+
+	// PublicKeys list public keys accepted for user token verification
+	PublicKeys() []PublicKey
 }
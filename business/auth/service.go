@@ -3,11 +3,13 @@ package auth
 import (
 	"errors"
 	"strings"
+	"time"
 
 	authPort "github.com/sepulsa/teleco/business/auth/port"
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
 	userPort "github.com/sepulsa/teleco/business/user/port"
 	"github.com/sepulsa/teleco/utils/auth"
+	"github.com/sepulsa/teleco/utils/config"
 	"github.com/sepulsa/teleco/utils/crypto"
 	"github.com/sepulsa/teleco/utils/helper"
 	"github.com/sepulsa/teleco/utils/validator"
@@ -30,12 +32,19 @@ func New(uRepo userPort.Repository, uTokenRepo authPort.Repository, pRepo partne
 }
 
 var (
-	jwt                     auth.JWT = auth.NewJWT()
+	jwt                     auth.JWT = auth.NewJWT(config.GetJWTConfig())
 	ErrUserEmailDuplicate   error    = errors.New("email already in use")
 	ErrGenerateToken        error    = errors.New("generate token failed")
 	ErrInvalidToken         error    = errors.New("invalid token")
 	ErrInvalidCredential    error    = errors.New("invalid credential")
 	ErrUserGeneratePassword error    = errors.New("generate password failed")
+	ErrSessionNotFound      error    = errors.New("session not found")
+
+	PasswordPolicy = config.GetPasswordPolicy()
+
+	// LastUsedInterval throttles last used updates so verifying a token
+	// does not write to the database on every request
+	LastUsedInterval = time.Minute
 )
 
 func (s *service) VerifyPartnerSignature(authData authPort.Signature) (bool, error) {
@@ -67,6 +76,9 @@ func (s *service) VerifyUserToken(tokenString string) (interface{}, error) {
 	if userToken.UserID == "" {
 		return nil, ErrInvalidToken
 	}
+	if time.Since(userToken.LastUsedAt) > LastUsedInterval {
+		_ = s.userTokenRepository.UpdateLastUsed(userToken.TokenID)
+	}
 	return token, nil
 }
 
@@ -76,6 +88,10 @@ func (s *service) UserRegister(userAuth authPort.UserAuthService) error {
 		return ErrUserEmailDuplicate
 	}
 
+	if err := PasswordPolicy.Verify(userAuth.Password); err != nil {
+		return err
+	}
+
 	hashedPassword, err := crypto.UserGeneratePassword(userAuth.Password)
 	if err != nil {
 		return ErrUserGeneratePassword
@@ -104,6 +120,9 @@ func (s *service) UserLogin(userAuth *authPort.UserAuthService) (err error) {
 			var userTokenRepo authPort.UserTokenRepo
 			userTokenRepo.TokenID = tokenID
 			userTokenRepo.UserID = userAuth.UserID
+			userTokenRepo.IPAddress = userAuth.IPAddress
+			userTokenRepo.UserAgent = userAuth.UserAgent
+			userTokenRepo.ExpiredAt = tokenExpiredAt(userAuth.KeepLogin)
 
 			return s.userTokenRepository.CreateData(userTokenRepo)
 		}
@@ -124,13 +143,79 @@ func (s *service) UserRefreshToken(refreshTokenString string) (userAuth authPort
 	if err = s.populateUserToken(refreshTokenClaims.ID, &userAuth); err == nil {
 		newTokenID := helper.GenerateUniqueID()
 		if err = s.generateTokens(newTokenID, &userAuth); err == nil {
-			err = s.updateUserToken(refreshTokenClaims.ID, newTokenID, userAuth.UserID)
+			err = s.updateUserToken(refreshTokenClaims.ID, newTokenID, userAuth)
 		}
 	}
 
 	return
 }
 
+func (s *service) UserSessions(tokenID string) ([]authPort.UserSession, error) {
+	sessions := make([]authPort.UserSession, 0)
+
+	currentToken := s.userTokenRepository.FindByTokenID(tokenID)
+	if currentToken.UserID == "" {
+		return sessions, ErrInvalidToken
+	}
+
+	userTokens, err := s.userTokenRepository.ListDataByUserID(currentToken.UserID)
+	if err != nil {
+		return sessions, err
+	}
+
+	var session authPort.UserSession
+	for i := range userTokens {
+		session.TokenID = userTokens[i].TokenID
+		session.IPAddress = userTokens[i].IPAddress
+		session.UserAgent = userTokens[i].UserAgent
+		session.Current = userTokens[i].TokenID == tokenID
+		session.CreatedAt = userTokens[i].CreatedAt
+		session.LastUsedAt = userTokens[i].LastUsedAt
+		session.ExpiredAt = userTokens[i].ExpiredAt
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
+
+func (s *service) UserRevokeSession(tokenID string, sessionTokenID string) error {
+	currentToken := s.userTokenRepository.FindByTokenID(tokenID)
+	if currentToken.UserID == "" {
+		return ErrInvalidToken
+	}
+
+	// a user may only revoke their own sessions
+	sessionToken := s.userTokenRepository.FindByTokenID(sessionTokenID)
+	if sessionToken.UserID != currentToken.UserID {
+		return ErrSessionNotFound
+	}
+
+	return s.userTokenRepository.DeleteData(sessionTokenID)
+}
+
+func (s *service) PublicKeys() []authPort.PublicKey {
+	jwks := jwt.JWKS()
+
+	var publicKey authPort.PublicKey
+	publicKeys := make([]authPort.PublicKey, 0, len(jwks.Keys))
+	for i := range jwks.Keys {
+		publicKey.KeyType = jwks.Keys[i].KeyType
+		publicKey.KeyID = jwks.Keys[i].KeyID
+		publicKey.Use = jwks.Keys[i].Use
+		publicKey.Algorithm = jwks.Keys[i].Algorithm
+		publicKey.N = jwks.Keys[i].N
+		publicKey.E = jwks.Keys[i].E
+		publicKey.Curve = jwks.Keys[i].Curve
+		publicKey.X = jwks.Keys[i].X
+		publicKey.Y = jwks.Keys[i].Y
+
+		publicKeys = append(publicKeys, publicKey)
+	}
+
+	return publicKeys
+}
+
 func (s *service) generateToken(tokenID string, userAuth *authPort.UserAuthService) (err error) {
 	var tokenClaims auth.JWTClaims
 	tokenClaims.ID = tokenID
@@ -151,11 +236,14 @@ func (s *service) generateRefreshToken(tokenID string, userAuth *authPort.UserAu
 	return
 }
 
-func (s *service) updateUserToken(oldTokenID, newtokenID, userID string) error {
+func (s *service) updateUserToken(oldTokenID, newtokenID string, userAuth authPort.UserAuthService) error {
 	var userTokenRepo authPort.UserTokenRepo
 
 	userTokenRepo.TokenID = newtokenID
-	userTokenRepo.UserID = userID
+	userTokenRepo.UserID = userAuth.UserID
+	userTokenRepo.IPAddress = userAuth.IPAddress
+	userTokenRepo.UserAgent = userAuth.UserAgent
+	userTokenRepo.ExpiredAt = tokenExpiredAt(true)
 
 	if err := s.userTokenRepository.CreateData(userTokenRepo); err == nil {
 		return s.userTokenRepository.DeleteData(oldTokenID)
@@ -176,6 +264,8 @@ func (s *service) populateUserToken(tokenID string, userAuth *authPort.UserAuthS
 	token := s.userTokenRepository.FindByTokenID(tokenID)
 	if token.UserID != "" {
 		userAuth.UserID = token.UserID
+		userAuth.IPAddress = token.IPAddress
+		userAuth.UserAgent = token.UserAgent
 		user, err := s.userRepository.ReadData(userAuth.UserID)
 		if err == nil {
 			userAuth.Email = user.Email
@@ -197,3 +287,12 @@ func (s *service) bindUserCredential(user *authPort.UserAuthService) bool {
 
 	return false
 }
+
+// tokenExpiredAt returns when a session ends, refresh token lifetime applies when it was issued
+func tokenExpiredAt(withRefreshToken bool) time.Time {
+	hours := config.GetJWTExpiredToken()
+	if withRefreshToken {
+		hours = config.GetJWTExpiredRefreshToken()
+	}
+	return time.Now().Add(time.Hour * time.Duration(hours))
+}
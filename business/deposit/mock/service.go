@@ -0,0 +1,45 @@
+package mock
+
+import (
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type service struct {
+	mock.Mock
+}
+
+func New() *service {
+	return &service{}
+}
+
+func (s *service) Balance(partnerCode string) (depositPort.Balance, error) {
+	result := s.Called(partnerCode)
+	return result.Get(0).(depositPort.Balance), result.Error(1)
+}
+
+func (s *service) Topup(partnerCode string, amount int64, reference string) error {
+	result := s.Called(partnerCode, amount, reference)
+	return result.Error(0)
+}
+
+func (s *service) Ledger(partnerCode string) ([]depositPort.Entry, error) {
+	result := s.Called(partnerCode)
+	return result.Get(0).([]depositPort.Entry), result.Error(1)
+}
+
+func (s *service) Hold(partnerCode string, transactionId string, amount int64) (string, error) {
+	result := s.Called(partnerCode, transactionId, amount)
+	return result.String(0), result.Error(1)
+}
+
+func (s *service) Settle(holdId string, issuerRescode string, issuerTransactionId string, failed bool) error {
+	result := s.Called(holdId, issuerRescode, issuerTransactionId, failed)
+	return result.Error(0)
+}
+
+func (s *service) Refund(partnerCode string, issuerTransactionId string) error {
+	result := s.Called(partnerCode, issuerTransactionId)
+	return result.Error(0)
+}
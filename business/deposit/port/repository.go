@@ -0,0 +1,91 @@
+package port
+
+import "time"
+
+const (
+	// ledger accounts, every entry moves an amount from its debit account to its credit account
+	AccountCash      = "cash"
+	AccountAvailable = "available"
+	AccountHeld      = "held"
+	AccountRevenue   = "revenue"
+
+	EntryTopup   = "topup"
+	EntryHold    = "hold"
+	EntryCapture = "capture"
+	EntryRelease = "release"
+	EntryRefund  = "refund"
+
+	HoldStatusHeld     = "held"
+	HoldStatusCaptured = "captured"
+	HoldStatusReleased = "released"
+	HoldStatusRefunded = "refunded"
+)
+
+type (
+	// AccountRepo prepaid deposit of a partner, partners without one are postpaid
+	AccountRepo struct {
+		PartnerCode string    `json:"partner_code"`
+		Balance     int64     `json:"balance"`
+		Held        int64     `json:"held"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+
+	// HoldRepo amount of an order taken from the balance until the order is settled
+	HoldRepo struct {
+		ID                  string    `json:"id"`
+		PartnerCode         string    `json:"partner_code"`
+		TransactionId       string    `json:"transaction_id"`
+		IssuerTransactionId string    `json:"issuer_transaction_id"`
+		Amount              int64     `json:"amount"`
+		Status              string    `json:"status"`
+		CreatedAt           time.Time `json:"created_at"`
+		UpdatedAt           time.Time `json:"updated_at"`
+	}
+
+	EntryRepo struct {
+		ID            string    `json:"id"`
+		PartnerCode   string    `json:"partner_code"`
+		Type          string    `json:"type"`
+		DebitAccount  string    `json:"debit_account"`
+		CreditAccount string    `json:"credit_account"`
+		Amount        int64     `json:"amount"`
+		Reference     string    `json:"reference"`
+		CreatedAt     time.Time `json:"created_at"`
+	}
+)
+
+// Repository is outbound port
+type Repository interface {
+	//FindAccount get the deposit account of the partner
+	FindAccount(partnerCode string) (AccountRepo, error)
+
+	//Topup add to the balance, the account is created on the first topup
+	Topup(partnerCode string, amount int64) error
+
+	//HoldBalance move the amount from the balance to held only when the balance covers it
+	HoldBalance(partnerCode string, amount int64) (bool, error)
+
+	//MoveBalance add the amounts to the balance and held, negative amounts are subtracted
+	MoveBalance(partnerCode string, balance int64, held int64) error
+
+	//CreateHold insert new hold and return it with its ID
+	CreateHold(hold HoldRepo) (HoldRepo, error)
+
+	//ReadHold get hold by ID
+	ReadHold(ID string) (HoldRepo, error)
+
+	//FindHold get the hold of a partner transaction
+	FindHold(partnerCode string, transactionId string) (HoldRepo, error)
+
+	//FindHoldByIssuerTransaction get the hold of a partner issuer transaction
+	FindHoldByIssuerTransaction(partnerCode string, issuerTransactionId string) (HoldRepo, error)
+
+	//UpdateHoldStatus change the hold status only when it is still in the from status
+	UpdateHoldStatus(ID string, from string, to string, issuerTransactionId string) (bool, error)
+
+	//CreateEntry insert new ledger entry
+	CreateEntry(entry EntryRepo) error
+
+	//ListEntries get ledger entries of the partner, newest first
+	ListEntries(partnerCode string) ([]EntryRepo, error)
+}
@@ -0,0 +1,44 @@
+package port
+
+import "time"
+
+type (
+	Balance struct {
+		PartnerCode string    `json:"partner_code"`
+		Balance     int64     `json:"balance"`
+		Held        int64     `json:"held"`
+		UpdatedAt   time.Time `json:"updated_at"`
+	}
+
+	Entry struct {
+		ID            string    `json:"id"`
+		PartnerCode   string    `json:"partner_code"`
+		Type          string    `json:"type"`
+		DebitAccount  string    `json:"debit_account"`
+		CreditAccount string    `json:"credit_account"`
+		Amount        int64     `json:"amount"`
+		Reference     string    `json:"reference"`
+		CreatedAt     time.Time `json:"created_at"`
+	}
+)
+
+// Service is inbound port
+type Service interface {
+	// Balance get the deposit balance of the partner
+	Balance(partnerCode string) (Balance, error)
+
+	// Topup add the amount to the partner balance
+	Topup(partnerCode string, amount int64, reference string) error
+
+	// Ledger get ledger entries of the partner, newest first
+	Ledger(partnerCode string) ([]Entry, error)
+
+	// Hold take the amount of the transaction from the partner balance, an empty hold ID is returned for postpaid partners
+	Hold(partnerCode string, transactionId string, amount int64) (string, error)
+
+	// Settle capture the hold on success, release it on failure and keep it while the order is pending
+	Settle(holdId string, issuerRescode string, issuerTransactionId string, failed bool) error
+
+	// Refund give back a captured hold after the order is reversed
+	Refund(partnerCode string, issuerTransactionId string) error
+}
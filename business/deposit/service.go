@@ -0,0 +1,177 @@
+package deposit
+
+import (
+	"encoding/json"
+	"errors"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+)
+
+type (
+	service struct {
+		depositRepository depositPort.Repository
+		partnerRepository partnerPort.Repository
+	}
+)
+
+var (
+	ErrAccountNotFound     = "Deposit account not found"
+	ErrHoldNotFound        = "Deposit hold not found"
+	ErrPartnerNotFound     = "Partner not found"
+	ErrInvalidAmount       = "Amount must be greater than zero"
+	ErrInsufficientBalance = "Insufficient deposit balance"
+	ErrDuplicateHold       = "Transaction already holds deposit"
+	ErrUnpricedOrder       = "Prepaid orders need a product price"
+)
+
+func New(depositRepository depositPort.Repository, partnerRepository partnerPort.Repository) depositPort.Service {
+	return &service{
+		depositRepository,
+		partnerRepository,
+	}
+}
+
+func (s *service) Balance(partnerCode string) (balance depositPort.Balance, err error) {
+	account, err := s.depositRepository.FindAccount(partnerCode)
+	if err != nil {
+		return
+	}
+	balance = depositPort.Balance{
+		PartnerCode: account.PartnerCode,
+		Balance:     account.Balance,
+		Held:        account.Held,
+		UpdatedAt:   account.UpdatedAt,
+	}
+	return
+}
+
+func (s *service) Topup(partnerCode string, amount int64, reference string) error {
+	if amount <= 0 {
+		return errors.New(ErrInvalidAmount)
+	}
+	if s.partnerRepository.FindByCode(partnerCode).ID == "" {
+		return errors.New(ErrPartnerNotFound)
+	}
+	if err := s.depositRepository.Topup(partnerCode, amount); err != nil {
+		return err
+	}
+	return s.record(partnerCode, depositPort.EntryTopup, depositPort.AccountCash, depositPort.AccountAvailable, amount, reference)
+}
+
+func (s *service) Ledger(partnerCode string) (entries []depositPort.Entry, err error) {
+	datas, err := s.depositRepository.ListEntries(partnerCode)
+	if err != nil {
+		return
+	}
+	entries = make([]depositPort.Entry, 0, len(datas))
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &entries)
+	}
+	return
+}
+
+func (s *service) Hold(partnerCode string, transactionId string, amount int64) (string, error) {
+	if _, err := s.depositRepository.FindAccount(partnerCode); err != nil {
+		if err.Error() == ErrAccountNotFound {
+			// postpaid partner
+			return "", nil
+		}
+		return "", err
+	}
+	if amount <= 0 {
+		return "", errors.New(ErrUnpricedOrder)
+	}
+	if _, err := s.depositRepository.FindHold(partnerCode, transactionId); err == nil {
+		return "", apperror.NewConflict(ErrDuplicateHold)
+	} else if err.Error() != ErrHoldNotFound {
+		return "", err
+	}
+
+	// the balance check and the debit are a single conditional update so concurrent orders can't overdraw
+	held, err := s.depositRepository.HoldBalance(partnerCode, amount)
+	if err != nil {
+		return "", err
+	}
+	if !held {
+		return "", errors.New(ErrInsufficientBalance)
+	}
+	hold, err := s.depositRepository.CreateHold(depositPort.HoldRepo{
+		PartnerCode:   partnerCode,
+		TransactionId: transactionId,
+		Amount:        amount,
+		Status:        depositPort.HoldStatusHeld,
+	})
+	if err != nil {
+		// a concurrent order of the same transaction got the hold first, its conflict is returned as is
+		s.depositRepository.MoveBalance(partnerCode, amount, -amount)
+		return "", err
+	}
+	if err := s.record(partnerCode, depositPort.EntryHold, depositPort.AccountAvailable, depositPort.AccountHeld, amount, transactionId); err != nil {
+		// the order is refused, its hold is released so the ledger and the balance stay in step
+		if moved, _ := s.depositRepository.UpdateHoldStatus(hold.ID, depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, ""); moved {
+			s.depositRepository.MoveBalance(partnerCode, amount, -amount)
+		}
+		return "", err
+	}
+	return hold.ID, nil
+}
+
+func (s *service) Settle(holdId string, issuerRescode string, issuerTransactionId string, failed bool) error {
+	if holdId == "" {
+		return nil
+	}
+	if !failed && issuerRescode == orderPort.RescodePending {
+		return nil
+	}
+	hold, err := s.depositRepository.ReadHold(holdId)
+	if err != nil {
+		return err
+	}
+
+	if !failed && issuerRescode == orderPort.RescodeSuccess {
+		return s.transition(hold, depositPort.HoldStatusHeld, depositPort.HoldStatusCaptured, issuerTransactionId, 0, -hold.Amount,
+			depositPort.EntryCapture, depositPort.AccountHeld, depositPort.AccountRevenue)
+	}
+	return s.transition(hold, depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, issuerTransactionId, hold.Amount, -hold.Amount,
+		depositPort.EntryRelease, depositPort.AccountHeld, depositPort.AccountAvailable)
+}
+
+func (s *service) Refund(partnerCode string, issuerTransactionId string) error {
+	hold, err := s.depositRepository.FindHoldByIssuerTransaction(partnerCode, issuerTransactionId)
+	if err != nil {
+		if err.Error() == ErrHoldNotFound {
+			// postpaid or unpriced order
+			return nil
+		}
+		return err
+	}
+	return s.transition(hold, depositPort.HoldStatusCaptured, depositPort.HoldStatusRefunded, issuerTransactionId, hold.Amount, 0,
+		depositPort.EntryRefund, depositPort.AccountRevenue, depositPort.AccountAvailable)
+}
+
+// transition move the hold status first, a hold already moved by a concurrent settlement is left untouched
+func (s *service) transition(hold depositPort.HoldRepo, from string, to string, issuerTransactionId string, balance int64, held int64, entryType string, debitAccount string, creditAccount string) error {
+	moved, err := s.depositRepository.UpdateHoldStatus(hold.ID, from, to, issuerTransactionId)
+	if err != nil || !moved {
+		return err
+	}
+	if err := s.depositRepository.MoveBalance(hold.PartnerCode, balance, held); err != nil {
+		return err
+	}
+	return s.record(hold.PartnerCode, entryType, debitAccount, creditAccount, hold.Amount, hold.TransactionId)
+}
+
+func (s *service) record(partnerCode string, entryType string, debitAccount string, creditAccount string, amount int64, reference string) error {
+	return s.depositRepository.CreateEntry(depositPort.EntryRepo{
+		PartnerCode:   partnerCode,
+		Type:          entryType,
+		DebitAccount:  debitAccount,
+		CreditAccount: creditAccount,
+		Amount:        amount,
+		Reference:     reference,
+	})
+}
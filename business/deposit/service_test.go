@@ -0,0 +1,167 @@
+package deposit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	depositService "github.com/sepulsa/teleco/business/deposit"
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	depositRepo "github.com/sepulsa/teleco/modules/repository/mock/deposit"
+	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
+	"github.com/sepulsa/teleco/utils/apperror"
+)
+
+var (
+	TestHoldID        = "6138813fb95630b0b528b160"
+	TestPartnerCode   = "partner001"
+	TestTransactionId = "1001"
+	TestIssuerTrxId   = "ISS1001"
+	TestAmount        = int64(10500)
+)
+
+func entryOf(entryType string, debitAccount string, creditAccount string) interface{} {
+	return mock.MatchedBy(func(entry depositPort.EntryRepo) bool {
+		return entry.Type == entryType && entry.DebitAccount == debitAccount && entry.CreditAccount == creditAccount && entry.Amount == TestAmount
+	})
+}
+
+func TestTopup(t *testing.T) {
+	repository := depositRepo.New()
+	partnerRepository := partnerRepo.New()
+	service := depositService.New(repository, partnerRepository)
+
+	partnerRepository.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{ID: "1"})
+	partnerRepository.On("FindByCode", "unknown").Return(partnerPort.PartnerRepo{})
+
+	// success
+	repository.On("Topup", TestPartnerCode, TestAmount).Return(nil).Once()
+	repository.On("CreateEntry", entryOf(depositPort.EntryTopup, depositPort.AccountCash, depositPort.AccountAvailable)).Return(nil).Once()
+	assert.Nil(t, service.Topup(TestPartnerCode, TestAmount, "TRF-1"))
+
+	// invalid amount
+	err := service.Topup(TestPartnerCode, 0, "TRF-1")
+	assert.Equal(t, depositService.ErrInvalidAmount, err.Error())
+
+	// unknown partner
+	err = service.Topup("unknown", TestAmount, "TRF-1")
+	assert.Equal(t, depositService.ErrPartnerNotFound, err.Error())
+	repository.AssertExpectations(t)
+}
+
+func TestBalance(t *testing.T) {
+	repository := depositRepo.New()
+	service := depositService.New(repository, nil)
+
+	repository.On("FindAccount", TestPartnerCode).Return(depositPort.AccountRepo{PartnerCode: TestPartnerCode, Balance: 5000, Held: TestAmount}, nil).Once()
+	balance, err := service.Balance(TestPartnerCode)
+	if assert.Nil(t, err) {
+		assert.Equal(t, int64(5000), balance.Balance)
+		assert.Equal(t, TestAmount, balance.Held)
+	}
+}
+
+func TestHold(t *testing.T) {
+	repository := depositRepo.New()
+	service := depositService.New(repository, nil)
+
+	// postpaid partner
+	repository.On("FindAccount", "postpaid").Return(depositPort.AccountRepo{}, errors.New(depositService.ErrAccountNotFound)).Once()
+	holdId, err := service.Hold("postpaid", TestTransactionId, TestAmount)
+	assert.Nil(t, err)
+	assert.Empty(t, holdId)
+
+	repository.On("FindAccount", TestPartnerCode).Return(depositPort.AccountRepo{PartnerCode: TestPartnerCode}, nil)
+
+	// success
+	repository.On("FindHold", TestPartnerCode, TestTransactionId).Return(depositPort.HoldRepo{}, errors.New(depositService.ErrHoldNotFound)).Twice()
+	repository.On("HoldBalance", TestPartnerCode, TestAmount).Return(true, nil).Once()
+	repository.On("CreateHold", mock.MatchedBy(func(hold depositPort.HoldRepo) bool {
+		return hold.Status == depositPort.HoldStatusHeld && hold.Amount == TestAmount
+	})).Return(depositPort.HoldRepo{ID: TestHoldID}, nil).Once()
+	repository.On("CreateEntry", entryOf(depositPort.EntryHold, depositPort.AccountAvailable, depositPort.AccountHeld)).Return(nil).Once()
+	holdId, err = service.Hold(TestPartnerCode, TestTransactionId, TestAmount)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestHoldID, holdId)
+	}
+
+	// insufficient balance
+	repository.On("HoldBalance", TestPartnerCode, TestAmount).Return(false, nil).Once()
+	_, err = service.Hold(TestPartnerCode, TestTransactionId, TestAmount)
+	assert.Equal(t, depositService.ErrInsufficientBalance, err.Error())
+
+	// transaction already held
+	repository.On("FindHold", TestPartnerCode, TestTransactionId).Return(depositPort.HoldRepo{ID: TestHoldID}, nil).Once()
+	_, err = service.Hold(TestPartnerCode, TestTransactionId, TestAmount)
+	assert.Equal(t, depositService.ErrDuplicateHold, err.Error())
+
+	// transaction held concurrently, the balance goes back
+	repository.On("FindHold", TestPartnerCode, TestTransactionId).Return(depositPort.HoldRepo{}, errors.New(depositService.ErrHoldNotFound)).Once()
+	repository.On("HoldBalance", TestPartnerCode, TestAmount).Return(true, nil).Once()
+	repository.On("CreateHold", mock.Anything).Return(depositPort.HoldRepo{}, apperror.NewConflict(depositService.ErrDuplicateHold)).Once()
+	repository.On("MoveBalance", TestPartnerCode, TestAmount, -TestAmount).Return(nil).Once()
+	_, err = service.Hold(TestPartnerCode, TestTransactionId, TestAmount)
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
+	// ledger entry failed, the hold is released and the balance goes back
+	repository.On("FindHold", TestPartnerCode, TestTransactionId).Return(depositPort.HoldRepo{}, errors.New(depositService.ErrHoldNotFound)).Once()
+	repository.On("HoldBalance", TestPartnerCode, TestAmount).Return(true, nil).Once()
+	repository.On("CreateHold", mock.Anything).Return(depositPort.HoldRepo{ID: TestHoldID}, nil).Once()
+	repository.On("CreateEntry", mock.Anything).Return(errors.New("no reachable servers")).Once()
+	repository.On("UpdateHoldStatus", TestHoldID, depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, "").Return(true, nil).Once()
+	repository.On("MoveBalance", TestPartnerCode, TestAmount, -TestAmount).Return(nil).Once()
+	_, err = service.Hold(TestPartnerCode, TestTransactionId, TestAmount)
+	assert.Equal(t, "no reachable servers", err.Error())
+	repository.AssertExpectations(t)
+}
+
+func TestSettle(t *testing.T) {
+	repository := depositRepo.New()
+	service := depositService.New(repository, nil)
+	hold := depositPort.HoldRepo{ID: TestHoldID, PartnerCode: TestPartnerCode, TransactionId: TestTransactionId, Amount: TestAmount, Status: depositPort.HoldStatusHeld}
+	repository.On("ReadHold", TestHoldID).Return(hold, nil)
+
+	// no hold, pending order
+	assert.Nil(t, service.Settle("", orderPort.RescodeSuccess, TestIssuerTrxId, false))
+	assert.Nil(t, service.Settle(TestHoldID, orderPort.RescodePending, "", false))
+	repository.AssertNotCalled(t, "ReadHold", TestHoldID)
+
+	// success captures
+	repository.On("UpdateHoldStatus", TestHoldID, depositPort.HoldStatusHeld, depositPort.HoldStatusCaptured, TestIssuerTrxId).Return(true, nil).Once()
+	repository.On("MoveBalance", TestPartnerCode, int64(0), -TestAmount).Return(nil).Once()
+	repository.On("CreateEntry", entryOf(depositPort.EntryCapture, depositPort.AccountHeld, depositPort.AccountRevenue)).Return(nil).Once()
+	assert.Nil(t, service.Settle(TestHoldID, orderPort.RescodeSuccess, TestIssuerTrxId, false))
+
+	// failure releases
+	repository.On("UpdateHoldStatus", TestHoldID, depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, "").Return(true, nil).Once()
+	repository.On("MoveBalance", TestPartnerCode, TestAmount, -TestAmount).Return(nil).Once()
+	repository.On("CreateEntry", entryOf(depositPort.EntryRelease, depositPort.AccountHeld, depositPort.AccountAvailable)).Return(nil).Once()
+	assert.Nil(t, service.Settle(TestHoldID, "", "", true))
+
+	// already settled
+	repository.On("UpdateHoldStatus", TestHoldID, depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, "").Return(false, nil).Once()
+	assert.Nil(t, service.Settle(TestHoldID, "99", "", false))
+	repository.AssertExpectations(t)
+}
+
+func TestRefund(t *testing.T) {
+	repository := depositRepo.New()
+	service := depositService.New(repository, nil)
+
+	// unknown hold
+	repository.On("FindHoldByIssuerTransaction", TestPartnerCode, "unknown").Return(depositPort.HoldRepo{}, errors.New(depositService.ErrHoldNotFound)).Once()
+	assert.Nil(t, service.Refund(TestPartnerCode, "unknown"))
+
+	// success
+	hold := depositPort.HoldRepo{ID: TestHoldID, PartnerCode: TestPartnerCode, Amount: TestAmount, Status: depositPort.HoldStatusCaptured}
+	repository.On("FindHoldByIssuerTransaction", TestPartnerCode, TestIssuerTrxId).Return(hold, nil).Once()
+	repository.On("UpdateHoldStatus", TestHoldID, depositPort.HoldStatusCaptured, depositPort.HoldStatusRefunded, TestIssuerTrxId).Return(true, nil).Once()
+	repository.On("MoveBalance", TestPartnerCode, TestAmount, int64(0)).Return(nil).Once()
+	repository.On("CreateEntry", entryOf(depositPort.EntryRefund, depositPort.AccountRevenue, depositPort.AccountAvailable)).Return(nil).Once()
+	assert.Nil(t, service.Refund(TestPartnerCode, TestIssuerTrxId))
+	repository.AssertExpectations(t)
+}
@@ -0,0 +1,102 @@
+package issuer
+
+import (
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+)
+
+type (
+	balanceService struct {
+		issuerRepository  issuerPort.Repository
+		balanceRepository issuerPort.BalanceRepository
+		notifier          issuerPort.Notifier
+	}
+)
+
+var (
+	ErrBalanceNotFound = "Issuer balance not reported yet"
+)
+
+// NewBalance service tracking the deposit kept at each issuer, alerts are dropped when notifier is nil
+func NewBalance(issuerRepository issuerPort.Repository, balanceRepository issuerPort.BalanceRepository, notifier issuerPort.Notifier) issuerPort.BalanceService {
+	return &balanceService{
+		issuerRepository,
+		balanceRepository,
+		notifier,
+	}
+}
+
+func (s *balanceService) ReadData(ID string) (balance issuerPort.IssuerBalance, err error) {
+	issuer, err := s.issuerRepository.ReadData(ID)
+	if err != nil {
+		return
+	}
+	data, err := s.balanceRepository.FindByIssuerCode(issuer.Code)
+	if err != nil {
+		return
+	}
+	balance = issuerPort.IssuerBalance{
+		IssuerCode: data.IssuerCode,
+		Balance:    data.Balance,
+		Source:     data.Source,
+		Threshold:  issuer.LowBalanceThreshold,
+		Low:        isLow(data.Balance, issuer.LowBalanceThreshold),
+		UpdatedAt:  data.UpdatedAt,
+	}
+	return
+}
+
+func (s *balanceService) UpdateData(ID string, balance int64) error {
+	issuer, err := s.issuerRepository.ReadData(ID)
+	if err != nil {
+		return err
+	}
+	return s.Report(issuer.Code, balance, issuerPort.BalanceSourceManual)
+}
+
+func (s *balanceService) Report(issuerCode string, balance int64, source string) error {
+	data, err := s.balanceRepository.SaveBalance(issuerCode, balance, source)
+	if err != nil {
+		return err
+	}
+	return s.checkThreshold(data)
+}
+
+func (s *balanceService) Deduct(issuerCode string, amount int64) error {
+	if amount <= 0 {
+		return nil
+	}
+	data, found, err := s.balanceRepository.DeductBalance(issuerCode, amount)
+	if err != nil || !found {
+		return err
+	}
+	return s.checkThreshold(data)
+}
+
+// checkThreshold alert once when the balance goes below the issuer threshold,
+// the alert is armed again when the balance is back above it
+func (s *balanceService) checkThreshold(balance issuerPort.Balance) error {
+	issuer := s.issuerRepository.FindByCode(balance.IssuerCode)
+	low := isLow(balance.Balance, issuer.LowBalanceThreshold)
+	if low == balance.Alerted {
+		return nil
+	}
+	// the flag is switched first so concurrent updates send a single alert
+	changed, err := s.balanceRepository.UpdateAlerted(balance.IssuerCode, low)
+	if err != nil || !changed || !low || s.notifier == nil {
+		return err
+	}
+	return s.notifier.NotifyLowBalance(issuerPort.LowBalanceAlert{
+		IssuerCode:  balance.IssuerCode,
+		IssuerLabel: issuer.Label,
+		Balance:     balance.Balance,
+		Threshold:   issuer.LowBalanceThreshold,
+		Source:      balance.Source,
+		CreatedAt:   time.Now(),
+	})
+}
+
+func isLow(balance int64, threshold int64) bool {
+	return threshold > 0 && balance < threshold
+}
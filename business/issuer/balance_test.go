@@ -0,0 +1,123 @@
+package issuer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	issuerService "github.com/sepulsa/teleco/business/issuer"
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	notifierMock "github.com/sepulsa/teleco/modules/notifier/mock"
+	issuerRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer"
+	issuerBalanceRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer/balance"
+)
+
+var (
+	TestThreshold int64 = 100000
+)
+
+func TestReadBalance(t *testing.T) {
+	repository := issuerRepo.New()
+	balanceRepository := issuerBalanceRepo.New()
+	service := issuerService.NewBalance(repository, balanceRepository, nil)
+
+	// success
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode, LowBalanceThreshold: TestThreshold}, nil).Once()
+	balanceRepository.On("FindByIssuerCode", TestCode).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 5000, Source: issuerPort.BalanceSourceEstimate}, nil).Once()
+	balance, err := service.ReadData(TestID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, int64(5000), balance.Balance)
+		assert.Equal(t, TestThreshold, balance.Threshold)
+		assert.True(t, balance.Low)
+	}
+
+	// never reported
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode}, nil).Once()
+	balanceRepository.On("FindByIssuerCode", TestCode).Return(issuerPort.Balance{}, errors.New(issuerService.ErrBalanceNotFound)).Once()
+	_, err = service.ReadData(TestID)
+	assert.Equal(t, issuerService.ErrBalanceNotFound, err.Error())
+}
+
+func TestReportBalance(t *testing.T) {
+	repository := issuerRepo.New()
+	balanceRepository := issuerBalanceRepo.New()
+	notifier := notifierMock.New()
+	service := issuerService.NewBalance(repository, balanceRepository, notifier)
+	repository.On("FindByCode", TestCode).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode, Label: TestLabel, LowBalanceThreshold: TestThreshold})
+
+	// below threshold, alerted once
+	balanceRepository.On("SaveBalance", TestCode, int64(5000), issuerPort.BalanceSourceResponse).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 5000, Source: issuerPort.BalanceSourceResponse}, nil).Once()
+	balanceRepository.On("UpdateAlerted", TestCode, true).Return(true, nil).Once()
+	notifier.On("NotifyLowBalance", mock.MatchedBy(func(alert issuerPort.LowBalanceAlert) bool {
+		return alert.IssuerCode == TestCode && alert.Balance == 5000 && alert.Threshold == TestThreshold
+	})).Return(nil).Once()
+	assert.Nil(t, service.Report(TestCode, 5000, issuerPort.BalanceSourceResponse))
+
+	// still below threshold, no new alert
+	balanceRepository.On("SaveBalance", TestCode, int64(4000), issuerPort.BalanceSourceResponse).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 4000, Alerted: true}, nil).Once()
+	assert.Nil(t, service.Report(TestCode, 4000, issuerPort.BalanceSourceResponse))
+
+	// alert already sent by another process
+	balanceRepository.On("SaveBalance", TestCode, int64(3000), issuerPort.BalanceSourceInquiry).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 3000}, nil).Once()
+	balanceRepository.On("UpdateAlerted", TestCode, true).Return(false, nil).Once()
+	assert.Nil(t, service.Report(TestCode, 3000, issuerPort.BalanceSourceInquiry))
+
+	// back above threshold, alert armed again
+	balanceRepository.On("SaveBalance", TestCode, int64(500000), issuerPort.BalanceSourceResponse).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 500000, Alerted: true}, nil).Once()
+	balanceRepository.On("UpdateAlerted", TestCode, false).Return(true, nil).Once()
+	assert.Nil(t, service.Report(TestCode, 500000, issuerPort.BalanceSourceResponse))
+
+	// error mongo
+	balanceRepository.On("SaveBalance", TestCode, int64(1), issuerPort.BalanceSourceResponse).Return(issuerPort.Balance{}, errors.New("")).Once()
+	assert.NotNil(t, service.Report(TestCode, 1, issuerPort.BalanceSourceResponse))
+
+	balanceRepository.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestDeductBalance(t *testing.T) {
+	repository := issuerRepo.New()
+	balanceRepository := issuerBalanceRepo.New()
+	notifier := notifierMock.New()
+	service := issuerService.NewBalance(repository, balanceRepository, notifier)
+
+	// unpriced purchase
+	assert.Nil(t, service.Deduct(TestCode, 0))
+
+	// never reported, nothing to estimate from
+	balanceRepository.On("DeductBalance", TestCode, int64(10000)).Return(issuerPort.Balance{}, false, nil).Once()
+	assert.Nil(t, service.Deduct(TestCode, 10000))
+
+	// threshold disabled
+	repository.On("FindByCode", TestCode).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode}).Once()
+	balanceRepository.On("DeductBalance", TestCode, int64(10000)).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 10}, true, nil).Once()
+	assert.Nil(t, service.Deduct(TestCode, 10000))
+
+	// below threshold
+	repository.On("FindByCode", TestCode).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode, LowBalanceThreshold: TestThreshold}).Once()
+	balanceRepository.On("DeductBalance", TestCode, int64(10000)).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 90000, Source: issuerPort.BalanceSourceEstimate}, true, nil).Once()
+	balanceRepository.On("UpdateAlerted", TestCode, true).Return(true, nil).Once()
+	notifier.On("NotifyLowBalance", mock.Anything).Return(errors.New("")).Once()
+	assert.NotNil(t, service.Deduct(TestCode, 10000))
+
+	balanceRepository.AssertExpectations(t)
+	notifier.AssertExpectations(t)
+}
+
+func TestUpdateBalance(t *testing.T) {
+	repository := issuerRepo.New()
+	balanceRepository := issuerBalanceRepo.New()
+	service := issuerService.NewBalance(repository, balanceRepository, nil)
+
+	// success
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode}, nil).Once()
+	repository.On("FindByCode", TestCode).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode}).Once()
+	balanceRepository.On("SaveBalance", TestCode, int64(1000000), issuerPort.BalanceSourceManual).Return(issuerPort.Balance{IssuerCode: TestCode, Balance: 1000000}, nil).Once()
+	assert.Nil(t, service.UpdateData(TestID, 1000000))
+
+	// issuer not found
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{}, errors.New(TestErrInvalidID)).Once()
+	assert.NotNil(t, service.UpdateData(TestID, 1000000))
+}
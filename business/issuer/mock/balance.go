@@ -0,0 +1,35 @@
+package mock
+
+import (
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type balanceService struct {
+	mock.Mock
+}
+
+func NewBalance() *balanceService {
+	return &balanceService{}
+}
+
+func (s *balanceService) ReadData(ID string) (issuerPort.IssuerBalance, error) {
+	result := s.Called(ID)
+	return result.Get(0).(issuerPort.IssuerBalance), result.Error(1)
+}
+
+func (s *balanceService) UpdateData(ID string, balance int64) error {
+	result := s.Called(ID, balance)
+	return result.Error(0)
+}
+
+func (s *balanceService) Report(issuerCode string, balance int64, source string) error {
+	result := s.Called(issuerCode, balance, source)
+	return result.Error(0)
+}
+
+func (s *balanceService) Deduct(issuerCode string, amount int64) error {
+	result := s.Called(issuerCode, amount)
+	return result.Error(0)
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -38,3 +39,18 @@ func (s *service) ListData() ([]issuerPort.IssuerService, error) {
 	result := s.Called()
 	return result.Get(0).([]issuerPort.IssuerService), result.Error(1)
 }
+
+func (s *service) SearchData(listQuery query.Query) ([]issuerPort.IssuerService, query.Page, error) {
+	result := s.Called(listQuery)
+	return result.Get(0).([]issuerPort.IssuerService), result.Get(1).(query.Page), result.Error(2)
+}
+
+func (s *service) CircuitState(ID string) (issuerPort.CircuitState, error) {
+	result := s.Called(ID)
+	return result.Get(0).(issuerPort.CircuitState), result.Error(1)
+}
+
+func (s *service) ForceOpenCircuit(ID string, forced bool) error {
+	result := s.Called(ID, forced)
+	return result.Error(0)
+}
@@ -0,0 +1,19 @@
+package port
+
+import "time"
+
+// LowBalanceAlert sent once when an issuer balance goes below its threshold
+type LowBalanceAlert struct {
+	IssuerCode  string    `json:"issuer_code"`
+	IssuerLabel string    `json:"issuer_label"`
+	Balance     int64     `json:"balance"`
+	Threshold   int64     `json:"threshold"`
+	Source      string    `json:"source"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Notifier is outbound port
+type Notifier interface {
+	//NotifyLowBalance tell ops the issuer deposit needs a top up
+	NotifyLowBalance(alert LowBalanceAlert) error
+}
@@ -1,5 +1,30 @@
 package port
 
+import (
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+)
+
+const (
+	// StatusActive issuer orders are consumed by the worker, also assumed when status is empty
+	StatusActive = "active"
+	// StatusInactive issuer queue consumer is stopped by the worker
+	StatusInactive = "inactive"
+
+	// CircuitForcedOpen state reported while ops keep the issuer closed for maintenance
+	CircuitForcedOpen = "forced_open"
+
+	// BalanceSourceResponse balance reported by the issuer along an order response
+	BalanceSourceResponse = "response"
+	// BalanceSourceInquiry balance returned by the issuer balance inquiry
+	BalanceSourceInquiry = "inquiry"
+	// BalanceSourceEstimate last known balance minus the base price of the successful purchases since
+	BalanceSourceEstimate = "estimate"
+	// BalanceSourceManual balance set through the internal API, usually after a top up at the issuer
+	BalanceSourceManual = "manual"
+)
+
 type (
 	IssuerRepo struct {
 		ID               string `json:"id"`
@@ -9,6 +34,62 @@ type (
 		ThreadNum        int    `json:"thread_num"`
 		ThreadTimeout    int    `json:"thread_timeout"`
 		QueueWorkerLimit int    `json:"queue_worker_limit"`
+		Status           string `json:"status"`
+
+		CircuitBreaker    CircuitBreaker `json:"circuit_breaker"`
+		CircuitForcedOpen bool           `json:"circuit_forced_open"`
+
+		LowBalanceThreshold int64 `json:"low_balance_threshold"`
+
+		SettlementMapping SettlementMapping `json:"settlement_mapping"`
+
+		// Version incremented by every UpdateData, an update of another version is refused
+		Version int `json:"version"`
+	}
+
+	// SettlementMapping header names of the issuer settlement file columns
+	SettlementMapping struct {
+		TransactionId       string `json:"transaction_id"`
+		IssuerTransactionId string `json:"issuer_transaction_id"`
+		Amount              string `json:"amount"`
+		Status              string `json:"status"`
+		// SuccessStatus values of the status column meaning success, any other value is a failure
+		SuccessStatus []string `json:"success_status"`
+		// Delimiter column separator, comma when empty
+		Delimiter string `json:"delimiter"`
+	}
+
+	// CircuitBreaker thresholds of the issuer circuit breaker, disabled while both rates are zero
+	CircuitBreaker struct {
+		ErrorRate        float64 `json:"error_rate"`
+		SlowCallRate     float64 `json:"slow_call_rate"`
+		SlowCallDuration int     `json:"slow_call_duration"` // milliseconds
+		MinimumCalls     int     `json:"minimum_calls"`
+		Window           int     `json:"window"`        // seconds
+		OpenDuration     int     `json:"open_duration"` // seconds
+		HalfOpenCalls    int     `json:"half_open_calls"`
+	}
+
+	// CircuitState last transition of the issuer circuit breaker, breakers run per instance and the last
+	// instance to trip or recover writes it, so it is advisory, only the forced open flag of the issuer is shared
+	CircuitState struct {
+		IssuerCode string    `json:"issuer_code"`
+		State      string    `json:"state"`
+		Calls      int       `json:"calls"`
+		Failures   int       `json:"failures"`
+		SlowCalls  int       `json:"slow_calls"`
+		OpenedAt   time.Time `json:"opened_at"`
+		UpdatedAt  time.Time `json:"updated_at"`
+	}
+
+	// Balance deposit kept at the issuer as last known by teleco
+	Balance struct {
+		IssuerCode string `json:"issuer_code"`
+		Balance    int64  `json:"balance"`
+		Source     string `json:"source"`
+		// Alerted low balance alert sent, cleared once the balance is back above the threshold
+		Alerted   bool      `json:"alerted"`
+		UpdatedAt time.Time `json:"updated_at"`
 	}
 )
 
@@ -23,7 +104,7 @@ type Repository interface {
 	//ReadData get data by ID
 	ReadData(ID string) (IssuerRepo, error)
 
-	//UpdateData update new data
+	//UpdateData update new data, a conflict error when the stored version is not issuer.Version
 	UpdateData(issuer IssuerRepo) error
 
 	//DeleteData delete data
@@ -31,4 +112,34 @@ type Repository interface {
 
 	//ListData get list data
 	ListData() ([]IssuerRepo, error)
+
+	//SearchData get a page of issuers, searched on code and label, filtered on status
+	SearchData(listQuery query.Query) ([]IssuerRepo, query.Page, error)
+
+	//UpdateCircuitForcedOpen keep the issuer circuit open regardless of its calls
+	UpdateCircuitForcedOpen(ID string, forced bool) error
+}
+
+// CircuitRepository is outbound port
+type CircuitRepository interface {
+	//SaveState store the state after a transition, overwriting the one stored by other instances
+	SaveState(state CircuitState) error
+
+	//FindByIssuerCode get the last state, a closed state is returned when none was stored
+	FindByIssuerCode(code string) (CircuitState, error)
+}
+
+// BalanceRepository is outbound port
+type BalanceRepository interface {
+	//FindByIssuerCode get the balance, an error is returned while the issuer balance was never reported
+	FindByIssuerCode(code string) (Balance, error)
+
+	//SaveBalance store the balance reported by the issuer or set by ops
+	SaveBalance(code string, balance int64, source string) (Balance, error)
+
+	//DeductBalance decrease a stored balance, false when the balance was never reported
+	DeductBalance(code string, amount int64) (Balance, bool, error)
+
+	//UpdateAlerted switch the alert flag, false when the flag already had the value
+	UpdateAlerted(code string, alerted bool) (bool, error)
 }
@@ -1,5 +1,11 @@
 package port
 
+import (
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+)
+
 type (
 	IssuerService struct {
 		ID               string `json:"id"`
@@ -9,6 +15,28 @@ type (
 		ThreadNum        int    `json:"thread_num"`
 		ThreadTimeout    int    `json:"thread_timeout"`
 		QueueWorkerLimit int    `json:"queue_worker_limit"`
+		Status           string `json:"status"`
+
+		CircuitBreaker    CircuitBreaker `json:"circuit_breaker"`
+		CircuitForcedOpen bool           `json:"circuit_forced_open"`
+
+		// LowBalanceThreshold alert once the issuer balance goes below it, zero disables the alert
+		LowBalanceThreshold int64 `json:"low_balance_threshold"`
+
+		// SettlementMapping columns of the issuer settlement file read by the reconciliation
+		SettlementMapping SettlementMapping `json:"settlement_mapping"`
+
+		// Version the update is based on, see IssuerRepo.Version
+		Version int `json:"version"`
+	}
+
+	IssuerBalance struct {
+		IssuerCode string    `json:"issuer_code"`
+		Balance    int64     `json:"balance"`
+		Source     string    `json:"source"`
+		Threshold  int64     `json:"threshold"`
+		Low        bool      `json:"low"`
+		UpdatedAt  time.Time `json:"updated_at"`
 	}
 )
 
@@ -28,4 +56,28 @@ type Service interface {
 
 	// ListData get list data
 	ListData() ([]IssuerService, error)
+
+	// SearchData get a page of issuers, searched on code and label, filtered on status
+	SearchData(listQuery query.Query) ([]IssuerService, query.Page, error)
+
+	// CircuitState get the circuit breaker state of an issuer
+	CircuitState(ID string) (CircuitState, error)
+
+	// ForceOpenCircuit keep the issuer circuit open for maintenance, or release it
+	ForceOpenCircuit(ID string, forced bool) error
+}
+
+// BalanceService is inbound port
+type BalanceService interface {
+	// ReadData get the tracked balance of an issuer
+	ReadData(ID string) (IssuerBalance, error)
+
+	// UpdateData set the balance of an issuer, after a top up at the issuer
+	UpdateData(ID string, balance int64) error
+
+	// Report store a balance told by the issuer
+	Report(issuerCode string, balance int64, source string) error
+
+	// Deduct estimate the balance after a successful purchase, ignored while the balance was never reported
+	Deduct(issuerCode string, amount int64) error
 }
@@ -2,39 +2,52 @@ package issuer
 
 import (
 	"encoding/json"
-	"errors"
 
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 type (
 	service struct {
-		issuerRepository issuerPort.Repository
+		issuerRepository        issuerPort.Repository
+		circuitRepository       issuerPort.CircuitRepository
+		partnerIssuerRepository partnerIssuerPort.Repository
 	}
 )
 
 var (
 	ErrDuplicateCode = "Code already in use"
+	ErrIssuerMapped  = "Issuer still mapped to partners, remove the partner issuer mappings first"
 )
 
-func New(issuerRepository issuerPort.Repository) issuerPort.Service {
+func New(issuerRepository issuerPort.Repository, circuitRepository issuerPort.CircuitRepository, partnerIssuerRepository partnerIssuerPort.Repository) issuerPort.Service {
 	return &service{
 		issuerRepository,
+		circuitRepository,
+		partnerIssuerRepository,
 	}
 }
 
 func (s *service) CreateData(issuer issuerPort.IssuerService) error {
 	existingIssuer := s.issuerRepository.FindByCode(issuer.Code)
 	if existingIssuer.ID != "" {
-		return errors.New(ErrDuplicateCode)
+		return apperror.NewConflict(ErrDuplicateCode)
 	}
 
 	data := issuerPort.IssuerRepo{
-		Code:          issuer.Code,
-		Label:         issuer.Label,
-		Config:        issuer.Config,
-		ThreadNum:     issuer.ThreadNum,
-		ThreadTimeout: issuer.ThreadTimeout,
+		Code:             issuer.Code,
+		Label:            issuer.Label,
+		Config:           issuer.Config,
+		ThreadNum:        issuer.ThreadNum,
+		ThreadTimeout:    issuer.ThreadTimeout,
+		QueueWorkerLimit: issuer.QueueWorkerLimit,
+		Status:           issuer.Status,
+		CircuitBreaker:   issuer.CircuitBreaker,
+
+		LowBalanceThreshold: issuer.LowBalanceThreshold,
+		SettlementMapping:   issuer.SettlementMapping,
 	}
 	return s.issuerRepository.CreateData(data)
 }
@@ -45,12 +58,22 @@ func (s *service) ReadData(ID string) (issuer issuerPort.IssuerService, err erro
 		return
 	}
 	issuer = issuerPort.IssuerService{
-		ID:            data.ID,
-		Code:          data.Code,
-		Label:         data.Label,
-		Config:        data.Config,
-		ThreadNum:     data.ThreadNum,
-		ThreadTimeout: data.ThreadTimeout,
+		ID:               data.ID,
+		Code:             data.Code,
+		Label:            data.Label,
+		Config:           data.Config,
+		ThreadNum:        data.ThreadNum,
+		ThreadTimeout:    data.ThreadTimeout,
+		QueueWorkerLimit: data.QueueWorkerLimit,
+		Status:           data.Status,
+
+		CircuitBreaker:    data.CircuitBreaker,
+		CircuitForcedOpen: data.CircuitForcedOpen,
+
+		LowBalanceThreshold: data.LowBalanceThreshold,
+		SettlementMapping:   data.SettlementMapping,
+
+		Version: data.Version,
 	}
 	return
 }
@@ -63,21 +86,39 @@ func (s *service) UpdateData(issuer issuerPort.IssuerService) error {
 	if existingData.Code != issuer.Code {
 		existingIssuer := s.issuerRepository.FindByCode(issuer.Code)
 		if existingIssuer.ID != "" {
-			return errors.New(ErrDuplicateCode)
+			return apperror.NewConflict(ErrDuplicateCode)
 		}
 	}
 	data := issuerPort.IssuerRepo{
-		ID:            issuer.ID,
-		Code:          issuer.Code,
-		Label:         issuer.Label,
-		Config:        issuer.Config,
-		ThreadNum:     issuer.ThreadNum,
-		ThreadTimeout: issuer.ThreadTimeout,
+		ID:               issuer.ID,
+		Code:             issuer.Code,
+		Label:            issuer.Label,
+		Config:           issuer.Config,
+		ThreadNum:        issuer.ThreadNum,
+		ThreadTimeout:    issuer.ThreadTimeout,
+		QueueWorkerLimit: issuer.QueueWorkerLimit,
+		Status:           issuer.Status,
+		CircuitBreaker:   issuer.CircuitBreaker,
+
+		LowBalanceThreshold: issuer.LowBalanceThreshold,
+		SettlementMapping:   issuer.SettlementMapping,
+
+		Version: issuer.Version,
 	}
 	return s.issuerRepository.UpdateData(data)
 }
 
+// DeleteData is refused while partner issuer mappings still route partners to the issuer
 func (s *service) DeleteData(ID string) error {
+	if _, err := s.issuerRepository.ReadData(ID); err != nil {
+		return err
+	}
+	mapped := query.Query{Limit: 1, Filters: map[string]string{"issuer_id": ID}}
+	if _, page, err := s.partnerIssuerRepository.SearchData(mapped); err != nil {
+		return err
+	} else if page.Total > 0 {
+		return apperror.NewConflict(ErrIssuerMapped)
+	}
 	return s.issuerRepository.DeleteData(ID)
 }
 
@@ -93,3 +134,39 @@ func (s *service) ListData() (issuers []issuerPort.IssuerService, err error) {
 
 	return
 }
+
+func (s *service) SearchData(listQuery query.Query) (issuers []issuerPort.IssuerService, page query.Page, err error) {
+	datas, page, err := s.issuerRepository.SearchData(listQuery)
+	if err != nil {
+		return
+	}
+	issuers = make([]issuerPort.IssuerService, 0, len(datas))
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &issuers)
+	}
+
+	return
+}
+
+func (s *service) CircuitState(ID string) (state issuerPort.CircuitState, err error) {
+	data, err := s.issuerRepository.ReadData(ID)
+	if err != nil {
+		return
+	}
+	if state, err = s.circuitRepository.FindByIssuerCode(data.Code); err != nil {
+		return
+	}
+	state.IssuerCode = data.Code
+	if data.CircuitForcedOpen {
+		state.State = issuerPort.CircuitForcedOpen
+	}
+	return
+}
+
+func (s *service) ForceOpenCircuit(ID string, forced bool) error {
+	if _, err := s.issuerRepository.ReadData(ID); err != nil {
+		return err
+	}
+	return s.issuerRepository.UpdateCircuitForcedOpen(ID, forced)
+}
@@ -9,7 +9,12 @@ import (
 
 	issuerService "github.com/sepulsa/teleco/business/issuer"
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
 	issuerRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer"
+	issuerCircuitRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer/circuit"
+	partnerIssuerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner/issuer"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 var (
@@ -18,6 +23,7 @@ var (
 	TestLabel         = "issuer testing"
 	TestThreadNum     = 5
 	TestThreadTimeout = 30
+	TestQueueLimit    = 10
 
 	TestErrInvalidID = "Invalid ID"
 )
@@ -26,30 +32,34 @@ func TestCreateData(t *testing.T) {
 	repository := issuerRepo.New()
 
 	dataService := issuerPort.IssuerService{
-		ID:            "",
-		Code:          TestCode,
-		Label:         TestLabel,
-		ThreadNum:     TestThreadNum,
-		ThreadTimeout: TestThreadTimeout,
+		ID:               "",
+		Code:             TestCode,
+		Label:            TestLabel,
+		ThreadNum:        TestThreadNum,
+		ThreadTimeout:    TestThreadTimeout,
+		QueueWorkerLimit: TestQueueLimit,
 	}
 
 	// success
 	repository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: ""}).Once()
-	repository.On("CreateData", mock.Anything).Return(nil).Once()
-	service := issuerService.New(repository)
+	repository.On("CreateData", mock.MatchedBy(func(data issuerPort.IssuerRepo) bool {
+		return data.QueueWorkerLimit == TestQueueLimit
+	})).Return(nil).Once()
+	service := issuerService.New(repository, nil, nil)
 	err := service.CreateData(dataService)
 	assert.Nil(t, err)
 
 	// duplicate code
 	repository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: TestID}).Once()
-	service = issuerService.New(repository)
+	service = issuerService.New(repository, nil, nil)
 	err = service.CreateData(dataService)
 	assert.Equal(t, issuerService.ErrDuplicateCode, err.Error())
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
 
 	// error mongo
 	repository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: ""}).Once()
 	repository.On("CreateData", mock.Anything).Return(errors.New("")).Once()
-	service = issuerService.New(repository)
+	service = issuerService.New(repository, nil, nil)
 	err = service.CreateData(dataService)
 	assert.NotNil(t, err)
 }
@@ -59,26 +69,30 @@ func TestReadData(t *testing.T) {
 
 	id := TestID
 	dataRepo := issuerPort.IssuerRepo{
-		ID:            TestID,
-		Code:          TestCode,
-		Label:         TestLabel,
-		ThreadNum:     TestThreadNum,
-		ThreadTimeout: TestThreadTimeout,
+		ID:               TestID,
+		Code:             TestCode,
+		Label:            TestLabel,
+		ThreadNum:        TestThreadNum,
+		ThreadTimeout:    TestThreadTimeout,
+		QueueWorkerLimit: TestQueueLimit,
+		Status:           issuerPort.StatusInactive,
 	}
 
 	// success
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
-	service := issuerService.New(repository)
+	service := issuerService.New(repository, nil, nil)
 	issuer, err := service.ReadData(id)
 	if assert.Nil(t, err) {
 		assert.Equal(t, dataRepo.ID, issuer.ID)
 		assert.Equal(t, dataRepo.Code, issuer.Code)
 		assert.Equal(t, dataRepo.Label, issuer.Label)
+		assert.Equal(t, dataRepo.QueueWorkerLimit, issuer.QueueWorkerLimit)
+		assert.Equal(t, dataRepo.Status, issuer.Status)
 	}
 
 	// error
 	repository.On("ReadData", mock.Anything).Return(issuerPort.IssuerRepo{}, errors.New(TestErrInvalidID)).Once()
-	service = issuerService.New(repository)
+	service = issuerService.New(repository, nil, nil)
 	_, err = service.ReadData(id)
 	assert.Equal(t, TestErrInvalidID, err.Error())
 }
@@ -106,20 +120,20 @@ func TestUpdateData(t *testing.T) {
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
 	repository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: ""}).Once()
 	repository.On("UpdateData", mock.Anything).Return(nil).Once()
-	service := issuerService.New(repository)
+	service := issuerService.New(repository, nil, nil)
 	err := service.UpdateData(dataService)
 	assert.Nil(t, err)
 
 	// error invalid id
 	repository.On("ReadData", mock.Anything).Return(issuerPort.IssuerRepo{}, errors.New(TestErrInvalidID)).Once()
-	service = issuerService.New(repository)
+	service = issuerService.New(repository, nil, nil)
 	err = service.UpdateData(dataService)
 	assert.Equal(t, TestErrInvalidID, err.Error())
 
 	// error duplicate
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
 	repository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: TestID}).Once()
-	service = issuerService.New(repository)
+	service = issuerService.New(repository, nil, nil)
 	err = service.UpdateData(dataService)
 	assert.Equal(t, issuerService.ErrDuplicateCode, err.Error())
 
@@ -127,27 +141,40 @@ func TestUpdateData(t *testing.T) {
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
 	repository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: ""}).Once()
 	repository.On("UpdateData", mock.Anything).Return(errors.New("")).Once()
-	service = issuerService.New(repository)
+	service = issuerService.New(repository, nil, nil)
 	err = service.UpdateData(dataService)
 	assert.NotNil(t, err)
 }
 
 func TestDeleteData(t *testing.T) {
 	repository := issuerRepo.New()
+	partnerIssuerRepository := partnerIssuerRepo.New()
+	service := issuerService.New(repository, nil, partnerIssuerRepository)
 
 	id := TestID
+	mapped := query.Query{Limit: 1, Filters: map[string]string{"issuer_id": id}}
 
 	// success
-	repository.On("DeleteData", mock.Anything).Return(nil).Once()
-	service := issuerService.New(repository)
+	repository.On("ReadData", id).Return(issuerPort.IssuerRepo{ID: id}, nil).Once()
+	partnerIssuerRepository.On("SearchData", mapped).Return([]partnerIssuerPort.PartnerIssuerRepo{}, query.Page{Total: 0}, nil).Once()
+	repository.On("DeleteData", id).Return(nil).Once()
 	err := service.DeleteData(id)
 	assert.Nil(t, err)
 
+	// error still mapped to a partner
+	repository.On("ReadData", id).Return(issuerPort.IssuerRepo{ID: id}, nil).Once()
+	partnerIssuerRepository.On("SearchData", mapped).Return([]partnerIssuerPort.PartnerIssuerRepo{{ID: "m1"}}, query.Page{Total: 1}, nil).Once()
+	err = service.DeleteData(id)
+	assert.Equal(t, issuerService.ErrIssuerMapped, err.Error())
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
 	// error
-	repository.On("DeleteData", mock.Anything).Return(errors.New(TestErrInvalidID)).Once()
-	service = issuerService.New(repository)
+	repository.On("ReadData", id).Return(issuerPort.IssuerRepo{}, errors.New(TestErrInvalidID)).Once()
 	err = service.DeleteData(id)
 	assert.Equal(t, TestErrInvalidID, err.Error())
+
+	repository.AssertExpectations(t)
+	partnerIssuerRepository.AssertExpectations(t)
 }
 
 func TestListData(t *testing.T) {
@@ -165,7 +192,7 @@ func TestListData(t *testing.T) {
 
 	// success
 	repository.On("ListData").Return(dataRepo, nil).Once()
-	service := issuerService.New(repository)
+	service := issuerService.New(repository, nil, nil)
 	issuers, err := service.ListData()
 	if assert.Nil(t, err) {
 		assert.Equal(t, TestID, issuers[0].ID)
@@ -175,7 +202,67 @@ func TestListData(t *testing.T) {
 
 	// error
 	repository.On("ListData").Return([]issuerPort.IssuerRepo{}, errors.New("")).Once()
-	service = issuerService.New(repository)
+	service = issuerService.New(repository, nil, nil)
 	_, err = service.ListData()
 	assert.NotNil(t, err)
 }
+
+func TestCircuitState(t *testing.T) {
+	repository := issuerRepo.New()
+	circuitRepository := issuerCircuitRepo.New()
+
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode}, nil).Once()
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{ID: TestID, Code: TestCode, CircuitForcedOpen: true}, nil).Once()
+	circuitRepository.On("FindByIssuerCode", TestCode).Return(issuerPort.CircuitState{State: "open", Failures: 5}, nil)
+	service := issuerService.New(repository, circuitRepository, nil)
+
+	// breaker state
+	state, err := service.CircuitState(TestID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestCode, state.IssuerCode)
+		assert.Equal(t, "open", state.State)
+		assert.Equal(t, 5, state.Failures)
+	}
+
+	// forced open wins
+	state, err = service.CircuitState(TestID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, issuerPort.CircuitForcedOpen, state.State)
+	}
+}
+
+func TestForceOpenCircuit(t *testing.T) {
+	repository := issuerRepo.New()
+
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{}, errors.New(TestErrInvalidID)).Once()
+	repository.On("ReadData", TestID).Return(issuerPort.IssuerRepo{ID: TestID}, nil).Once()
+	repository.On("UpdateCircuitForcedOpen", TestID, true).Return(nil).Once()
+	service := issuerService.New(repository, nil, nil)
+
+	// error invalid id
+	err := service.ForceOpenCircuit(TestID, true)
+	assert.Equal(t, TestErrInvalidID, err.Error())
+
+	// success
+	assert.Nil(t, service.ForceOpenCircuit(TestID, true))
+	repository.AssertExpectations(t)
+}
+
+func TestSearchData(t *testing.T) {
+	repository := issuerRepo.New()
+	service := issuerService.New(repository, nil, nil)
+	listQuery := query.Query{Search: "iss", Filters: map[string]string{"status": issuerPort.StatusActive}}
+
+	// success
+	repository.On("SearchData", listQuery).Return([]issuerPort.IssuerRepo{{ID: TestID, Code: TestCode}}, query.Page{Total: 1, Page: 1, Limit: 20}, nil).Once()
+	issuers, page, err := service.SearchData(listQuery)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestCode, issuers[0].Code)
+		assert.Equal(t, 1, page.Total)
+	}
+
+	// error
+	repository.On("SearchData", listQuery).Return([]issuerPort.IssuerRepo{}, query.Page{}, errors.New(query.ErrInvalidSort)).Once()
+	_, _, err = service.SearchData(listQuery)
+	assert.Equal(t, query.ErrInvalidSort, err.Error())
+}
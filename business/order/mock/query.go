@@ -0,0 +1,25 @@
+package mock
+
+import (
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type queryService struct {
+	mock.Mock
+}
+
+func NewQuery() *queryService {
+	return &queryService{}
+}
+
+func (s *queryService) SearchData(query orderPort.OrderQuery) (orderPort.OrderList, error) {
+	result := s.Called(query)
+	return result.Get(0).(orderPort.OrderList), result.Error(1)
+}
+
+func (s *queryService) ReadData(ID string) (orderPort.OrderDetail, error) {
+	result := s.Called(ID)
+	return result.Get(0).(orderPort.OrderDetail), result.Error(1)
+}
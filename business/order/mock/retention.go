@@ -0,0 +1,20 @@
+package mock
+
+import (
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type retentionService struct {
+	mock.Mock
+}
+
+func NewRetention() *retentionService {
+	return &retentionService{}
+}
+
+func (s *retentionService) Run(dryRun bool) (orderPort.RetentionReport, error) {
+	result := s.Called(dryRun)
+	return result.Get(0).(orderPort.RetentionReport), result.Error(1)
+}
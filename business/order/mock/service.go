@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+
 	orderPort "github.com/sepulsa/teleco/business/order/port"
 
 	"github.com/stretchr/testify/mock"
@@ -14,17 +16,17 @@ func New() *service {
 	return &service{}
 }
 
-func (s *service) Purchase(order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
-	result := s.Called(order)
+func (s *service) Purchase(ctx context.Context, order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
+	result := s.Called(ctx, order)
 	return result.Get(0).(orderPort.OrderServiceResult), result.Error(1)
 }
 
-func (s *service) Advise(order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
-	result := s.Called(order)
+func (s *service) Advise(ctx context.Context, order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
+	result := s.Called(ctx, order)
 	return result.Get(0).(orderPort.OrderServiceResult), result.Error(1)
 }
 
-func (s *service) Reversal(order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
-	result := s.Called(order)
+func (s *service) Reversal(ctx context.Context, order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
+	result := s.Called(ctx, order)
 	return result.Get(0).(orderPort.OrderServiceResult), result.Error(1)
 }
@@ -1,5 +1,11 @@
 package port
 
+import (
+	"context"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+)
+
 type (
 	OrderIssuerApi struct {
 		ID                  string `json:"id"`
@@ -16,6 +22,23 @@ type (
 		PartnerCallbackUrl  string `json:"partner_callback_url"`
 		PartnerId           string `json:"partner_id"`
 		IssuerId            string `json:"issuer_id"`
+
+		PartnerReservedThread int `json:"partner_reserved_thread"`
+		PartnerMaxThread      int `json:"partner_max_thread"`
+
+		IssuerCircuitBreaker    issuerPort.CircuitBreaker `json:"issuer_circuit_breaker"`
+		IssuerCircuitForcedOpen bool                      `json:"issuer_circuit_forced_open"`
+
+		// ProductCode, Route and Price of a product order, kept on the record of a late result
+		ProductCode string     `json:"product_code"`
+		Route       int        `json:"route"`
+		Price       OrderPrice `json:"price"`
+
+		// DepositHoldId settled by whoever gets the result of a pending order
+		DepositHoldId string `json:"deposit_hold_id"`
+
+		// Queued order read from the issuer queue, it fails instead of being queued again when the pool is full
+		Queued bool `json:"queued"`
 	}
 
 	OrderIssuerApiResult struct {
@@ -26,10 +49,8 @@ type (
 		RawData             string `json:"rawdata"`
 		RequestData         string `json:"request_data"`
 		ResponseData        string `json:"response_data"`
-	}
-
-	Error struct {
-		Err error
+		// IssuerBalance deposit left at the issuer, nil when the issuer does not report it
+		IssuerBalance *int64 `json:"issuer_balance,omitempty"`
 	}
 )
 
@@ -37,22 +58,35 @@ const (
 	Purchase string = "purchase"
 	Advise   string = "advise"
 	Reversal string = "reversal"
+
+	// RescodeSuccess order completed by the issuer
+	RescodeSuccess string = "00"
+	// RescodePending order still in progress, its result is sent to the partner callback
+	RescodePending string = "10"
+	// RescodeCircuitOpen issuer not called because its circuit is open
+	RescodeCircuitOpen string = "91"
+	// RescodePoolFull queued order not called because the issuer thread pool was still full
+	RescodePoolFull string = "92"
 )
 
 // IssuerApi is outbound port
 type IssuerApi interface {
-	//Do ...
-	Do(order OrderIssuerApi) (OrderIssuerApiResult, error)
+	//Do run the order in the issuer threadpool, a result with timeout rescode is returned when ctx is done first
+	//and a result with circuit open rescode without calling the issuer while its circuit is open
+	Do(ctx context.Context, order OrderIssuerApi) (OrderIssuerApiResult, error)
 }
 
 // Issuer is outbound port
 type Issuer interface {
 	//Purchase ...
-	Purchase(order OrderIssuerApi, orderResult *OrderIssuerApiResult, err *Error)
+	Purchase(ctx context.Context, order OrderIssuerApi) (OrderIssuerApiResult, error)
 
 	//Advise ...
-	Advise(order OrderIssuerApi, orderResult *OrderIssuerApiResult, err *Error)
+	Advise(ctx context.Context, order OrderIssuerApi) (OrderIssuerApiResult, error)
 
 	//Reversal ...
-	Reversal(order OrderIssuerApi, orderResult *OrderIssuerApiResult, err *Error)
+	Reversal(ctx context.Context, order OrderIssuerApi) (OrderIssuerApiResult, error)
+
+	//Balance inquiry of the deposit left at the issuer, only the issuer and partner issuer config are set in order
+	Balance(ctx context.Context, order OrderIssuerApi) (OrderIssuerApiResult, error)
 }
@@ -1,5 +1,7 @@
 package port
 
+import "time"
+
 type (
 	OrderRepo struct {
 		ID                   string `json:"id"`
@@ -14,11 +16,83 @@ type (
 		ResponseData         string `json:"response_data"`
 		CallbackRequestData  string `json:"callback_request_data"`
 		CallbackResponseData string `json:"callback_response_data"`
+		ProductCode          string `json:"product_code"`
+		Route                int    `json:"route"`
+		// Price partner price of the product when the order was made, empty when the product has no price
+		Price OrderPrice `json:"price"`
+		// IssuerRescode issuer answer, pending while the result is sent later to the partner callback
+		IssuerRescode string    `json:"issuer_rescode"`
+		SerialNumber  string    `json:"serial_number"`
+		CreatedAt     time.Time `json:"created_at"`
+	}
+
+	// PurchaseFilter purchase records made between From, inclusive, and Until, exclusive, empty ids match any
+	PurchaseFilter struct {
+		IssuerId  string
+		PartnerId string
+		From      time.Time
+		Until     time.Time
+	}
+
+	// OrderFilter search on the order records, empty fields match any
+	OrderFilter struct {
+		PartnerId   string
+		IssuerId    string
+		CommandType string
+		// Status one of StatusSuccess, StatusFailed or StatusPending, matched against the issuer rescode
+		Status              string
+		TransactionId       string
+		IssuerTransactionId string
+		CustomerNumber      string
+		From                time.Time
+		Until               time.Time
+		// Sort SortCreatedAt or SortCreatedAtDesc, newest first when empty
+		Sort   string
+		Cursor string
+		Limit  int
+	}
+
+	OrderPrice struct {
+		PriceId      string `json:"price_id"`
+		BasePrice    int64  `json:"base_price"`
+		SellingPrice int64  `json:"selling_price"`
+		Fee          int64  `json:"fee"`
 	}
 )
 
+const (
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	// StatusPending result not known yet, sent later to the partner callback
+	StatusPending = "pending"
+
+	SortCreatedAt     = "created_at"
+	SortCreatedAtDesc = "-created_at"
+)
+
 // Repository is outbound port
 type Repository interface {
 	//CreateData insert new data
 	CreateData(issuer OrderRepo) error
+
+	//ListPurchases get the purchase records matching the filter, oldest first
+	ListPurchases(filter PurchaseFilter) ([]OrderRepo, error)
+
+	//SearchData get a page of records matching the filter and the cursor of the next page, empty on the last page
+	SearchData(filter OrderFilter) ([]OrderRepo, string, error)
+
+	//ReadData get data by ID
+	ReadData(ID string) (OrderRepo, error)
+
+	//ListTimeline get the records of a partner transaction and of its issuer transactions, oldest first
+	ListTimeline(partnerId string, transactionId string, issuerTransactionIds []string) ([]OrderRepo, error)
+
+	//RemoveData permanently remove the records, returns how many were removed
+	RemoveData(IDs []string) (int, error)
+}
+
+// Archive is outbound port keeping the records removed by the retention
+type Archive interface {
+	//Write append the records to the archive of the run started at runAt, returns where they are kept
+	Write(runAt time.Time, orders []OrderRepo) (string, error)
 }
@@ -1,16 +1,26 @@
 package port
 
+import (
+	"context"
+	"time"
+)
+
 type OrderService struct {
-	ID                  string `json:"id"`
-	TransactionId       string `json:"transaction_id"`
-	IssuerProductId     string `json:"issuer_product_id"`
-	CustomerNumber      string `json:"customer_number"`
-	PartnerCode         string `json:"partner_code"`
-	IssuerCode          string `json:"issuer_code"`
-	IssuerTransactionId string `json:"issuer_transaction_id"`
+	ID                  string     `json:"id"`
+	TransactionId       string     `json:"transaction_id"`
+	IssuerProductId     string     `json:"issuer_product_id"`
+	CustomerNumber      string     `json:"customer_number"`
+	PartnerCode         string     `json:"partner_code"`
+	IssuerCode          string     `json:"issuer_code"`
+	IssuerTransactionId string     `json:"issuer_transaction_id"`
+	ProductCode         string     `json:"product_code"`
+	Route               int        `json:"route"`
+	Price               OrderPrice `json:"price"`
+	DepositHoldId       string     `json:"deposit_hold_id"`
 }
 
 type OrderServiceResult struct {
+	IssuerCode          string `json:"issuer_code"`
 	IssuerTransactionId string `json:"issuer_transaction_id"`
 	SerialNumber        string `json:"serial_number"`
 	IssuerRescode       string `json:"issuer_rescode"`
@@ -18,14 +28,99 @@ type OrderServiceResult struct {
 	RawData             string `json:"rawdata"`
 }
 
+// OrderQuery search of the internal API, codes are resolved by the service
+type OrderQuery struct {
+	PartnerCode         string
+	IssuerCode          string
+	CommandType         string
+	Status              string
+	TransactionId       string
+	IssuerTransactionId string
+	CustomerNumber      string
+	From                time.Time
+	Until               time.Time
+	Sort                string
+	Cursor              string
+	Limit               int
+}
+
+// OrderRecord one record of the order log, raw data are redacted
+type OrderRecord struct {
+	ID                   string     `json:"id"`
+	CommandType          string     `json:"command_type"`
+	TransactionId        string     `json:"transaction_id"`
+	IssuerProductId      string     `json:"issuer_product_id"`
+	ProductCode          string     `json:"product_code"`
+	CustomerNumber       string     `json:"customer_number"`
+	PartnerCode          string     `json:"partner_code"`
+	IssuerCode           string     `json:"issuer_code"`
+	IssuerTransactionId  string     `json:"issuer_transaction_id"`
+	IssuerRescode        string     `json:"issuer_rescode"`
+	Status               string     `json:"status"`
+	SerialNumber         string     `json:"serial_number"`
+	Route                int        `json:"route"`
+	Price                OrderPrice `json:"price"`
+	RequestData          string     `json:"request_data"`
+	ResponseData         string     `json:"response_data"`
+	CallbackRequestData  string     `json:"callback_request_data"`
+	CallbackResponseData string     `json:"callback_response_data"`
+	CreatedAt            time.Time  `json:"created_at"`
+}
+
+type OrderList struct {
+	Orders     []OrderRecord `json:"orders"`
+	NextCursor string        `json:"next_cursor"`
+}
+
+// OrderDetail a record with every issuer attempt and callback of its transaction
+type OrderDetail struct {
+	OrderRecord
+	// FinalStatus last known result of the transaction
+	FinalStatus string        `json:"final_status"`
+	Timeline    []OrderRecord `json:"timeline"`
+}
+
+// RetentionReport outcome of one retention run, nothing is archived nor removed in a dry run
+type RetentionReport struct {
+	DryRun bool `json:"dry_run"`
+	// Cutoff records created before it are past the max age
+	Cutoff time.Time `json:"cutoff"`
+	// Matched records past the max age, Oldest and Newest are their creation times
+	Matched  int       `json:"matched"`
+	Oldest   time.Time `json:"oldest"`
+	Newest   time.Time `json:"newest"`
+	Archived int       `json:"archived"`
+	Deleted  int       `json:"deleted"`
+	// Location where the archived records are kept
+	Location   string    `json:"location"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// QueryService is inbound port
+type QueryService interface {
+	//SearchData get a page of orders, raw data are left out
+	SearchData(query OrderQuery) (OrderList, error)
+
+	//ReadData get an order with its timeline
+	ReadData(ID string) (OrderDetail, error)
+}
+
 // Service is inbound port
 type Service interface {
 	//Purchase ...
-	Purchase(order OrderService) (OrderServiceResult, error)
+	Purchase(ctx context.Context, order OrderService) (OrderServiceResult, error)
 
 	//Advise ...
-	Advise(order OrderService) (OrderServiceResult, error)
+	Advise(ctx context.Context, order OrderService) (OrderServiceResult, error)
 
 	//Reversal ...
-	Reversal(order OrderService) (OrderServiceResult, error)
+	Reversal(ctx context.Context, order OrderService) (OrderServiceResult, error)
+}
+
+// RetentionService is inbound port
+type RetentionService interface {
+	//Run archive then remove the records older than the max age, the report is returned with the error
+	//of an interrupted run
+	Run(dryRun bool) (RetentionReport, error)
 }
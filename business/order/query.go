@@ -0,0 +1,198 @@
+package order
+
+import (
+	"regexp"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+)
+
+type (
+	queryService struct {
+		orderRepository   orderPort.Repository
+		partnerRepository partnerPort.Repository
+		issuerRepository  issuerPort.Repository
+	}
+)
+
+const (
+	defaultLimit = 20
+	redacted     = "***"
+)
+
+var (
+	// secretField matches the value of a secret field, the json may be escaped inside another json string
+	secretField = regexp.MustCompile(`(?i)(\\?"[\w-]*(?:pin|password|secret|token|signature|authorization)[\w-]*\\?"\s*:\s*\\?")[^"\\]*`)
+	// secretHeader matches the value of an authorization header in an http dump
+	secretHeader = regexp.MustCompile(`(?im)^((?:proxy-)?authorization:[ \t]*)[^\r\n]*`)
+)
+
+// NewQuery service reading the order log for the internal API
+func NewQuery(orderRepository orderPort.Repository, partnerRepository partnerPort.Repository, issuerRepository issuerPort.Repository) orderPort.QueryService {
+	return &queryService{
+		orderRepository,
+		partnerRepository,
+		issuerRepository,
+	}
+}
+
+func (s *queryService) SearchData(query orderPort.OrderQuery) (list orderPort.OrderList, err error) {
+	list.Orders = []orderPort.OrderRecord{}
+	filter := orderPort.OrderFilter{
+		CommandType:         query.CommandType,
+		Status:              query.Status,
+		TransactionId:       query.TransactionId,
+		IssuerTransactionId: query.IssuerTransactionId,
+		CustomerNumber:      query.CustomerNumber,
+		From:                query.From,
+		Until:               query.Until,
+		Sort:                query.Sort,
+		Cursor:              query.Cursor,
+		Limit:               query.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = defaultLimit
+	}
+	// unknown codes match no order
+	if query.PartnerCode != "" {
+		if filter.PartnerId = s.partnerRepository.FindByCode(query.PartnerCode).ID; filter.PartnerId == "" {
+			return
+		}
+	}
+	if query.IssuerCode != "" {
+		if filter.IssuerId = s.issuerRepository.FindByCode(query.IssuerCode).ID; filter.IssuerId == "" {
+			return
+		}
+	}
+
+	orders, next, err := s.orderRepository.SearchData(filter)
+	if err != nil {
+		return
+	}
+	codes := newCodes(s.partnerRepository, s.issuerRepository)
+	for _, order := range orders {
+		record := codes.record(order)
+		record.RequestData = ""
+		record.ResponseData = ""
+		record.CallbackRequestData = ""
+		record.CallbackResponseData = ""
+		list.Orders = append(list.Orders, record)
+	}
+	list.NextCursor = next
+	return
+}
+
+func (s *queryService) ReadData(ID string) (detail orderPort.OrderDetail, err error) {
+	order, err := s.orderRepository.ReadData(ID)
+	if err != nil {
+		return
+	}
+
+	issuerTransactionIds := []string{}
+	if order.IssuerTransactionId != "" {
+		issuerTransactionIds = append(issuerTransactionIds, order.IssuerTransactionId)
+	}
+	orders := []orderPort.OrderRepo{order}
+	if order.TransactionId != "" {
+		if orders, err = s.orderRepository.ListTimeline(order.PartnerId, order.TransactionId, issuerTransactionIds); err != nil {
+			return
+		}
+	}
+
+	codes := newCodes(s.partnerRepository, s.issuerRepository)
+	detail.OrderRecord = codes.record(order)
+	detail.FinalStatus = orderPort.StatusPending
+	detail.Timeline = []orderPort.OrderRecord{}
+	for _, data := range orders {
+		record := codes.record(data)
+		detail.Timeline = append(detail.Timeline, record)
+		// a reversal answers for itself, not for the purchase
+		if record.CommandType != orderPort.Reversal && record.Status != orderPort.StatusPending {
+			detail.FinalStatus = record.Status
+		}
+	}
+	return
+}
+
+// codes resolves partner and issuer ids once per call
+type codes struct {
+	partnerRepository partnerPort.Repository
+	issuerRepository  issuerPort.Repository
+	partners          map[string]string
+	issuers           map[string]string
+}
+
+func newCodes(partnerRepository partnerPort.Repository, issuerRepository issuerPort.Repository) *codes {
+	return &codes{
+		partnerRepository,
+		issuerRepository,
+		map[string]string{},
+		map[string]string{},
+	}
+}
+
+func (c *codes) partner(ID string) string {
+	if ID == "" {
+		return ""
+	}
+	if code, ok := c.partners[ID]; ok {
+		return code
+	}
+	partner, _ := c.partnerRepository.ReadData(ID)
+	c.partners[ID] = partner.Code
+	return partner.Code
+}
+
+func (c *codes) issuer(ID string) string {
+	if ID == "" {
+		return ""
+	}
+	if code, ok := c.issuers[ID]; ok {
+		return code
+	}
+	issuer, _ := c.issuerRepository.ReadData(ID)
+	c.issuers[ID] = issuer.Code
+	return issuer.Code
+}
+
+func (c *codes) record(order orderPort.OrderRepo) orderPort.OrderRecord {
+	return orderPort.OrderRecord{
+		ID:                   order.ID,
+		CommandType:          order.CommandType,
+		TransactionId:        order.TransactionId,
+		IssuerProductId:      order.IssuerProductId,
+		ProductCode:          order.ProductCode,
+		CustomerNumber:       order.CustomerNumber,
+		PartnerCode:          c.partner(order.PartnerId),
+		IssuerCode:           c.issuer(order.IssuerId),
+		IssuerTransactionId:  order.IssuerTransactionId,
+		IssuerRescode:        order.IssuerRescode,
+		Status:               Status(order.IssuerRescode),
+		SerialNumber:         order.SerialNumber,
+		Route:                order.Route,
+		Price:                order.Price,
+		RequestData:          Redact(order.RequestData),
+		ResponseData:         Redact(order.ResponseData),
+		CallbackRequestData:  Redact(order.CallbackRequestData),
+		CallbackResponseData: Redact(order.CallbackResponseData),
+		CreatedAt:            order.CreatedAt,
+	}
+}
+
+// Status of an issuer rescode
+func Status(rescode string) string {
+	switch rescode {
+	case orderPort.RescodeSuccess:
+		return orderPort.StatusSuccess
+	case orderPort.RescodePending, "":
+		return orderPort.StatusPending
+	}
+	return orderPort.StatusFailed
+}
+
+// Redact masks credentials kept in the raw request and response dumps
+func Redact(raw string) string {
+	raw = secretField.ReplaceAllString(raw, "${1}"+redacted)
+	return secretHeader.ReplaceAllString(raw, "${1}"+redacted)
+}
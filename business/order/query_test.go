@@ -0,0 +1,99 @@
+package order_test
+
+import (
+	"errors"
+	"testing"
+
+	orderService "github.com/sepulsa/teleco/business/order"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	issuerRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer"
+	orderRepo "github.com/sepulsa/teleco/modules/repository/mock/order"
+	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	ErrInvalidCursor = "Invalid cursor"
+	ErrOrderNotFound = "Order not found"
+)
+
+func TestSearchData(t *testing.T) {
+	orderRepository := orderRepo.New()
+	issuerRepository := issuerRepo.New()
+	partnerRepository := partnerRepo.New()
+	service := orderService.NewQuery(orderRepository, partnerRepository, issuerRepository)
+
+	// unknown partner matches no order
+	partnerRepository.On("FindByCode", "unknown").Return(partnerPort.PartnerRepo{}).Once()
+	list, err := service.SearchData(orderPort.OrderQuery{PartnerCode: "unknown"})
+	assert.Nil(t, err)
+	assert.Empty(t, list.Orders)
+	orderRepository.AssertNotCalled(t, "SearchData", mock.Anything)
+
+	// Error invalid cursor
+	orderRepository.On("SearchData", orderPort.OrderFilter{Cursor: "x", Limit: 20}).Return([]orderPort.OrderRepo{}, "", errors.New(ErrInvalidCursor)).Once()
+	_, err = service.SearchData(orderPort.OrderQuery{Cursor: "x"})
+	assert.Equal(t, ErrInvalidCursor, err.Error())
+
+	// Success codes resolved, raw data left out
+	partnerRepository.On("FindByCode", "partner").Return(partnerPort.PartnerRepo{ID: "p1", Code: "partner"}).Once()
+	partnerRepository.On("ReadData", "p1").Return(partnerPort.PartnerRepo{ID: "p1", Code: "partner"}, nil).Once()
+	issuerRepository.On("ReadData", "i1").Return(issuerPort.IssuerRepo{ID: "i1", Code: "dummy"}, nil).Once()
+	orderRepository.On("SearchData", orderPort.OrderFilter{PartnerId: "p1", Status: orderPort.StatusFailed, Limit: 2}).Return([]orderPort.OrderRepo{
+		{ID: "o1", PartnerId: "p1", IssuerId: "i1", IssuerRescode: "05", RequestData: "{}"},
+		{ID: "o2", PartnerId: "p1", IssuerId: "i1", IssuerRescode: "06"},
+	}, "next", nil).Once()
+	list, err = service.SearchData(orderPort.OrderQuery{PartnerCode: "partner", Status: orderPort.StatusFailed, Limit: 2})
+	if assert.Nil(t, err) && assert.Len(t, list.Orders, 2) {
+		assert.Equal(t, "next", list.NextCursor)
+		assert.Equal(t, "partner", list.Orders[0].PartnerCode)
+		assert.Equal(t, "dummy", list.Orders[1].IssuerCode)
+		assert.Equal(t, orderPort.StatusFailed, list.Orders[0].Status)
+		assert.Empty(t, list.Orders[0].RequestData)
+	}
+	partnerRepository.AssertExpectations(t)
+	issuerRepository.AssertExpectations(t)
+}
+
+func TestReadData(t *testing.T) {
+	orderRepository := orderRepo.New()
+	issuerRepository := issuerRepo.New()
+	partnerRepository := partnerRepo.New()
+	service := orderService.NewQuery(orderRepository, partnerRepository, issuerRepository)
+
+	// Error not found
+	orderRepository.On("ReadData", "missing").Return(orderPort.OrderRepo{}, errors.New(ErrOrderNotFound)).Once()
+	_, err := service.ReadData("missing")
+	assert.Equal(t, ErrOrderNotFound, err.Error())
+
+	// Success timeline with a pending purchase settled by its callback
+	purchase := orderPort.OrderRepo{
+		ID:                  "o1",
+		CommandType:         orderPort.Purchase,
+		TransactionId:       "trx1",
+		PartnerId:           "p1",
+		IssuerId:            "i1",
+		IssuerTransactionId: "itrx1",
+		IssuerRescode:       orderPort.RescodePending,
+		RequestData:         `{"header":{"Authorization":"Bearer abc"},"body":"{\"id\":\"1\",\"pin\":\"1234\"}"}`,
+		ResponseData:        "HTTP/1.1 200 OK\r\nAuthorization: Bearer abc\r\n\r\n{}",
+	}
+	callback := orderPort.OrderRepo{ID: "o2", CommandType: orderPort.Purchase, TransactionId: "trx1", PartnerId: "p1", IssuerId: "i1", IssuerTransactionId: "itrx1", IssuerRescode: orderPort.RescodeSuccess, CallbackRequestData: `{"password":"secret"}`}
+	orderRepository.On("ReadData", "o1").Return(purchase, nil).Once()
+	orderRepository.On("ListTimeline", "p1", "trx1", []string{"itrx1"}).Return([]orderPort.OrderRepo{purchase, callback}, nil).Once()
+	partnerRepository.On("ReadData", "p1").Return(partnerPort.PartnerRepo{ID: "p1", Code: "partner"}, nil).Once()
+	issuerRepository.On("ReadData", "i1").Return(issuerPort.IssuerRepo{ID: "i1", Code: "dummy"}, nil).Once()
+	detail, err := service.ReadData("o1")
+	if assert.Nil(t, err) && assert.Len(t, detail.Timeline, 2) {
+		assert.Equal(t, orderPort.StatusPending, detail.Status)
+		assert.Equal(t, orderPort.StatusSuccess, detail.FinalStatus)
+		assert.Equal(t, "partner", detail.PartnerCode)
+		assert.Equal(t, `{"header":{"Authorization":"***"},"body":"{\"id\":\"1\",\"pin\":\"***\"}"}`, detail.Timeline[0].RequestData)
+		assert.Equal(t, "HTTP/1.1 200 OK\r\nAuthorization: ***\r\n\r\n{}", detail.Timeline[0].ResponseData)
+		assert.Equal(t, `{"password":"***"}`, detail.Timeline[1].CallbackRequestData)
+	}
+}
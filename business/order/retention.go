@@ -0,0 +1,90 @@
+package order
+
+import (
+	"errors"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/sepulsa/teleco/utils/mgo"
+)
+
+type (
+	retentionService struct {
+		orderRepository orderPort.Repository
+		archive         orderPort.Archive
+		// maxAge age of the records kept in the order collection
+		maxAge mgo.MaxAge
+	}
+)
+
+var (
+	ErrRetentionDisabled = "Order retention is disabled, database.mongo.max_age is not set"
+
+	// RetentionBatchSize records archived then removed at once
+	RetentionBatchSize = 500
+)
+
+// NewRetention service moving the records past the max age from the order collection to the archive
+func NewRetention(orderRepository orderPort.Repository, archive orderPort.Archive, maxAge mgo.MaxAge) orderPort.RetentionService {
+	return &retentionService{
+		orderRepository,
+		archive,
+		maxAge,
+	}
+}
+
+// Run reads the records oldest first, each batch is removed only once it is archived, so an interrupted
+// run leaves every record either in the collection or in the archive
+func (s *retentionService) Run(dryRun bool) (report orderPort.RetentionReport, err error) {
+	report.DryRun = dryRun
+	report.StartedAt = time.Now()
+	defer func() {
+		report.FinishedAt = time.Now()
+	}()
+	if s.maxAge.IsZero() {
+		err = errors.New(ErrRetentionDisabled)
+		return
+	}
+	report.Cutoff = s.maxAge.Cutoff(report.StartedAt)
+
+	filter := orderPort.OrderFilter{
+		Until: report.Cutoff,
+		Sort:  orderPort.SortCreatedAt,
+		Limit: RetentionBatchSize,
+	}
+	for {
+		var orders []orderPort.OrderRepo
+		if orders, filter.Cursor, err = s.orderRepository.SearchData(filter); err != nil {
+			return
+		}
+		if len(orders) == 0 {
+			return
+		}
+		if report.Matched == 0 {
+			report.Oldest = orders[0].CreatedAt
+		}
+		report.Matched += len(orders)
+		report.Newest = orders[len(orders)-1].CreatedAt
+
+		if !dryRun {
+			if report.Location, err = s.archive.Write(report.StartedAt, orders); err != nil {
+				return
+			}
+			report.Archived += len(orders)
+
+			IDs := make([]string, 0, len(orders))
+			for _, order := range orders {
+				IDs = append(IDs, order.ID)
+			}
+			var removed int
+			if removed, err = s.orderRepository.RemoveData(IDs); err != nil {
+				return
+			}
+			report.Deleted += removed
+		}
+		// the cursor keeps its position once the records before it are removed
+		if filter.Cursor == "" {
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package order_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	orderService "github.com/sepulsa/teleco/business/order"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	archiveMock "github.com/sepulsa/teleco/modules/archive/mock"
+	orderRepo "github.com/sepulsa/teleco/modules/repository/mock/order"
+	"github.com/sepulsa/teleco/utils/mgo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRetentionRun(t *testing.T) {
+	defer func(batchSize int) {
+		orderService.RetentionBatchSize = batchSize
+	}(orderService.RetentionBatchSize)
+	orderService.RetentionBatchSize = 2
+
+	orderRepository := orderRepo.New()
+	archive := archiveMock.New()
+
+	// Error max age not set
+	service := orderService.NewRetention(orderRepository, archive, mgo.MaxAge{})
+	_, err := service.Run(false)
+	assert.Equal(t, orderService.ErrRetentionDisabled, err.Error())
+	orderRepository.AssertNotCalled(t, "SearchData", mock.Anything)
+
+	service = orderService.NewRetention(orderRepository, archive, mgo.MaxAge{Months: 6})
+	first := []orderPort.OrderRepo{
+		{ID: "o1", CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: "o2", CreatedAt: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	second := []orderPort.OrderRepo{
+		{ID: "o3", CreatedAt: time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)},
+	}
+	batch := func(cursor string) interface{} {
+		return mock.MatchedBy(func(filter orderPort.OrderFilter) bool {
+			return filter.Cursor == cursor && filter.Sort == orderPort.SortCreatedAt && filter.Limit == 2 &&
+				filter.Until.Before(time.Now().AddDate(0, -6, 1)) && filter.Until.After(time.Now().AddDate(0, -6, -1))
+		})
+	}
+
+	// Success dry run only counts
+	orderRepository.On("SearchData", batch("")).Return(first, "next", nil).Once()
+	orderRepository.On("SearchData", batch("next")).Return(second, "", nil).Once()
+	report, err := service.Run(true)
+	if assert.Nil(t, err) {
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 3, report.Matched)
+		assert.Equal(t, first[0].CreatedAt, report.Oldest)
+		assert.Equal(t, second[0].CreatedAt, report.Newest)
+		assert.Zero(t, report.Archived)
+		assert.Zero(t, report.Deleted)
+	}
+	archive.AssertNotCalled(t, "Write", mock.Anything, mock.Anything)
+	orderRepository.AssertNotCalled(t, "RemoveData", mock.Anything)
+
+	// Error archive failed, the batch is kept
+	orderRepository.On("SearchData", batch("")).Return(first, "next", nil).Once()
+	archive.On("Write", mock.Anything, first).Return("", errors.New("disk full")).Once()
+	report, err = service.Run(false)
+	assert.Equal(t, "disk full", err.Error())
+	assert.Equal(t, 2, report.Matched)
+	assert.Zero(t, report.Archived)
+	orderRepository.AssertNotCalled(t, "RemoveData", mock.Anything)
+
+	// Success every batch archived then removed
+	orderRepository.On("SearchData", batch("")).Return(first, "next", nil).Once()
+	orderRepository.On("SearchData", batch("next")).Return(second, "", nil).Once()
+	archive.On("Write", mock.Anything, first).Return("archive/orders.jsonl.gz", nil).Once()
+	archive.On("Write", mock.Anything, second).Return("archive/orders.jsonl.gz", nil).Once()
+	orderRepository.On("RemoveData", []string{"o1", "o2"}).Return(2, nil).Once()
+	orderRepository.On("RemoveData", []string{"o3"}).Return(1, nil).Once()
+	report, err = service.Run(false)
+	if assert.Nil(t, err) {
+		assert.False(t, report.DryRun)
+		assert.Equal(t, 3, report.Matched)
+		assert.Equal(t, 3, report.Archived)
+		assert.Equal(t, 3, report.Deleted)
+		assert.Equal(t, "archive/orders.jsonl.gz", report.Location)
+		assert.False(t, report.FinishedAt.Before(report.StartedAt))
+	}
+	orderRepository.AssertExpectations(t)
+	archive.AssertExpectations(t)
+}
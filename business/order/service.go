@@ -1,12 +1,20 @@
 package order
 
 import (
+	"context"
 	"errors"
+	"time"
 
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
 	orderPort "github.com/sepulsa/teleco/business/order/port"
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	"github.com/sepulsa/teleco/utils/apperror"
 )
 
 type (
@@ -16,24 +24,137 @@ type (
 		partnerIssuerRepository partnerIssuerPort.Repository
 		orderRepository         orderPort.Repository
 		issuerApi               orderPort.IssuerApi
+		productRepository       productPort.Repository
+		routeService            routePort.Service
+		priceService            pricePort.Service
+		depositService          depositPort.Service
+		ratelimitService        ratelimitPort.Service
 	}
 )
 
 var (
-	ErrConfigNotFound = "Partner Issuer Config Not Found"
+	ErrConfigNotFound    = "Partner Issuer Config Not Found"
+	ErrRouteNotAvailable = "No route available for the product"
+	ErrProductNotFound   = "Product not found"
+	ErrProductInactive   = "Product is not available"
+	ErrIssuerRateLimited = "Too many requests to the issuers of the product"
 )
 
-func New(issuerRepository issuerPort.Repository, partnerRepository partnerPort.Repository, partnerIssuerRepository partnerIssuerPort.Repository, orderRepository orderPort.Repository, issuerApi orderPort.IssuerApi) orderPort.Service {
+func New(issuerRepository issuerPort.Repository, partnerRepository partnerPort.Repository, partnerIssuerRepository partnerIssuerPort.Repository, orderRepository orderPort.Repository, issuerApi orderPort.IssuerApi, productRepository productPort.Repository, routeService routePort.Service, priceService pricePort.Service, depositService depositPort.Service, ratelimitService ratelimitPort.Service) orderPort.Service {
 	return &service{
 		issuerRepository,
 		partnerRepository,
 		partnerIssuerRepository,
 		orderRepository,
 		issuerApi,
+		productRepository,
+		routeService,
+		priceService,
+		depositService,
+		ratelimitService,
 	}
 }
 
-func (s *service) Purchase(order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
+func (s *service) Purchase(ctx context.Context, order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
+	if order.ProductCode == "" {
+		// issuer product orders have no price, only postpaid partners get through
+		if _, err := s.depositService.Hold(order.PartnerCode, order.TransactionId, 0); err != nil {
+			return orderPort.OrderServiceResult{}, err
+		}
+		return s.purchase(ctx, order)
+	}
+
+	product := s.productRepository.FindByCode(order.ProductCode)
+	if product.ID == "" {
+		return orderPort.OrderServiceResult{}, errors.New(ErrProductNotFound)
+	}
+	if !product.Active {
+		return orderPort.OrderServiceResult{}, errors.New(ErrProductInactive)
+	}
+
+	route, err := s.routeService.Plan(order.ProductCode)
+	if err != nil {
+		return orderPort.OrderServiceResult{}, err
+	}
+
+	// keep the price of the purchase time on the order, failing over does not change it,
+	// a product without price goes on unpriced
+	quote, err := s.priceService.Quote(order.PartnerCode, order.ProductCode, time.Now())
+	if err == nil {
+		order.Price = orderPort.OrderPrice(quote)
+	} else if apperror.KindOf(err) != apperror.NotFound {
+		return orderPort.OrderServiceResult{}, err
+	}
+
+	// take the price from the prepaid deposit before any issuer is called
+	order.DepositHoldId, err = s.depositService.Hold(order.PartnerCode, order.TransactionId, order.Price.SellingPrice+order.Price.Fee)
+	if err != nil {
+		return orderPort.OrderServiceResult{}, err
+	}
+
+	result, err := s.purchaseRoute(ctx, order, route)
+	s.depositService.Settle(order.DepositHoldId, result.IssuerRescode, result.IssuerTransactionId, err != nil)
+
+	return result, err
+}
+
+// purchaseRoute try the product route candidates in plan order, failing over on the route rescodes
+func (s *service) purchaseRoute(ctx context.Context, order orderPort.OrderService, route routePort.RouteService) (orderPort.OrderServiceResult, error) {
+	var result orderPort.OrderServiceResult
+	err := errors.New(ErrRouteNotAvailable)
+	for i, candidate := range route.Candidates {
+		routed := order
+		routed.IssuerCode = candidate.IssuerCode
+		routed.IssuerProductId = candidate.IssuerProductId
+		routed.Route = i + 1
+
+		if s.issuerLimited(order.PartnerCode, candidate.IssuerCode) {
+			// the partner used up its rate limit of this issuer, the request only took the partner one
+			err = errors.New(ErrIssuerRateLimited)
+			continue
+		}
+		candidateResult, candidateErr := s.purchase(ctx, routed)
+		if candidateErr != nil && candidateErr.Error() == ErrConfigNotFound {
+			// partner is not mapped to this issuer
+			continue
+		}
+		result, err = candidateResult, candidateErr
+		if err != nil || !failover(route.FailoverRescodes, result.IssuerRescode) {
+			break
+		}
+	}
+
+	return result, err
+}
+
+// issuerLimited whether the partner used up its rate limit of the issuer, the order goes on when the limiter
+// store fails and is limited when its bucket is contended, as the rate limit middleware does
+func (s *service) issuerLimited(partnerCode string, issuerCode string) bool {
+	result, err := s.ratelimitService.AllowIssuer(partnerCode, issuerCode)
+	if apperror.KindOf(err) == apperror.Conflict {
+		return true
+	}
+	return err == nil && !result.Allowed
+}
+
+// failover whether the next candidate is tried, an open circuit always is, a pending order never is
+// since the issuer may still complete it under the same transaction id and deposit hold
+func failover(rescodes []string, rescode string) bool {
+	switch rescode {
+	case orderPort.RescodeCircuitOpen:
+		return true
+	case orderPort.RescodePending:
+		return false
+	}
+	for _, code := range rescodes {
+		if code == rescode {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) purchase(ctx context.Context, order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
 	// Get Data Partner Issuer
 	issuerData := s.issuerRepository.FindByCode(order.IssuerCode)
 	partnerData := s.partnerRepository.FindByCode(order.PartnerCode)
@@ -58,8 +179,19 @@ func (s *service) Purchase(order orderPort.OrderService) (orderPort.OrderService
 		PartnerCallbackUrl:  partnerData.CallbackUrl,
 		PartnerId:           partnerData.ID,
 		IssuerId:            issuerData.ID,
+
+		PartnerReservedThread: partnerIssuerData.ReservedThread,
+		PartnerMaxThread:      partnerIssuerData.MaxThread,
+
+		IssuerCircuitBreaker:    issuerData.CircuitBreaker,
+		IssuerCircuitForcedOpen: issuerData.CircuitForcedOpen,
+
+		ProductCode:   order.ProductCode,
+		Route:         order.Route,
+		Price:         order.Price,
+		DepositHoldId: order.DepositHoldId,
 	}
-	issuerResult, errApi := s.issuerApi.Do(orderIssuer)
+	issuerResult, errApi := s.issuerApi.Do(ctx, orderIssuer)
 
 	// Store Log Data
 	orderData := orderPort.OrderRepo{
@@ -72,6 +204,11 @@ func (s *service) Purchase(order orderPort.OrderService) (orderPort.OrderService
 		IssuerTransactionId: issuerResult.IssuerTransactionId,
 		RequestData:         issuerResult.RequestData,
 		ResponseData:        issuerResult.ResponseData,
+		ProductCode:         order.ProductCode,
+		Route:               order.Route,
+		Price:               order.Price,
+		IssuerRescode:       issuerResult.IssuerRescode,
+		SerialNumber:        issuerResult.SerialNumber,
 	}
 	s.orderRepository.CreateData(orderData)
 
@@ -80,6 +217,7 @@ func (s *service) Purchase(order orderPort.OrderService) (orderPort.OrderService
 	}
 
 	result := orderPort.OrderServiceResult{
+		IssuerCode:          order.IssuerCode,
 		IssuerTransactionId: issuerResult.IssuerTransactionId,
 		SerialNumber:        issuerResult.SerialNumber,
 		IssuerRescode:       issuerResult.IssuerRescode,
@@ -90,7 +228,7 @@ func (s *service) Purchase(order orderPort.OrderService) (orderPort.OrderService
 	return result, nil
 }
 
-func (s *service) Advise(order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
+func (s *service) Advise(ctx context.Context, order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
 	// Get Data Partner Issuer
 	issuerData := s.issuerRepository.FindByCode(order.IssuerCode)
 	partnerData := s.partnerRepository.FindByCode(order.PartnerCode)
@@ -113,8 +251,14 @@ func (s *service) Advise(order orderPort.OrderService) (orderPort.OrderServiceRe
 		PartnerCallbackUrl:  partnerData.CallbackUrl,
 		PartnerId:           partnerData.ID,
 		IssuerId:            issuerData.ID,
+
+		PartnerReservedThread: partnerIssuerData.ReservedThread,
+		PartnerMaxThread:      partnerIssuerData.MaxThread,
+
+		IssuerCircuitBreaker:    issuerData.CircuitBreaker,
+		IssuerCircuitForcedOpen: issuerData.CircuitForcedOpen,
 	}
-	issuerResult, errApi := s.issuerApi.Do(orderIssuer)
+	issuerResult, errApi := s.issuerApi.Do(ctx, orderIssuer)
 
 	// Store Log Data
 	orderData := orderPort.OrderRepo{
@@ -124,6 +268,7 @@ func (s *service) Advise(order orderPort.OrderService) (orderPort.OrderServiceRe
 		IssuerId:            issuerData.ID,
 		RequestData:         issuerResult.RequestData,
 		ResponseData:        issuerResult.ResponseData,
+		IssuerRescode:       issuerResult.IssuerRescode,
 	}
 	s.orderRepository.CreateData(orderData)
 
@@ -132,6 +277,7 @@ func (s *service) Advise(order orderPort.OrderService) (orderPort.OrderServiceRe
 	}
 
 	result := orderPort.OrderServiceResult{
+		IssuerCode:          order.IssuerCode,
 		IssuerTransactionId: issuerResult.IssuerTransactionId,
 		SerialNumber:        issuerResult.SerialNumber,
 		IssuerRescode:       issuerResult.IssuerRescode,
@@ -142,7 +288,7 @@ func (s *service) Advise(order orderPort.OrderService) (orderPort.OrderServiceRe
 	return result, nil
 }
 
-func (s *service) Reversal(order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
+func (s *service) Reversal(ctx context.Context, order orderPort.OrderService) (orderPort.OrderServiceResult, error) {
 	// Get Data Partner Issuer
 	issuerData := s.issuerRepository.FindByCode(order.IssuerCode)
 	partnerData := s.partnerRepository.FindByCode(order.PartnerCode)
@@ -165,8 +311,14 @@ func (s *service) Reversal(order orderPort.OrderService) (orderPort.OrderService
 		PartnerCallbackUrl:  partnerData.CallbackUrl,
 		PartnerId:           partnerData.ID,
 		IssuerId:            issuerData.ID,
+
+		PartnerReservedThread: partnerIssuerData.ReservedThread,
+		PartnerMaxThread:      partnerIssuerData.MaxThread,
+
+		IssuerCircuitBreaker:    issuerData.CircuitBreaker,
+		IssuerCircuitForcedOpen: issuerData.CircuitForcedOpen,
 	}
-	issuerResult, errApi := s.issuerApi.Do(orderIssuer)
+	issuerResult, errApi := s.issuerApi.Do(ctx, orderIssuer)
 
 	// Store Log Data
 	orderData := orderPort.OrderRepo{
@@ -176,6 +328,7 @@ func (s *service) Reversal(order orderPort.OrderService) (orderPort.OrderService
 		PartnerId:           partnerData.ID,
 		IssuerId:            issuerData.ID,
 		IssuerTransactionId: issuerResult.IssuerTransactionId,
+		IssuerRescode:       issuerResult.IssuerRescode,
 	}
 	s.orderRepository.CreateData(orderData)
 
@@ -183,7 +336,13 @@ func (s *service) Reversal(order orderPort.OrderService) (orderPort.OrderService
 		return orderPort.OrderServiceResult{}, errApi
 	}
 
+	// give the deposit back once the issuer confirms the reversal
+	if issuerResult.IssuerRescode == orderPort.RescodeSuccess {
+		s.depositService.Refund(order.PartnerCode, order.IssuerTransactionId)
+	}
+
 	result := orderPort.OrderServiceResult{
+		IssuerCode:          order.IssuerCode,
 		Message:             issuerResult.Message,
 		RawData:             issuerResult.RawData,
 		IssuerTransactionId: issuerResult.IssuerTransactionId,
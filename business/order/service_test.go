@@ -1,8 +1,10 @@
 package order_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	orderService "github.com/sepulsa/teleco/business/order"
 	orderPort "github.com/sepulsa/teleco/business/order/port"
@@ -10,12 +12,22 @@ import (
 	orderRepo "github.com/sepulsa/teleco/modules/repository/mock/order"
 	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
 	partnerIssuerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner/issuer"
+	productRepo "github.com/sepulsa/teleco/modules/repository/mock/product"
 
+	depositService "github.com/sepulsa/teleco/business/deposit/mock"
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	priceService "github.com/sepulsa/teleco/business/price/mock"
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	ratelimitService "github.com/sepulsa/teleco/business/ratelimit/mock"
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+	routeService "github.com/sepulsa/teleco/business/route/mock"
+	routePort "github.com/sepulsa/teleco/business/route/port"
 
 	issuerApi "github.com/sepulsa/teleco/modules/issuerapi/mock"
+	"github.com/sepulsa/teleco/utils/apperror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -23,6 +35,7 @@ import (
 var (
 	ErrIssuerCodeNotFound = "Issuer Code Not Found"
 	ErrConfigNotFound     = "Partner Issuer Config Not Found"
+	ErrUnpricedOrder      = "Prepaid orders need a product price"
 )
 
 func TestPurchase(t *testing.T) {
@@ -31,30 +44,37 @@ func TestPurchase(t *testing.T) {
 	partnerRepository := partnerRepo.New()
 	partnerIssuerRepository := partnerIssuerRepo.New()
 	issuerApi := issuerApi.New()
-	service := orderService.New(issuerRepository, partnerRepository, partnerIssuerRepository, orderRepository, issuerApi)
+	depositService := depositService.New()
+	service := orderService.New(issuerRepository, partnerRepository, partnerIssuerRepository, orderRepository, issuerApi, nil, nil, nil, depositService, nil)
+
+	// Error prepaid partner without product price
+	depositService.On("Hold", "prepaid", mock.Anything, int64(0)).Return("", errors.New(ErrUnpricedOrder)).Once()
+	_, err := service.Purchase(context.Background(), orderPort.OrderService{PartnerCode: "prepaid"})
+	assert.Equal(t, ErrUnpricedOrder, err.Error())
+	depositService.On("Hold", mock.Anything, mock.Anything, int64(0)).Return("", nil)
 
 	// Error Partner Issuer Not found
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(ErrConfigNotFound)).Once()
-	_, err := service.Purchase(orderPort.OrderService{})
+	_, err = service.Purchase(context.Background(), orderPort.OrderService{})
 	assert.NotNil(t, err)
 	assert.Equal(t, ErrConfigNotFound, err.Error())
 
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{Config: "{\"\":\"\"}"}, nil).Once()
-	issuerApi.On("Do", mock.Anything).Return(orderPort.OrderIssuerApiResult{}, errors.New("test error")).Once()
+	issuerApi.On("Do", mock.Anything, mock.Anything).Return(orderPort.OrderIssuerApiResult{}, errors.New("test error")).Once()
 	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
-	_, err = service.Purchase(orderPort.OrderService{})
+	_, err = service.Purchase(context.Background(), orderPort.OrderService{})
 	assert.NotNil(t, err)
 
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{Config: "{\"\":\"\"}"}, nil).Once()
-	issuerApi.On("Do", mock.Anything).Return(orderPort.OrderIssuerApiResult{}, nil).Once()
+	issuerApi.On("Do", mock.Anything, mock.Anything).Return(orderPort.OrderIssuerApiResult{}, nil).Once()
 	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
-	_, err = service.Purchase(orderPort.OrderService{})
+	_, err = service.Purchase(context.Background(), orderPort.OrderService{})
 	assert.Nil(t, err)
 }
 
@@ -64,30 +84,30 @@ func TestAdvise(t *testing.T) {
 	partnerRepository := partnerRepo.New()
 	partnerIssuerRepository := partnerIssuerRepo.New()
 	issuerApi := issuerApi.New()
-	service := orderService.New(issuerRepository, partnerRepository, partnerIssuerRepository, orderRepository, issuerApi)
+	service := orderService.New(issuerRepository, partnerRepository, partnerIssuerRepository, orderRepository, issuerApi, nil, nil, nil, nil, nil)
 
 	// Error Partner Issuer Not found
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(ErrConfigNotFound)).Once()
-	_, err := service.Advise(orderPort.OrderService{})
+	_, err := service.Advise(context.Background(), orderPort.OrderService{})
 	assert.NotNil(t, err)
 	assert.Equal(t, ErrConfigNotFound, err.Error())
 
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{Config: "{\"\":\"\"}"}, nil).Once()
-	issuerApi.On("Do", mock.Anything).Return(orderPort.OrderIssuerApiResult{}, errors.New("test error")).Once()
+	issuerApi.On("Do", mock.Anything, mock.Anything).Return(orderPort.OrderIssuerApiResult{}, errors.New("test error")).Once()
 	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
-	_, err = service.Advise(orderPort.OrderService{})
+	_, err = service.Advise(context.Background(), orderPort.OrderService{})
 	assert.NotNil(t, err)
 
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{Config: "{\"\":\"\"}"}, nil).Once()
-	issuerApi.On("Do", mock.Anything).Return(orderPort.OrderIssuerApiResult{}, nil).Once()
+	issuerApi.On("Do", mock.Anything, mock.Anything).Return(orderPort.OrderIssuerApiResult{}, nil).Once()
 	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
-	_, err = service.Advise(orderPort.OrderService{})
+	_, err = service.Advise(context.Background(), orderPort.OrderService{})
 	assert.Nil(t, err)
 }
 
@@ -97,29 +117,178 @@ func TestReversal(t *testing.T) {
 	partnerRepository := partnerRepo.New()
 	partnerIssuerRepository := partnerIssuerRepo.New()
 	issuerApi := issuerApi.New()
-	service := orderService.New(issuerRepository, partnerRepository, partnerIssuerRepository, orderRepository, issuerApi)
+	depositService := depositService.New()
+	service := orderService.New(issuerRepository, partnerRepository, partnerIssuerRepository, orderRepository, issuerApi, nil, nil, nil, depositService, nil)
 
 	// Error Partner Issuer Not found
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(ErrConfigNotFound)).Once()
-	_, err := service.Reversal(orderPort.OrderService{})
+	_, err := service.Reversal(context.Background(), orderPort.OrderService{})
 	assert.NotNil(t, err)
 	assert.Equal(t, ErrConfigNotFound, err.Error())
 
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{Config: "{\"\":\"\"}"}, nil).Once()
-	issuerApi.On("Do", mock.Anything).Return(orderPort.OrderIssuerApiResult{}, errors.New("test error")).Once()
+	issuerApi.On("Do", mock.Anything, mock.Anything).Return(orderPort.OrderIssuerApiResult{}, errors.New("test error")).Once()
 	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
-	_, err = service.Reversal(orderPort.OrderService{})
+	_, err = service.Reversal(context.Background(), orderPort.OrderService{})
 	assert.NotNil(t, err)
 
 	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
 	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
 	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{Config: "{\"\":\"\"}"}, nil).Once()
-	issuerApi.On("Do", mock.Anything).Return(orderPort.OrderIssuerApiResult{}, nil).Once()
+	issuerApi.On("Do", mock.Anything, mock.Anything).Return(orderPort.OrderIssuerApiResult{}, nil).Once()
 	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
-	_, err = service.Reversal(orderPort.OrderService{})
+	_, err = service.Reversal(context.Background(), orderPort.OrderService{})
 	assert.Nil(t, err)
+	// confirmed reversal refunds the deposit
+	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"}).Once()
+	issuerRepository.On("FindByCode", mock.Anything).Return(issuerPort.IssuerRepo{ID: "12345", Config: "\"\":\"\""}).Once()
+	partnerIssuerRepository.On("FindByPartnerIssuerID", mock.Anything, mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{Config: "{\"\":\"\"}"}, nil).Once()
+	issuerApi.On("Do", mock.Anything, mock.Anything).Return(orderPort.OrderIssuerApiResult{IssuerRescode: orderPort.RescodeSuccess}, nil).Once()
+	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
+	depositService.On("Refund", "partner001", "ISS1001").Return(nil).Once()
+	_, err = service.Reversal(context.Background(), orderPort.OrderService{PartnerCode: "partner001", IssuerTransactionId: "ISS1001"})
+	assert.Nil(t, err)
+	depositService.AssertExpectations(t)
+}
+
+func TestPurchaseRoute(t *testing.T) {
+	orderRepository := orderRepo.New()
+	issuerRepository := issuerRepo.New()
+	partnerRepository := partnerRepo.New()
+	partnerIssuerRepository := partnerIssuerRepo.New()
+	issuerApi := issuerApi.New()
+	routeService := routeService.New()
+	priceService := priceService.New()
+	depositService := depositService.New()
+	productRepository := productRepo.New()
+	ratelimitService := ratelimitService.New()
+	service := orderService.New(issuerRepository, partnerRepository, partnerIssuerRepository, orderRepository, issuerApi, productRepository, routeService, priceService, depositService, ratelimitService)
+
+	route := routePort.RouteService{
+		ProductCode: "TSEL10",
+		Candidates: []routePort.Candidate{
+			{IssuerCode: "unmapped", IssuerProductId: "U10"},
+			{IssuerCode: "down", IssuerProductId: "D10"},
+			{IssuerCode: "up", IssuerProductId: "P10"},
+		},
+		FailoverRescodes: []string{orderPort.RescodeCircuitOpen},
+	}
+	productRepository.On("FindByCode", "unknown").Return(productPort.ProductRepo{}).Once()
+	productRepository.On("FindByCode", "TSEL25").Return(productPort.ProductRepo{ID: "25", Code: "TSEL25"}).Once()
+	productRepository.On("FindByCode", "TSEL50").Return(productPort.ProductRepo{ID: "50", Code: "TSEL50", Active: true}).Once()
+	productRepository.On("FindByCode", mock.Anything).Return(productPort.ProductRepo{ID: "10", Active: true})
+	routeService.On("Plan", "TSEL50").Return(routePort.RouteService{}, errors.New("Route not found")).Once()
+	routeService.On("Plan", "TSEL10").Return(route, nil)
+	priceService.On("Quote", mock.Anything, "TSEL10", mock.Anything).Return(pricePort.Quote{PriceId: "price", BasePrice: 10000, SellingPrice: 10500}, nil)
+	priceService.On("Quote", mock.Anything, "TSEL75", mock.Anything).Return(pricePort.Quote{}, errors.New("no reachable servers")).Once()
+	priceService.On("Quote", mock.Anything, mock.Anything, mock.Anything).Return(pricePort.Quote{}, apperror.NewNotFound("Price not found"))
+	depositService.On("Hold", mock.Anything, mock.Anything, int64(10500)).Return("hold", nil)
+	depositService.On("Hold", mock.Anything, mock.Anything, int64(0)).Return("", nil)
+	depositService.On("Settle", "hold", "00", mock.Anything, false).Return(nil).Once()
+	depositService.On("Settle", "", orderPort.RescodeCircuitOpen, mock.Anything, false).Return(nil).Once()
+	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{ID: "12345"})
+	ratelimitService.On("AllowIssuer", mock.Anything, "busy").Return(ratelimitPort.Result{RetryAfter: time.Second}, nil)
+	ratelimitService.On("AllowIssuer", mock.Anything, mock.Anything).Return(ratelimitPort.Result{Allowed: true}, nil)
+	for _, code := range []string{"unmapped", "down", "up"} {
+		issuerRepository.On("FindByCode", code).Return(issuerPort.IssuerRepo{ID: code, Code: code})
+	}
+	partnerIssuerRepository.On("FindByPartnerIssuerID", "12345", "unmapped").Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(ErrConfigNotFound))
+	partnerIssuerRepository.On("FindByPartnerIssuerID", "12345", mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{}, nil)
+	issuerApi.On("Do", mock.Anything, mock.MatchedBy(func(order orderPort.OrderIssuerApi) bool {
+		return order.IssuerCode == "down" && order.DepositHoldId == "hold"
+	})).Return(orderPort.OrderIssuerApiResult{IssuerRescode: orderPort.RescodeCircuitOpen}, nil).Once()
+	issuerApi.On("Do", mock.Anything, mock.MatchedBy(func(order orderPort.OrderIssuerApi) bool {
+		return order.IssuerCode == "down"
+	})).Return(orderPort.OrderIssuerApiResult{IssuerRescode: orderPort.RescodeCircuitOpen}, nil)
+	issuerApi.On("Do", mock.Anything, mock.MatchedBy(func(order orderPort.OrderIssuerApi) bool {
+		// product orders carry what the record of a late result needs
+		return order.IssuerCode == "up" && order.IssuerProductId == "P10" && order.ProductCode == "TSEL10" && order.Route == 3 && order.Price.BasePrice == 10000
+	})).Return(orderPort.OrderIssuerApiResult{IssuerRescode: "00"}, nil)
+	orderRepository.On("CreateData", mock.MatchedBy(func(order orderPort.OrderRepo) bool {
+		return order.ProductCode == "TSEL10" && order.IssuerId == "down" && order.Route == 2 && order.Price.SellingPrice == 10500
+	})).Return(nil).Once()
+	orderRepository.On("CreateData", mock.MatchedBy(func(order orderPort.OrderRepo) bool {
+		return order.ProductCode == "TSEL10" && order.IssuerId == "up" && order.Route == 3 && order.Price.PriceId == "price"
+	})).Return(nil).Once()
+
+	// error product not found
+	_, err := service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "unknown"})
+	assert.EqualError(t, err, orderService.ErrProductNotFound)
+
+	// error product inactive
+	_, err = service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL25"})
+	assert.EqualError(t, err, orderService.ErrProductInactive)
+
+	// error route not found
+	_, err = service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL50"})
+	assert.NotNil(t, err)
+
+	// error price store, only a missing price goes on unpriced
+	routeService.On("Plan", "TSEL75").Return(route, nil).Once()
+	_, err = service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL75"})
+	assert.EqualError(t, err, "no reachable servers")
+
+	// unmapped candidate skipped, open circuit fails over
+	result, err := service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL10"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "up", result.IssuerCode)
+		assert.Equal(t, "00", result.IssuerRescode)
+	}
+	orderRepository.AssertExpectations(t)
+
+	// every candidate fails over, last result is returned
+	route.Candidates = route.Candidates[:2]
+	routeService.On("Plan", "TSEL5").Return(route, nil).Once()
+	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
+	result, err = service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL5"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, orderPort.RescodeCircuitOpen, result.IssuerRescode)
+	}
+	depositService.AssertExpectations(t)
+
+	// a pending order is not failed over even when the route lists its rescode
+	route.Candidates = []routePort.Candidate{{IssuerCode: "slow", IssuerProductId: "S10"}, {IssuerCode: "up", IssuerProductId: "P10"}}
+	route.FailoverRescodes = []string{orderPort.RescodePending}
+	routeService.On("Plan", "TSEL1").Return(route, nil).Once()
+	issuerRepository.On("FindByCode", "slow").Return(issuerPort.IssuerRepo{ID: "slow", Code: "slow"})
+	issuerApi.On("Do", mock.Anything, mock.MatchedBy(func(order orderPort.OrderIssuerApi) bool {
+		return order.IssuerCode == "slow"
+	})).Return(orderPort.OrderIssuerApiResult{IssuerRescode: orderPort.RescodePending}, nil).Once()
+	depositService.On("Settle", "", orderPort.RescodePending, mock.Anything, false).Return(nil).Once()
+	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
+	result, err = service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL1"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "slow", result.IssuerCode)
+		assert.Equal(t, orderPort.RescodePending, result.IssuerRescode)
+	}
+
+	// a candidate over the partner issuer rate limit is skipped
+	route.Candidates = []routePort.Candidate{{IssuerCode: "busy", IssuerProductId: "B10"}, {IssuerCode: "slow", IssuerProductId: "S10"}}
+	route.FailoverRescodes = nil
+	routeService.On("Plan", "TSEL2").Return(route, nil).Once()
+	issuerApi.On("Do", mock.Anything, mock.MatchedBy(func(order orderPort.OrderIssuerApi) bool {
+		return order.IssuerCode == "slow" && order.Route == 2
+	})).Return(orderPort.OrderIssuerApiResult{IssuerRescode: "00"}, nil).Once()
+	depositService.On("Settle", "", "00", mock.Anything, false).Return(nil).Once()
+	orderRepository.On("CreateData", mock.Anything).Return(nil).Once()
+	result, err = service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL2"})
+	if assert.Nil(t, err) {
+		assert.Equal(t, "slow", result.IssuerCode)
+	}
+	issuerApi.AssertNotCalled(t, "Do", mock.Anything, mock.MatchedBy(func(order orderPort.OrderIssuerApi) bool {
+		return order.IssuerCode == "busy"
+	}))
+
+	// error every candidate over its rate limit
+	route.Candidates = route.Candidates[:1]
+	routeService.On("Plan", "TSEL3").Return(route, nil).Once()
+	depositService.On("Settle", "", "", "", true).Return(nil).Once()
+	_, err = service.Purchase(context.Background(), orderPort.OrderService{ProductCode: "TSEL3"})
+	assert.EqualError(t, err, orderService.ErrIssuerRateLimited)
+	depositService.AssertExpectations(t)
+	issuerApi.AssertExpectations(t)
 }
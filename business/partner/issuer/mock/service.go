@@ -2,6 +2,7 @@ package mock
 
 import (
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -38,3 +39,8 @@ func (s *service) ListData() ([]partnerIssuerPort.PartnerIssuerService, error) {
 	result := s.Called()
 	return result.Get(0).([]partnerIssuerPort.PartnerIssuerService), result.Error(1)
 }
+
+func (s *service) SearchData(listQuery query.Query) ([]partnerIssuerPort.PartnerIssuerService, query.Page, error) {
+	result := s.Called(listQuery)
+	return result.Get(0).([]partnerIssuerPort.PartnerIssuerService), result.Get(1).(query.Page), result.Error(2)
+}
@@ -1,13 +1,23 @@
 package port
 
-import "time"
+import (
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+)
 
 type (
 	PartnerIssuerRepo struct {
-		ID        string    `json:"id"`
-		PartnerId string    `json:"partner_id"`
-		IssuerId  string    `json:"issuer_id"`
-		Config    string    `json:"config"`
+		ID        string `json:"id"`
+		PartnerId string `json:"partner_id"`
+		IssuerId  string `json:"issuer_id"`
+		Config    string `json:"config"`
+		// ReservedThread slots of the issuer threadpool kept for this partner
+		ReservedThread int `json:"reserved_thread"`
+		// MaxThread slots of the issuer threadpool this partner may use at once, zero means no cap
+		MaxThread int `json:"max_thread"`
+		// Version incremented by every UpdateData, an update of another version is refused
+		Version   int       `json:"version"`
 		CreatedAt time.Time `json:"created_at"`
 		UpdatedAt time.Time `json:"updated_at"`
 	}
@@ -24,7 +34,7 @@ type Repository interface {
 	//ReadData get data by ID
 	ReadData(ID string) (PartnerIssuerRepo, error)
 
-	//UpdateData update new data
+	//UpdateData update new data, a conflict error when the stored version is not partnerIssuer.Version
 	UpdateData(partnerIssuer PartnerIssuerRepo) error
 
 	//DeleteData delete data
@@ -32,4 +42,7 @@ type Repository interface {
 
 	//ListData get list data
 	ListData() ([]PartnerIssuerRepo, error)
+
+	//SearchData get a page of partner issuers, filtered on partner_id and issuer_id
+	SearchData(listQuery query.Query) ([]PartnerIssuerRepo, query.Page, error)
 }
@@ -1,16 +1,23 @@
 package port
 
-import "time"
+import (
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+)
 
 type (
 	PartnerIssuerService struct {
-		ID        string    `json:"id"`
-		PartnerId string    `json:"partner_id"`
-		IssuerId  string    `json:"issuer_id"`
-		Config    string    `json:"config"`
-		CreatedAt time.Time `json:"created_at"`
-		UpdatedAt time.Time `json:"updated_at"`
-		DeletedAt time.Time `json:"deleted_at"`
+		ID             string    `json:"id"`
+		PartnerId      string    `json:"partner_id"`
+		IssuerId       string    `json:"issuer_id"`
+		Config         string    `json:"config"`
+		ReservedThread int       `json:"reserved_thread"`
+		MaxThread      int       `json:"max_thread"`
+		Version        int       `json:"version"`
+		CreatedAt      time.Time `json:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at"`
+		DeletedAt      time.Time `json:"deleted_at"`
 	}
 )
 
@@ -30,4 +37,7 @@ type Service interface {
 
 	// //ListData get list data
 	ListData() ([]PartnerIssuerService, error)
+
+	// SearchData get a page of partner issuers, filtered on partner_id and issuer_id
+	SearchData(listQuery query.Query) ([]PartnerIssuerService, query.Page, error)
 }
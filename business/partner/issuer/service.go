@@ -3,30 +3,67 @@ package issuer
 import (
 	"encoding/json"
 
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 type (
 	service struct {
 		partnerIssuerRepository partnerIssuerPort.Repository
+		partnerRepository       partnerPort.Repository
+		issuerRepository        issuerPort.Repository
 	}
 )
 
 var (
-	ErrDuplicateCode = "Code already in use"
+	ErrDuplicatePair         = "Partner already mapped to the issuer"
+	ErrPartnerIssuerNotFound = "Partner Issuer not found"
 )
 
-func New(partnerIssuerRepository partnerIssuerPort.Repository) partnerIssuerPort.Service {
+func New(partnerIssuerRepository partnerIssuerPort.Repository, partnerRepository partnerPort.Repository, issuerRepository issuerPort.Repository) partnerIssuerPort.Service {
 	return &service{
 		partnerIssuerRepository,
+		partnerRepository,
+		issuerRepository,
 	}
 }
 
+// validate checks the partner and issuer exist and are not mapped already by another record
+func (s *service) validate(partnerIssuer partnerIssuerPort.PartnerIssuerService) error {
+	if _, err := s.partnerRepository.ReadData(partnerIssuer.PartnerId); err != nil {
+		return apperror.NewInvalid(err.Error())
+	}
+	if _, err := s.issuerRepository.ReadData(partnerIssuer.IssuerId); err != nil {
+		return apperror.NewInvalid(err.Error())
+	}
+	existing, err := s.partnerIssuerRepository.FindByPartnerIssuerID(partnerIssuer.PartnerId, partnerIssuer.IssuerId)
+	if err != nil {
+		if err.Error() == ErrPartnerIssuerNotFound {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != partnerIssuer.ID {
+		return apperror.NewConflict(ErrDuplicatePair)
+	}
+	return nil
+}
+
 func (s *service) CreateData(partnerIssuer partnerIssuerPort.PartnerIssuerService) error {
+	if err := s.validate(partnerIssuer); err != nil {
+		return err
+	}
+
 	data := partnerIssuerPort.PartnerIssuerRepo{
 		PartnerId: partnerIssuer.PartnerId,
 		IssuerId:  partnerIssuer.IssuerId,
 		Config:    partnerIssuer.Config,
+
+		ReservedThread: partnerIssuer.ReservedThread,
+		MaxThread:      partnerIssuer.MaxThread,
 	}
 	return s.partnerIssuerRepository.CreateData(data)
 }
@@ -41,6 +78,10 @@ func (s *service) ReadData(ID string) (partnerIssuer partnerIssuerPort.PartnerIs
 		PartnerId: data.PartnerId,
 		IssuerId:  data.IssuerId,
 		Config:    data.Config,
+
+		ReservedThread: data.ReservedThread,
+		MaxThread:      data.MaxThread,
+		Version:        data.Version,
 	}
 	return
 }
@@ -50,11 +91,18 @@ func (s *service) UpdateData(partnerIssuer partnerIssuerPort.PartnerIssuerServic
 	if err != nil {
 		return err
 	}
+	if err = s.validate(partnerIssuer); err != nil {
+		return err
+	}
 	data := partnerIssuerPort.PartnerIssuerRepo{
 		ID:        partnerIssuer.ID,
 		PartnerId: partnerIssuer.PartnerId,
 		IssuerId:  partnerIssuer.IssuerId,
 		Config:    partnerIssuer.Config,
+
+		ReservedThread: partnerIssuer.ReservedThread,
+		MaxThread:      partnerIssuer.MaxThread,
+		Version:        partnerIssuer.Version,
 	}
 	return s.partnerIssuerRepository.UpdateData(data)
 }
@@ -75,3 +123,17 @@ func (s *service) ListData() (partnerIssuers []partnerIssuerPort.PartnerIssuerSe
 
 	return
 }
+
+func (s *service) SearchData(listQuery query.Query) (partnerIssuers []partnerIssuerPort.PartnerIssuerService, page query.Page, err error) {
+	datas, page, err := s.partnerIssuerRepository.SearchData(listQuery)
+	if err != nil {
+		return
+	}
+	partnerIssuers = make([]partnerIssuerPort.PartnerIssuerService, 0, len(datas))
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &partnerIssuers)
+	}
+
+	return
+}
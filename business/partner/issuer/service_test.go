@@ -7,9 +7,15 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
 	partnerIssuerService "github.com/sepulsa/teleco/business/partner/issuer"
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	issuerRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer"
+	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
 	partnerIssuerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner/issuer"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 var (
@@ -18,11 +24,17 @@ var (
 	TestIssuerID  = "6138813fb95630b0b528b163"
 	TestConfig    = "config"
 
-	TestErrInvalidID = "Invalid ID"
+	TestErrInvalidID             = "Invalid ID"
+	TestErrPartnerNotFound       = "Partner not found"
+	TestErrIssuerNotFound        = "Issuer not found"
+	TestErrPartnerIssuerNotFound = "Partner Issuer not found"
 )
 
 func TestCreateData(t *testing.T) {
 	repository := partnerIssuerRepo.New()
+	partnerRepository := partnerRepo.New()
+	issuerRepository := issuerRepo.New()
+	service := partnerIssuerService.New(repository, partnerRepository, issuerRepository)
 
 	dataService := partnerIssuerPort.PartnerIssuerService{
 		ID:        "",
@@ -32,16 +44,51 @@ func TestCreateData(t *testing.T) {
 	}
 
 	// success
+	partnerRepository.On("ReadData", TestPartnerID).Return(partnerPort.PartnerRepo{ID: TestPartnerID}, nil).Once()
+	issuerRepository.On("ReadData", TestIssuerID).Return(issuerPort.IssuerRepo{ID: TestIssuerID}, nil).Once()
+	repository.On("FindByPartnerIssuerID", TestPartnerID, TestIssuerID).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(TestErrPartnerIssuerNotFound)).Once()
 	repository.On("CreateData", mock.Anything).Return(nil).Once()
-	service := partnerIssuerService.New(repository)
 	err := service.CreateData(dataService)
 	assert.Nil(t, err)
 
+	// error partner not found
+	partnerRepository.On("ReadData", TestPartnerID).Return(partnerPort.PartnerRepo{}, errors.New(TestErrPartnerNotFound)).Once()
+	err = service.CreateData(dataService)
+	assert.Equal(t, TestErrPartnerNotFound, err.Error())
+	assert.Equal(t, apperror.Invalid, apperror.KindOf(err))
+
+	// error issuer not found
+	partnerRepository.On("ReadData", TestPartnerID).Return(partnerPort.PartnerRepo{ID: TestPartnerID}, nil).Once()
+	issuerRepository.On("ReadData", TestIssuerID).Return(issuerPort.IssuerRepo{}, errors.New(TestErrIssuerNotFound)).Once()
+	err = service.CreateData(dataService)
+	assert.Equal(t, TestErrIssuerNotFound, err.Error())
+	assert.Equal(t, apperror.Invalid, apperror.KindOf(err))
+
+	// error pair already mapped
+	partnerRepository.On("ReadData", TestPartnerID).Return(partnerPort.PartnerRepo{ID: TestPartnerID}, nil).Once()
+	issuerRepository.On("ReadData", TestIssuerID).Return(issuerPort.IssuerRepo{ID: TestIssuerID}, nil).Once()
+	repository.On("FindByPartnerIssuerID", TestPartnerID, TestIssuerID).Return(partnerIssuerPort.PartnerIssuerRepo{ID: TestID}, nil).Once()
+	err = service.CreateData(dataService)
+	assert.Equal(t, partnerIssuerService.ErrDuplicatePair, err.Error())
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
+	// error pair lookup failed
+	partnerRepository.On("ReadData", TestPartnerID).Return(partnerPort.PartnerRepo{ID: TestPartnerID}, nil).Once()
+	issuerRepository.On("ReadData", TestIssuerID).Return(issuerPort.IssuerRepo{ID: TestIssuerID}, nil).Once()
+	repository.On("FindByPartnerIssuerID", TestPartnerID, TestIssuerID).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New("connection refused")).Once()
+	err = service.CreateData(dataService)
+	assert.Equal(t, "connection refused", err.Error())
+	repository.AssertNumberOfCalls(t, "CreateData", 1)
+
 	// error mongo
+	partnerRepository.On("ReadData", TestPartnerID).Return(partnerPort.PartnerRepo{ID: TestPartnerID}, nil).Once()
+	issuerRepository.On("ReadData", TestIssuerID).Return(issuerPort.IssuerRepo{ID: TestIssuerID}, nil).Once()
+	repository.On("FindByPartnerIssuerID", TestPartnerID, TestIssuerID).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(TestErrPartnerIssuerNotFound)).Once()
 	repository.On("CreateData", mock.Anything).Return(errors.New("")).Once()
-	service = partnerIssuerService.New(repository)
 	err = service.CreateData(dataService)
 	assert.NotNil(t, err)
+
+	repository.AssertExpectations(t)
 }
 
 func TestReadData(t *testing.T) {
@@ -57,7 +104,7 @@ func TestReadData(t *testing.T) {
 
 	// success
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
-	service := partnerIssuerService.New(repository)
+	service := partnerIssuerService.New(repository, nil, nil)
 	partnerIssuer, err := service.ReadData(id)
 	if assert.Nil(t, err) {
 		assert.Equal(t, dataRepo.ID, partnerIssuer.ID)
@@ -67,19 +114,22 @@ func TestReadData(t *testing.T) {
 
 	// error
 	repository.On("ReadData", mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(TestErrInvalidID)).Once()
-	service = partnerIssuerService.New(repository)
+	service = partnerIssuerService.New(repository, nil, nil)
 	_, err = service.ReadData(id)
 	assert.Equal(t, TestErrInvalidID, err.Error())
 }
 
 func TestUpdateData(t *testing.T) {
 	repository := partnerIssuerRepo.New()
+	partnerRepository := partnerRepo.New()
+	issuerRepository := issuerRepo.New()
+	service := partnerIssuerService.New(repository, partnerRepository, issuerRepository)
 
 	dataService := partnerIssuerPort.PartnerIssuerService{
 		ID:        TestID,
-		PartnerId: TestPartnerID + TestPartnerID,
+		PartnerId: TestPartnerID,
 		IssuerId:  TestIssuerID,
-		Config:    TestConfig,
+		Config:    TestConfig + TestConfig,
 	}
 
 	dataRepo := partnerIssuerPort.PartnerIssuerRepo{
@@ -88,26 +138,36 @@ func TestUpdateData(t *testing.T) {
 		IssuerId:  TestIssuerID,
 		Config:    TestConfig,
 	}
+	partnerRepository.On("ReadData", TestPartnerID).Return(partnerPort.PartnerRepo{ID: TestPartnerID}, nil)
+	issuerRepository.On("ReadData", TestIssuerID).Return(issuerPort.IssuerRepo{ID: TestIssuerID}, nil)
 
-	// success
-	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
+	// success the pair is mapped by the record itself
+	repository.On("ReadData", TestID).Return(dataRepo, nil).Once()
+	repository.On("FindByPartnerIssuerID", TestPartnerID, TestIssuerID).Return(dataRepo, nil).Once()
 	repository.On("UpdateData", mock.Anything).Return(nil).Once()
-	service := partnerIssuerService.New(repository)
 	err := service.UpdateData(dataService)
 	assert.Nil(t, err)
 
 	// error invalid id
-	repository.On("ReadData", mock.Anything).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(TestErrInvalidID)).Once()
-	service = partnerIssuerService.New(repository)
+	repository.On("ReadData", TestID).Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New(TestErrInvalidID)).Once()
 	err = service.UpdateData(dataService)
 	assert.Equal(t, TestErrInvalidID, err.Error())
 
+	// error pair mapped by another record
+	repository.On("ReadData", TestID).Return(dataRepo, nil).Once()
+	repository.On("FindByPartnerIssuerID", TestPartnerID, TestIssuerID).Return(partnerIssuerPort.PartnerIssuerRepo{ID: "6138813fb95630b0b528b161"}, nil).Once()
+	err = service.UpdateData(dataService)
+	assert.Equal(t, partnerIssuerService.ErrDuplicatePair, err.Error())
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
 	// error mongo
-	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
+	repository.On("ReadData", TestID).Return(dataRepo, nil).Once()
+	repository.On("FindByPartnerIssuerID", TestPartnerID, TestIssuerID).Return(dataRepo, nil).Once()
 	repository.On("UpdateData", mock.Anything).Return(errors.New("")).Once()
-	service = partnerIssuerService.New(repository)
 	err = service.UpdateData(dataService)
 	assert.NotNil(t, err)
+
+	repository.AssertExpectations(t)
 }
 
 func TestDeleteData(t *testing.T) {
@@ -117,13 +177,13 @@ func TestDeleteData(t *testing.T) {
 
 	// success
 	repository.On("DeleteData", mock.Anything).Return(nil).Once()
-	service := partnerIssuerService.New(repository)
+	service := partnerIssuerService.New(repository, nil, nil)
 	err := service.DeleteData(id)
 	assert.Nil(t, err)
 
 	// error
 	repository.On("DeleteData", mock.Anything).Return(errors.New(TestErrInvalidID)).Once()
-	service = partnerIssuerService.New(repository)
+	service = partnerIssuerService.New(repository, nil, nil)
 	err = service.DeleteData(id)
 	assert.Equal(t, TestErrInvalidID, err.Error())
 }
@@ -142,7 +202,7 @@ func TestListData(t *testing.T) {
 
 	// success
 	repository.On("ListData").Return(dataRepo, nil).Once()
-	service := partnerIssuerService.New(repository)
+	service := partnerIssuerService.New(repository, nil, nil)
 	partnerIssuers, err := service.ListData()
 	if assert.Nil(t, err) {
 		assert.Equal(t, TestID, partnerIssuers[0].ID)
@@ -153,7 +213,26 @@ func TestListData(t *testing.T) {
 
 	// error
 	repository.On("ListData").Return([]partnerIssuerPort.PartnerIssuerRepo{}, errors.New("")).Once()
-	service = partnerIssuerService.New(repository)
+	service = partnerIssuerService.New(repository, nil, nil)
 	_, err = service.ListData()
 	assert.NotNil(t, err)
 }
+
+func TestSearchData(t *testing.T) {
+	repository := partnerIssuerRepo.New()
+	service := partnerIssuerService.New(repository, nil, nil)
+	listQuery := query.Query{Filters: map[string]string{"partner_id": TestPartnerID}}
+
+	// success
+	repository.On("SearchData", listQuery).Return([]partnerIssuerPort.PartnerIssuerRepo{{ID: TestID, PartnerId: TestPartnerID, IssuerId: TestIssuerID}}, query.Page{Total: 1, Page: 1, Limit: 20}, nil).Once()
+	partnerIssuers, page, err := service.SearchData(listQuery)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestIssuerID, partnerIssuers[0].IssuerId)
+		assert.Equal(t, 1, page.Total)
+	}
+
+	// error
+	repository.On("SearchData", listQuery).Return([]partnerIssuerPort.PartnerIssuerRepo{}, query.Page{}, errors.New("")).Once()
+	_, _, err = service.SearchData(listQuery)
+	assert.NotNil(t, err)
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -38,3 +39,8 @@ func (s *service) ListData() ([]partnerPort.PartnerService, error) {
 	result := s.Called()
 	return result.Get(0).([]partnerPort.PartnerService), result.Error(1)
 }
+
+func (s *service) SearchData(listQuery query.Query) ([]partnerPort.PartnerService, query.Page, error) {
+	result := s.Called(listQuery)
+	return result.Get(0).([]partnerPort.PartnerService), result.Get(1).(query.Page), result.Error(2)
+}
@@ -2,22 +2,36 @@ package port
 
 import (
 	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 type (
 	PartnerRepo struct {
-		ID          string    `json:"id"`
-		Code        string    `json:"code"`
-		Name        string    `json:"name"`
-		Pic         string    `json:"pic"`
-		Address     string    `json:"address"`
-		CallbackUrl string    `json:"callback_url"`
-		IpWhitelist []string  `json:"ip_whitelist"`
-		Status      string    `json:"status"`
-		SecretKey   string    `json:"secret_key"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
-		DeletedAt   time.Time `json:"deleted_at"`
+		ID          string   `json:"id"`
+		Code        string   `json:"code"`
+		Name        string   `json:"name"`
+		Pic         string   `json:"pic"`
+		Address     string   `json:"address"`
+		CallbackUrl string   `json:"callback_url"`
+		IpWhitelist []string `json:"ip_whitelist"`
+		Status      string   `json:"status"`
+		SecretKey   string   `json:"secret_key"`
+		// RateLimit applies to all requests of the partner
+		RateLimit RateLimit `json:"rate_limit"`
+		// IssuerRateLimit applies per issuer code on top of RateLimit
+		IssuerRateLimit map[string]RateLimit `json:"issuer_rate_limit"`
+		// Version incremented by every UpdateData, an update of another version is refused
+		Version   int       `json:"version"`
+		CreatedAt time.Time `json:"created_at"`
+		UpdatedAt time.Time `json:"updated_at"`
+		DeletedAt time.Time `json:"deleted_at"`
+	}
+
+	// RateLimit token bucket refilled with Rate tokens per second up to Burst, zero Rate disables the limit
+	RateLimit struct {
+		Rate  float64 `json:"rate"`
+		Burst int     `json:"burst"`
 	}
 )
 
@@ -32,7 +46,7 @@ type Repository interface {
 	//ReadData get data by ID
 	ReadData(ID string) (PartnerRepo, error)
 
-	//UpdateData update new data
+	//UpdateData update new data, a conflict error when the stored version is not partner.Version
 	UpdateData(partner PartnerRepo) error
 
 	//DeleteData delete data
@@ -40,4 +54,7 @@ type Repository interface {
 
 	//ListData get list data
 	ListData() ([]PartnerRepo, error)
+
+	//SearchData get a page of partners, searched on code, name and pic, filtered on status
+	SearchData(listQuery query.Query) ([]PartnerRepo, query.Page, error)
 }
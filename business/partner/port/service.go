@@ -2,22 +2,27 @@ package port
 
 import (
 	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 type (
 	PartnerService struct {
-		ID          string    `json:"id"`
-		Code        string    `json:"code"`
-		Name        string    `json:"name"`
-		Pic         string    `json:"pic"`
-		Address     string    `json:"address"`
-		CallbackUrl string    `json:"callback_url"`
-		IpWhitelist []string  `json:"ip_whitelist"`
-		Status      string    `json:"status"`
-		SecretKey   string    `json:"secret_key"`
-		CreatedAt   time.Time `json:"created_at"`
-		UpdatedAt   time.Time `json:"updated_at"`
-		DeletedAt   time.Time `json:"deleted_at"`
+		ID              string               `json:"id"`
+		Code            string               `json:"code"`
+		Name            string               `json:"name"`
+		Pic             string               `json:"pic"`
+		Address         string               `json:"address"`
+		CallbackUrl     string               `json:"callback_url"`
+		IpWhitelist     []string             `json:"ip_whitelist"`
+		Status          string               `json:"status"`
+		SecretKey       string               `json:"secret_key"`
+		RateLimit       RateLimit            `json:"rate_limit"`
+		IssuerRateLimit map[string]RateLimit `json:"issuer_rate_limit"`
+		Version         int                  `json:"version"`
+		CreatedAt       time.Time            `json:"created_at"`
+		UpdatedAt       time.Time            `json:"updated_at"`
+		DeletedAt       time.Time            `json:"deleted_at"`
 	}
 )
 
@@ -37,4 +42,7 @@ type Service interface {
 
 	//ListData get list data
 	ListData() ([]PartnerService, error)
+
+	// SearchData get a page of partners, searched on code, name and pic, filtered on status
+	SearchData(listQuery query.Query) ([]PartnerService, query.Page, error)
 }
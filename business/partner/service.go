@@ -3,26 +3,37 @@ package partner
 import (
 	"encoding/json"
 
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 type (
 	service struct {
-		partnerRepository partnerPort.Repository
+		partnerRepository       partnerPort.Repository
+		partnerIssuerRepository partnerIssuerPort.Repository
 	}
 )
 
 var (
-	ErrDuplicateName = "Name already in use"
+	ErrDuplicateCode = "Code already in use"
+	ErrPartnerMapped = "Partner still mapped to issuers, remove the partner issuer mappings first"
 )
 
-func New(partnerRepository partnerPort.Repository) partnerPort.Service {
+func New(partnerRepository partnerPort.Repository, partnerIssuerRepository partnerIssuerPort.Repository) partnerPort.Service {
 	return &service{
 		partnerRepository,
+		partnerIssuerRepository,
 	}
 }
 
 func (s *service) CreateData(partner partnerPort.PartnerService) error {
+	existingPartner := s.partnerRepository.FindByCode(partner.Code)
+	if existingPartner.ID != "" {
+		return apperror.NewConflict(ErrDuplicateCode)
+	}
+
 	data := partnerPort.PartnerRepo{
 		Code:        partner.Code,
 		Name:        partner.Name,
@@ -32,6 +43,9 @@ func (s *service) CreateData(partner partnerPort.PartnerService) error {
 		IpWhitelist: partner.IpWhitelist,
 		Status:      partner.Status,
 		SecretKey:   partner.SecretKey,
+
+		RateLimit:       partner.RateLimit,
+		IssuerRateLimit: partner.IssuerRateLimit,
 	}
 	return s.partnerRepository.CreateData(data)
 }
@@ -52,18 +66,28 @@ func (s *service) ReadData(ID string) (partner partnerPort.PartnerService, err e
 		IpWhitelist: data.IpWhitelist,
 		Status:      data.Status,
 		SecretKey:   data.SecretKey,
-		CreatedAt:   data.CreatedAt,
-		UpdatedAt:   data.CreatedAt,
-		DeletedAt:   data.DeletedAt,
+
+		RateLimit:       data.RateLimit,
+		IssuerRateLimit: data.IssuerRateLimit,
+		Version:         data.Version,
+		CreatedAt:       data.CreatedAt,
+		UpdatedAt:       data.UpdatedAt,
+		DeletedAt:       data.DeletedAt,
 	}
 	return
 }
 
 func (s *service) UpdateData(partner partnerPort.PartnerService) error {
-	_, err := s.partnerRepository.ReadData(partner.ID)
+	existingData, err := s.partnerRepository.ReadData(partner.ID)
 	if err != nil {
 		return err
 	}
+	if existingData.Code != partner.Code {
+		existingPartner := s.partnerRepository.FindByCode(partner.Code)
+		if existingPartner.ID != "" {
+			return apperror.NewConflict(ErrDuplicateCode)
+		}
+	}
 	data := partnerPort.PartnerRepo{
 		ID:          partner.ID,
 		Code:        partner.Code,
@@ -74,14 +98,28 @@ func (s *service) UpdateData(partner partnerPort.PartnerService) error {
 		IpWhitelist: partner.IpWhitelist,
 		Status:      partner.Status,
 		SecretKey:   partner.SecretKey,
-		CreatedAt:   partner.CreatedAt,
-		UpdatedAt:   partner.CreatedAt,
-		DeletedAt:   partner.DeletedAt,
+
+		RateLimit:       partner.RateLimit,
+		IssuerRateLimit: partner.IssuerRateLimit,
+		Version:         partner.Version,
+		CreatedAt:       partner.CreatedAt,
+		UpdatedAt:       partner.UpdatedAt,
+		DeletedAt:       partner.DeletedAt,
 	}
 	return s.partnerRepository.UpdateData(data)
 }
 
+// DeleteData is refused while partner issuer mappings still route the partner to issuers
 func (s *service) DeleteData(ID string) error {
+	if _, err := s.partnerRepository.ReadData(ID); err != nil {
+		return err
+	}
+	mapped := query.Query{Limit: 1, Filters: map[string]string{"partner_id": ID}}
+	if _, page, err := s.partnerIssuerRepository.SearchData(mapped); err != nil {
+		return err
+	} else if page.Total > 0 {
+		return apperror.NewConflict(ErrPartnerMapped)
+	}
 	return s.partnerRepository.DeleteData(ID)
 }
 
@@ -97,3 +135,17 @@ func (s *service) ListData() (partners []partnerPort.PartnerService, err error)
 
 	return
 }
+
+func (s *service) SearchData(listQuery query.Query) (partners []partnerPort.PartnerService, page query.Page, err error) {
+	datas, page, err := s.partnerRepository.SearchData(listQuery)
+	if err != nil {
+		return
+	}
+	partners = make([]partnerPort.PartnerService, 0, len(datas))
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &partners)
+	}
+
+	return
+}
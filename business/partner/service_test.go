@@ -3,13 +3,18 @@ package partner_test
 import (
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
 	partnerService "github.com/sepulsa/teleco/business/partner"
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
 	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
+	partnerIssuerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner/issuer"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
 )
 
 var (
@@ -42,14 +47,23 @@ func TestCreateData(t *testing.T) {
 	}
 
 	// success
+	repository.On("FindByCode", TestPartnerCode1).Return(partnerPort.PartnerRepo{}).Once()
 	repository.On("CreateData", mock.Anything).Return(nil).Once()
-	service := partnerService.New(repository)
+	service := partnerService.New(repository, nil)
 	err := service.CreateData(dataService)
 	assert.Nil(t, err)
 
+	// duplicate code
+	repository.On("FindByCode", TestPartnerCode1).Return(partnerPort.PartnerRepo{ID: TestPartnerID1}).Once()
+	service = partnerService.New(repository, nil)
+	err = service.CreateData(dataService)
+	assert.Equal(t, partnerService.ErrDuplicateCode, err.Error())
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
 	// error mongo
+	repository.On("FindByCode", TestPartnerCode1).Return(partnerPort.PartnerRepo{}).Once()
 	repository.On("CreateData", mock.Anything).Return(errors.New("")).Once()
-	service = partnerService.New(repository)
+	service = partnerService.New(repository, nil)
 	err = service.CreateData(dataService)
 	assert.NotNil(t, err)
 }
@@ -68,13 +82,17 @@ func TestReadData(t *testing.T) {
 		IpWhitelist: TestPartnerIpwhitelist1,
 		Status:      TestPartnerStatus1,
 		SecretKey:   TestPartnerSecretKey1,
+
+		Version:   2,
+		CreatedAt: time.Date(2021, 9, 13, 0, 0, 0, 0, time.UTC),
+		UpdatedAt: time.Date(2021, 9, 14, 0, 0, 0, 0, time.UTC),
 	}
 
 	t.Log(dataRepo)
 
 	// success
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
-	service := partnerService.New(repository)
+	service := partnerService.New(repository, nil)
 	partner, err := service.ReadData(id)
 	if assert.Nil(t, err) {
 		assert.Equal(t, dataRepo.ID, partner.ID)
@@ -85,11 +103,14 @@ func TestReadData(t *testing.T) {
 		assert.Equal(t, dataRepo.CallbackUrl, partner.CallbackUrl)
 		assert.Equal(t, dataRepo.IpWhitelist, partner.IpWhitelist)
 		assert.Equal(t, dataRepo.Status, partner.Status)
+		assert.Equal(t, dataRepo.Version, partner.Version)
+		assert.Equal(t, dataRepo.CreatedAt, partner.CreatedAt)
+		assert.Equal(t, dataRepo.UpdatedAt, partner.UpdatedAt)
 	}
 
 	// error
 	repository.On("ReadData", mock.Anything).Return(partnerPort.PartnerRepo{}, errors.New(TestErrInvalidID)).Once()
-	service = partnerService.New(repository)
+	service = partnerService.New(repository, nil)
 	_, err = service.ReadData(id)
 	assert.Equal(t, TestErrInvalidID, err.Error())
 }
@@ -107,6 +128,8 @@ func TestUpdateData(t *testing.T) {
 		IpWhitelist: TestPartnerIpwhitelist1,
 		Status:      TestPartnerStatus1,
 		SecretKey:   TestPartnerSecretKey1,
+
+		Version: 2,
 	}
 
 	dataRepo := partnerPort.PartnerRepo{
@@ -122,41 +145,66 @@ func TestUpdateData(t *testing.T) {
 
 	// success
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
-	repository.On("UpdateData", mock.Anything).Return(nil).Once()
-	service := partnerService.New(repository)
+	repository.On("FindByCode", dataService.Code).Return(partnerPort.PartnerRepo{}).Once()
+	repository.On("UpdateData", mock.MatchedBy(func(data partnerPort.PartnerRepo) bool {
+		return data.Version == dataService.Version
+	})).Return(nil).Once()
+	service := partnerService.New(repository, nil)
 	err := service.UpdateData(dataService)
 	assert.Nil(t, err)
 
+	// duplicate code
+	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
+	repository.On("FindByCode", dataService.Code).Return(partnerPort.PartnerRepo{ID: "613f12af2edd3a56323f0d1e"}).Once()
+	service = partnerService.New(repository, nil)
+	err = service.UpdateData(dataService)
+	assert.Equal(t, partnerService.ErrDuplicateCode, err.Error())
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
 	// error invalid id
 	repository.On("ReadData", mock.Anything).Return(partnerPort.PartnerRepo{}, errors.New(TestErrInvalidID)).Once()
-	service = partnerService.New(repository)
+	service = partnerService.New(repository, nil)
 	err = service.UpdateData(dataService)
 	assert.Equal(t, TestErrInvalidID, err.Error())
 
 	// error mongo
 	repository.On("ReadData", mock.Anything).Return(dataRepo, nil).Once()
+	repository.On("FindByCode", dataService.Code).Return(partnerPort.PartnerRepo{}).Once()
 	repository.On("UpdateData", mock.Anything).Return(errors.New("")).Once()
-	service = partnerService.New(repository)
+	service = partnerService.New(repository, nil)
 	err = service.UpdateData(dataService)
 	assert.NotNil(t, err)
 }
 
 func TestDeleteData(t *testing.T) {
 	repository := partnerRepo.New()
+	partnerIssuerRepository := partnerIssuerRepo.New()
+	service := partnerService.New(repository, partnerIssuerRepository)
 
 	id := TestPartnerID1
+	mapped := query.Query{Limit: 1, Filters: map[string]string{"partner_id": id}}
 
 	// success
-	repository.On("DeleteData", mock.Anything).Return(nil).Once()
-	service := partnerService.New(repository)
+	repository.On("ReadData", id).Return(partnerPort.PartnerRepo{ID: id}, nil).Once()
+	partnerIssuerRepository.On("SearchData", mapped).Return([]partnerIssuerPort.PartnerIssuerRepo{}, query.Page{Total: 0}, nil).Once()
+	repository.On("DeleteData", id).Return(nil).Once()
 	err := service.DeleteData(id)
 	assert.Nil(t, err)
 
+	// error still mapped to an issuer
+	repository.On("ReadData", id).Return(partnerPort.PartnerRepo{ID: id}, nil).Once()
+	partnerIssuerRepository.On("SearchData", mapped).Return([]partnerIssuerPort.PartnerIssuerRepo{{ID: "m1"}}, query.Page{Total: 1}, nil).Once()
+	err = service.DeleteData(id)
+	assert.Equal(t, partnerService.ErrPartnerMapped, err.Error())
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
 	// error
-	repository.On("DeleteData", mock.Anything).Return(errors.New(TestErrInvalidID)).Once()
-	service = partnerService.New(repository)
+	repository.On("ReadData", id).Return(partnerPort.PartnerRepo{}, errors.New(TestErrInvalidID)).Once()
 	err = service.DeleteData(id)
 	assert.Equal(t, TestErrInvalidID, err.Error())
+
+	repository.AssertExpectations(t)
+	partnerIssuerRepository.AssertExpectations(t)
 }
 
 func TestListData(t *testing.T) {
@@ -177,7 +225,7 @@ func TestListData(t *testing.T) {
 
 	// success
 	repository.On("ListData").Return(dataRepo, nil).Once()
-	service := partnerService.New(repository)
+	service := partnerService.New(repository, nil)
 	partners, err := service.ListData()
 	if assert.Nil(t, err) {
 		assert.Equal(t, TestPartnerID1, partners[0].ID)
@@ -193,7 +241,29 @@ func TestListData(t *testing.T) {
 
 	// error
 	repository.On("ListData").Return([]partnerPort.PartnerRepo{}, errors.New("")).Once()
-	service = partnerService.New(repository)
+	service = partnerService.New(repository, nil)
 	_, err = service.ListData()
 	assert.NotNil(t, err)
 }
+
+func TestSearchData(t *testing.T) {
+	repository := partnerRepo.New()
+	service := partnerService.New(repository, nil)
+	listQuery := query.Query{Page: 2, Limit: 1, Sort: "-name"}
+
+	// success
+	repository.On("SearchData", listQuery).Return([]partnerPort.PartnerRepo{{ID: TestPartnerID1, Code: TestPartnerCode1}}, query.Page{Total: 2, Page: 2, Limit: 1}, nil).Once()
+	partners, page, err := service.SearchData(listQuery)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestPartnerCode1, partners[0].Code)
+		assert.Equal(t, 2, page.Total)
+	}
+
+	// empty page
+	repository.On("SearchData", listQuery).Return([]partnerPort.PartnerRepo{}, query.Page{Total: 0, Page: 2, Limit: 1}, nil).Once()
+	partners, _, err = service.SearchData(listQuery)
+	if assert.Nil(t, err) {
+		assert.NotNil(t, partners)
+		assert.Empty(t, partners)
+	}
+}
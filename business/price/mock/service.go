@@ -0,0 +1,52 @@
+package mock
+
+import (
+	"time"
+
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type service struct {
+	mock.Mock
+}
+
+func New() *service {
+	return &service{}
+}
+
+func (s *service) CreateData(price pricePort.PriceService) error {
+	result := s.Called(price)
+	return result.Error(0)
+}
+
+func (s *service) ReadData(ID string) (pricePort.PriceService, error) {
+	result := s.Called(ID)
+	return result.Get(0).(pricePort.PriceService), result.Error(1)
+}
+
+func (s *service) UpdateData(price pricePort.PriceService) error {
+	result := s.Called(price)
+	return result.Error(0)
+}
+
+func (s *service) DeleteData(ID string) error {
+	result := s.Called(ID)
+	return result.Error(0)
+}
+
+func (s *service) ListData() ([]pricePort.PriceService, error) {
+	result := s.Called()
+	return result.Get(0).([]pricePort.PriceService), result.Error(1)
+}
+
+func (s *service) ImportData(prices []pricePort.PriceService) ([]pricePort.ImportError, error) {
+	result := s.Called(prices)
+	return result.Get(0).([]pricePort.ImportError), result.Error(1)
+}
+
+func (s *service) Quote(partnerCode string, productCode string, at time.Time) (pricePort.Quote, error) {
+	result := s.Called(partnerCode, productCode, at)
+	return result.Get(0).(pricePort.Quote), result.Error(1)
+}
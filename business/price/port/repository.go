@@ -0,0 +1,45 @@
+package port
+
+import "time"
+
+type (
+	PriceRepo struct {
+		ID          string `json:"id"`
+		PartnerCode string `json:"partner_code"`
+		ProductCode string `json:"product_code"`
+		// BasePrice paid to the issuer
+		BasePrice int64 `json:"base_price"`
+		// SellingPrice paid by the partner, when zero it is the base price plus the margins
+		SellingPrice  int64   `json:"selling_price"`
+		Margin        int64   `json:"margin"`
+		MarginPercent float64 `json:"margin_percent"`
+		// Fee charged to the partner on top of the selling price
+		Fee           int64     `json:"fee"`
+		EffectiveFrom time.Time `json:"effective_from"`
+		// EffectiveUntil is exclusive, zero means open ended
+		EffectiveUntil time.Time `json:"effective_until"`
+		CreatedAt      time.Time `json:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at"`
+	}
+)
+
+// Repository is outbound port
+type Repository interface {
+	//FindByPartnerProduct get every price of the product for the partner
+	FindByPartnerProduct(partnerCode string, productCode string) ([]PriceRepo, error)
+
+	//CreateData insert new data
+	CreateData(price PriceRepo) error
+
+	//ReadData get data by ID
+	ReadData(ID string) (PriceRepo, error)
+
+	//UpdateData update new data
+	UpdateData(price PriceRepo) error
+
+	//DeleteData delete data
+	DeleteData(ID string) error
+
+	//ListData get list data
+	ListData() ([]PriceRepo, error)
+}
@@ -0,0 +1,58 @@
+package port
+
+import "time"
+
+type (
+	PriceService struct {
+		ID             string    `json:"id"`
+		PartnerCode    string    `json:"partner_code"`
+		ProductCode    string    `json:"product_code"`
+		BasePrice      int64     `json:"base_price"`
+		SellingPrice   int64     `json:"selling_price"`
+		Margin         int64     `json:"margin"`
+		MarginPercent  float64   `json:"margin_percent"`
+		Fee            int64     `json:"fee"`
+		EffectiveFrom  time.Time `json:"effective_from"`
+		EffectiveUntil time.Time `json:"effective_until"`
+		CreatedAt      time.Time `json:"created_at"`
+		UpdatedAt      time.Time `json:"updated_at"`
+	}
+
+	// Quote price of a product for a partner at a point in time
+	Quote struct {
+		PriceId      string `json:"price_id"`
+		BasePrice    int64  `json:"base_price"`
+		SellingPrice int64  `json:"selling_price"`
+		Fee          int64  `json:"fee"`
+	}
+
+	// ImportError rejected row of an import, rows start at one
+	ImportError struct {
+		Row     int    `json:"row"`
+		Message string `json:"message"`
+	}
+)
+
+// Service is inbound port
+type Service interface {
+	// CreateData insert new data
+	CreateData(price PriceService) error
+
+	// ReadData get data by ID
+	ReadData(ID string) (PriceService, error)
+
+	// UpdateData update new data
+	UpdateData(price PriceService) error
+
+	// DeleteData delete data
+	DeleteData(ID string) error
+
+	// ListData get list data
+	ListData() ([]PriceService, error)
+
+	// ImportData insert every price or none of them when a row is rejected
+	ImportData(prices []PriceService) ([]ImportError, error)
+
+	// Quote get the price of the product for the partner effective at the time, a NotFound error when none is
+	Quote(partnerCode string, productCode string, at time.Time) (Quote, error)
+}
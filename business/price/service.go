@@ -0,0 +1,192 @@
+package price
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+)
+
+type (
+	service struct {
+		priceRepository   pricePort.Repository
+		partnerRepository partnerPort.Repository
+		productRepository productPort.Repository
+	}
+)
+
+var (
+	ErrPriceNotFound         = "Price not found"
+	ErrPartnerNotFound       = "Partner not found"
+	ErrProductNotFound       = "Product not found"
+	ErrInvalidEffectiveRange = "Effective until must be after effective from"
+	ErrOverlappingPrice      = "Effective range overlaps another price of the product"
+)
+
+func New(priceRepository pricePort.Repository, partnerRepository partnerPort.Repository, productRepository productPort.Repository) pricePort.Service {
+	return &service{
+		priceRepository,
+		partnerRepository,
+		productRepository,
+	}
+}
+
+func (s *service) CreateData(price pricePort.PriceService) error {
+	if err := s.check(price, nil); err != nil {
+		return err
+	}
+	return s.priceRepository.CreateData(toRepo(price))
+}
+
+func (s *service) ReadData(ID string) (price pricePort.PriceService, err error) {
+	data, err := s.priceRepository.ReadData(ID)
+	if err != nil {
+		return
+	}
+	price = pricePort.PriceService{
+		ID:             data.ID,
+		PartnerCode:    data.PartnerCode,
+		ProductCode:    data.ProductCode,
+		BasePrice:      data.BasePrice,
+		SellingPrice:   data.SellingPrice,
+		Margin:         data.Margin,
+		MarginPercent:  data.MarginPercent,
+		Fee:            data.Fee,
+		EffectiveFrom:  data.EffectiveFrom,
+		EffectiveUntil: data.EffectiveUntil,
+		CreatedAt:      data.CreatedAt,
+		UpdatedAt:      data.UpdatedAt,
+	}
+	return
+}
+
+func (s *service) UpdateData(price pricePort.PriceService) error {
+	if _, err := s.priceRepository.ReadData(price.ID); err != nil {
+		return err
+	}
+	if err := s.check(price, nil); err != nil {
+		return err
+	}
+	return s.priceRepository.UpdateData(toRepo(price))
+}
+
+func (s *service) DeleteData(ID string) error {
+	return s.priceRepository.DeleteData(ID)
+}
+
+func (s *service) ListData() (prices []pricePort.PriceService, err error) {
+	datas, err := s.priceRepository.ListData()
+	if err != nil {
+		return
+	}
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &prices)
+	}
+
+	return
+}
+
+func (s *service) ImportData(prices []pricePort.PriceService) ([]pricePort.ImportError, error) {
+	// rows must not overlap each other either
+	imported := make(map[string][]pricePort.PriceRepo)
+	importErrors := make([]pricePort.ImportError, 0)
+	for i, price := range prices {
+		key := price.PartnerCode + "|" + price.ProductCode
+		if err := s.check(price, imported[key]); err != nil {
+			importErrors = append(importErrors, pricePort.ImportError{Row: i + 1, Message: err.Error()})
+			continue
+		}
+		imported[key] = append(imported[key], toRepo(price))
+	}
+	if len(importErrors) > 0 {
+		return importErrors, nil
+	}
+
+	for _, price := range prices {
+		if err := s.priceRepository.CreateData(toRepo(price)); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+func (s *service) Quote(partnerCode string, productCode string, at time.Time) (quote pricePort.Quote, err error) {
+	datas, err := s.priceRepository.FindByPartnerProduct(partnerCode, productCode)
+	if err != nil {
+		return
+	}
+	for _, data := range datas {
+		if data.EffectiveFrom.After(at) || (!data.EffectiveUntil.IsZero() && !at.Before(data.EffectiveUntil)) {
+			continue
+		}
+		quote = pricePort.Quote{
+			PriceId:      data.ID,
+			BasePrice:    data.BasePrice,
+			SellingPrice: sellingPrice(data),
+			Fee:          data.Fee,
+		}
+		return
+	}
+	err = apperror.NewNotFound(ErrPriceNotFound)
+	return
+}
+
+// check price references and its effective range against the stored prices of the product and the pending ones
+func (s *service) check(price pricePort.PriceService, pending []pricePort.PriceRepo) error {
+	if !price.EffectiveUntil.IsZero() && !price.EffectiveUntil.After(price.EffectiveFrom) {
+		return errors.New(ErrInvalidEffectiveRange)
+	}
+	if s.partnerRepository.FindByCode(price.PartnerCode).ID == "" {
+		return errors.New(ErrPartnerNotFound)
+	}
+	if s.productRepository.FindByCode(price.ProductCode).ID == "" {
+		return errors.New(ErrProductNotFound)
+	}
+
+	existing, err := s.priceRepository.FindByPartnerProduct(price.PartnerCode, price.ProductCode)
+	if err != nil {
+		return err
+	}
+	for _, other := range append(existing, pending...) {
+		if other.ID != "" && other.ID == price.ID {
+			continue
+		}
+		if overlap(price.EffectiveFrom, price.EffectiveUntil, other.EffectiveFrom, other.EffectiveUntil) {
+			return errors.New(ErrOverlappingPrice)
+		}
+	}
+	return nil
+}
+
+// overlap of two [from, until) ranges, a zero until is open ended
+func overlap(fromA, untilA, fromB, untilB time.Time) bool {
+	return (untilB.IsZero() || fromA.Before(untilB)) && (untilA.IsZero() || fromB.Before(untilA))
+}
+
+func sellingPrice(price pricePort.PriceRepo) int64 {
+	if price.SellingPrice > 0 {
+		return price.SellingPrice
+	}
+	return price.BasePrice + price.Margin + int64(math.Round(float64(price.BasePrice)*price.MarginPercent/100))
+}
+
+func toRepo(price pricePort.PriceService) pricePort.PriceRepo {
+	return pricePort.PriceRepo{
+		ID:             price.ID,
+		PartnerCode:    price.PartnerCode,
+		ProductCode:    price.ProductCode,
+		BasePrice:      price.BasePrice,
+		SellingPrice:   price.SellingPrice,
+		Margin:         price.Margin,
+		MarginPercent:  price.MarginPercent,
+		Fee:            price.Fee,
+		EffectiveFrom:  price.EffectiveFrom,
+		EffectiveUntil: price.EffectiveUntil,
+	}
+}
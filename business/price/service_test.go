@@ -0,0 +1,199 @@
+package price_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	priceService "github.com/sepulsa/teleco/business/price"
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
+	priceRepo "github.com/sepulsa/teleco/modules/repository/mock/price"
+	productRepo "github.com/sepulsa/teleco/modules/repository/mock/product"
+	"github.com/sepulsa/teleco/utils/apperror"
+)
+
+var (
+	TestID          = "6138813fb95630b0b528b160"
+	TestPartnerCode = "partner001"
+	TestProductCode = "TSEL10"
+	TestJanuary     = time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	TestFebruary    = time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	TestMarch       = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	TestErrInvalidID = "Invalid ID"
+)
+
+func newRepositories() (*priceRepo.Repository, *partnerRepo.Repository, *productRepo.Repository) {
+	repository := priceRepo.New()
+	partnerRepository := partnerRepo.New()
+	productRepository := productRepo.New()
+	partnerRepository.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{ID: "1", Code: TestPartnerCode})
+	partnerRepository.On("FindByCode", mock.Anything).Return(partnerPort.PartnerRepo{})
+	productRepository.On("FindByCode", TestProductCode).Return(productPort.ProductRepo{ID: "2", Code: TestProductCode})
+	productRepository.On("FindByCode", mock.Anything).Return(productPort.ProductRepo{})
+	return repository, partnerRepository, productRepository
+}
+
+func TestCreateData(t *testing.T) {
+	repository, partnerRepository, productRepository := newRepositories()
+	service := priceService.New(repository, partnerRepository, productRepository)
+
+	dataService := pricePort.PriceService{
+		PartnerCode:    TestPartnerCode,
+		ProductCode:    TestProductCode,
+		BasePrice:      10000,
+		Margin:         500,
+		EffectiveFrom:  TestFebruary,
+		EffectiveUntil: TestMarch,
+	}
+
+	// success
+	repository.On("FindByPartnerProduct", TestPartnerCode, TestProductCode).Return([]pricePort.PriceRepo{
+		{ID: TestID, EffectiveFrom: TestJanuary, EffectiveUntil: TestFebruary},
+	}, nil).Once()
+	repository.On("CreateData", mock.Anything).Return(nil).Once()
+	assert.Nil(t, service.CreateData(dataService))
+
+	// overlapping an open ended price
+	repository.On("FindByPartnerProduct", TestPartnerCode, TestProductCode).Return([]pricePort.PriceRepo{
+		{ID: TestID, EffectiveFrom: TestJanuary},
+	}, nil).Once()
+	err := service.CreateData(dataService)
+	assert.Equal(t, priceService.ErrOverlappingPrice, err.Error())
+
+	// invalid effective range
+	invalid := dataService
+	invalid.EffectiveUntil = TestJanuary
+	err = service.CreateData(invalid)
+	assert.Equal(t, priceService.ErrInvalidEffectiveRange, err.Error())
+
+	// unknown partner
+	invalid = dataService
+	invalid.PartnerCode = "unknown"
+	err = service.CreateData(invalid)
+	assert.Equal(t, priceService.ErrPartnerNotFound, err.Error())
+
+	// unknown product
+	invalid = dataService
+	invalid.ProductCode = "unknown"
+	err = service.CreateData(invalid)
+	assert.Equal(t, priceService.ErrProductNotFound, err.Error())
+}
+
+func TestReadData(t *testing.T) {
+	repository := priceRepo.New()
+	service := priceService.New(repository, nil, nil)
+
+	// success
+	repository.On("ReadData", TestID).Return(pricePort.PriceRepo{ID: TestID, PartnerCode: TestPartnerCode, BasePrice: 10000}, nil).Once()
+	price, err := service.ReadData(TestID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestPartnerCode, price.PartnerCode)
+		assert.Equal(t, int64(10000), price.BasePrice)
+	}
+
+	// error
+	repository.On("ReadData", TestID).Return(pricePort.PriceRepo{}, errors.New(TestErrInvalidID)).Once()
+	_, err = service.ReadData(TestID)
+	assert.Equal(t, TestErrInvalidID, err.Error())
+}
+
+func TestUpdateData(t *testing.T) {
+	repository, partnerRepository, productRepository := newRepositories()
+	service := priceService.New(repository, partnerRepository, productRepository)
+
+	dataService := pricePort.PriceService{
+		ID:            TestID,
+		PartnerCode:   TestPartnerCode,
+		ProductCode:   TestProductCode,
+		BasePrice:     10000,
+		EffectiveFrom: TestJanuary,
+	}
+
+	// success, the price does not overlap itself
+	repository.On("ReadData", TestID).Return(pricePort.PriceRepo{ID: TestID}, nil).Once()
+	repository.On("FindByPartnerProduct", TestPartnerCode, TestProductCode).Return([]pricePort.PriceRepo{
+		{ID: TestID, EffectiveFrom: TestJanuary},
+	}, nil).Once()
+	repository.On("UpdateData", mock.Anything).Return(nil).Once()
+	assert.Nil(t, service.UpdateData(dataService))
+}
+
+func TestDeleteData(t *testing.T) {
+	repository := priceRepo.New()
+
+	repository.On("DeleteData", TestID).Return(nil).Once()
+	service := priceService.New(repository, nil, nil)
+	assert.Nil(t, service.DeleteData(TestID))
+}
+
+func TestListData(t *testing.T) {
+	repository := priceRepo.New()
+
+	repository.On("ListData").Return([]pricePort.PriceRepo{{ID: TestID, PartnerCode: TestPartnerCode}}, nil).Once()
+	service := priceService.New(repository, nil, nil)
+	prices, err := service.ListData()
+	if assert.Nil(t, err) && assert.Len(t, prices, 1) {
+		assert.Equal(t, TestPartnerCode, prices[0].PartnerCode)
+	}
+}
+
+func TestImportData(t *testing.T) {
+	repository, partnerRepository, productRepository := newRepositories()
+	service := priceService.New(repository, partnerRepository, productRepository)
+	repository.On("FindByPartnerProduct", TestPartnerCode, TestProductCode).Return([]pricePort.PriceRepo{}, nil)
+
+	january := pricePort.PriceService{PartnerCode: TestPartnerCode, ProductCode: TestProductCode, BasePrice: 10000, EffectiveFrom: TestJanuary, EffectiveUntil: TestFebruary}
+	february := pricePort.PriceService{PartnerCode: TestPartnerCode, ProductCode: TestProductCode, BasePrice: 10100, EffectiveFrom: TestFebruary}
+
+	// rows overlapping each other reject the whole import
+	importErrors, err := service.ImportData([]pricePort.PriceService{january, february, january})
+	if assert.Nil(t, err) && assert.Len(t, importErrors, 1) {
+		assert.Equal(t, 3, importErrors[0].Row)
+		assert.Equal(t, priceService.ErrOverlappingPrice, importErrors[0].Message)
+	}
+	repository.AssertNotCalled(t, "CreateData", mock.Anything)
+
+	// success
+	repository.On("CreateData", mock.Anything).Return(nil).Twice()
+	importErrors, err = service.ImportData([]pricePort.PriceService{january, february})
+	assert.Nil(t, err)
+	assert.Empty(t, importErrors)
+	repository.AssertNumberOfCalls(t, "CreateData", 2)
+}
+
+func TestQuote(t *testing.T) {
+	repository := priceRepo.New()
+	service := priceService.New(repository, nil, nil)
+
+	repository.On("FindByPartnerProduct", TestPartnerCode, TestProductCode).Return([]pricePort.PriceRepo{
+		{ID: "february", BasePrice: 10000, Margin: 200, MarginPercent: 2.5, Fee: 100, EffectiveFrom: TestFebruary},
+		{ID: "january", BasePrice: 10000, SellingPrice: 10500, EffectiveFrom: TestJanuary, EffectiveUntil: TestFebruary},
+	}, nil)
+
+	// selling price
+	quote, err := service.Quote(TestPartnerCode, TestProductCode, TestJanuary.Add(time.Hour))
+	if assert.Nil(t, err) {
+		assert.Equal(t, "january", quote.PriceId)
+		assert.Equal(t, int64(10500), quote.SellingPrice)
+	}
+
+	// margins, effective until is exclusive
+	quote, err = service.Quote(TestPartnerCode, TestProductCode, TestFebruary)
+	if assert.Nil(t, err) {
+		assert.Equal(t, "february", quote.PriceId)
+		assert.Equal(t, int64(10450), quote.SellingPrice)
+		assert.Equal(t, int64(100), quote.Fee)
+	}
+
+	// not effective yet
+	_, err = service.Quote(TestPartnerCode, TestProductCode, TestJanuary.Add(-time.Hour))
+	assert.Equal(t, priceService.ErrPriceNotFound, err.Error())
+	assert.Equal(t, apperror.NotFound, apperror.KindOf(err))
+}
@@ -0,0 +1,45 @@
+package mock
+
+import (
+	productPort "github.com/sepulsa/teleco/business/product/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type service struct {
+	mock.Mock
+}
+
+func New() *service {
+	return &service{}
+}
+
+func (s *service) CreateData(product productPort.ProductService) error {
+	result := s.Called(product)
+	return result.Error(0)
+}
+
+func (s *service) ReadData(ID string) (productPort.ProductService, error) {
+	result := s.Called(ID)
+	return result.Get(0).(productPort.ProductService), result.Error(1)
+}
+
+func (s *service) UpdateData(product productPort.ProductService) error {
+	result := s.Called(product)
+	return result.Error(0)
+}
+
+func (s *service) DeleteData(ID string) error {
+	result := s.Called(ID)
+	return result.Error(0)
+}
+
+func (s *service) ListData() ([]productPort.ProductService, error) {
+	result := s.Called()
+	return result.Get(0).([]productPort.ProductService), result.Error(1)
+}
+
+func (s *service) ListPartnerProducts(partnerCode string) ([]productPort.ProductService, error) {
+	result := s.Called(partnerCode)
+	return result.Get(0).([]productPort.ProductService), result.Error(1)
+}
@@ -0,0 +1,54 @@
+package port
+
+import "time"
+
+const (
+	TypePulsa    = "pulsa"
+	TypeData     = "data"
+	TypePLNToken = "pln_token"
+)
+
+type (
+	ProductRepo struct {
+		ID           string `json:"id"`
+		Code         string `json:"code"`
+		Name         string `json:"name"`
+		Operator     string `json:"operator"`
+		Type         string `json:"type"`
+		Denomination int64  `json:"denomination"`
+		Active       bool   `json:"active"`
+		// Issuers product id of the teleco product at every issuer serving it
+		Issuers   []IssuerProduct `json:"issuers"`
+		CreatedAt time.Time       `json:"created_at"`
+		UpdatedAt time.Time       `json:"updated_at"`
+	}
+
+	IssuerProduct struct {
+		IssuerCode      string `json:"issuer_code"`
+		IssuerProductId string `json:"issuer_product_id"`
+	}
+)
+
+// Repository is outbound port
+type Repository interface {
+	//FindByCode find product by teleco product code
+	FindByCode(code string) ProductRepo
+
+	//CreateData insert new data
+	CreateData(product ProductRepo) error
+
+	//ReadData get data by ID
+	ReadData(ID string) (ProductRepo, error)
+
+	//UpdateData update new data
+	UpdateData(product ProductRepo) error
+
+	//DeleteData delete data
+	DeleteData(ID string) error
+
+	//ListData get list data
+	ListData() ([]ProductRepo, error)
+
+	//ListActive get list of active products
+	ListActive() ([]ProductRepo, error)
+}
@@ -0,0 +1,39 @@
+package port
+
+import "time"
+
+type (
+	ProductService struct {
+		ID           string          `json:"id"`
+		Code         string          `json:"code"`
+		Name         string          `json:"name"`
+		Operator     string          `json:"operator"`
+		Type         string          `json:"type"`
+		Denomination int64           `json:"denomination"`
+		Active       bool            `json:"active"`
+		Issuers      []IssuerProduct `json:"issuers"`
+		CreatedAt    time.Time       `json:"created_at"`
+		UpdatedAt    time.Time       `json:"updated_at"`
+	}
+)
+
+// Service is inbound port
+type Service interface {
+	// CreateData insert new data
+	CreateData(product ProductService) error
+
+	// ReadData get data by ID
+	ReadData(ID string) (ProductService, error)
+
+	// UpdateData update new data
+	UpdateData(product ProductService) error
+
+	// DeleteData delete data
+	DeleteData(ID string) error
+
+	// ListData get list data
+	ListData() ([]ProductService, error)
+
+	// ListPartnerProducts get active products routed to at least one issuer enabled for the partner
+	ListPartnerProducts(partnerCode string) ([]ProductService, error)
+}
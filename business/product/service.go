@@ -0,0 +1,205 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	routePort "github.com/sepulsa/teleco/business/route/port"
+)
+
+type (
+	service struct {
+		productRepository       productPort.Repository
+		issuerRepository        issuerPort.Repository
+		partnerRepository       partnerPort.Repository
+		partnerIssuerRepository partnerIssuerPort.Repository
+		routeRepository         routePort.Repository
+	}
+)
+
+var (
+	ErrDuplicateCode     = "Product code already in use"
+	ErrProductNotFound   = "Product not found"
+	ErrPartnerNotFound   = "Partner not found"
+	ErrDuplicateIssuer   = "Issuer mapped more than once"
+	ErrUnknownIssuerCode = "Issuer code not found"
+)
+
+func New(
+	productRepository productPort.Repository,
+	issuerRepository issuerPort.Repository,
+	partnerRepository partnerPort.Repository,
+	partnerIssuerRepository partnerIssuerPort.Repository,
+	routeRepository routePort.Repository,
+) productPort.Service {
+	return &service{
+		productRepository,
+		issuerRepository,
+		partnerRepository,
+		partnerIssuerRepository,
+		routeRepository,
+	}
+}
+
+func (s *service) CreateData(product productPort.ProductService) error {
+	existingProduct := s.productRepository.FindByCode(product.Code)
+	if existingProduct.ID != "" {
+		return errors.New(ErrDuplicateCode)
+	}
+	if err := s.checkIssuers(product.Issuers); err != nil {
+		return err
+	}
+
+	data := productPort.ProductRepo{
+		Code:         product.Code,
+		Name:         product.Name,
+		Operator:     product.Operator,
+		Type:         product.Type,
+		Denomination: product.Denomination,
+		Active:       product.Active,
+		Issuers:      product.Issuers,
+	}
+	return s.productRepository.CreateData(data)
+}
+
+func (s *service) ReadData(ID string) (product productPort.ProductService, err error) {
+	data, err := s.productRepository.ReadData(ID)
+	if err != nil {
+		return
+	}
+	product = productPort.ProductService{
+		ID:           data.ID,
+		Code:         data.Code,
+		Name:         data.Name,
+		Operator:     data.Operator,
+		Type:         data.Type,
+		Denomination: data.Denomination,
+		Active:       data.Active,
+		Issuers:      data.Issuers,
+		CreatedAt:    data.CreatedAt,
+		UpdatedAt:    data.UpdatedAt,
+	}
+	return
+}
+
+func (s *service) UpdateData(product productPort.ProductService) error {
+	existingData, err := s.productRepository.ReadData(product.ID)
+	if err != nil {
+		return err
+	}
+	if existingData.Code != product.Code {
+		existingProduct := s.productRepository.FindByCode(product.Code)
+		if existingProduct.ID != "" {
+			return errors.New(ErrDuplicateCode)
+		}
+	}
+	if err := s.checkIssuers(product.Issuers); err != nil {
+		return err
+	}
+
+	data := productPort.ProductRepo{
+		ID:           product.ID,
+		Code:         product.Code,
+		Name:         product.Name,
+		Operator:     product.Operator,
+		Type:         product.Type,
+		Denomination: product.Denomination,
+		Active:       product.Active,
+		Issuers:      product.Issuers,
+	}
+	return s.productRepository.UpdateData(data)
+}
+
+func (s *service) DeleteData(ID string) error {
+	return s.productRepository.DeleteData(ID)
+}
+
+func (s *service) ListData() (products []productPort.ProductService, err error) {
+	datas, err := s.productRepository.ListData()
+	if err != nil {
+		return
+	}
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &products)
+	}
+
+	return
+}
+
+func (s *service) ListPartnerProducts(partnerCode string) (products []productPort.ProductService, err error) {
+	partner := s.partnerRepository.FindByCode(partnerCode)
+	if partner.ID == "" {
+		err = errors.New(ErrPartnerNotFound)
+		return
+	}
+
+	datas, err := s.productRepository.ListActive()
+	if err != nil {
+		return
+	}
+
+	// products share issuers, resolve every issuer once
+	enabled := make(map[string]bool)
+	isEnabled := func(issuerCode string) bool {
+		if result, found := enabled[issuerCode]; found {
+			return result
+		}
+		enabled[issuerCode] = s.issuerEnabled(partner.ID, issuerCode)
+		return enabled[issuerCode]
+	}
+
+	// listed from the route candidates, the issuers a purchase of the product is sent to
+	products = make([]productPort.ProductService, 0, len(datas))
+	for _, data := range datas {
+		route := s.routeRepository.FindByProductCode(data.Code)
+		for _, candidate := range route.Candidates {
+			if !isEnabled(candidate.IssuerCode) {
+				continue
+			}
+			products = append(products, productPort.ProductService{
+				ID:           data.ID,
+				Code:         data.Code,
+				Name:         data.Name,
+				Operator:     data.Operator,
+				Type:         data.Type,
+				Denomination: data.Denomination,
+				Active:       data.Active,
+				Issuers:      data.Issuers,
+				CreatedAt:    data.CreatedAt,
+				UpdatedAt:    data.UpdatedAt,
+			})
+			break
+		}
+	}
+
+	return
+}
+
+// issuerEnabled the issuer is active and configured for the partner
+func (s *service) issuerEnabled(partnerId string, issuerCode string) bool {
+	issuer := s.issuerRepository.FindByCode(issuerCode)
+	if issuer.ID == "" || issuer.Status == issuerPort.StatusInactive {
+		return false
+	}
+	_, err := s.partnerIssuerRepository.FindByPartnerIssuerID(partnerId, issuer.ID)
+	return err == nil
+}
+
+func (s *service) checkIssuers(issuers []productPort.IssuerProduct) error {
+	seen := make(map[string]bool, len(issuers))
+	for _, issuer := range issuers {
+		if seen[issuer.IssuerCode] {
+			return errors.New(ErrDuplicateIssuer)
+		}
+		seen[issuer.IssuerCode] = true
+		if s.issuerRepository.FindByCode(issuer.IssuerCode).ID == "" {
+			return errors.New(ErrUnknownIssuerCode)
+		}
+	}
+	return nil
+}
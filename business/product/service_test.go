@@ -0,0 +1,185 @@
+package product_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	productService "github.com/sepulsa/teleco/business/product"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	issuerRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer"
+	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
+	partnerIssuerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner/issuer"
+	productRepo "github.com/sepulsa/teleco/modules/repository/mock/product"
+	routeRepo "github.com/sepulsa/teleco/modules/repository/mock/route"
+)
+
+var (
+	TestID          = "6138813fb95630b0b528b160"
+	TestCode        = "TSEL10"
+	TestPartnerCode = "partner001"
+	TestPartnerID   = "6138813fb95630b0b528b161"
+	TestIssuers     = []productPort.IssuerProduct{
+		{IssuerCode: "primary", IssuerProductId: "P10"},
+		{IssuerCode: "backup", IssuerProductId: "B10"},
+	}
+
+	TestErrInvalidID = "Invalid ID"
+)
+
+func TestCreateData(t *testing.T) {
+	repository := productRepo.New()
+	issuerRepository := issuerRepo.New()
+
+	dataService := productPort.ProductService{
+		Code:         TestCode,
+		Name:         "Telkomsel 10.000",
+		Operator:     "telkomsel",
+		Type:         productPort.TypePulsa,
+		Denomination: 10000,
+		Active:       true,
+		Issuers:      TestIssuers,
+	}
+	issuerRepository.On("FindByCode", "primary").Return(issuerPort.IssuerRepo{ID: "1"})
+	issuerRepository.On("FindByCode", "backup").Return(issuerPort.IssuerRepo{ID: "2"})
+
+	// success
+	repository.On("FindByCode", TestCode).Return(productPort.ProductRepo{}).Once()
+	repository.On("CreateData", mock.Anything).Return(nil).Once()
+	service := productService.New(repository, issuerRepository, nil, nil, nil)
+	assert.Nil(t, service.CreateData(dataService))
+
+	// duplicate code
+	repository.On("FindByCode", TestCode).Return(productPort.ProductRepo{ID: TestID}).Once()
+	err := service.CreateData(dataService)
+	assert.Equal(t, productService.ErrDuplicateCode, err.Error())
+
+	// unknown issuer code
+	dataService.Issuers = []productPort.IssuerProduct{{IssuerCode: "unknown", IssuerProductId: "U10"}}
+	issuerRepository.On("FindByCode", "unknown").Return(issuerPort.IssuerRepo{}).Once()
+	repository.On("FindByCode", TestCode).Return(productPort.ProductRepo{}).Once()
+	err = service.CreateData(dataService)
+	assert.Equal(t, productService.ErrUnknownIssuerCode, err.Error())
+
+	// issuer mapped twice
+	dataService.Issuers = []productPort.IssuerProduct{TestIssuers[0], TestIssuers[0]}
+	repository.On("FindByCode", TestCode).Return(productPort.ProductRepo{}).Once()
+	err = service.CreateData(dataService)
+	assert.Equal(t, productService.ErrDuplicateIssuer, err.Error())
+}
+
+func TestReadData(t *testing.T) {
+	repository := productRepo.New()
+
+	dataRepo := productPort.ProductRepo{
+		ID:           TestID,
+		Code:         TestCode,
+		Denomination: 10000,
+		Issuers:      TestIssuers,
+	}
+
+	// success
+	repository.On("ReadData", TestID).Return(dataRepo, nil).Once()
+	service := productService.New(repository, nil, nil, nil, nil)
+	product, err := service.ReadData(TestID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestCode, product.Code)
+		assert.Equal(t, int64(10000), product.Denomination)
+		assert.Equal(t, TestIssuers, product.Issuers)
+	}
+
+	// error
+	repository.On("ReadData", TestID).Return(productPort.ProductRepo{}, errors.New(TestErrInvalidID)).Once()
+	_, err = service.ReadData(TestID)
+	assert.Equal(t, TestErrInvalidID, err.Error())
+}
+
+func TestUpdateData(t *testing.T) {
+	repository := productRepo.New()
+	issuerRepository := issuerRepo.New()
+
+	dataService := productPort.ProductService{
+		ID:      TestID,
+		Code:    TestCode + "X",
+		Issuers: TestIssuers[:1],
+	}
+	issuerRepository.On("FindByCode", "primary").Return(issuerPort.IssuerRepo{ID: "1"})
+
+	// success
+	repository.On("ReadData", TestID).Return(productPort.ProductRepo{ID: TestID, Code: TestCode}, nil).Once()
+	repository.On("FindByCode", TestCode+"X").Return(productPort.ProductRepo{}).Once()
+	repository.On("UpdateData", mock.Anything).Return(nil).Once()
+	service := productService.New(repository, issuerRepository, nil, nil, nil)
+	assert.Nil(t, service.UpdateData(dataService))
+
+	// duplicate code
+	repository.On("ReadData", TestID).Return(productPort.ProductRepo{ID: TestID, Code: TestCode}, nil).Once()
+	repository.On("FindByCode", TestCode+"X").Return(productPort.ProductRepo{ID: "other"}).Once()
+	err := service.UpdateData(dataService)
+	assert.Equal(t, productService.ErrDuplicateCode, err.Error())
+}
+
+func TestDeleteData(t *testing.T) {
+	repository := productRepo.New()
+
+	repository.On("DeleteData", TestID).Return(nil).Once()
+	service := productService.New(repository, nil, nil, nil, nil)
+	assert.Nil(t, service.DeleteData(TestID))
+}
+
+func TestListData(t *testing.T) {
+	repository := productRepo.New()
+
+	repository.On("ListData").Return([]productPort.ProductRepo{{ID: TestID, Code: TestCode, Issuers: TestIssuers}}, nil).Once()
+	service := productService.New(repository, nil, nil, nil, nil)
+	products, err := service.ListData()
+	if assert.Nil(t, err) && assert.Len(t, products, 1) {
+		assert.Equal(t, TestCode, products[0].Code)
+		assert.Len(t, products[0].Issuers, 2)
+	}
+}
+
+func TestListPartnerProducts(t *testing.T) {
+	repository := productRepo.New()
+	issuerRepository := issuerRepo.New()
+	partnerRepository := partnerRepo.New()
+	partnerIssuerRepository := partnerIssuerRepo.New()
+	routeRepository := routeRepo.New()
+	service := productService.New(repository, issuerRepository, partnerRepository, partnerIssuerRepository, routeRepository)
+
+	// error partner not found
+	partnerRepository.On("FindByCode", "unknown").Return(partnerPort.PartnerRepo{}).Once()
+	_, err := service.ListPartnerProducts("unknown")
+	assert.Equal(t, productService.ErrPartnerNotFound, err.Error())
+
+	// only products routed to an issuer active and enabled for the partner
+	partnerRepository.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{ID: TestPartnerID, Code: TestPartnerCode}).Once()
+	repository.On("ListActive").Return([]productPort.ProductRepo{
+		{ID: "1", Code: "TSEL10", Active: true, Issuers: TestIssuers},
+		{ID: "2", Code: "TSEL20", Active: true, Issuers: TestIssuers},
+		{ID: "3", Code: "XL10", Active: true},
+		{ID: "4", Code: "XL20", Active: true, Issuers: TestIssuers},
+	}, nil).Once()
+	routeRepository.On("FindByProductCode", "TSEL10").Return(routePort.RouteRepo{ProductCode: "TSEL10", Candidates: []routePort.Candidate{{IssuerCode: "backup"}, {IssuerCode: "primary"}}}).Once()
+	routeRepository.On("FindByProductCode", "TSEL20").Return(routePort.RouteRepo{ProductCode: "TSEL20", Candidates: []routePort.Candidate{{IssuerCode: "backup"}}}).Once()
+	routeRepository.On("FindByProductCode", "XL10").Return(routePort.RouteRepo{ProductCode: "XL10", Candidates: []routePort.Candidate{{IssuerCode: "paused"}}}).Once()
+	// a product without route can not be purchased
+	routeRepository.On("FindByProductCode", "XL20").Return(routePort.RouteRepo{}).Once()
+	issuerRepository.On("FindByCode", "primary").Return(issuerPort.IssuerRepo{ID: "10", Code: "primary"}).Once()
+	issuerRepository.On("FindByCode", "backup").Return(issuerPort.IssuerRepo{ID: "20", Code: "backup"}).Once()
+	issuerRepository.On("FindByCode", "paused").Return(issuerPort.IssuerRepo{ID: "30", Code: "paused", Status: issuerPort.StatusInactive}).Once()
+	partnerIssuerRepository.On("FindByPartnerIssuerID", TestPartnerID, "10").Return(partnerIssuerPort.PartnerIssuerRepo{ID: "100"}, nil).Once()
+	partnerIssuerRepository.On("FindByPartnerIssuerID", TestPartnerID, "20").Return(partnerIssuerPort.PartnerIssuerRepo{}, errors.New("Partner Issuer not found")).Once()
+	products, err := service.ListPartnerProducts(TestPartnerCode)
+	if assert.Nil(t, err) && assert.Len(t, products, 1) {
+		assert.Equal(t, "TSEL10", products[0].Code)
+	}
+	issuerRepository.AssertExpectations(t)
+	partnerIssuerRepository.AssertExpectations(t)
+}
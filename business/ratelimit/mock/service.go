@@ -0,0 +1,25 @@
+package mock
+
+import (
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type service struct {
+	mock.Mock
+}
+
+func New() *service {
+	return &service{}
+}
+
+func (s *service) Allow(partnerCode string, issuerCode string) (ratelimitPort.Result, error) {
+	result := s.Called(partnerCode, issuerCode)
+	return result.Get(0).(ratelimitPort.Result), result.Error(1)
+}
+
+func (s *service) AllowIssuer(partnerCode string, issuerCode string) (ratelimitPort.Result, error) {
+	result := s.Called(partnerCode, issuerCode)
+	return result.Get(0).(ratelimitPort.Result), result.Error(1)
+}
@@ -0,0 +1,14 @@
+package port
+
+import (
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+)
+
+// Repository is outbound port
+type Repository interface {
+	// Take consume one token of every named bucket of limits kept under key, or none when one of them is empty,
+	// must be atomic for concurrent callers and return an apperror.Conflict when it gives up under contention
+	Take(key string, limits map[string]partnerPort.RateLimit, now time.Time) (allowed bool, retryAfter time.Duration, err error)
+}
@@ -0,0 +1,20 @@
+package port
+
+import "time"
+
+type (
+	Result struct {
+		Allowed    bool
+		RetryAfter time.Duration
+	}
+)
+
+// Service is inbound port
+type Service interface {
+	// Allow check partner and partner+issuer rate limits of one request
+	Allow(partnerCode string, issuerCode string) (Result, error)
+
+	// AllowIssuer check only the partner+issuer rate limit, of a product order routed to the issuer
+	// once Allow checked the partner rate limit of its request
+	AllowIssuer(partnerCode string, issuerCode string) (Result, error)
+}
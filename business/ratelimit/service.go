@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+)
+
+type (
+	service struct {
+		partnerRepository partnerPort.Repository
+		bucketRepository  ratelimitPort.Repository
+	}
+)
+
+var (
+	// PartnerBucket bucket of every request of a partner
+	PartnerBucket = "partner"
+)
+
+func New(partnerRepository partnerPort.Repository, bucketRepository ratelimitPort.Repository) ratelimitPort.Service {
+	return &service{
+		partnerRepository,
+		bucketRepository,
+	}
+}
+
+func (s *service) Allow(partnerCode string, issuerCode string) (ratelimitPort.Result, error) {
+	return s.allow(partnerCode, issuerCode, true)
+}
+
+func (s *service) AllowIssuer(partnerCode string, issuerCode string) (ratelimitPort.Result, error) {
+	return s.allow(partnerCode, issuerCode, false)
+}
+
+// allow take the partner+issuer bucket, along with the partner one when partnerLimit is set
+func (s *service) allow(partnerCode string, issuerCode string, partnerLimit bool) (result ratelimitPort.Result, err error) {
+	result.Allowed = true
+
+	partner := s.partnerRepository.FindByCode(partnerCode)
+	if partner.ID == "" {
+		return
+	}
+
+	// both buckets are taken together so a request rejected by one does not cost a token of the other
+	limits := make(map[string]partnerPort.RateLimit, 2)
+	if partnerLimit && partner.RateLimit.Rate > 0 {
+		limits[PartnerBucket] = partner.RateLimit
+	}
+	if limit, found := partner.IssuerRateLimit[issuerCode]; found && limit.Rate > 0 && issuerCode != "" {
+		limits[IssuerBucket(issuerCode)] = limit
+	}
+	if len(limits) == 0 {
+		return
+	}
+
+	result.Allowed, result.RetryAfter, err = s.bucketRepository.Take(PartnerKey(partnerCode), limits, time.Now())
+	return
+}
+
+// PartnerKey buckets of a partner are kept together
+func PartnerKey(partnerCode string) string {
+	return "partner:" + partnerCode
+}
+
+// IssuerBucket bucket of the partner requests to one issuer
+func IssuerBucket(issuerCode string) string {
+	return "issuer:" + issuerCode
+}
@@ -0,0 +1,129 @@
+package ratelimit_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	ratelimitService "github.com/sepulsa/teleco/business/ratelimit"
+	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
+	bucketRepo "github.com/sepulsa/teleco/modules/repository/mock/ratelimit"
+)
+
+var (
+	TestPartnerID         = "613f12af2edd3a56323f0d1d"
+	TestPartnerCode       = "p1"
+	TestIssuerCode        = "dummy"
+	TestOtherIssuerCode   = "other"
+	TestPartnerKey        = "partner:p1"
+	TestIssuerBucket      = "issuer:dummy"
+	TestPartnerRateLimit  = partnerPort.RateLimit{Rate: 10, Burst: 20}
+	TestIssuerRateLimit   = partnerPort.RateLimit{Rate: 1, Burst: 5}
+	TestRetryAfter        = 500 * time.Millisecond
+	TestErrBucketRepoDown = errors.New("bucket repo down")
+)
+
+func TestAllow(t *testing.T) {
+	t.Run("Expect unknown partner allowed", func(t *testing.T) {
+		pRepo := partnerRepo.New()
+		bRepo := bucketRepo.New()
+		pRepo.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{})
+
+		result, err := ratelimitService.New(pRepo, bRepo).Allow(TestPartnerCode, TestIssuerCode)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		bRepo.AssertNotCalled(t, "Take", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expect no limit configured allowed", func(t *testing.T) {
+		pRepo := partnerRepo.New()
+		bRepo := bucketRepo.New()
+		pRepo.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{ID: TestPartnerID})
+
+		result, err := ratelimitService.New(pRepo, bRepo).Allow(TestPartnerCode, TestIssuerCode)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		bRepo.AssertNotCalled(t, "Take", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Expect partner limit exceeded", func(t *testing.T) {
+		pRepo := partnerRepo.New()
+		bRepo := bucketRepo.New()
+		pRepo.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{ID: TestPartnerID, RateLimit: TestPartnerRateLimit})
+		limits := map[string]partnerPort.RateLimit{ratelimitService.PartnerBucket: TestPartnerRateLimit}
+		bRepo.On("Take", TestPartnerKey, limits, mock.Anything).Return(false, TestRetryAfter, nil)
+
+		result, err := ratelimitService.New(pRepo, bRepo).Allow(TestPartnerCode, TestIssuerCode)
+		assert.Nil(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, TestRetryAfter, result.RetryAfter)
+	})
+
+	t.Run("Expect partner issuer limit exceeded", func(t *testing.T) {
+		pRepo := partnerRepo.New()
+		bRepo := bucketRepo.New()
+		pRepo.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{
+			ID:              TestPartnerID,
+			RateLimit:       TestPartnerRateLimit,
+			IssuerRateLimit: map[string]partnerPort.RateLimit{TestIssuerCode: TestIssuerRateLimit},
+		})
+		// both buckets in one call so a rejected request costs no partner token
+		bRepo.On("Take", TestPartnerKey, map[string]partnerPort.RateLimit{
+			ratelimitService.PartnerBucket: TestPartnerRateLimit,
+			TestIssuerBucket:               TestIssuerRateLimit,
+		}, mock.Anything).Return(false, TestRetryAfter, nil)
+		bRepo.On("Take", TestPartnerKey, map[string]partnerPort.RateLimit{
+			ratelimitService.PartnerBucket: TestPartnerRateLimit,
+		}, mock.Anything).Return(true, time.Duration(0), nil)
+
+		s := ratelimitService.New(pRepo, bRepo)
+		result, err := s.Allow(TestPartnerCode, TestIssuerCode)
+		assert.Nil(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, TestRetryAfter, result.RetryAfter)
+
+		// other issuer only uses the partner bucket
+		result, err = s.Allow(TestPartnerCode, TestOtherIssuerCode)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("Expect issuer limit only", func(t *testing.T) {
+		pRepo := partnerRepo.New()
+		bRepo := bucketRepo.New()
+		pRepo.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{
+			ID:              TestPartnerID,
+			RateLimit:       TestPartnerRateLimit,
+			IssuerRateLimit: map[string]partnerPort.RateLimit{TestIssuerCode: TestIssuerRateLimit},
+		})
+		// the partner bucket was already taken by the request
+		bRepo.On("Take", TestPartnerKey, map[string]partnerPort.RateLimit{
+			TestIssuerBucket: TestIssuerRateLimit,
+		}, mock.Anything).Return(false, TestRetryAfter, nil).Once()
+
+		s := ratelimitService.New(pRepo, bRepo)
+		result, err := s.AllowIssuer(TestPartnerCode, TestIssuerCode)
+		assert.Nil(t, err)
+		assert.False(t, result.Allowed)
+
+		// other issuer has no limit
+		result, err = s.AllowIssuer(TestPartnerCode, TestOtherIssuerCode)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		bRepo.AssertNumberOfCalls(t, "Take", 1)
+	})
+
+	t.Run("Expect bucket repository error", func(t *testing.T) {
+		pRepo := partnerRepo.New()
+		bRepo := bucketRepo.New()
+		pRepo.On("FindByCode", TestPartnerCode).Return(partnerPort.PartnerRepo{ID: TestPartnerID, RateLimit: TestPartnerRateLimit})
+		bRepo.On("Take", TestPartnerKey, mock.Anything, mock.Anything).Return(false, time.Duration(0), TestErrBucketRepoDown)
+
+		_, err := ratelimitService.New(pRepo, bRepo).Allow(TestPartnerCode, TestIssuerCode)
+		assert.Equal(t, TestErrBucketRepoDown, err)
+	})
+}
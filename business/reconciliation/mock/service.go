@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"io"
+	"time"
+
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type service struct {
+	mock.Mock
+}
+
+func New() *service {
+	return &service{}
+}
+
+func (s *service) Reconcile(issuerCode string, date time.Time, fileName string, file io.Reader) (reconciliationPort.RunService, []reconciliationPort.RowError, error) {
+	result := s.Called(issuerCode, date, fileName, file)
+	return result.Get(0).(reconciliationPort.RunService), result.Get(1).([]reconciliationPort.RowError), result.Error(2)
+}
+
+func (s *service) ReadRun(ID string) (reconciliationPort.RunService, error) {
+	result := s.Called(ID)
+	return result.Get(0).(reconciliationPort.RunService), result.Error(1)
+}
+
+func (s *service) ListRuns(issuerCode string) ([]reconciliationPort.RunService, error) {
+	result := s.Called(issuerCode)
+	return result.Get(0).([]reconciliationPort.RunService), result.Error(1)
+}
+
+func (s *service) ListItems(runId string, class string) ([]reconciliationPort.ItemService, error) {
+	result := s.Called(runId, class)
+	return result.Get(0).([]reconciliationPort.ItemService), result.Error(1)
+}
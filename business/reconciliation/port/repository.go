@@ -0,0 +1,73 @@
+package port
+
+import "time"
+
+const (
+	// ClassMatched row found on both sides with the same status and amount
+	ClassMatched = "matched"
+	// ClassMissingOurs row of the settlement file without purchase on our side
+	ClassMissingOurs = "missing_ours"
+	// ClassMissingIssuer successful purchase on our side absent from the settlement file
+	ClassMissingIssuer = "missing_issuer"
+	// ClassAmountMismatch issuer amount differs from the base price of the purchase
+	ClassAmountMismatch = "amount_mismatch"
+	// ClassStatusMismatch issuer status differs from the final status of the purchase
+	ClassStatusMismatch = "status_mismatch"
+	// ClassDuplicate row of a purchase already matched by a previous row
+	ClassDuplicate = "duplicate"
+
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	// StatusPending purchase result not known yet on our side
+	StatusPending = "pending"
+)
+
+type (
+	// RunRepo one reconciliation of an issuer settlement file against the purchases of a day
+	RunRepo struct {
+		ID             string    `json:"id"`
+		IssuerCode     string    `json:"issuer_code"`
+		FileName       string    `json:"file_name"`
+		Date           time.Time `json:"date"`
+		Rows           int       `json:"rows"`
+		Matched        int       `json:"matched"`
+		MissingOurs    int       `json:"missing_ours"`
+		MissingIssuer  int       `json:"missing_issuer"`
+		AmountMismatch int       `json:"amount_mismatch"`
+		StatusMismatch int       `json:"status_mismatch"`
+		Duplicate      int       `json:"duplicate"`
+		CreatedAt      time.Time `json:"created_at"`
+	}
+
+	// ItemRepo one row of the settlement file or one purchase absent from it
+	ItemRepo struct {
+		ID                  string `json:"id"`
+		RunId               string `json:"run_id"`
+		Class               string `json:"class"`
+		Row                 int    `json:"row"` // zero for purchases absent from the file
+		TransactionId       string `json:"transaction_id"`
+		IssuerTransactionId string `json:"issuer_transaction_id"`
+		Amount              int64  `json:"amount"`
+		IssuerAmount        int64  `json:"issuer_amount"`
+		Status              string `json:"status"`
+		IssuerStatus        string `json:"issuer_status"`
+	}
+)
+
+// Repository is outbound port
+type Repository interface {
+	//CreateRun insert new run, returned with its ID
+	CreateRun(run RunRepo) (RunRepo, error)
+
+	//CreateItems insert the items of a run
+	CreateItems(items []ItemRepo) error
+
+	//ReadRun get run by ID
+	ReadRun(ID string) (RunRepo, error)
+
+	//ListRuns get the runs of an issuer, of every issuer when issuerCode is empty, newest first
+	ListRuns(issuerCode string) ([]RunRepo, error)
+
+	//ListItems get the items of a run, of every class when class is empty
+	ListItems(runId string, class string) ([]ItemRepo, error)
+}
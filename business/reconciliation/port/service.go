@@ -0,0 +1,58 @@
+package port
+
+import (
+	"io"
+	"time"
+)
+
+type (
+	RunService struct {
+		ID             string    `json:"id"`
+		IssuerCode     string    `json:"issuer_code"`
+		FileName       string    `json:"file_name"`
+		Date           time.Time `json:"date"`
+		Rows           int       `json:"rows"`
+		Matched        int       `json:"matched"`
+		MissingOurs    int       `json:"missing_ours"`
+		MissingIssuer  int       `json:"missing_issuer"`
+		AmountMismatch int       `json:"amount_mismatch"`
+		StatusMismatch int       `json:"status_mismatch"`
+		Duplicate      int       `json:"duplicate"`
+		CreatedAt      time.Time `json:"created_at"`
+	}
+
+	ItemService struct {
+		ID                  string `json:"id"`
+		RunId               string `json:"run_id"`
+		Class               string `json:"class"`
+		Row                 int    `json:"row"`
+		TransactionId       string `json:"transaction_id"`
+		IssuerTransactionId string `json:"issuer_transaction_id"`
+		Amount              int64  `json:"amount"`
+		IssuerAmount        int64  `json:"issuer_amount"`
+		Status              string `json:"status"`
+		IssuerStatus        string `json:"issuer_status"`
+	}
+
+	// RowError settlement file row that could not be read
+	RowError struct {
+		Row     int    `json:"row"`
+		Message string `json:"message"`
+	}
+)
+
+// Service is inbound port
+type Service interface {
+	// Reconcile match the settlement file of an issuer against its purchases of the day and store the run,
+	// nothing is stored when a row can not be read
+	Reconcile(issuerCode string, date time.Time, fileName string, file io.Reader) (RunService, []RowError, error)
+
+	// ReadRun get run by ID
+	ReadRun(ID string) (RunService, error)
+
+	// ListRuns get the runs of an issuer, of every issuer when issuerCode is empty
+	ListRuns(issuerCode string) ([]RunService, error)
+
+	// ListItems get the items of a run, of every class when class is empty
+	ListItems(runId string, class string) ([]ItemService, error)
+}
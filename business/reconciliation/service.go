@@ -0,0 +1,229 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+)
+
+type (
+	service struct {
+		reconciliationRepository reconciliationPort.Repository
+		issuerRepository         issuerPort.Repository
+		orderRepository          orderPort.Repository
+	}
+
+	// purchase our side of a transaction, built from its purchase records
+	purchase struct {
+		TransactionId       string
+		IssuerTransactionId string
+		Amount              int64
+		Status              string
+		matched             bool
+	}
+)
+
+var (
+	ErrIssuerNotFound         = "Issuer not found"
+	ErrMappingNotConfigured   = "Issuer settlement mapping not configured"
+	ErrReconciliationNotFound = "Reconciliation not found"
+	ErrInvalidSettlement      = "Invalid settlement file"
+)
+
+func New(reconciliationRepository reconciliationPort.Repository, issuerRepository issuerPort.Repository, orderRepository orderPort.Repository) reconciliationPort.Service {
+	return &service{
+		reconciliationRepository,
+		issuerRepository,
+		orderRepository,
+	}
+}
+
+func (s *service) Reconcile(issuerCode string, date time.Time, fileName string, file io.Reader) (run reconciliationPort.RunService, rowErrors []reconciliationPort.RowError, err error) {
+	issuer := s.issuerRepository.FindByCode(issuerCode)
+	if issuer.ID == "" {
+		err = errors.New(ErrIssuerNotFound)
+		return
+	}
+	mapping := issuer.SettlementMapping
+	if mapping.TransactionId == "" && mapping.IssuerTransactionId == "" {
+		err = errors.New(ErrMappingNotConfigured)
+		return
+	}
+	rows, rowErrors, err := parseSettlement(file, mapping)
+	if err != nil {
+		err = fmt.Errorf("%s: %s", ErrInvalidSettlement, err.Error())
+		return
+	}
+	if len(rowErrors) > 0 {
+		return
+	}
+
+	from := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	filter := orderPort.PurchaseFilter{
+		IssuerId: issuer.ID,
+		From:     from,
+		Until:    from.AddDate(0, 0, 1),
+	}
+	orders, err := s.orderRepository.ListPurchases(filter)
+	if err != nil {
+		return
+	}
+	purchases, byTrx, byIssuerTrx := collectPurchases(orders)
+
+	runData := reconciliationPort.RunRepo{
+		IssuerCode: issuer.Code,
+		FileName:   fileName,
+		Date:       from,
+		Rows:       len(rows),
+	}
+	items := make([]reconciliationPort.ItemRepo, 0, len(rows))
+	for _, row := range rows {
+		item := reconciliationPort.ItemRepo{
+			Row:                 row.Row,
+			TransactionId:       row.TransactionId,
+			IssuerTransactionId: row.IssuerTransactionId,
+			IssuerAmount:        row.Amount,
+			IssuerStatus:        row.Status,
+		}
+		p := byIssuerTrx[row.IssuerTransactionId]
+		if p == nil {
+			p = byTrx[row.TransactionId]
+		}
+		if p != nil {
+			item.TransactionId = p.TransactionId
+			item.IssuerTransactionId = p.IssuerTransactionId
+			item.Amount = p.Amount
+			item.Status = p.Status
+		}
+		switch {
+		case p == nil:
+			item.Class = reconciliationPort.ClassMissingOurs
+		case p.matched:
+			item.Class = reconciliationPort.ClassDuplicate
+		case mapping.Status != "" && row.Status != p.Status:
+			item.Class = reconciliationPort.ClassStatusMismatch
+		case mapping.Amount != "" && p.Amount > 0 && row.Amount != p.Amount:
+			item.Class = reconciliationPort.ClassAmountMismatch
+		default:
+			item.Class = reconciliationPort.ClassMatched
+		}
+		if p != nil {
+			p.matched = true
+		}
+		items = append(items, item)
+	}
+	for _, p := range purchases {
+		if p.matched || p.Status != reconciliationPort.StatusSuccess {
+			continue
+		}
+		items = append(items, reconciliationPort.ItemRepo{
+			Class:               reconciliationPort.ClassMissingIssuer,
+			TransactionId:       p.TransactionId,
+			IssuerTransactionId: p.IssuerTransactionId,
+			Amount:              p.Amount,
+			Status:              p.Status,
+		})
+	}
+	for _, item := range items {
+		switch item.Class {
+		case reconciliationPort.ClassMatched:
+			runData.Matched++
+		case reconciliationPort.ClassMissingOurs:
+			runData.MissingOurs++
+		case reconciliationPort.ClassMissingIssuer:
+			runData.MissingIssuer++
+		case reconciliationPort.ClassAmountMismatch:
+			runData.AmountMismatch++
+		case reconciliationPort.ClassStatusMismatch:
+			runData.StatusMismatch++
+		case reconciliationPort.ClassDuplicate:
+			runData.Duplicate++
+		}
+	}
+
+	if runData, err = s.reconciliationRepository.CreateRun(runData); err != nil {
+		return
+	}
+	for i := range items {
+		items[i].RunId = runData.ID
+	}
+	if len(items) > 0 {
+		if err = s.reconciliationRepository.CreateItems(items); err != nil {
+			return
+		}
+	}
+	run = reconciliationPort.RunService(runData)
+	return
+}
+
+// collectPurchases one purchase per transaction id, its status is the last final result of its records
+// and its amount the base price of the product
+func collectPurchases(orders []orderPort.OrderRepo) ([]*purchase, map[string]*purchase, map[string]*purchase) {
+	purchases := make([]*purchase, 0)
+	byTrx := make(map[string]*purchase)
+	byIssuerTrx := make(map[string]*purchase)
+	for _, order := range orders {
+		p, found := byTrx[order.TransactionId]
+		if !found {
+			p = &purchase{TransactionId: order.TransactionId, Status: reconciliationPort.StatusPending}
+			purchases = append(purchases, p)
+			byTrx[order.TransactionId] = p
+		}
+		if order.IssuerTransactionId != "" {
+			p.IssuerTransactionId = order.IssuerTransactionId
+			byIssuerTrx[order.IssuerTransactionId] = p
+		}
+		if p.Amount == 0 {
+			p.Amount = order.Price.BasePrice
+		}
+		switch order.IssuerRescode {
+		case orderPort.RescodeSuccess:
+			p.Status = reconciliationPort.StatusSuccess
+		case orderPort.RescodePending, "":
+		default:
+			p.Status = reconciliationPort.StatusFailed
+		}
+	}
+	return purchases, byTrx, byIssuerTrx
+}
+
+func (s *service) ReadRun(ID string) (run reconciliationPort.RunService, err error) {
+	data, err := s.reconciliationRepository.ReadRun(ID)
+	if err != nil {
+		return
+	}
+	return reconciliationPort.RunService(data), nil
+}
+
+func (s *service) ListRuns(issuerCode string) (runs []reconciliationPort.RunService, err error) {
+	datas, err := s.reconciliationRepository.ListRuns(issuerCode)
+	if err != nil {
+		return
+	}
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &runs)
+	}
+	return
+}
+
+func (s *service) ListItems(runId string, class string) (items []reconciliationPort.ItemService, err error) {
+	if _, err = s.reconciliationRepository.ReadRun(runId); err != nil {
+		return
+	}
+	datas, err := s.reconciliationRepository.ListItems(runId, class)
+	if err != nil {
+		return
+	}
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &items)
+	}
+	return
+}
@@ -0,0 +1,154 @@
+package reconciliation_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	reconciliationService "github.com/sepulsa/teleco/business/reconciliation"
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+	issuerRepo "github.com/sepulsa/teleco/modules/repository/mock/issuer"
+	orderRepo "github.com/sepulsa/teleco/modules/repository/mock/order"
+	reconciliationRepo "github.com/sepulsa/teleco/modules/repository/mock/reconciliation"
+)
+
+var (
+	TestID         = "6138813fb95630b0b528b160"
+	TestIssuerID   = "6138813fb95630b0b528b161"
+	TestIssuerCode = "dummy"
+	TestFileName   = "settlement.csv"
+	TestDate       = time.Date(2021, 9, 8, 15, 4, 5, 0, time.UTC)
+
+	TestMapping = issuerPort.SettlementMapping{
+		TransactionId:       "IdTrx",
+		IssuerTransactionId: "ReffId",
+		Amount:              "Nominal",
+		Status:              "Status",
+		SuccessStatus:       []string{"SUKSES"},
+		Delimiter:           ";",
+	}
+)
+
+func priced(order orderPort.OrderRepo, basePrice int64) orderPort.OrderRepo {
+	order.Price = orderPort.OrderPrice{BasePrice: basePrice}
+	return order
+}
+
+func TestReconcile(t *testing.T) {
+	repository := reconciliationRepo.New()
+	issuerRepository := issuerRepo.New()
+	orderRepository := orderRepo.New()
+	service := reconciliationService.New(repository, issuerRepository, orderRepository)
+
+	issuerRepository.On("FindByCode", TestIssuerCode).Return(issuerPort.IssuerRepo{ID: TestIssuerID, Code: TestIssuerCode, SettlementMapping: TestMapping})
+	from := time.Date(2021, 9, 8, 0, 0, 0, 0, time.UTC)
+	filter := orderPort.PurchaseFilter{IssuerId: TestIssuerID, From: from, Until: from.AddDate(0, 0, 1)}
+	orders := []orderPort.OrderRepo{
+		// matched
+		priced(orderPort.OrderRepo{TransactionId: "trx1", IssuerTransactionId: "reff1", IssuerRescode: "00"}, 10000),
+		// pending then success through the callback, amount differs
+		priced(orderPort.OrderRepo{TransactionId: "trx2", IssuerRescode: "10"}, 20000),
+		{TransactionId: "trx2", IssuerTransactionId: "reff2", IssuerRescode: "00"},
+		// pending on our side, success at the issuer
+		priced(orderPort.OrderRepo{TransactionId: "trx3", IssuerRescode: "10"}, 10000),
+		// success absent from the file
+		priced(orderPort.OrderRepo{TransactionId: "trx4", IssuerTransactionId: "reff4", IssuerRescode: "00"}, 10000),
+		// failed absent from the file
+		priced(orderPort.OrderRepo{TransactionId: "trx5", IssuerRescode: "05"}, 10000),
+	}
+	orderRepository.On("ListPurchases", filter).Return(orders, nil).Once()
+	repository.On("CreateRun", mock.MatchedBy(func(run reconciliationPort.RunRepo) bool {
+		return run.Rows == 5 && run.Matched == 1 && run.AmountMismatch == 1 && run.StatusMismatch == 1 &&
+			run.MissingOurs == 1 && run.MissingIssuer == 1 && run.Duplicate == 1 && run.Date.Equal(from)
+	})).Return(reconciliationPort.RunRepo{ID: TestID, Rows: 5}, nil).Once()
+	repository.On("CreateItems", mock.MatchedBy(func(items []reconciliationPort.ItemRepo) bool {
+		if len(items) != 6 {
+			return false
+		}
+		for _, item := range items {
+			if item.RunId != TestID {
+				return false
+			}
+		}
+		return items[0].Class == reconciliationPort.ClassMatched &&
+			items[1].Class == reconciliationPort.ClassAmountMismatch && items[1].Amount == 20000 && items[1].IssuerAmount == 21000 &&
+			items[2].Class == reconciliationPort.ClassStatusMismatch && items[2].Status == reconciliationPort.StatusPending &&
+			items[3].Class == reconciliationPort.ClassMissingOurs &&
+			items[4].Class == reconciliationPort.ClassDuplicate &&
+			items[5].Class == reconciliationPort.ClassMissingIssuer && items[5].TransactionId == "trx4"
+	})).Return(nil).Once()
+	file := "IdTrx;ReffId;Nominal;Status\n" +
+		"trx1;reff1;10000.00;SUKSES\n" +
+		";reff2;21000;SUKSES\n" +
+		"trx3;reff3;10000;sukses\n" +
+		"trx9;reff9;10000;SUKSES\n" +
+		"trx1;reff1;10000;SUKSES\n"
+	run, rowErrors, err := service.Reconcile(TestIssuerCode, TestDate, TestFileName, strings.NewReader(file))
+	if assert.Nil(t, err) {
+		assert.Empty(t, rowErrors)
+		assert.Equal(t, TestID, run.ID)
+	}
+
+	// unreadable rows
+	file = "IdTrx;ReffId;Nominal;Status\n" +
+		";;10000;SUKSES\n" +
+		"trx1;reff1;ten;SUKSES\n"
+	_, rowErrors, err = service.Reconcile(TestIssuerCode, TestDate, TestFileName, strings.NewReader(file))
+	if assert.Nil(t, err) && assert.Len(t, rowErrors, 2) {
+		assert.Equal(t, 1, rowErrors[0].Row)
+		assert.Equal(t, 2, rowErrors[1].Row)
+	}
+
+	// mapped column missing from the file
+	_, _, err = service.Reconcile(TestIssuerCode, TestDate, TestFileName, strings.NewReader("IdTrx;ReffId;Status\n"))
+	if assert.NotNil(t, err) {
+		assert.True(t, strings.HasPrefix(err.Error(), reconciliationService.ErrInvalidSettlement))
+	}
+
+	// mongo error
+	orderRepository.On("ListPurchases", filter).Return([]orderPort.OrderRepo{}, errors.New("")).Once()
+	_, _, err = service.Reconcile(TestIssuerCode, TestDate, TestFileName, strings.NewReader("IdTrx;ReffId;Nominal;Status\n"))
+	assert.NotNil(t, err)
+
+	repository.AssertExpectations(t)
+	orderRepository.AssertExpectations(t)
+}
+
+func TestReconcileIssuer(t *testing.T) {
+	issuerRepository := issuerRepo.New()
+	service := reconciliationService.New(reconciliationRepo.New(), issuerRepository, orderRepo.New())
+
+	// issuer not found
+	issuerRepository.On("FindByCode", TestIssuerCode).Return(issuerPort.IssuerRepo{}).Once()
+	_, _, err := service.Reconcile(TestIssuerCode, TestDate, TestFileName, strings.NewReader(""))
+	assert.Equal(t, reconciliationService.ErrIssuerNotFound, err.Error())
+
+	// no mapping
+	issuerRepository.On("FindByCode", TestIssuerCode).Return(issuerPort.IssuerRepo{ID: TestIssuerID, Code: TestIssuerCode}).Once()
+	_, _, err = service.Reconcile(TestIssuerCode, TestDate, TestFileName, strings.NewReader(""))
+	assert.Equal(t, reconciliationService.ErrMappingNotConfigured, err.Error())
+}
+
+func TestListItems(t *testing.T) {
+	repository := reconciliationRepo.New()
+	service := reconciliationService.New(repository, nil, nil)
+
+	// success
+	repository.On("ReadRun", TestID).Return(reconciliationPort.RunRepo{ID: TestID}, nil).Once()
+	repository.On("ListItems", TestID, reconciliationPort.ClassMissingOurs).Return([]reconciliationPort.ItemRepo{{ID: "1", Class: reconciliationPort.ClassMissingOurs, TransactionId: "trx9"}}, nil).Once()
+	items, err := service.ListItems(TestID, reconciliationPort.ClassMissingOurs)
+	if assert.Nil(t, err) && assert.Len(t, items, 1) {
+		assert.Equal(t, "trx9", items[0].TransactionId)
+	}
+
+	// run not found
+	repository.On("ReadRun", TestID).Return(reconciliationPort.RunRepo{}, errors.New(reconciliationService.ErrReconciliationNotFound)).Once()
+	_, err = service.ListItems(TestID, "")
+	assert.Equal(t, reconciliationService.ErrReconciliationNotFound, err.Error())
+}
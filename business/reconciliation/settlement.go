@@ -0,0 +1,127 @@
+package reconciliation
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+)
+
+type (
+	// settlementRow issuer side of a purchase
+	settlementRow struct {
+		Row                 int
+		TransactionId       string
+		IssuerTransactionId string
+		Amount              int64
+		Status              string
+	}
+)
+
+var (
+	ErrSettlementEmpty         = "file is empty"
+	ErrSettlementMissingColumn = "no %s column"
+	ErrSettlementInvalidAmount = "invalid amount value"
+	ErrSettlementMissingId     = "transaction id is required"
+)
+
+// parseSettlement read the settlement file with the column mapping of the issuer
+func parseSettlement(r io.Reader, mapping issuerPort.SettlementMapping) ([]settlementRow, []reconciliationPort.RowError, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	if mapping.Delimiter != "" {
+		reader.Comma, _ = utf8.DecodeRuneInString(mapping.Delimiter)
+	}
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil, errors.New(ErrSettlementEmpty)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.ToLower(name))] = i
+	}
+	index := func(name string) (int, error) {
+		if name == "" {
+			return -1, nil
+		}
+		i, found := columns[strings.TrimSpace(strings.ToLower(name))]
+		if !found {
+			return -1, fmt.Errorf(ErrSettlementMissingColumn, name)
+		}
+		return i, nil
+	}
+	var trxCol, issuerTrxCol, amountCol, statusCol int
+	for _, col := range []struct {
+		name  string
+		index *int
+	}{
+		{mapping.TransactionId, &trxCol},
+		{mapping.IssuerTransactionId, &issuerTrxCol},
+		{mapping.Amount, &amountCol},
+		{mapping.Status, &statusCol},
+	} {
+		if *col.index, err = index(col.name); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	success := make(map[string]bool, len(mapping.SuccessStatus))
+	for _, status := range mapping.SuccessStatus {
+		success[strings.TrimSpace(strings.ToLower(status))] = true
+	}
+	field := func(record []string, i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	rows := make([]settlementRow, 0)
+	rowErrors := make([]reconciliationPort.RowError, 0)
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		data := settlementRow{
+			Row:                 row,
+			TransactionId:       field(record, trxCol),
+			IssuerTransactionId: field(record, issuerTrxCol),
+		}
+		if data.TransactionId == "" && data.IssuerTransactionId == "" {
+			rowErrors = append(rowErrors, reconciliationPort.RowError{Row: row, Message: ErrSettlementMissingId})
+			continue
+		}
+		if amountCol >= 0 {
+			// amounts may come with decimals, prices are whole rupiah
+			amount, err := strconv.ParseFloat(strings.ReplaceAll(field(record, amountCol), ",", ""), 64)
+			if err != nil {
+				rowErrors = append(rowErrors, reconciliationPort.RowError{Row: row, Message: ErrSettlementInvalidAmount})
+				continue
+			}
+			data.Amount = int64(math.Round(amount))
+		}
+		if statusCol >= 0 {
+			data.Status = reconciliationPort.StatusFailed
+			if success[strings.ToLower(field(record, statusCol))] {
+				data.Status = reconciliationPort.StatusSuccess
+			}
+		}
+		rows = append(rows, data)
+	}
+	return rows, rowErrors, nil
+}
@@ -0,0 +1,45 @@
+package mock
+
+import (
+	routePort "github.com/sepulsa/teleco/business/route/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type service struct {
+	mock.Mock
+}
+
+func New() *service {
+	return &service{}
+}
+
+func (s *service) CreateData(route routePort.RouteService) error {
+	result := s.Called(route)
+	return result.Error(0)
+}
+
+func (s *service) ReadData(ID string) (routePort.RouteService, error) {
+	result := s.Called(ID)
+	return result.Get(0).(routePort.RouteService), result.Error(1)
+}
+
+func (s *service) UpdateData(route routePort.RouteService) error {
+	result := s.Called(route)
+	return result.Error(0)
+}
+
+func (s *service) DeleteData(ID string) error {
+	result := s.Called(ID)
+	return result.Error(0)
+}
+
+func (s *service) ListData() ([]routePort.RouteService, error) {
+	result := s.Called()
+	return result.Get(0).([]routePort.RouteService), result.Error(1)
+}
+
+func (s *service) Plan(productCode string) (routePort.RouteService, error) {
+	result := s.Called(productCode)
+	return result.Get(0).(routePort.RouteService), result.Error(1)
+}
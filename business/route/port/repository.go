@@ -0,0 +1,40 @@
+package port
+
+type (
+	RouteRepo struct {
+		ID               string      `json:"id"`
+		ProductCode      string      `json:"product_code"`
+		Candidates       []Candidate `json:"candidates"`
+		FailoverRescodes []string    `json:"failover_rescodes"`
+	}
+
+	// Candidate issuer serving the product, lower priority is tried first and candidates
+	// sharing a priority are shuffled by weight
+	Candidate struct {
+		IssuerCode      string `json:"issuer_code"`
+		IssuerProductId string `json:"issuer_product_id"`
+		Priority        int    `json:"priority"`
+		Weight          int    `json:"weight"`
+	}
+)
+
+// Repository is outbound port
+type Repository interface {
+	//FindByProductCode find route by teleco product code
+	FindByProductCode(productCode string) RouteRepo
+
+	//CreateData insert new data
+	CreateData(route RouteRepo) error
+
+	//ReadData get data by ID
+	ReadData(ID string) (RouteRepo, error)
+
+	//UpdateData update new data
+	UpdateData(route RouteRepo) error
+
+	//DeleteData delete data
+	DeleteData(ID string) error
+
+	//ListData get list data
+	ListData() ([]RouteRepo, error)
+}
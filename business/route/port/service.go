@@ -0,0 +1,31 @@
+package port
+
+type (
+	RouteService struct {
+		ID               string      `json:"id"`
+		ProductCode      string      `json:"product_code"`
+		Candidates       []Candidate `json:"candidates"`
+		FailoverRescodes []string    `json:"failover_rescodes"`
+	}
+)
+
+// Service is inbound port
+type Service interface {
+	// CreateData insert new data
+	CreateData(route RouteService) error
+
+	// ReadData get data by ID
+	ReadData(ID string) (RouteService, error)
+
+	// UpdateData update new data
+	UpdateData(route RouteService) error
+
+	// DeleteData delete data
+	DeleteData(ID string) error
+
+	// ListData get list data
+	ListData() ([]RouteService, error)
+
+	// Plan get the route of a product with its candidates in the order they must be tried
+	Plan(productCode string) (RouteService, error)
+}
@@ -0,0 +1,172 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"math/rand"
+	"sort"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	routePort "github.com/sepulsa/teleco/business/route/port"
+)
+
+type (
+	service struct {
+		routeRepository routePort.Repository
+	}
+)
+
+var (
+	ErrDuplicateProductCode = "Product code already in use"
+	ErrRouteNotFound        = "Route not found"
+
+	// DefaultFailoverRescodes used when a route has none, an open circuit always fails over
+	DefaultFailoverRescodes = []string{orderPort.RescodeCircuitOpen}
+)
+
+func New(routeRepository routePort.Repository) routePort.Service {
+	return &service{
+		routeRepository,
+	}
+}
+
+func (s *service) CreateData(route routePort.RouteService) error {
+	existingRoute := s.routeRepository.FindByProductCode(route.ProductCode)
+	if existingRoute.ID != "" {
+		return errors.New(ErrDuplicateProductCode)
+	}
+
+	data := routePort.RouteRepo{
+		ProductCode:      route.ProductCode,
+		Candidates:       route.Candidates,
+		FailoverRescodes: route.FailoverRescodes,
+	}
+	return s.routeRepository.CreateData(data)
+}
+
+func (s *service) ReadData(ID string) (route routePort.RouteService, err error) {
+	data, err := s.routeRepository.ReadData(ID)
+	if err != nil {
+		return
+	}
+	route = routePort.RouteService{
+		ID:               data.ID,
+		ProductCode:      data.ProductCode,
+		Candidates:       data.Candidates,
+		FailoverRescodes: data.FailoverRescodes,
+	}
+	return
+}
+
+func (s *service) UpdateData(route routePort.RouteService) error {
+	existingData, err := s.routeRepository.ReadData(route.ID)
+	if err != nil {
+		return err
+	}
+	if existingData.ProductCode != route.ProductCode {
+		existingRoute := s.routeRepository.FindByProductCode(route.ProductCode)
+		if existingRoute.ID != "" {
+			return errors.New(ErrDuplicateProductCode)
+		}
+	}
+	data := routePort.RouteRepo{
+		ID:               route.ID,
+		ProductCode:      route.ProductCode,
+		Candidates:       route.Candidates,
+		FailoverRescodes: route.FailoverRescodes,
+	}
+	return s.routeRepository.UpdateData(data)
+}
+
+func (s *service) DeleteData(ID string) error {
+	return s.routeRepository.DeleteData(ID)
+}
+
+func (s *service) ListData() (routes []routePort.RouteService, err error) {
+	datas, err := s.routeRepository.ListData()
+	if err != nil {
+		return
+	}
+	if len(datas) > 0 {
+		d, _ := json.Marshal(datas)
+		json.Unmarshal(d, &routes)
+	}
+
+	return
+}
+
+func (s *service) Plan(productCode string) (route routePort.RouteService, err error) {
+	data := s.routeRepository.FindByProductCode(productCode)
+	if data.ID == "" {
+		err = errors.New(ErrRouteNotFound)
+		return
+	}
+	route = routePort.RouteService{
+		ID:               data.ID,
+		ProductCode:      data.ProductCode,
+		Candidates:       order(data.Candidates),
+		FailoverRescodes: failoverRescodes(data.FailoverRescodes),
+	}
+	return
+}
+
+// failoverRescodes rescodes of the route plus the default ones missing from it, a pending order is never
+// failed over since the issuer may still complete it
+func failoverRescodes(rescodes []string) []string {
+	var codes []string
+	for _, code := range append(append([]string{}, rescodes...), DefaultFailoverRescodes...) {
+		if code == orderPort.RescodePending || contains(codes, code) {
+			continue
+		}
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+func contains(codes []string, code string) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// order sort candidates by priority and shuffle each priority by weight, a weight below one counts as one
+func order(candidates []routePort.Candidate) []routePort.Candidate {
+	sorted := make([]routePort.Candidate, len(candidates))
+	copy(sorted, candidates)
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Priority < sorted[b].Priority
+	})
+
+	for start := 0; start < len(sorted); {
+		end := start
+		for end < len(sorted) && sorted[end].Priority == sorted[start].Priority {
+			end++
+		}
+		// weighted pick without replacement for every position of the group
+		for i := start; i < end-1; i++ {
+			total := 0
+			for _, candidate := range sorted[i:end] {
+				total += weight(candidate)
+			}
+			pick := rand.Intn(total)
+			for j := i; j < end; j++ {
+				if pick -= weight(sorted[j]); pick < 0 {
+					sorted[i], sorted[j] = sorted[j], sorted[i]
+					break
+				}
+			}
+		}
+		start = end
+	}
+	return sorted
+}
+
+func weight(candidate routePort.Candidate) int {
+	if candidate.Weight < 1 {
+		return 1
+	}
+	return candidate.Weight
+}
@@ -0,0 +1,146 @@
+package route_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	routeService "github.com/sepulsa/teleco/business/route"
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	routeRepo "github.com/sepulsa/teleco/modules/repository/mock/route"
+)
+
+var (
+	TestID          = "6138813fb95630b0b528b160"
+	TestProductCode = "TSEL10"
+	TestCandidates  = []routePort.Candidate{
+		{IssuerCode: "backup", IssuerProductId: "B10", Priority: 2},
+		{IssuerCode: "primary1", IssuerProductId: "P10", Priority: 1, Weight: 3},
+		{IssuerCode: "primary2", IssuerProductId: "Q10", Priority: 1, Weight: 1},
+	}
+
+	TestErrInvalidID = "Invalid ID"
+)
+
+func TestCreateData(t *testing.T) {
+	repository := routeRepo.New()
+
+	dataService := routePort.RouteService{
+		ProductCode: TestProductCode,
+		Candidates:  TestCandidates,
+	}
+
+	// success
+	repository.On("FindByProductCode", TestProductCode).Return(routePort.RouteRepo{}).Once()
+	repository.On("CreateData", mock.Anything).Return(nil).Once()
+	service := routeService.New(repository)
+	assert.Nil(t, service.CreateData(dataService))
+
+	// duplicate product code
+	repository.On("FindByProductCode", TestProductCode).Return(routePort.RouteRepo{ID: TestID}).Once()
+	err := service.CreateData(dataService)
+	assert.Equal(t, routeService.ErrDuplicateProductCode, err.Error())
+}
+
+func TestReadData(t *testing.T) {
+	repository := routeRepo.New()
+
+	dataRepo := routePort.RouteRepo{
+		ID:          TestID,
+		ProductCode: TestProductCode,
+		Candidates:  TestCandidates,
+	}
+
+	// success
+	repository.On("ReadData", TestID).Return(dataRepo, nil).Once()
+	service := routeService.New(repository)
+	route, err := service.ReadData(TestID)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestProductCode, route.ProductCode)
+		assert.Equal(t, TestCandidates, route.Candidates)
+	}
+
+	// error
+	repository.On("ReadData", TestID).Return(routePort.RouteRepo{}, errors.New(TestErrInvalidID)).Once()
+	_, err = service.ReadData(TestID)
+	assert.Equal(t, TestErrInvalidID, err.Error())
+}
+
+func TestUpdateData(t *testing.T) {
+	repository := routeRepo.New()
+
+	dataService := routePort.RouteService{
+		ID:          TestID,
+		ProductCode: TestProductCode + "X",
+		Candidates:  TestCandidates,
+	}
+
+	// success
+	repository.On("ReadData", TestID).Return(routePort.RouteRepo{ID: TestID, ProductCode: TestProductCode}, nil).Once()
+	repository.On("FindByProductCode", TestProductCode+"X").Return(routePort.RouteRepo{}).Once()
+	repository.On("UpdateData", mock.Anything).Return(nil).Once()
+	service := routeService.New(repository)
+	assert.Nil(t, service.UpdateData(dataService))
+
+	// duplicate product code
+	repository.On("ReadData", TestID).Return(routePort.RouteRepo{ID: TestID, ProductCode: TestProductCode}, nil).Once()
+	repository.On("FindByProductCode", TestProductCode+"X").Return(routePort.RouteRepo{ID: "other"}).Once()
+	err := service.UpdateData(dataService)
+	assert.Equal(t, routeService.ErrDuplicateProductCode, err.Error())
+}
+
+func TestDeleteData(t *testing.T) {
+	repository := routeRepo.New()
+
+	repository.On("DeleteData", TestID).Return(nil).Once()
+	service := routeService.New(repository)
+	assert.Nil(t, service.DeleteData(TestID))
+}
+
+func TestListData(t *testing.T) {
+	repository := routeRepo.New()
+
+	repository.On("ListData").Return([]routePort.RouteRepo{{ID: TestID, ProductCode: TestProductCode, Candidates: TestCandidates}}, nil).Once()
+	service := routeService.New(repository)
+	routes, err := service.ListData()
+	if assert.Nil(t, err) && assert.Len(t, routes, 1) {
+		assert.Equal(t, TestProductCode, routes[0].ProductCode)
+		assert.Len(t, routes[0].Candidates, 3)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	repository := routeRepo.New()
+
+	repository.On("FindByProductCode", "unknown").Return(routePort.RouteRepo{}).Once()
+	repository.On("FindByProductCode", TestProductCode).Return(routePort.RouteRepo{ID: TestID, ProductCode: TestProductCode, Candidates: TestCandidates})
+	service := routeService.New(repository)
+
+	// error route not found
+	_, err := service.Plan("unknown")
+	assert.Equal(t, routeService.ErrRouteNotFound, err.Error())
+
+	// priority first, weighted within a priority
+	firsts := make(map[string]int)
+	for i := 0; i < 400; i++ {
+		route, err := service.Plan(TestProductCode)
+		if !assert.Nil(t, err) {
+			return
+		}
+		assert.Equal(t, routeService.DefaultFailoverRescodes, route.FailoverRescodes)
+		assert.Equal(t, "backup", route.Candidates[2].IssuerCode)
+		firsts[route.Candidates[0].IssuerCode]++
+	}
+	assert.True(t, firsts["primary1"] > firsts["primary2"])
+	assert.True(t, firsts["primary2"] > 0)
+
+	// open circuit always fails over, pending never does
+	repository.On("FindByProductCode", "TSEL5").Return(routePort.RouteRepo{ID: TestID, ProductCode: "TSEL5", FailoverRescodes: []string{"06", orderPort.RescodePending}}).Once()
+	route, err := service.Plan("TSEL5")
+	if assert.Nil(t, err) {
+		assert.Equal(t, []string{"06", orderPort.RescodeCircuitOpen}, route.FailoverRescodes)
+	}
+}
@@ -0,0 +1,38 @@
+package mock
+
+import (
+	"io"
+	"time"
+
+	statementPort "github.com/sepulsa/teleco/business/statement/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type service struct {
+	mock.Mock
+}
+
+func New() *service {
+	return &service{}
+}
+
+func (s *service) Generate(partnerCode string, from time.Time, until time.Time) (statementPort.Statement, error) {
+	result := s.Called(partnerCode, from, until)
+	return result.Get(0).(statementPort.Statement), result.Error(1)
+}
+
+func (s *service) WriteCSV(w io.Writer, statement statementPort.Statement) error {
+	result := s.Called(w, statement)
+	return result.Error(0)
+}
+
+func (s *service) SignLink(partnerCode string, from time.Time, until time.Time, format string) (statementPort.Link, error) {
+	result := s.Called(partnerCode, from, until, format)
+	return result.Get(0).(statementPort.Link), result.Error(1)
+}
+
+func (s *service) VerifyLink(params statementPort.LinkParams) error {
+	result := s.Called(params)
+	return result.Error(0)
+}
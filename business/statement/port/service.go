@@ -0,0 +1,84 @@
+package port
+
+import (
+	"io"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	// StatusPending purchase result not known yet
+	StatusPending = "pending"
+)
+
+type (
+	// Statement purchases of a partner made between From, inclusive, and Until, exclusive
+	Statement struct {
+		PartnerCode string           `json:"partner_code"`
+		From        time.Time        `json:"from"`
+		Until       time.Time        `json:"until"`
+		Lines       []StatementLine  `json:"lines"`
+		Totals      []StatementTotal `json:"totals"`
+		Count       int              `json:"count"`
+		Amount      int64            `json:"amount"`
+		GeneratedAt time.Time        `json:"generated_at"`
+	}
+
+	// StatementLine one purchase with its final status, amount is only charged on success
+	StatementLine struct {
+		TransactionId       string    `json:"transaction_id"`
+		IssuerTransactionId string    `json:"issuer_transaction_id"`
+		ProductCode         string    `json:"product_code"`
+		IssuerProductId     string    `json:"issuer_product_id"`
+		CustomerNumber      string    `json:"customer_number"`
+		SerialNumber        string    `json:"serial_number"`
+		Status              string    `json:"status"`
+		SellingPrice        int64     `json:"selling_price"`
+		Fee                 int64     `json:"fee"`
+		Amount              int64     `json:"amount"`
+		CreatedAt           time.Time `json:"created_at"`
+	}
+
+	// StatementTotal purchases of a product with the same status
+	StatementTotal struct {
+		ProductCode string `json:"product_code"`
+		Status      string `json:"status"`
+		Count       int    `json:"count"`
+		Amount      int64  `json:"amount"`
+	}
+
+	// Link signed download link of a statement
+	Link struct {
+		Url       string    `json:"url"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	// LinkParams statement and signature read from a download link
+	LinkParams struct {
+		PartnerCode string
+		From        time.Time
+		Until       time.Time
+		Format      string
+		Expires     int64
+		Signature   string
+	}
+)
+
+// Service is inbound port
+type Service interface {
+	// Generate statement of a partner between from, inclusive, and until, exclusive
+	Generate(partnerCode string, from time.Time, until time.Time) (Statement, error)
+
+	// WriteCSV write the lines then the totals of the statement
+	WriteCSV(w io.Writer, statement Statement) error
+
+	// SignLink download link of a statement, signed with the partner secret key
+	SignLink(partnerCode string, from time.Time, until time.Time, format string) (Link, error)
+
+	// VerifyLink check the link is not expired and was signed for these params
+	VerifyLink(params LinkParams) error
+}
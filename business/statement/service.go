@@ -0,0 +1,254 @@
+package statement
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	statementPort "github.com/sepulsa/teleco/business/statement/port"
+)
+
+type (
+	service struct {
+		orderRepository   orderPort.Repository
+		partnerRepository partnerPort.Repository
+		// downloadUrl external API url of the download, the signed query is appended to it
+		downloadUrl string
+		// linkExpired validity of a download link
+		linkExpired time.Duration
+	}
+)
+
+var (
+	ErrPartnerNotFound  = "Partner not found"
+	ErrInvalidRange     = "until must not be before from"
+	ErrInvalidFormat    = "format must be one of csv json"
+	ErrLinkExpired      = "Download link expired"
+	ErrInvalidSignature = "Invalid download link signature"
+
+	// DateLayout of the from and until dates of a download link, until is inclusive in the link
+	DateLayout = "2006-01-02"
+)
+
+func New(orderRepository orderPort.Repository, partnerRepository partnerPort.Repository, downloadUrl string, linkExpired time.Duration) statementPort.Service {
+	return &service{
+		orderRepository,
+		partnerRepository,
+		downloadUrl,
+		linkExpired,
+	}
+}
+
+func (s *service) Generate(partnerCode string, from time.Time, until time.Time) (statement statementPort.Statement, err error) {
+	partner := s.partnerRepository.FindByCode(partnerCode)
+	if partner.ID == "" {
+		err = errors.New(ErrPartnerNotFound)
+		return
+	}
+	if !until.After(from) {
+		err = errors.New(ErrInvalidRange)
+		return
+	}
+	filter := orderPort.PurchaseFilter{
+		PartnerId: partner.ID,
+		From:      from,
+		Until:     until,
+	}
+	orders, err := s.orderRepository.ListPurchases(filter)
+	if err != nil {
+		return
+	}
+
+	statement = statementPort.Statement{
+		PartnerCode: partner.Code,
+		From:        from,
+		Until:       until,
+		Lines:       collectLines(orders),
+		GeneratedAt: time.Now(),
+	}
+	totals := make(map[statementPort.StatementTotal]*statementPort.StatementTotal)
+	for _, line := range statement.Lines {
+		key := statementPort.StatementTotal{ProductCode: line.ProductCode, Status: line.Status}
+		total, found := totals[key]
+		if !found {
+			total = &statementPort.StatementTotal{ProductCode: line.ProductCode, Status: line.Status}
+			totals[key] = total
+		}
+		total.Count++
+		total.Amount += line.Amount
+		statement.Count++
+		statement.Amount += line.Amount
+	}
+	statement.Totals = make([]statementPort.StatementTotal, 0, len(totals))
+	for _, total := range totals {
+		statement.Totals = append(statement.Totals, *total)
+	}
+	sort.Slice(statement.Totals, func(i, j int) bool {
+		if statement.Totals[i].ProductCode != statement.Totals[j].ProductCode {
+			return statement.Totals[i].ProductCode < statement.Totals[j].ProductCode
+		}
+		return statement.Totals[i].Status < statement.Totals[j].Status
+	})
+	return
+}
+
+// collectLines one line per transaction id, oldest first, a failed over purchase ends with the record
+// of the issuer that answered last
+func collectLines(orders []orderPort.OrderRepo) []statementPort.StatementLine {
+	lines := make([]*statementPort.StatementLine, 0)
+	byTrx := make(map[string]*statementPort.StatementLine)
+	for _, order := range orders {
+		line, found := byTrx[order.TransactionId]
+		if !found {
+			line = &statementPort.StatementLine{
+				TransactionId:  order.TransactionId,
+				CustomerNumber: order.CustomerNumber,
+				Status:         statementPort.StatusPending,
+				CreatedAt:      order.CreatedAt,
+			}
+			lines = append(lines, line)
+			byTrx[order.TransactionId] = line
+		}
+		if order.ProductCode != "" {
+			line.ProductCode = order.ProductCode
+		}
+		if order.IssuerProductId != "" {
+			line.IssuerProductId = order.IssuerProductId
+		}
+		if order.IssuerTransactionId != "" {
+			line.IssuerTransactionId = order.IssuerTransactionId
+		}
+		if order.SerialNumber != "" {
+			line.SerialNumber = order.SerialNumber
+		}
+		if line.SellingPrice == 0 {
+			line.SellingPrice = order.Price.SellingPrice
+			line.Fee = order.Price.Fee
+		}
+		switch order.IssuerRescode {
+		case orderPort.RescodeSuccess:
+			line.Status = statementPort.StatusSuccess
+		case orderPort.RescodePending, "":
+		default:
+			line.Status = statementPort.StatusFailed
+		}
+	}
+
+	result := make([]statementPort.StatementLine, 0, len(lines))
+	for _, line := range lines {
+		if line.ProductCode == "" {
+			line.ProductCode = line.IssuerProductId
+		}
+		if line.Status == statementPort.StatusSuccess {
+			line.Amount = line.SellingPrice + line.Fee
+		}
+		result = append(result, *line)
+	}
+	return result
+}
+
+func (s *service) WriteCSV(w io.Writer, statement statementPort.Statement) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"transaction_id", "issuer_transaction_id", "product_code", "issuer_product_id", "customer_number", "serial_number", "status", "selling_price", "fee", "amount", "created_at"})
+	for _, line := range statement.Lines {
+		writer.Write([]string{
+			line.TransactionId,
+			line.IssuerTransactionId,
+			line.ProductCode,
+			line.IssuerProductId,
+			line.CustomerNumber,
+			line.SerialNumber,
+			line.Status,
+			strconv.FormatInt(line.SellingPrice, 10),
+			strconv.FormatInt(line.Fee, 10),
+			strconv.FormatInt(line.Amount, 10),
+			line.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	writer.Write(nil)
+	writer.Write([]string{"product_code", "status", "count", "amount"})
+	for _, total := range statement.Totals {
+		writer.Write([]string{total.ProductCode, total.Status, strconv.Itoa(total.Count), strconv.FormatInt(total.Amount, 10)})
+	}
+	writer.Write([]string{"total", "", strconv.Itoa(statement.Count), strconv.FormatInt(statement.Amount, 10)})
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (s *service) SignLink(partnerCode string, from time.Time, until time.Time, format string) (link statementPort.Link, err error) {
+	partner := s.partnerRepository.FindByCode(partnerCode)
+	if partner.ID == "" {
+		err = errors.New(ErrPartnerNotFound)
+		return
+	}
+	if !until.After(from) {
+		err = errors.New(ErrInvalidRange)
+		return
+	}
+	if format != statementPort.FormatCSV && format != statementPort.FormatJSON {
+		err = errors.New(ErrInvalidFormat)
+		return
+	}
+
+	link.ExpiresAt = time.Now().Add(s.linkExpired)
+	params := statementPort.LinkParams{
+		PartnerCode: partner.Code,
+		From:        from,
+		Until:       until,
+		Format:      format,
+		Expires:     link.ExpiresAt.Unix(),
+	}
+	query := url.Values{}
+	query.Set("partner_code", params.PartnerCode)
+	query.Set("from", from.Format(DateLayout))
+	query.Set("until", until.AddDate(0, 0, -1).Format(DateLayout))
+	query.Set("format", format)
+	query.Set("expires", strconv.FormatInt(params.Expires, 10))
+	query.Set("signature", sign(partner.SecretKey, params))
+	separator := "?"
+	if strings.Contains(s.downloadUrl, "?") {
+		separator = "&"
+	}
+	link.Url = s.downloadUrl + separator + query.Encode()
+	return
+}
+
+func (s *service) VerifyLink(params statementPort.LinkParams) error {
+	if time.Now().Unix() > params.Expires {
+		return errors.New(ErrLinkExpired)
+	}
+	partner := s.partnerRepository.FindByCode(params.PartnerCode)
+	if strings.TrimSpace(partner.SecretKey) == "" {
+		return errors.New(ErrInvalidSignature)
+	}
+	if !hmac.Equal([]byte(params.Signature), []byte(sign(partner.SecretKey, params))) {
+		return errors.New(ErrInvalidSignature)
+	}
+	return nil
+}
+
+// sign hmac of the link params, rotating the partner secret key revokes its links
+func sign(secret string, params statementPort.LinkParams) string {
+	payload := strings.Join([]string{
+		params.PartnerCode,
+		params.From.Format(DateLayout),
+		params.Until.Format(DateLayout),
+		params.Format,
+		strconv.FormatInt(params.Expires, 10),
+	}, ":")
+	digest := hmac.New(sha256.New, []byte(secret))
+	digest.Write([]byte(payload))
+	return hex.EncodeToString(digest.Sum(nil))
+}
@@ -0,0 +1,134 @@
+package statement_test
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	statementService "github.com/sepulsa/teleco/business/statement"
+	statementPort "github.com/sepulsa/teleco/business/statement/port"
+	orderRepo "github.com/sepulsa/teleco/modules/repository/mock/order"
+	partnerRepo "github.com/sepulsa/teleco/modules/repository/mock/partner"
+)
+
+var (
+	TestPartnerID   = "6138813fb95630b0b528b160"
+	TestPartnerCode = "partner001"
+	TestSecretKey   = "secret"
+	TestFrom        = time.Date(2021, 9, 1, 0, 0, 0, 0, time.Local)
+	TestUntil       = time.Date(2021, 10, 1, 0, 0, 0, 0, time.Local)
+	TestPartner     = partnerPort.PartnerRepo{ID: TestPartnerID, Code: TestPartnerCode, SecretKey: TestSecretKey}
+	TestDownloadUrl = "/api/v1/statement/download"
+	TestLinkExpired = 24 * time.Hour
+)
+
+func TestGenerate(t *testing.T) {
+	orderRepository := orderRepo.New()
+	partnerRepository := partnerRepo.New()
+	service := statementService.New(orderRepository, partnerRepository, TestDownloadUrl, TestLinkExpired)
+
+	price := orderPort.OrderPrice{SellingPrice: 10500, Fee: 500}
+	orders := []orderPort.OrderRepo{
+		{TransactionId: "trx1", ProductCode: "TSEL10", IssuerTransactionId: "reff1", SerialNumber: "sn1", IssuerRescode: "00", Price: price},
+		// failed over to a second issuer
+		{TransactionId: "trx2", ProductCode: "TSEL10", IssuerRescode: "68", Price: price},
+		{TransactionId: "trx2", ProductCode: "TSEL10", IssuerTransactionId: "reff2", SerialNumber: "sn2", IssuerRescode: "00", Price: price},
+		// pending then failed through the callback
+		{TransactionId: "trx3", ProductCode: "TSEL10", IssuerRescode: "10", Price: price},
+		{TransactionId: "trx3", IssuerRescode: "05"},
+		// purchase by issuer product id
+		{TransactionId: "trx4", IssuerProductId: "S10", IssuerRescode: "10"},
+	}
+	partnerRepository.On("FindByCode", TestPartnerCode).Return(TestPartner)
+	filter := orderPort.PurchaseFilter{PartnerId: TestPartnerID, From: TestFrom, Until: TestUntil}
+	orderRepository.On("ListPurchases", filter).Return(orders, nil).Once()
+	statement, err := service.Generate(TestPartnerCode, TestFrom, TestUntil)
+	if assert.Nil(t, err) && assert.Len(t, statement.Lines, 4) {
+		assert.Equal(t, 4, statement.Count)
+		assert.Equal(t, int64(22000), statement.Amount)
+		assert.Equal(t, "sn2", statement.Lines[1].SerialNumber)
+		assert.Equal(t, statementPort.StatusSuccess, statement.Lines[1].Status)
+		assert.Equal(t, statementPort.StatusFailed, statement.Lines[2].Status)
+		assert.Equal(t, int64(0), statement.Lines[2].Amount)
+		assert.Equal(t, "S10", statement.Lines[3].ProductCode)
+		assert.Equal(t, statementPort.StatusPending, statement.Lines[3].Status)
+		assert.Equal(t, []statementPort.StatementTotal{
+			{ProductCode: "S10", Status: statementPort.StatusPending, Count: 1},
+			{ProductCode: "TSEL10", Status: statementPort.StatusFailed, Count: 1},
+			{ProductCode: "TSEL10", Status: statementPort.StatusSuccess, Count: 2, Amount: 22000},
+		}, statement.Totals)
+
+		var csv bytes.Buffer
+		if assert.Nil(t, service.WriteCSV(&csv, statement)) {
+			rows := strings.Split(strings.TrimSpace(csv.String()), "\n")
+			assert.Len(t, rows, 11)
+			assert.True(t, strings.HasPrefix(rows[1], "trx1,reff1,TSEL10,,,sn1,success,10500,500,11000,"))
+			assert.Equal(t, "total,,4,22000", rows[10])
+		}
+	}
+
+	// invalid range
+	_, err = service.Generate(TestPartnerCode, TestUntil, TestFrom)
+	assert.Equal(t, statementService.ErrInvalidRange, err.Error())
+
+	// mongo error
+	orderRepository.On("ListPurchases", filter).Return([]orderPort.OrderRepo{}, errors.New("")).Once()
+	_, err = service.Generate(TestPartnerCode, TestFrom, TestUntil)
+	assert.NotNil(t, err)
+
+	// partner not found
+	partnerRepository.On("FindByCode", "other").Return(partnerPort.PartnerRepo{}).Once()
+	_, err = service.Generate("other", TestFrom, TestUntil)
+	assert.Equal(t, statementService.ErrPartnerNotFound, err.Error())
+}
+
+func TestLink(t *testing.T) {
+	partnerRepository := partnerRepo.New()
+	service := statementService.New(orderRepo.New(), partnerRepository, TestDownloadUrl, TestLinkExpired)
+	partnerRepository.On("FindByCode", TestPartnerCode).Return(TestPartner)
+
+	link, err := service.SignLink(TestPartnerCode, TestFrom, TestUntil, statementPort.FormatCSV)
+	if !assert.Nil(t, err) {
+		return
+	}
+	linkUrl, err := url.Parse(link.Url)
+	if !assert.Nil(t, err) {
+		return
+	}
+	query := linkUrl.Query()
+	assert.Equal(t, "2021-09-01", query.Get("from"))
+	assert.Equal(t, "2021-09-30", query.Get("until"))
+	expires, _ := strconv.ParseInt(query.Get("expires"), 10, 64)
+	assert.Equal(t, link.ExpiresAt.Unix(), expires)
+	params := statementPort.LinkParams{
+		PartnerCode: TestPartnerCode,
+		From:        TestFrom,
+		Until:       TestUntil,
+		Format:      statementPort.FormatCSV,
+		Expires:     expires,
+		Signature:   query.Get("signature"),
+	}
+	assert.Nil(t, service.VerifyLink(params))
+
+	// other range
+	tampered := params
+	tampered.Until = TestUntil.AddDate(0, 1, 0)
+	assert.Equal(t, statementService.ErrInvalidSignature, service.VerifyLink(tampered).Error())
+
+	// expired
+	expired := params
+	expired.Expires = time.Now().Add(-time.Minute).Unix()
+	assert.Equal(t, statementService.ErrLinkExpired, service.VerifyLink(expired).Error())
+
+	// invalid format
+	_, err = service.SignLink(TestPartnerCode, TestFrom, TestUntil, "xlsx")
+	assert.Equal(t, statementService.ErrInvalidFormat, err.Error())
+}
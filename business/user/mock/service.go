@@ -2,6 +2,7 @@ package mock
 
 import (
 	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -38,3 +39,28 @@ func (s *service) ListData() ([]userPort.UserService, error) {
 	result := s.Called()
 	return result.Get(0).([]userPort.UserService), result.Error(1)
 }
+
+func (s *service) SearchData(listQuery query.Query) ([]userPort.UserService, query.Page, error) {
+	result := s.Called(listQuery)
+	return result.Get(0).([]userPort.UserService), result.Get(1).(query.Page), result.Error(2)
+}
+
+func (s *service) RevokeSessions(ID string) error {
+	result := s.Called(ID)
+	return result.Error(0)
+}
+
+func (s *service) ChangePassword(tokenID string, oldPassword string, newPassword string) error {
+	result := s.Called(tokenID, oldPassword, newPassword)
+	return result.Error(0)
+}
+
+func (s *service) ResetPassword(ID string) error {
+	result := s.Called(ID)
+	return result.Error(0)
+}
+
+func (s *service) ConfirmResetPassword(token string, newPassword string) error {
+	result := s.Called(token, newPassword)
+	return result.Error(0)
+}
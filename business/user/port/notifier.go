@@ -0,0 +1,17 @@
+package port
+
+import "time"
+
+// PasswordReset one-time token sent to the user, never returned to the admin requesting it
+type PasswordReset struct {
+	Email     string    `json:"email"`
+	Fullname  string    `json:"fullname"`
+	Token     string    `json:"token"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+// Notifier is outbound port
+type Notifier interface {
+	//NotifyPasswordReset deliver the reset token to the user mailbox
+	NotifyPasswordReset(reset PasswordReset) error
+}
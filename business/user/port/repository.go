@@ -1,11 +1,21 @@
 package port
 
+import (
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+)
+
 type (
 	UserRepo struct {
-		ID       string `json:"id"`
-		Email    string `json:"email"`
-		Fullname string `json:"fullname"`
-		Password string `json:"password"`
+		ID                  string    `json:"id"`
+		Email               string    `json:"email"`
+		Fullname            string    `json:"fullname"`
+		Password            string    `json:"password"`
+		PasswordHistory     []string  `json:"password_history"`
+		ResetTokenExpiredAt time.Time `json:"reset_token_expired_at"`
+		// Version incremented by every UpdateData, an update of another version is refused
+		Version int `json:"version"`
 	}
 )
 
@@ -14,11 +24,20 @@ type Repository interface {
 	// FindByEmail find issuer by code
 	FindByEmail(email string) UserRepo
 
+	// FindByResetToken find user owning an unexpired password reset token
+	FindByResetToken(tokenHash string) UserRepo
+
 	// CreateData insert new data
 	CreateData(user UserRepo) error
 
+	// UpdateData update data, a new password also replaces the password
+	// history and invalidates any pending reset token, a conflict error when
+	// the stored version is not user.Version
 	UpdateData(user UserRepo) error
 
+	// UpdateResetToken store password reset token hash
+	UpdateResetToken(ID string, tokenHash string, expiredAt time.Time) error
+
 	// ReadData get data by ID
 	ReadData(ID string) (UserRepo, error)
 
@@ -27,4 +46,7 @@ type Repository interface {
 
 	// ListData get list data
 	ListData() ([]UserRepo, error)
+
+	//SearchData get a page of users, searched on email and fullname
+	SearchData(listQuery query.Query) ([]UserRepo, query.Page, error)
 }
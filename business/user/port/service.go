@@ -1,11 +1,14 @@
 package port
 
+import "github.com/sepulsa/teleco/utils/query"
+
 type (
 	UserService struct {
 		ID       string `json:"id"`
 		Email    string `json:"email"`
 		Fullname string `json:"fullname"`
 		Password string `json:"password"`
+		Version  int    `json:"version"`
 	}
 )
 
@@ -25,4 +28,19 @@ type Service interface {
 
 	// ListData get list data
 	ListData() ([]UserService, error)
+
+	// SearchData get a page of users, searched on email and fullname
+	SearchData(listQuery query.Query) ([]UserService, query.Page, error)
+
+	// RevokeSessions revoke all active sessions of a user
+	RevokeSessions(ID string) error
+
+	// ChangePassword change password of the user owning tokenID
+	ChangePassword(tokenID string, oldPassword string, newPassword string) error
+
+	// ResetPassword issue one-time password reset token and send it to the user
+	ResetPassword(ID string) error
+
+	// ConfirmResetPassword set new password using a reset token
+	ConfirmResetPassword(token string, newPassword string) error
 }
@@ -3,28 +3,46 @@ package user
 import (
 	"errors"
 	"strings"
+	"time"
 
 	authPort "github.com/sepulsa/teleco/business/auth/port"
 	userPort "github.com/sepulsa/teleco/business/user/port"
 	"github.com/sepulsa/teleco/utils/crypto"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/sepulsa/teleco/utils/validator"
 )
 
 type (
 	service struct {
 		userRepository      userPort.Repository
 		userTokenRepository authPort.Repository
+		notifier            userPort.Notifier
+		passwordPolicy      validator.PasswordPolicy
+		// passwordResetExpired lifetime of a password reset token in minutes
+		passwordResetExpired int
 	}
 )
 
 var (
 	ErrDuplicateEmail       = "Email already in use"
 	ErrUserGeneratePassword = "generate password failed"
+	ErrInvalidToken         = "invalid token"
+	ErrInvalidOldPassword   = "old password is invalid"
+	ErrPasswordReused       = "password has been used recently"
+	ErrInvalidResetToken    = "invalid or expired reset token"
+	ErrSendResetToken       = "password reset mail could not be sent"
+
+	resetTokenSize = 32
 )
 
-func New(userRepository userPort.Repository, userTokenRepository authPort.Repository) userPort.Service {
+func New(userRepository userPort.Repository, userTokenRepository authPort.Repository, notifier userPort.Notifier,
+	passwordPolicy validator.PasswordPolicy, passwordResetExpired int) userPort.Service {
 	return &service{
 		userRepository,
 		userTokenRepository,
+		notifier,
+		passwordPolicy,
+		passwordResetExpired,
 	}
 }
 
@@ -40,7 +58,9 @@ func (s *service) CreateData(user userPort.UserService) error {
 	data.Fullname = user.Fullname
 
 	if strings.TrimSpace(user.Password) != "" {
-		generatePassword(user.Password, &data)
+		if err := s.bindPassword(userPort.UserRepo{}, user.Password, &data); err != nil {
+			return err
+		}
 	}
 
 	return s.userRepository.CreateData(data)
@@ -54,6 +74,7 @@ func (s *service) ReadData(ID string) (userPort.UserService, error) {
 		user.ID = data.ID
 		user.Email = data.Email
 		user.Fullname = data.Fullname
+		user.Version = data.Version
 	}
 	return user, err
 }
@@ -75,12 +96,21 @@ func (s *service) UpdateData(user userPort.UserService) error {
 	data.ID = user.ID
 	data.Email = user.Email
 	data.Fullname = user.Fullname
+	data.Version = user.Version
 
-	if strings.TrimSpace(user.Password) != "" {
-		generatePassword(user.Password, &data)
+	passwordChanged := strings.TrimSpace(user.Password) != ""
+	if passwordChanged {
+		if err := s.bindPassword(existingData, user.Password, &data); err != nil {
+			return err
+		}
 	}
 
-	return s.userRepository.UpdateData(data)
+	err = s.userRepository.UpdateData(data)
+	if err == nil && passwordChanged {
+		// force re-login everywhere with the new password
+		_ = s.userTokenRepository.DeleteDataByUserID(user.ID)
+	}
+	return err
 }
 
 func (s *service) DeleteData(ID string) error {
@@ -91,6 +121,66 @@ func (s *service) DeleteData(ID string) error {
 	return err
 }
 
+func (s *service) RevokeSessions(ID string) error {
+	if _, err := s.userRepository.ReadData(ID); err != nil {
+		return err
+	}
+	return s.userTokenRepository.DeleteDataByUserID(ID)
+}
+
+func (s *service) ChangePassword(tokenID string, oldPassword string, newPassword string) error {
+	userToken := s.userTokenRepository.FindByTokenID(tokenID)
+	if userToken.UserID == "" {
+		return errors.New(ErrInvalidToken)
+	}
+
+	existingData, err := s.userRepository.ReadData(userToken.UserID)
+	if err != nil {
+		return err
+	}
+	if !crypto.UserVerifyPassword(oldPassword, existingData.Password) {
+		return errors.New(ErrInvalidOldPassword)
+	}
+
+	return s.replacePassword(existingData, newPassword)
+}
+
+func (s *service) ResetPassword(ID string) error {
+	existingData, err := s.userRepository.ReadData(ID)
+	if err != nil {
+		return err
+	}
+
+	token, err := crypto.RandomToken(resetTokenSize)
+	if err != nil {
+		return errors.New(ErrUserGeneratePassword)
+	}
+	expiredAt := time.Now().Add(time.Minute * time.Duration(s.passwordResetExpired))
+	if err := s.userRepository.UpdateResetToken(ID, crypto.HashToken(token), expiredAt); err != nil {
+		return err
+	}
+
+	reset := userPort.PasswordReset{
+		Email:     existingData.Email,
+		Fullname:  existingData.Fullname,
+		Token:     token,
+		ExpiredAt: expiredAt,
+	}
+	if err := s.notifier.NotifyPasswordReset(reset); err != nil {
+		return errors.New(ErrSendResetToken)
+	}
+	return nil
+}
+
+func (s *service) ConfirmResetPassword(token string, newPassword string) error {
+	existingData := s.userRepository.FindByResetToken(crypto.HashToken(token))
+	if existingData.ID == "" {
+		return errors.New(ErrInvalidResetToken)
+	}
+
+	return s.replacePassword(existingData, newPassword)
+}
+
 func (s *service) ListData() ([]userPort.UserService, error) {
 	users := make([]userPort.UserService, 0)
 
@@ -104,6 +194,7 @@ func (s *service) ListData() ([]userPort.UserService, error) {
 		user.ID = datas[i].ID
 		user.Email = datas[i].Email
 		user.Fullname = datas[i].Fullname
+		user.Version = datas[i].Version
 
 		users = append(users, user)
 	}
@@ -111,7 +202,82 @@ func (s *service) ListData() ([]userPort.UserService, error) {
 	return users, nil
 }
 
-func generatePassword(stringPwd string, data *userPort.UserRepo) {
-	hashedPassword, _ := crypto.UserGeneratePassword(stringPwd)
+func (s *service) SearchData(listQuery query.Query) ([]userPort.UserService, query.Page, error) {
+	users := make([]userPort.UserService, 0)
+
+	datas, page, err := s.userRepository.SearchData(listQuery)
+	if err != nil {
+		return users, page, err
+	}
+
+	for i := range datas {
+		users = append(users, userPort.UserService{
+			ID:       datas[i].ID,
+			Email:    datas[i].Email,
+			Fullname: datas[i].Fullname,
+			Version:  datas[i].Version,
+		})
+	}
+
+	return users, page, nil
+}
+
+// replacePassword store new password of existing user and revoke all of their sessions
+func (s *service) replacePassword(existingData userPort.UserRepo, password string) error {
+	var data userPort.UserRepo
+
+	data.ID = existingData.ID
+	data.Email = existingData.Email
+	data.Fullname = existingData.Fullname
+	data.Version = existingData.Version
+
+	if err := s.bindPassword(existingData, password, &data); err != nil {
+		return err
+	}
+	if err := s.userRepository.UpdateData(data); err != nil {
+		return err
+	}
+
+	return s.userTokenRepository.DeleteDataByUserID(existingData.ID)
+}
+
+// bindPassword validate password against policy and history of existingData, then hash it into data
+func (s *service) bindPassword(existingData userPort.UserRepo, password string, data *userPort.UserRepo) error {
+	if err := s.passwordPolicy.Verify(password); err != nil {
+		return err
+	}
+
+	recentPasswords := recentPasswordHashes(existingData, s.passwordPolicy.History)
+	for i := range recentPasswords {
+		if crypto.UserVerifyPassword(password, recentPasswords[i]) {
+			return errors.New(ErrPasswordReused)
+		}
+	}
+
+	hashedPassword, err := crypto.UserGeneratePassword(password)
+	if err != nil {
+		return errors.New(ErrUserGeneratePassword)
+	}
 	data.Password = hashedPassword
+	// together with the new password this keeps the last History passwords
+	data.PasswordHistory = recentPasswordHashes(existingData, s.passwordPolicy.History-1)
+
+	return nil
+}
+
+// recentPasswordHashes returns up to n last password hashes, the current one first
+func recentPasswordHashes(data userPort.UserRepo, n int) []string {
+	hashes := make([]string, 0)
+	if data.Password != "" {
+		hashes = append(hashes, data.Password)
+	}
+	hashes = append(hashes, data.PasswordHistory...)
+
+	if n < 0 {
+		n = 0
+	}
+	if len(hashes) > n {
+		hashes = hashes[:n]
+	}
+	return hashes
 }
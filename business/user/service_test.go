@@ -4,21 +4,29 @@ import (
 	"errors"
 	"testing"
 
+	mockNotifier "github.com/sepulsa/teleco/modules/notifier/mock"
 	mockUserRepo "github.com/sepulsa/teleco/modules/repository/mock/user"
 	mockUserTokenRepo "github.com/sepulsa/teleco/modules/repository/mock/usertoken"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	authPort "github.com/sepulsa/teleco/business/auth/port"
 	userServ "github.com/sepulsa/teleco/business/user"
 	"github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/crypto"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/sepulsa/teleco/utils/validator"
 )
 
 var (
-	TestID       = "6138813fb95630b0b528b160"
-	TestIDErr    = "1 2 3"
-	TestEmail    = "test@test.com"
-	TestFullname = "Test Fullname"
-	TestPassword = "test"
+	TestID           = "6138813fb95630b0b528b160"
+	TestIDErr        = "1 2 3"
+	TestEmail        = "test@test.com"
+	TestFullname     = "Test Fullname"
+	TestPassword     = "test"
+	TestNewPassword  = "Test1234"
+	TestResetToken   = "reset-token"
+	TestResetExpired = 60
 
 	UserID  = "615321f9b95630b0b529867c"
 	TokenID = "6328402206636078"
@@ -51,7 +59,7 @@ func TestCreateData(t *testing.T) {
 	// end populate
 
 	// inject
-	s := userServ.New(userRepo, userTokenRepo)
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{MinLength: 4}, TestResetExpired)
 
 	// err duplicate email
 	err := s.CreateData(dataUserServ)
@@ -59,6 +67,11 @@ func TestCreateData(t *testing.T) {
 
 	// success
 	assert.Nil(t, s.CreateData(dataUserServ))
+
+	// err password policy
+	s = userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{MinLength: 8}, TestResetExpired)
+	err = s.CreateData(dataUserServ)
+	assert.NotNil(t, err)
 }
 
 func TestUpdateData(t *testing.T) {
@@ -85,10 +98,11 @@ func TestUpdateData(t *testing.T) {
 	userRepo.On("FindByEmail", dataUserServ.Email).Return(dataUserRepo).Once()
 	userRepo.On("FindByEmail", dataUserServ.Email).Return(port.UserRepo{})
 	userRepo.On("UpdateData", mock.Anything).Return(nil)
+	userTokenRepo.On("DeleteDataByUserID", TestID).Return(nil).Once()
 	// end populate
 
 	// inject
-	s := userServ.New(userRepo, userTokenRepo)
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{MinLength: 4}, TestResetExpired)
 
 	// err repo, invalid id or user not found
 	assert.NotNil(t, s.UpdateData(dataUserServ))
@@ -97,8 +111,9 @@ func TestUpdateData(t *testing.T) {
 	err := s.UpdateData(dataUserServ)
 	assert.Equal(t, TestErrDuplicateEmail.Error(), err.Error())
 
-	// success
+	// success, password changed revokes sessions
 	assert.Nil(t, s.UpdateData(dataUserServ))
+	userTokenRepo.AssertExpectations(t)
 }
 
 func TestReadData(t *testing.T) {
@@ -116,7 +131,7 @@ func TestReadData(t *testing.T) {
 	// end populate
 
 	// inject
-	s := userServ.New(userRepo, userTokenRepo)
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{}, TestResetExpired)
 
 	// success
 	user, err := s.ReadData(TestID)
@@ -142,7 +157,7 @@ func TestDeleteData(t *testing.T) {
 	// end populate
 
 	// inject
-	s := userServ.New(userRepo, userTokenRepo)
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{}, TestResetExpired)
 
 	// success
 	err := s.DeleteData(TestID)
@@ -153,6 +168,123 @@ func TestDeleteData(t *testing.T) {
 	assert.Equal(t, TestErrInvalidID.Error(), err.Error())
 }
 
+func TestRevokeSessions(t *testing.T) {
+	userRepo := mockUserRepo.New()
+	userRepo.On("ReadData", TestID).Return(port.UserRepo{ID: TestID}, nil)
+	userRepo.On("ReadData", TestIDErr).Return(port.UserRepo{}, TestErrInvalidID)
+	userTokenRepo := mockUserTokenRepo.New()
+	userTokenRepo.On("DeleteDataByUserID", TestID).Return(nil)
+
+	// inject
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{}, TestResetExpired)
+
+	// success
+	assert.Nil(t, s.RevokeSessions(TestID))
+
+	// failed
+	err := s.RevokeSessions(TestIDErr)
+	assert.Equal(t, TestErrInvalidID.Error(), err.Error())
+}
+
+func TestChangePassword(t *testing.T) {
+	storedPassword, _ := crypto.UserGeneratePassword(TestPassword)
+	dataUserRepo := port.UserRepo{
+		ID:       TestID,
+		Email:    TestEmail,
+		Fullname: TestFullname,
+		Password: storedPassword,
+	}
+	userRepo := mockUserRepo.New()
+	userRepo.On("ReadData", TestID).Return(dataUserRepo, nil)
+	userRepo.On("UpdateData", mock.Anything).Return(nil).Once()
+	userTokenRepo := mockUserTokenRepo.New()
+	userTokenRepo.On("FindByTokenID", TokenID).Return(authPort.UserTokenRepo{}).Once()
+	userTokenRepo.On("FindByTokenID", TokenID).Return(authPort.UserTokenRepo{UserID: TestID, TokenID: TokenID})
+	userTokenRepo.On("DeleteDataByUserID", TestID).Return(nil).Once()
+
+	// inject
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{MinLength: 8, RequireUpper: true, RequireDigit: true, History: 2}, TestResetExpired)
+
+	// err invalid token
+	err := s.ChangePassword(TokenID, TestPassword, TestNewPassword)
+	assert.Equal(t, userServ.ErrInvalidToken, err.Error())
+
+	// err wrong old password
+	err = s.ChangePassword(TokenID, "wrong", TestNewPassword)
+	assert.Equal(t, userServ.ErrInvalidOldPassword, err.Error())
+
+	// err password policy
+	err = s.ChangePassword(TokenID, TestPassword, "weak")
+	assert.NotNil(t, err)
+
+	// err reuse current password
+	s = userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{History: 2}, TestResetExpired)
+	err = s.ChangePassword(TokenID, TestPassword, TestPassword)
+	assert.Equal(t, userServ.ErrPasswordReused, err.Error())
+
+	// success, current password kept in history
+	err = s.ChangePassword(TokenID, TestPassword, TestNewPassword)
+	if assert.Nil(t, err) {
+		updated := userRepo.Calls[len(userRepo.Calls)-1].Arguments.Get(0).(port.UserRepo)
+		assert.Equal(t, []string{storedPassword}, updated.PasswordHistory)
+		assert.True(t, crypto.UserVerifyPassword(TestNewPassword, updated.Password))
+	}
+	userTokenRepo.AssertExpectations(t)
+}
+
+func TestResetPassword(t *testing.T) {
+	userRepo := mockUserRepo.New()
+	userRepo.On("ReadData", TestID).Return(port.UserRepo{ID: TestID, Email: TestEmail, Fullname: TestFullname}, nil)
+	userRepo.On("ReadData", TestIDErr).Return(port.UserRepo{}, TestErrInvalidID)
+	userRepo.On("UpdateResetToken", TestID, mock.Anything, mock.Anything).Return(nil)
+	notifier := mockNotifier.New()
+
+	// inject
+	s := userServ.New(userRepo, mockUserTokenRepo.New(), notifier, validator.PasswordPolicy{}, TestResetExpired)
+
+	// success, the token is mailed to the user and only its hash is stored
+	var sent port.PasswordReset
+	notifier.On("NotifyPasswordReset", mock.Anything).Run(func(args mock.Arguments) {
+		sent = args.Get(0).(port.PasswordReset)
+	}).Return(nil).Once()
+	if assert.Nil(t, s.ResetPassword(TestID)) {
+		assert.Equal(t, TestEmail, sent.Email)
+		assert.Equal(t, TestFullname, sent.Fullname)
+		assert.NotEmpty(t, sent.Token)
+		userRepo.AssertCalled(t, "UpdateResetToken", TestID, crypto.HashToken(sent.Token), mock.Anything)
+	}
+
+	// mail failed
+	notifier.On("NotifyPasswordReset", mock.Anything).Return(errors.New("")).Once()
+	err := s.ResetPassword(TestID)
+	assert.Equal(t, userServ.ErrSendResetToken, err.Error())
+
+	// failed
+	err = s.ResetPassword(TestIDErr)
+	assert.Equal(t, TestErrInvalidID.Error(), err.Error())
+	notifier.AssertExpectations(t)
+}
+
+func TestConfirmResetPassword(t *testing.T) {
+	userRepo := mockUserRepo.New()
+	userRepo.On("FindByResetToken", crypto.HashToken("expired")).Return(port.UserRepo{})
+	userRepo.On("FindByResetToken", crypto.HashToken(TestResetToken)).Return(port.UserRepo{ID: TestID, Email: TestEmail})
+	userRepo.On("UpdateData", mock.Anything).Return(nil).Once()
+	userTokenRepo := mockUserTokenRepo.New()
+	userTokenRepo.On("DeleteDataByUserID", TestID).Return(nil).Once()
+
+	// inject
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{MinLength: 8}, TestResetExpired)
+
+	// err invalid or expired token
+	err := s.ConfirmResetPassword("expired", TestNewPassword)
+	assert.Equal(t, userServ.ErrInvalidResetToken, err.Error())
+
+	// success
+	assert.Nil(t, s.ConfirmResetPassword(TestResetToken, TestNewPassword))
+	userTokenRepo.AssertExpectations(t)
+}
+
 func TestListData(t *testing.T) {
 	// populate data user
 	datasUserRepo := []port.UserRepo{
@@ -170,7 +302,7 @@ func TestListData(t *testing.T) {
 	// end populate
 
 	// inject
-	s := userServ.New(userRepo, userTokenRepo)
+	s := userServ.New(userRepo, userTokenRepo, nil, validator.PasswordPolicy{}, TestResetExpired)
 
 	// success
 	users, err := s.ListData()
@@ -184,3 +316,23 @@ func TestListData(t *testing.T) {
 	_, err = s.ListData()
 	assert.NotNil(t, err)
 }
+
+func TestSearchData(t *testing.T) {
+	userRepo := mockUserRepo.New()
+	s := userServ.New(userRepo, mockUserTokenRepo.New(), nil, validator.PasswordPolicy{}, TestResetExpired)
+	listQuery := query.Query{Search: "test@", Sort: "email"}
+
+	// success, password is left out
+	userRepo.On("SearchData", listQuery).Return([]port.UserRepo{{ID: TestID, Email: TestEmail, Fullname: TestFullname, Password: TestPassword}}, query.Page{Total: 1, Page: 1, Limit: 20}, nil).Once()
+	users, page, err := s.SearchData(listQuery)
+	if assert.Nil(t, err) {
+		assert.Equal(t, TestEmail, users[0].Email)
+		assert.Empty(t, users[0].Password)
+		assert.Equal(t, 1, page.Total)
+	}
+
+	// error
+	userRepo.On("SearchData", listQuery).Return([]port.UserRepo{}, query.Page{}, errors.New("")).Once()
+	_, _, err = s.SearchData(listQuery)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,27 @@
+package archive
+
+import (
+	"errors"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/sepulsa/teleco/modules/archive/file"
+	orderRepository "github.com/sepulsa/teleco/modules/repository/mongodb/order"
+	"github.com/sepulsa/teleco/utils/config"
+)
+
+var (
+	ErrArchiveCollection = "database.mongo.archive target collection needs the mongodb database driver"
+)
+
+// New archive of the retention found in database.mongo.archive, compressed files unless the collection is chosen,
+// the collection needs the mongodb driver
+func New() orderPort.Archive {
+	conf := config.GetMongoArchive()
+	if conf.Target == config.ArchiveTargetCollection {
+		if config.GetDatabaseDriver() != config.DatabaseDriverMongoDB {
+			panic(errors.New(ErrArchiveCollection))
+		}
+		return orderRepository.NewArchive(config.Mgo)
+	}
+	return file.New(conf.Path)
+}
@@ -0,0 +1,52 @@
+package file
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+)
+
+type (
+	// File keeps the archived records of a run as gzip compressed JSON lines, one file per run
+	File struct {
+		dir string
+	}
+)
+
+var (
+	// NameLayout name of the archive file of a run, from its start time
+	NameLayout = "orders-20060102T150405Z.jsonl.gz"
+)
+
+func New(dir string) *File {
+	return &File{dir}
+}
+
+// Write appends a gzip member to the file of the run, gzip readers read the members as one stream
+func (f *File) Write(runAt time.Time, orders []orderPort.OrderRepo) (string, error) {
+	if err := os.MkdirAll(f.dir, 0755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(f.dir, runAt.UTC().Format(NameLayout))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0640)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	w := gzip.NewWriter(file)
+	encoder := json.NewEncoder(w)
+	for _, order := range orders {
+		if err = encoder.Encode(order); err != nil {
+			return "", err
+		}
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	return path, file.Sync()
+}
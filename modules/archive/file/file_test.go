@@ -0,0 +1,45 @@
+package file
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := New(dir)
+	runAt := time.Date(2021, 9, 8, 10, 0, 0, 0, time.UTC)
+	path, err := archive.Write(runAt, []orderPort.OrderRepo{{ID: "o1", RequestData: "{}"}, {ID: "o2"}})
+	require.NoError(t, err)
+	assert.Equal(t, dir+"/orders-20210908T100000Z.jsonl.gz", path)
+	_, err = archive.Write(runAt, []orderPort.OrderRepo{{ID: "o3"}})
+	require.NoError(t, err)
+
+	// batches of a run are read back as one stream
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	r, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	var IDs []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var order orderPort.OrderRepo
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &order))
+		IDs = append(IDs, order.ID)
+	}
+	assert.NoError(t, scanner.Err())
+	assert.Equal(t, []string{"o1", "o2", "o3"}, IDs)
+}
@@ -0,0 +1,22 @@
+package mock
+
+import (
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Archive struct {
+	mock.Mock
+}
+
+func New() *Archive {
+	return &Archive{}
+}
+
+func (a *Archive) Write(runAt time.Time, orders []orderPort.OrderRepo) (string, error) {
+	result := a.Called(runAt, orders)
+	return result.String(0), result.Error(1)
+}
@@ -1,6 +1,7 @@
 package dummy
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 
@@ -42,6 +43,7 @@ type (
 		Msg          string `json:"msg"`
 		Status       string `json:"status"`
 		SerialNumber string `json:"serial_number"`
+		Saldo        *int64 `json:"saldo"`
 	}
 )
 
@@ -49,7 +51,7 @@ func New() *Issuer {
 	return &Issuer{}
 }
 
-func (is *Issuer) Purchase(order orderPort.OrderIssuerApi, result *orderPort.OrderIssuerApiResult, errOrder *orderPort.Error) {
+func (is *Issuer) Purchase(ctx context.Context, order orderPort.OrderIssuerApi) (result orderPort.OrderIssuerApiResult, err error) {
 
 	// Parse json string issuer config
 	var issuerConfig IssuerConfig
@@ -85,13 +87,12 @@ func (is *Issuer) Purchase(order orderPort.OrderIssuerApi, result *orderPort.Ord
 	result.RequestData = string(b)
 
 	// Hit API
-	res, err := httpParam.HttpDo()
+	res, err := httpParam.HttpDoContext(ctx)
 
 	// log response
 	result.ResponseData = httpdump.DumpResponse(res)
 
 	if err != nil {
-		errOrder.Err = err
 		return
 	}
 	defer res.Body.Close()
@@ -99,7 +100,6 @@ func (is *Issuer) Purchase(order orderPort.OrderIssuerApi, result *orderPort.Ord
 	// read response
 	response, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		errOrder.Err = err
 		return
 	}
 
@@ -112,9 +112,12 @@ func (is *Issuer) Purchase(order orderPort.OrderIssuerApi, result *orderPort.Ord
 	result.Message = resData.Msg
 	result.IssuerRescode = resData.Rc
 	result.RawData = string(response)
+	result.IssuerBalance = resData.Saldo
+
+	return
 }
 
-func (is *Issuer) Advise(order orderPort.OrderIssuerApi, result *orderPort.OrderIssuerApiResult, errOrder *orderPort.Error) {
+func (is *Issuer) Advise(ctx context.Context, order orderPort.OrderIssuerApi) (result orderPort.OrderIssuerApiResult, err error) {
 	// Parse json string issuer config
 	var issuerConfig IssuerConfig
 	json.Unmarshal([]byte(order.IssuerConfig), &issuerConfig)
@@ -147,13 +150,12 @@ func (is *Issuer) Advise(order orderPort.OrderIssuerApi, result *orderPort.Order
 	result.RequestData = string(b)
 
 	// Hit API
-	res, err := httpParam.HttpDo()
+	res, err := httpParam.HttpDoContext(ctx)
 
 	// log response
 	result.ResponseData = httpdump.DumpResponse(res)
 
 	if err != nil {
-		errOrder.Err = err
 		return
 	}
 	defer res.Body.Close()
@@ -161,7 +163,6 @@ func (is *Issuer) Advise(order orderPort.OrderIssuerApi, result *orderPort.Order
 	// read response
 	response, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		errOrder.Err = err
 		return
 	}
 
@@ -174,10 +175,12 @@ func (is *Issuer) Advise(order orderPort.OrderIssuerApi, result *orderPort.Order
 	result.Message = resData.Msg
 	result.IssuerRescode = resData.Rc
 	result.RawData = string(response)
+	result.IssuerBalance = resData.Saldo
 
+	return
 }
 
-func (is *Issuer) Reversal(order orderPort.OrderIssuerApi, result *orderPort.OrderIssuerApiResult, errOrder *orderPort.Error) {
+func (is *Issuer) Reversal(ctx context.Context, order orderPort.OrderIssuerApi) (result orderPort.OrderIssuerApiResult, err error) {
 
 	// Parse json string issuer config
 	var issuerConfig IssuerConfig
@@ -211,13 +214,12 @@ func (is *Issuer) Reversal(order orderPort.OrderIssuerApi, result *orderPort.Ord
 	result.RequestData = string(b)
 
 	// Hit API
-	res, err := httpParam.HttpDo()
+	res, err := httpParam.HttpDoContext(ctx)
 
 	// log response
 	result.ResponseData = httpdump.DumpResponse(res)
 
 	if err != nil {
-		errOrder.Err = err
 		return
 	}
 	defer res.Body.Close()
@@ -225,7 +227,6 @@ func (is *Issuer) Reversal(order orderPort.OrderIssuerApi, result *orderPort.Ord
 	// read response
 	response, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		errOrder.Err = err
 		return
 	}
 
@@ -238,4 +239,67 @@ func (is *Issuer) Reversal(order orderPort.OrderIssuerApi, result *orderPort.Ord
 	result.Message = resData.Msg
 	result.IssuerRescode = resData.Rc
 	result.RawData = string(response)
+	result.IssuerBalance = resData.Saldo
+
+	return
+}
+
+func (is *Issuer) Balance(ctx context.Context, order orderPort.OrderIssuerApi) (result orderPort.OrderIssuerApiResult, err error) {
+	// Parse json string issuer config
+	var issuerConfig IssuerConfig
+	json.Unmarshal([]byte(order.IssuerConfig), &issuerConfig)
+
+	// Parse json string config
+	var partnerIssuerConfig PartnerIssuerConfig
+	json.Unmarshal([]byte(order.PartnerIssuerConfig), &partnerIssuerConfig)
+	reqData := RequestData{
+		ID:       partnerIssuerConfig.ID,
+		PIN:      partnerIssuerConfig.PIN,
+		User:     partnerIssuerConfig.User,
+		Password: partnerIssuerConfig.Password,
+	}
+	b, _ := json.Marshal(reqData)
+
+	// Set HTTP Parameters
+	var httpParam httpclient.HttpParam
+	header := make(map[string]string)
+	header["Content-Type"] = "application/json"
+
+	httpParam.Url = issuerConfig.Url + "/balance"
+	httpParam.Method = "post"
+	httpParam.Header = header
+	httpParam.Body = string(b)
+	httpParam.Timeout = 30
+
+	// log request
+	b, _ = json.Marshal(httpParam)
+	result.RequestData = string(b)
+
+	// Hit API
+	res, err := httpParam.HttpDoContext(ctx)
+
+	// log response
+	result.ResponseData = httpdump.DumpResponse(res)
+
+	if err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	// read response
+	response, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return
+	}
+
+	// parse response
+	var resData ResponseData
+	json.Unmarshal(response, &resData)
+
+	result.Message = resData.Msg
+	result.IssuerRescode = resData.Rc
+	result.RawData = string(response)
+	result.IssuerBalance = resData.Saldo
+
+	return
 }
@@ -1,22 +1,125 @@
 package issuerapi
 
 import (
+	"context"
+	"sync"
+	"time"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
 	orderPort "github.com/sepulsa/teleco/business/order/port"
 	"github.com/sepulsa/teleco/modules/issuerapi/task"
+	"github.com/sepulsa/teleco/utils/circuitbreaker"
+	log "github.com/sepulsa/teleco/utils/logger"
 	"github.com/sepulsa/teleco/utils/threadpool"
 )
 
 type (
-	issuerApi struct{}
+	issuerApi struct {
+		circuitRepository issuerPort.CircuitRepository
+		depositService    depositPort.Service
+		balanceService    issuerPort.BalanceService
+
+		mu       sync.Mutex
+		breakers map[string]*circuitbreaker.Breaker
+	}
+)
+
+var (
+	packageLog = "teleco/modules/issuerapi"
+
+	ErrCircuitOpen = "Issuer temporarily unavailable"
 )
 
-func New() *issuerApi {
-	return &issuerApi{}
+func New(circuitRepository issuerPort.CircuitRepository, depositService depositPort.Service, balanceService issuerPort.BalanceService) *issuerApi {
+	return &issuerApi{
+		circuitRepository: circuitRepository,
+		depositService:    depositService,
+		balanceService:    balanceService,
+		breakers:          make(map[string]*circuitbreaker.Breaker),
+	}
+}
+
+func (is *issuerApi) Do(ctx context.Context, order orderPort.OrderIssuerApi) (orderPort.OrderIssuerApiResult, error) {
+	if order.IssuerCircuitForcedOpen {
+		return circuitOpenResult(), nil
+	}
+	settings := toSettings(order.IssuerCircuitBreaker)
+	breaker := is.breaker(order.IssuerCode)
+	if !breaker.Allow(time.Now(), settings) {
+		return circuitOpenResult(), nil
+	}
+
+	ot := &task.OrderTask{Order: order, DepositService: is.depositService, BalanceService: is.balanceService}
+	quota := threadpool.Quota{
+		Key:      order.PartnerId,
+		Reserved: order.PartnerReservedThread,
+		Max:      order.PartnerMaxThread,
+	}
+	start := time.Now()
+	result := threadpool.RunWithQuota(ctx, order.IssuerCode, order.IssuerThreadNum, order.IssuerThreadTimeout, quota, ot).(task.OrderTaskResult)
+
+	// a full pool says nothing about the issuer health
+	if result.Err == nil && (result.Result.Message == task.ErrConcurrentLimit || result.Result.Message == task.ErrPoolFull) {
+		breaker.Cancel()
+		return result.Result, result.Err
+	}
+	failed := result.Err != nil || result.Result.Message == task.ErrTimeout
+	now := time.Now()
+	if breaker.Done(now, settings, failed, now.Sub(start)) {
+		is.saveState(order.IssuerCode, breaker.Snapshot())
+	}
+
+	return result.Result, result.Err
+}
+
+// breaker of the issuer, breakers live in the process so every instance trips on its own calls
+func (is *issuerApi) breaker(issuerCode string) *circuitbreaker.Breaker {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+	breaker, found := is.breakers[issuerCode]
+	if !found {
+		breaker = circuitbreaker.New()
+		is.breakers[issuerCode] = breaker
+	}
+	return breaker
+}
+
+// saveState report the transition of this instance, the stored state is not read back to trip other instances
+func (is *issuerApi) saveState(issuerCode string, snapshot circuitbreaker.Snapshot) {
+	log.Info().Str("event", "circuit.state").Str("package", packageLog).Msgf("Issuer %s circuit %s", issuerCode, snapshot.State)
+	if is.circuitRepository == nil {
+		return
+	}
+	state := issuerPort.CircuitState{
+		IssuerCode: issuerCode,
+		State:      snapshot.State,
+		Calls:      snapshot.Calls,
+		Failures:   snapshot.Failures,
+		SlowCalls:  snapshot.SlowCalls,
+		OpenedAt:   snapshot.OpenedAt,
+		UpdatedAt:  snapshot.UpdatedAt,
+	}
+	if err := is.circuitRepository.SaveState(state); err != nil {
+		log.Error().Str("event", "circuit.error").Str("package", packageLog).Msgf("Error Save Circuit State %s: %s", issuerCode, err.Error())
+	}
+}
+
+func toSettings(conf issuerPort.CircuitBreaker) circuitbreaker.Settings {
+	return circuitbreaker.Settings{
+		ErrorRate:     conf.ErrorRate,
+		SlowCallRate:  conf.SlowCallRate,
+		SlowCall:      time.Duration(conf.SlowCallDuration) * time.Millisecond,
+		MinimumCalls:  conf.MinimumCalls,
+		Window:        time.Duration(conf.Window) * time.Second,
+		OpenDuration:  time.Duration(conf.OpenDuration) * time.Second,
+		HalfOpenCalls: conf.HalfOpenCalls,
+	}
 }
 
-func (is *issuerApi) Do(order orderPort.OrderIssuerApi) (orderResult orderPort.OrderIssuerApiResult, err error) {
-	var errPort orderPort.Error
-	ot := &task.OrderTask{Order: order, OrderResult: &orderResult, Err: &errPort}
-	threadpool.Run(order.IssuerCode, order.IssuerThreadNum, order.IssuerThreadTimeout, ot)
-	return orderResult, errPort.Err
+func circuitOpenResult() orderPort.OrderIssuerApiResult {
+	return orderPort.OrderIssuerApiResult{
+		IssuerRescode: orderPort.RescodeCircuitOpen,
+		Message:       ErrCircuitOpen,
+	}
 }
@@ -1,6 +1,8 @@
 package mock
 
 import (
+	"context"
+
 	orderPort "github.com/sepulsa/teleco/business/order/port"
 	"github.com/stretchr/testify/mock"
 )
@@ -15,7 +17,7 @@ func New() *issuerApi {
 	return &issuerApi{}
 }
 
-func (is *issuerApi) Do(order orderPort.OrderIssuerApi) (orderPort.OrderIssuerApiResult, error) {
-	result := is.Called(order)
+func (is *issuerApi) Do(ctx context.Context, order orderPort.OrderIssuerApi) (orderPort.OrderIssuerApiResult, error) {
+	result := is.Called(ctx, order)
 	return result.Get(0).(orderPort.OrderIssuerApiResult), result.Error(1)
 }
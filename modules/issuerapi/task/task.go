@@ -1,27 +1,40 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
 	orderPort "github.com/sepulsa/teleco/business/order/port"
 	"github.com/sepulsa/teleco/modules/callback"
 	"github.com/sepulsa/teleco/modules/issuerapi/issuer/dummy"
-	orderRepository "github.com/sepulsa/teleco/modules/repository/mongodb/order"
-	"github.com/sepulsa/teleco/utils/config"
+	"github.com/sepulsa/teleco/modules/repository"
+	log "github.com/sepulsa/teleco/utils/logger"
 	"github.com/sepulsa/teleco/utils/queue/producer"
 )
 
-type OrderTask struct {
-	Order       orderPort.OrderIssuerApi
-	OrderResult *orderPort.OrderIssuerApiResult
-	Err         *orderPort.Error
-}
+type (
+	OrderTask struct {
+		Order          orderPort.OrderIssuerApi
+		DepositService depositPort.Service
+		BalanceService issuerPort.BalanceService
+	}
+
+	// OrderTaskResult value returned by every OrderTask path
+	OrderTaskResult struct {
+		Result orderPort.OrderIssuerApiResult
+		Err    error
+	}
+)
 
 var (
 	ErrTimeout            = "Transaction still in progress"
 	ErrConcurrentLimit    = "Concurrent limit reached. Transaction added to queue process."
+	ErrPoolFull           = "Concurrent limit reached. Queued transaction failed."
 	ErrIssuerCodeNotFound = "Issuer API Not Found"
+	ErrBalanceNotReported = "Issuer did not report its balance"
 )
 
 func getIssuerAPI(issuerCode string) (orderPort.Issuer, error) {
@@ -33,32 +46,90 @@ func getIssuerAPI(issuerCode string) (orderPort.Issuer, error) {
 	}
 }
 
-func (t *OrderTask) Run() {
-	issuerApi, err := getIssuerAPI(t.Order.IssuerCode)
+func execute(ctx context.Context, order orderPort.OrderIssuerApi, balanceServ issuerPort.BalanceService) (result OrderTaskResult) {
+	issuerApi, err := getIssuerAPI(order.IssuerCode)
 	if err != nil {
-		t.Err.Err = err
+		result.Err = err
 		return
 	}
-	switch t.Order.CommandType {
+	switch order.CommandType {
 	case orderPort.Purchase:
-		issuerApi.Purchase(t.Order, t.OrderResult, t.Err)
+		result.Result, result.Err = issuerApi.Purchase(ctx, order)
 	case orderPort.Advise:
-		issuerApi.Advise(t.Order, t.OrderResult, t.Err)
+		result.Result, result.Err = issuerApi.Advise(ctx, order)
 	case orderPort.Reversal:
-		issuerApi.Reversal(t.Order, t.OrderResult, t.Err)
+		result.Result, result.Err = issuerApi.Reversal(ctx, order)
+	}
+	trackBalance(balanceServ, order, result)
+	return
+}
+
+// trackBalance keep the issuer balance from the one the issuer reported,
+// or estimated from the base price of a successful purchase
+func trackBalance(balanceServ issuerPort.BalanceService, order orderPort.OrderIssuerApi, result OrderTaskResult) {
+	if result.Err != nil {
+		return
+	}
+	var err error
+	switch {
+	case result.Result.IssuerBalance != nil:
+		err = balanceServ.Report(order.IssuerCode, *result.Result.IssuerBalance, issuerPort.BalanceSourceResponse)
+	case order.CommandType == orderPort.Purchase && result.Result.IssuerRescode == orderPort.RescodeSuccess:
+		// the base price is what the issuer charges
+		err = balanceServ.Deduct(order.IssuerCode, order.Price.BasePrice)
+	}
+	if err != nil {
+		log.Error().Str("event", "issuer.balance.error").Str("package", packageLog).Msgf("Error Track Issuer Balance %s: %s", order.IssuerCode, err.Error())
+	}
+}
+
+// InquiryBalance ask the issuer its balance, issuers without a balance inquiry only get estimated balances
+func InquiryBalance(ctx context.Context, balanceServ issuerPort.BalanceService, issuer issuerPort.IssuerService) error {
+	issuerApi, err := getIssuerAPI(issuer.Code)
+	if err != nil {
+		return err
+	}
+	order := orderPort.OrderIssuerApi{
+		IssuerCode:   issuer.Code,
+		IssuerConfig: issuer.Config,
+	}
+	result, err := issuerApi.Balance(ctx, order)
+	if err != nil {
+		return err
+	}
+	if result.IssuerBalance == nil {
+		return errors.New(ErrBalanceNotReported)
+	}
+	return balanceServ.Report(issuer.Code, *result.IssuerBalance, issuerPort.BalanceSourceInquiry)
+}
+
+// settleDeposit settle the deposit hold of a purchase answered after the partner got a pending result
+func settleDeposit(depositServ depositPort.Service, order orderPort.OrderIssuerApi, result OrderTaskResult) {
+	if order.DepositHoldId == "" {
+		return
+	}
+	if err := depositServ.Settle(order.DepositHoldId, result.Result.IssuerRescode, result.Result.IssuerTransactionId, result.Err != nil); err != nil {
+		log.Error().Str("event", "deposit.error").Str("package", packageLog).Msgf("Error Settle Deposit Hold %s: %s", order.DepositHoldId, err.Error())
 	}
 }
 
-func (t *OrderTask) RunWhenTimeout() {
-	t.OrderResult.IssuerRescode = "10"
-	t.OrderResult.Message = ErrTimeout
+func (t *OrderTask) Run(ctx context.Context) interface{} {
+	return execute(ctx, t.Order, t.BalanceService)
 }
 
-func (t *OrderTask) RunAfterTimeout() {
+func (t *OrderTask) RunWhenTimeout() interface{} {
+	var result OrderTaskResult
+	result.Result.IssuerRescode = orderPort.RescodePending
+	result.Result.Message = ErrTimeout
+	return result
+}
+
+func (t *OrderTask) RunAfterTimeout(result interface{}) {
+	settleDeposit(t.DepositService, t.Order, result.(OrderTaskResult))
+	orderResult := result.(OrderTaskResult).Result
 	callbackPort := callback.New()
-	callBackResult := callbackPort.Do(t.Order, *t.OrderResult)
-	db := config.Mgo
-	orderRepo := orderRepository.New(db)
+	callBackResult := callbackPort.Do(t.Order, orderResult)
+	orderRepo := repository.NewOrder()
 	// Store Log Data
 	orderData := orderPort.OrderRepo{
 		CommandType:          t.Order.CommandType,
@@ -67,20 +138,36 @@ func (t *OrderTask) RunAfterTimeout() {
 		CustomerNumber:       t.Order.CustomerNumber,
 		PartnerId:            t.Order.PartnerId,
 		IssuerId:             t.Order.IssuerId,
-		IssuerTransactionId:  t.OrderResult.IssuerTransactionId,
-		RequestData:          t.OrderResult.RequestData,
-		ResponseData:         t.OrderResult.ResponseData,
+		IssuerTransactionId:  orderResult.IssuerTransactionId,
+		ProductCode:          t.Order.ProductCode,
+		Route:                t.Order.Route,
+		Price:                t.Order.Price,
+		RequestData:          orderResult.RequestData,
+		ResponseData:         orderResult.ResponseData,
 		CallbackRequestData:  callBackResult.RequestData,
 		CallbackResponseData: callBackResult.ResponseData,
+		IssuerRescode:        orderResult.IssuerRescode,
+		SerialNumber:         orderResult.SerialNumber,
 	}
 	orderRepo.CreateData(orderData)
 }
 
-func (t *OrderTask) RunWhenFull() {
-	t.OrderResult.IssuerRescode = "10"
-	t.OrderResult.Message = ErrConcurrentLimit
-	js, _ := json.Marshal(t.Order)
+// RunWhenFull queue the order to its issuer queue, an order already read from the queue fails instead
+// so its hold is released and the partner gets the failure callback rather than looping through the queue
+func (t *OrderTask) RunWhenFull() interface{} {
+	var result OrderTaskResult
+	if t.Order.Queued {
+		result.Result.IssuerRescode = orderPort.RescodePoolFull
+		result.Result.Message = ErrPoolFull
+		return result
+	}
+	result.Result.IssuerRescode = orderPort.RescodePending
+	result.Result.Message = ErrConcurrentLimit
+	order := t.Order
+	order.Queued = true
+	js, _ := json.Marshal(order)
 	str := string(js)
 	queueName := "teleco_" + t.Order.IssuerCode
 	producer.Queue.CreateItem(queueName, str)
+	return result
 }
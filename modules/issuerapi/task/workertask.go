@@ -1,12 +1,13 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
 
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
 	orderPort "github.com/sepulsa/teleco/business/order/port"
 	"github.com/sepulsa/teleco/modules/callback"
-	orderRepository "github.com/sepulsa/teleco/modules/repository/mongodb/order"
-	"github.com/sepulsa/teleco/utils/config"
+	"github.com/sepulsa/teleco/modules/repository"
 	log "github.com/sepulsa/teleco/utils/logger"
 )
 
@@ -14,32 +15,31 @@ var (
 	packageLog = "teleco/modules/issuerapi/task"
 )
 
-type WorkerTask struct{}
+type WorkerTask struct {
+	// IssuerApi runs the queued orders through the issuer circuit breaker and thread pool
+	IssuerApi      orderPort.IssuerApi
+	DepositService depositPort.Service
+}
 
 func (t *WorkerTask) Run(payload string) {
 	var order orderPort.OrderIssuerApi
-	var orderResult orderPort.OrderIssuerApiResult
-	var errs orderPort.Error
 	json.Unmarshal([]byte(payload), &order)
 
-	issuerApi, err := getIssuerAPI(order.IssuerCode)
-	if err != nil {
-		errs.Err = err
+	if _, err := getIssuerAPI(order.IssuerCode); err != nil {
 		return
 	}
-	switch order.CommandType {
-	case orderPort.Purchase:
-		issuerApi.Purchase(order, &orderResult, &errs)
-	case orderPort.Advise:
-		issuerApi.Advise(order, &orderResult, &errs)
-	case orderPort.Reversal:
-		issuerApi.Reversal(order, &orderResult, &errs)
+
+	orderResult, err := t.IssuerApi.Do(context.Background(), order)
+	// settled by RunAfterTimeout once the issuer answers, a full pool fails the order with RescodePoolFull
+	if err == nil && orderResult.Message == ErrTimeout {
+		log.Info().Str("event", "queue.pending").Str("package", packageLog).Msgf("Payload: %s", payload)
+		return
 	}
+	settleDeposit(t.DepositService, order, OrderTaskResult{Result: orderResult, Err: err})
 
 	callbackPort := callback.New()
 	callBackResult := callbackPort.Do(order, orderResult)
-	db := config.Mgo
-	orderRepo := orderRepository.New(db)
+	orderRepo := repository.NewOrder()
 	// Store Log Data
 	orderData := orderPort.OrderRepo{
 		CommandType:          order.CommandType,
@@ -49,10 +49,15 @@ func (t *WorkerTask) Run(payload string) {
 		PartnerId:            order.PartnerId,
 		IssuerId:             order.IssuerId,
 		IssuerTransactionId:  orderResult.IssuerTransactionId,
+		ProductCode:          order.ProductCode,
+		Route:                order.Route,
+		Price:                order.Price,
 		RequestData:          orderResult.RequestData,
 		ResponseData:         orderResult.ResponseData,
 		CallbackRequestData:  callBackResult.RequestData,
 		CallbackResponseData: callBackResult.ResponseData,
+		IssuerRescode:        orderResult.IssuerRescode,
+		SerialNumber:         orderResult.SerialNumber,
 	}
 	orderRepo.CreateData(orderData)
 	log.Info().Str("event", "queue.executed").Str("package", packageLog).Msgf("Payload: %s", payload)
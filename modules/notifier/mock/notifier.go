@@ -0,0 +1,26 @@
+package mock
+
+import (
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	userPort "github.com/sepulsa/teleco/business/user/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Notifier struct {
+	mock.Mock
+}
+
+func New() *Notifier {
+	return &Notifier{}
+}
+
+func (n *Notifier) NotifyLowBalance(alert issuerPort.LowBalanceAlert) error {
+	result := n.Called(alert)
+	return result.Error(0)
+}
+
+func (n *Notifier) NotifyPasswordReset(reset userPort.PasswordReset) error {
+	result := n.Called(reset)
+	return result.Error(0)
+}
@@ -0,0 +1,64 @@
+package notifier
+
+import (
+	"errors"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/modules/notifier/smtp"
+	"github.com/sepulsa/teleco/modules/notifier/webhook"
+	"github.com/sepulsa/teleco/utils/config"
+	log "github.com/sepulsa/teleco/utils/logger"
+)
+
+type (
+	// notifiers alert is logged then sent to every configured channel
+	notifiers []issuerPort.Notifier
+
+	// noMail refuse to deliver what must only reach a mailbox
+	noMail struct{}
+)
+
+var (
+	ErrSMTPRequired = "notifier.smtp is not configured"
+
+	packageLog = "teleco/modules/notifier"
+)
+
+// New notifier of the channels found in the notifier config, alerts are only logged when none is configured
+func New() issuerPort.Notifier {
+	var channels notifiers
+	if url := config.GetNotifierWebhookUrl(); url != "" {
+		channels = append(channels, webhook.New(url))
+	}
+	if conf := config.GetNotifierSMTP(); conf.Host != "" {
+		channels = append(channels, smtp.New(conf))
+	}
+	return channels
+}
+
+// NewPasswordReset mail notifier of the password reset tokens, tokens are neither logged nor posted to the webhook
+func NewPasswordReset() userPort.Notifier {
+	if conf := config.GetNotifierSMTP(); conf.Host != "" {
+		return smtp.New(conf)
+	}
+	return noMail{}
+}
+
+func (noMail) NotifyPasswordReset(reset userPort.PasswordReset) error {
+	return errors.New(ErrSMTPRequired)
+}
+
+func (n notifiers) NotifyLowBalance(alert issuerPort.LowBalanceAlert) (err error) {
+	log.Warn().
+		Str("event", "issuer.balance.low").
+		Str("package", packageLog).
+		Msgf("Issuer %s balance %d below threshold %d", alert.IssuerCode, alert.Balance, alert.Threshold)
+	for _, channel := range n {
+		if errNotify := channel.NotifyLowBalance(alert); errNotify != nil {
+			log.Error().Str("event", "notifier.error").Str("package", packageLog).Msgf("Error Notify Low Balance %s: %s", alert.IssuerCode, errNotify.Error())
+			err = errNotify
+		}
+	}
+	return
+}
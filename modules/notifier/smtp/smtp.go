@@ -0,0 +1,59 @@
+package smtp
+
+import (
+	"fmt"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/config"
+)
+
+type (
+	SMTP struct {
+		conf config.SMTPConfig
+	}
+)
+
+var (
+	subject = "[teleco] Low balance at issuer %s"
+	body    = "Balance of issuer %s (%s) is %d, below the threshold of %d.\r\nSource: %s\r\nTime: %s\r\n"
+
+	resetSubject = "[teleco] Password reset"
+	resetBody    = "Hello %s,\r\n\r\nAn admin requested a password reset of your account. Set a new password with this token before %s:\r\n\r\n%s\r\n"
+)
+
+// New mail notifier, any SMTP server works including a local stand-in such as MailHog
+func New(conf config.SMTPConfig) *SMTP {
+	return &SMTP{conf}
+}
+
+func (s *SMTP) NotifyLowBalance(alert issuerPort.LowBalanceAlert) error {
+	return s.send(s.conf.To, fmt.Sprintf(subject, alert.IssuerCode), fmt.Sprintf(body, alert.IssuerCode, alert.IssuerLabel, alert.Balance, alert.Threshold, alert.Source, alert.CreatedAt.Format("2006-01-02 15:04:05 MST")))
+}
+
+// NotifyPasswordReset mail the token to the user only, not to the alert recipients
+func (s *SMTP) NotifyPasswordReset(reset userPort.PasswordReset) error {
+	return s.send([]string{reset.Email}, resetSubject, fmt.Sprintf(resetBody, reset.Fullname, reset.ExpiredAt.Format("2006-01-02 15:04:05 MST"), reset.Token))
+}
+
+func (s *SMTP) send(to []string, subject string, body string) error {
+	var auth smtp.Auth
+	if s.conf.Username != "" {
+		auth = smtp.PlainAuth("", s.conf.Username, s.conf.Password, s.conf.Host)
+	}
+	addr := s.conf.Host + ":" + strconv.Itoa(s.conf.Port)
+	return smtp.SendMail(addr, auth, s.conf.From, to, message(s.conf.From, to, subject, body))
+}
+
+func message(from string, to []string, subject string, body string) []byte {
+	var msg strings.Builder
+	msg.WriteString("From: " + from + "\r\n")
+	msg.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	msg.WriteString("Subject: " + subject + "\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(body)
+	return []byte(msg.String())
+}
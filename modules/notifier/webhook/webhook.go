@@ -0,0 +1,48 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/net/httpclient"
+)
+
+type (
+	Webhook struct {
+		url string
+	}
+)
+
+var (
+	ErrUnexpectedStatus = "Webhook responded with status %d"
+)
+
+func New(url string) *Webhook {
+	return &Webhook{url}
+}
+
+func (w *Webhook) NotifyLowBalance(alert issuerPort.LowBalanceAlert) error {
+	b, _ := json.Marshal(alert)
+
+	var httpParam httpclient.HttpParam
+	header := make(map[string]string)
+	header["Content-Type"] = "application/json"
+
+	httpParam.Url = w.url
+	httpParam.Method = "post"
+	httpParam.Header = header
+	httpParam.Body = string(b)
+	httpParam.Timeout = 30
+
+	res, err := httpParam.HttpDo()
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf(ErrUnexpectedStatus, res.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+// Package conformance holds the behaviour every repository backend shares with the MongoDB one,
+// each suite expects an empty repository
+package conformance
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sepulsa/teleco/utils/apperror"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+var (
+	// MongoHostEnv MongoDB server the MongoDB repositories are checked against, skipped while unset
+	MongoHostEnv = "TELECO_TEST_MONGO_HOST"
+)
+
+// MongoDB empty database dropped at the end of the test
+func MongoDB(t *testing.T) *mongo.MongoDatabase {
+	host := os.Getenv(MongoHostEnv)
+	if host == "" {
+		t.Skip(MongoHostEnv + " is not set")
+	}
+	session, err := mgo.Dial(host)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := session.DB("teleco_test_" + bson.NewObjectId().Hex())
+	t.Cleanup(func() {
+		db.DropDatabase()
+		session.Close()
+	})
+	return &mongo.MongoDatabase{Database: db}
+}
+
+// assertConflict checks an update of a version read before another update is refused
+func assertConflict(t *testing.T, err error) bool {
+	return assert.Error(t, err) && assert.Equal(t, apperror.Conflict, apperror.KindOf(err), err.Error())
+}
+
+// missingID well formed identifier of no record
+func missingID() string {
+	return bson.NewObjectId().Hex()
+}
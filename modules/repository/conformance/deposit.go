@@ -0,0 +1,116 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrAccountNotFound = "Deposit account not found"
+	ErrHoldNotFound    = "Deposit hold not found"
+)
+
+// Deposit checks a deposit repository
+func Deposit(t *testing.T, repository depositPort.Repository) {
+	_, err := repository.FindAccount("partner")
+	assert.EqualError(t, err, ErrAccountNotFound)
+	held, err := repository.HoldBalance("partner", 100)
+	if assert.NoError(t, err) {
+		assert.False(t, held)
+	}
+
+	// the first topup opens the account
+	require.NoError(t, repository.Topup("partner", 1000))
+	require.NoError(t, repository.Topup("partner", 500))
+	account, err := repository.FindAccount("partner")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "partner", account.PartnerCode)
+		assert.Equal(t, int64(1500), account.Balance)
+		assert.Zero(t, account.Held)
+	}
+
+	held, err = repository.HoldBalance("partner", 1200)
+	if assert.NoError(t, err) {
+		assert.True(t, held)
+	}
+	held, err = repository.HoldBalance("partner", 400)
+	if assert.NoError(t, err) {
+		assert.False(t, held)
+	}
+	assert.NoError(t, repository.MoveBalance("partner", 200, -200))
+	account, err = repository.FindAccount("partner")
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(500), account.Balance)
+		assert.Equal(t, int64(1000), account.Held)
+	}
+
+	hold, err := repository.CreateHold(depositPort.HoldRepo{PartnerCode: "partner", TransactionId: "trx", Amount: 1000, Status: depositPort.HoldStatusHeld})
+	require.NoError(t, err)
+	require.NotEmpty(t, hold.ID)
+	assert.False(t, hold.CreatedAt.IsZero())
+	read, err := repository.ReadHold(hold.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1000), read.Amount)
+		assert.Equal(t, depositPort.HoldStatusHeld, read.Status)
+	}
+	read, err = repository.FindHold("partner", "trx")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hold.ID, read.ID)
+	}
+	_, err = repository.FindHoldByIssuerTransaction("partner", "issuer-trx")
+	assert.EqualError(t, err, ErrHoldNotFound)
+
+	// one hold per partner transaction
+	_, err = repository.CreateHold(depositPort.HoldRepo{PartnerCode: "partner", TransactionId: "trx", Amount: 500, Status: depositPort.HoldStatusHeld})
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+
+	// a hold moves on only from the expected status
+	updated, err := repository.UpdateHoldStatus(hold.ID, depositPort.HoldStatusHeld, depositPort.HoldStatusCaptured, "issuer-trx")
+	if assert.NoError(t, err) {
+		assert.True(t, updated)
+	}
+	updated, err = repository.UpdateHoldStatus(hold.ID, depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, "")
+	if assert.NoError(t, err) {
+		assert.False(t, updated)
+	}
+	read, err = repository.FindHoldByIssuerTransaction("partner", "issuer-trx")
+	if assert.NoError(t, err) {
+		assert.Equal(t, hold.ID, read.ID)
+		assert.Equal(t, depositPort.HoldStatusCaptured, read.Status)
+	}
+	updated, err = repository.UpdateHoldStatus(missingID(), depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, "")
+	if assert.NoError(t, err) {
+		assert.False(t, updated)
+	}
+	_, err = repository.ReadHold(missingID())
+	assert.EqualError(t, err, ErrHoldNotFound)
+	_, err = repository.ReadHold("x")
+	assert.EqualError(t, err, ErrInvalidID)
+	_, err = repository.UpdateHoldStatus("x", depositPort.HoldStatusHeld, depositPort.HoldStatusReleased, "")
+	assert.EqualError(t, err, ErrInvalidID)
+
+	for _, entry := range []depositPort.EntryRepo{
+		{PartnerCode: "partner", Type: depositPort.EntryTopup, DebitAccount: depositPort.AccountCash, CreditAccount: depositPort.AccountAvailable, Amount: 1500},
+		{PartnerCode: "other", Type: depositPort.EntryTopup, DebitAccount: depositPort.AccountCash, CreditAccount: depositPort.AccountAvailable, Amount: 10},
+		{PartnerCode: "partner", Type: depositPort.EntryHold, DebitAccount: depositPort.AccountAvailable, CreditAccount: depositPort.AccountHeld, Amount: 1000, Reference: "trx"},
+	} {
+		require.NoError(t, repository.CreateEntry(entry))
+		time.Sleep(2 * time.Millisecond)
+	}
+	entries, err := repository.ListEntries("partner")
+	if assert.NoError(t, err) && assert.Len(t, entries, 2) {
+		assert.Equal(t, depositPort.EntryHold, entries[0].Type)
+		assert.Equal(t, "trx", entries[0].Reference)
+		assert.Equal(t, depositPort.EntryTopup, entries[1].Type)
+		assert.NotEmpty(t, entries[1].ID)
+	}
+	entries, err = repository.ListEntries("nobody")
+	if assert.NoError(t, err) {
+		assert.Empty(t, entries)
+	}
+}
@@ -0,0 +1,158 @@
+package conformance
+
+import (
+	"testing"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/circuitbreaker"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrIssuerNotFound  = "Issuer not found"
+	ErrBalanceNotFound = "Issuer balance not reported yet"
+)
+
+// Issuer checks an issuer repository
+func Issuer(t *testing.T, repository issuerPort.Repository) {
+	assert.Empty(t, repository.FindByCode("alpha").ID)
+	for _, code := range []string{"beta", "alpha", "gamma"} {
+		require.NoError(t, repository.CreateData(issuerPort.IssuerRepo{
+			Code:              code,
+			Label:             "Issuer " + code,
+			Status:            issuerPort.StatusActive,
+			ThreadNum:         2,
+			CircuitBreaker:    issuerPort.CircuitBreaker{ErrorRate: 0.5, Window: 60},
+			SettlementMapping: issuerPort.SettlementMapping{Amount: "amount", SuccessStatus: []string{"OK"}},
+		}))
+	}
+
+	issuer := repository.FindByCode("alpha")
+	require.NotEmpty(t, issuer.ID)
+	assert.Equal(t, "Issuer alpha", issuer.Label)
+	assert.Equal(t, 2, issuer.ThreadNum)
+	assert.Equal(t, issuerPort.CircuitBreaker{ErrorRate: 0.5, Window: 60}, issuer.CircuitBreaker)
+	assert.Equal(t, []string{"OK"}, issuer.SettlementMapping.SuccessStatus)
+	read, err := repository.ReadData(issuer.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, issuer, read)
+	}
+
+	// the circuit is only forced open through UpdateCircuitForcedOpen
+	assert.NoError(t, repository.UpdateCircuitForcedOpen(issuer.ID, true))
+	issuer.Label = "Alpha"
+	issuer.Status = issuerPort.StatusInactive
+	assert.NoError(t, repository.UpdateData(issuer))
+	read, err = repository.ReadData(issuer.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Alpha", read.Label)
+		assert.Equal(t, issuerPort.StatusInactive, read.Status)
+		assert.True(t, read.CircuitForcedOpen)
+		assert.Equal(t, 1, read.Version)
+	}
+	issuer.Label = "Stale"
+	assertConflict(t, repository.UpdateData(issuer))
+
+	issuers, page, err := repository.SearchData(query.Query{Sort: "code", Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, issuers, 2) {
+		assert.Equal(t, "alpha", issuers[0].Code)
+		assert.Equal(t, "beta", issuers[1].Code)
+		assert.Equal(t, 3, page.Total)
+		assert.NotEmpty(t, page.NextCursor)
+	}
+	issuers, page, err = repository.SearchData(query.Query{Sort: "code", Limit: 2, Cursor: page.NextCursor})
+	if assert.NoError(t, err) && assert.Len(t, issuers, 1) {
+		assert.Equal(t, "gamma", issuers[0].Code)
+		assert.Empty(t, page.NextCursor)
+	}
+	issuers, _, err = repository.SearchData(query.Query{Sort: "-code", Page: 2, Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, issuers, 1) {
+		assert.Equal(t, "alpha", issuers[0].Code)
+	}
+	issuers, page, err = repository.SearchData(query.Query{Search: "ISSUER G", Filters: map[string]string{"status": issuerPort.StatusActive}})
+	if assert.NoError(t, err) && assert.Len(t, issuers, 1) {
+		assert.Equal(t, "gamma", issuers[0].Code)
+		assert.Equal(t, 1, page.Total)
+	}
+	_, _, err = repository.SearchData(query.Query{Sort: "config"})
+	assert.EqualError(t, err, query.ErrInvalidSort)
+	_, _, err = repository.SearchData(query.Query{Filters: map[string]string{"config": "x"}})
+	assert.EqualError(t, err, query.ErrInvalidFilter)
+	_, _, err = repository.SearchData(query.Query{Cursor: "x"})
+	assert.EqualError(t, err, query.ErrInvalidCursor)
+
+	assert.NoError(t, repository.DeleteData(issuer.ID))
+	assert.EqualError(t, repository.DeleteData(issuer.ID), ErrIssuerNotFound)
+	assert.Empty(t, repository.FindByCode("alpha").ID)
+	_, err = repository.ReadData(issuer.ID)
+	assert.EqualError(t, err, ErrIssuerNotFound)
+	assert.EqualError(t, repository.UpdateData(read), ErrIssuerNotFound)
+	issuers, err = repository.ListData()
+	if assert.NoError(t, err) {
+		assert.Len(t, issuers, 2)
+	}
+
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrInvalidID)
+	assert.EqualError(t, repository.DeleteData("x"), ErrInvalidID)
+	assert.EqualError(t, repository.UpdateData(issuerPort.IssuerRepo{ID: missingID()}), ErrIssuerNotFound)
+	assert.EqualError(t, repository.UpdateCircuitForcedOpen(missingID(), true), ErrIssuerNotFound)
+}
+
+// IssuerCircuit checks an issuer circuit repository
+func IssuerCircuit(t *testing.T, repository issuerPort.CircuitRepository) {
+	state, err := repository.FindByIssuerCode("alpha")
+	if assert.NoError(t, err) {
+		assert.Equal(t, issuerPort.CircuitState{IssuerCode: "alpha", State: circuitbreaker.StateClosed}, state)
+	}
+
+	for _, calls := range []int{10, 20} {
+		assert.NoError(t, repository.SaveState(issuerPort.CircuitState{IssuerCode: "alpha", State: circuitbreaker.StateOpen, Calls: calls, Failures: 5}))
+	}
+	state, err = repository.FindByIssuerCode("alpha")
+	if assert.NoError(t, err) {
+		assert.Equal(t, circuitbreaker.StateOpen, state.State)
+		assert.Equal(t, 20, state.Calls)
+		assert.Equal(t, 5, state.Failures)
+	}
+}
+
+// IssuerBalance checks an issuer balance repository
+func IssuerBalance(t *testing.T, repository issuerPort.BalanceRepository) {
+	_, err := repository.FindByIssuerCode("alpha")
+	assert.EqualError(t, err, ErrBalanceNotFound)
+	_, deducted, err := repository.DeductBalance("alpha", 100)
+	assert.NoError(t, err)
+	assert.False(t, deducted)
+	alerted, err := repository.UpdateAlerted("alpha", true)
+	assert.NoError(t, err)
+	assert.False(t, alerted)
+
+	balance, err := repository.SaveBalance("alpha", 1000, issuerPort.BalanceSourceInquiry)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1000), balance.Balance)
+		assert.Equal(t, issuerPort.BalanceSourceInquiry, balance.Source)
+	}
+	balance, deducted, err = repository.DeductBalance("alpha", 300)
+	if assert.NoError(t, err) && assert.True(t, deducted) {
+		assert.Equal(t, int64(700), balance.Balance)
+		assert.Equal(t, issuerPort.BalanceSourceEstimate, balance.Source)
+	}
+
+	alerted, err = repository.UpdateAlerted("alpha", true)
+	assert.NoError(t, err)
+	assert.True(t, alerted)
+	alerted, err = repository.UpdateAlerted("alpha", true)
+	assert.NoError(t, err)
+	assert.False(t, alerted)
+
+	// a reported balance keeps the alert flag
+	balance, err = repository.SaveBalance("alpha", 50, issuerPort.BalanceSourceResponse)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(50), balance.Balance)
+		assert.True(t, balance.Alerted)
+	}
+}
@@ -0,0 +1,112 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrOrderNotFound = "Order not found"
+	ErrInvalidCursor = "Invalid cursor"
+)
+
+// Order checks an order repository
+func Order(t *testing.T, repository orderPort.Repository) {
+	from := time.Now().Add(-time.Second)
+	orders := []orderPort.OrderRepo{
+		{CommandType: orderPort.Purchase, TransactionId: "trx-1", PartnerId: "partner", IssuerId: "issuer", IssuerTransactionId: "issuer-trx-1", IssuerRescode: orderPort.RescodePending, CustomerNumber: "0811"},
+		{CommandType: orderPort.Advise, TransactionId: "trx-advise", PartnerId: "partner", IssuerId: "issuer", IssuerTransactionId: "issuer-trx-1", IssuerRescode: orderPort.RescodeSuccess},
+		{CommandType: orderPort.Purchase, TransactionId: "trx-2", PartnerId: "partner", IssuerId: "other", IssuerRescode: "14", Price: orderPort.OrderPrice{PriceId: "price", BasePrice: 9000, SellingPrice: 10000, Fee: 500}},
+		{CommandType: orderPort.Purchase, TransactionId: "trx-1", PartnerId: "other", IssuerId: "issuer", IssuerRescode: orderPort.RescodeSuccess},
+	}
+	for _, order := range orders {
+		require.NoError(t, repository.CreateData(order))
+		// distinct creation times keep the expected order on millisecond clocks
+		time.Sleep(2 * time.Millisecond)
+	}
+	until := time.Now().Add(time.Second)
+
+	purchases, err := repository.ListPurchases(orderPort.PurchaseFilter{PartnerId: "partner", From: from, Until: until})
+	if assert.NoError(t, err) && assert.Len(t, purchases, 2) {
+		assert.Equal(t, "trx-1", purchases[0].TransactionId)
+		assert.Equal(t, "trx-2", purchases[1].TransactionId)
+		assert.Equal(t, orderPort.OrderPrice{PriceId: "price", BasePrice: 9000, SellingPrice: 10000, Fee: 500}, purchases[1].Price)
+	}
+	purchases, err = repository.ListPurchases(orderPort.PurchaseFilter{IssuerId: "issuer", From: from, Until: until})
+	if assert.NoError(t, err) {
+		assert.Len(t, purchases, 2)
+	}
+	purchases, err = repository.ListPurchases(orderPort.PurchaseFilter{From: until, Until: until.Add(time.Hour)})
+	if assert.NoError(t, err) {
+		assert.Empty(t, purchases)
+	}
+
+	// newest first unless sorted on created_at
+	found, next, err := repository.SearchData(orderPort.OrderFilter{Limit: 3})
+	if assert.NoError(t, err) && assert.Len(t, found, 3) {
+		assert.Equal(t, "other", found[0].PartnerId)
+		assert.Equal(t, "trx-2", found[1].TransactionId)
+		assert.NotEmpty(t, next)
+	}
+	found, next, err = repository.SearchData(orderPort.OrderFilter{Limit: 3, Cursor: next})
+	if assert.NoError(t, err) && assert.Len(t, found, 1) {
+		assert.Equal(t, "trx-1", found[0].TransactionId)
+		assert.Empty(t, next)
+	}
+	found, _, err = repository.SearchData(orderPort.OrderFilter{Sort: orderPort.SortCreatedAt, Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, found, 2) {
+		assert.Equal(t, "trx-1", found[0].TransactionId)
+		assert.Equal(t, "trx-advise", found[1].TransactionId)
+	}
+
+	for status, count := range map[string]int{orderPort.StatusSuccess: 2, orderPort.StatusPending: 1, orderPort.StatusFailed: 1} {
+		found, _, err = repository.SearchData(orderPort.OrderFilter{Status: status, Limit: 20})
+		if assert.NoError(t, err) {
+			assert.Len(t, found, count, status)
+		}
+	}
+	found, _, err = repository.SearchData(orderPort.OrderFilter{PartnerId: "partner", CommandType: orderPort.Purchase, CustomerNumber: "0811", Limit: 20})
+	if assert.NoError(t, err) {
+		assert.Len(t, found, 1)
+	}
+	found, _, err = repository.SearchData(orderPort.OrderFilter{IssuerTransactionId: "issuer-trx-1", From: from, Until: until, Limit: 20})
+	if assert.NoError(t, err) {
+		assert.Len(t, found, 2)
+	}
+	_, _, err = repository.SearchData(orderPort.OrderFilter{Cursor: "x", Limit: 20})
+	assert.EqualError(t, err, ErrInvalidCursor)
+
+	found, _, _ = repository.SearchData(orderPort.OrderFilter{TransactionId: "trx-1", PartnerId: "partner", Limit: 20})
+	require.Len(t, found, 1)
+	order, err := repository.ReadData(found[0].ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "issuer-trx-1", order.IssuerTransactionId)
+		assert.Equal(t, orderPort.RescodePending, order.IssuerRescode)
+	}
+	timeline, err := repository.ListTimeline("partner", "trx-1", []string{"issuer-trx-1"})
+	if assert.NoError(t, err) && assert.Len(t, timeline, 2) {
+		assert.Equal(t, orderPort.Purchase, timeline[0].CommandType)
+		assert.Equal(t, orderPort.Advise, timeline[1].CommandType)
+	}
+
+	// removed records are gone, unknown ids are ignored
+	removed, err := repository.RemoveData([]string{found[0].ID, missingID(), "x"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, removed)
+	}
+	_, err = repository.ReadData(found[0].ID)
+	assert.EqualError(t, err, ErrOrderNotFound)
+	found, _, err = repository.SearchData(orderPort.OrderFilter{Limit: 20})
+	if assert.NoError(t, err) {
+		assert.Len(t, found, 3)
+	}
+
+	_, err = repository.ReadData(missingID())
+	assert.EqualError(t, err, ErrOrderNotFound)
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrInvalidID)
+}
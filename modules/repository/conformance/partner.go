@@ -0,0 +1,154 @@
+package conformance
+
+import (
+	"testing"
+
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrPartnerNotFound       = "Partner not found"
+	ErrPartnerIssuerNotFound = "Partner Issuer not found"
+)
+
+// Partner checks a partner repository
+func Partner(t *testing.T, repository partnerPort.Repository) {
+	assert.Empty(t, repository.FindByCode("alpha").ID)
+	for _, code := range []string{"beta", "alpha", "gamma"} {
+		require.NoError(t, repository.CreateData(partnerPort.PartnerRepo{
+			Code:            code,
+			Name:            "Partner " + code,
+			Pic:             "pic@" + code,
+			Status:          "active",
+			IpWhitelist:     []string{"127.0.0.1"},
+			SecretKey:       "secret-" + code,
+			RateLimit:       partnerPort.RateLimit{Rate: 10, Burst: 20},
+			IssuerRateLimit: map[string]partnerPort.RateLimit{"issuer": {Rate: 1, Burst: 2}},
+		}))
+	}
+
+	partner := repository.FindByCode("alpha")
+	require.NotEmpty(t, partner.ID)
+	assert.Equal(t, "Partner alpha", partner.Name)
+	assert.Equal(t, []string{"127.0.0.1"}, partner.IpWhitelist)
+	assert.Equal(t, "secret-alpha", partner.SecretKey)
+	assert.Equal(t, partnerPort.RateLimit{Rate: 10, Burst: 20}, partner.RateLimit)
+	assert.Equal(t, map[string]partnerPort.RateLimit{"issuer": {Rate: 1, Burst: 2}}, partner.IssuerRateLimit)
+	read, err := repository.ReadData(partner.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, partner.Code, read.Code)
+		assert.Equal(t, partner.IssuerRateLimit, read.IssuerRateLimit)
+	}
+
+	// issuer rate limits left out are removed
+	partner.Name = "Alpha"
+	partner.IpWhitelist = []string{"10.0.0.1", "10.0.0.2"}
+	partner.IssuerRateLimit = nil
+	assert.NoError(t, repository.UpdateData(partner))
+	read, err = repository.ReadData(partner.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Alpha", read.Name)
+		assert.Equal(t, []string{"10.0.0.1", "10.0.0.2"}, read.IpWhitelist)
+		assert.Empty(t, read.IssuerRateLimit)
+		assert.Equal(t, 1, read.Version)
+	}
+	partner.Name = "Stale"
+	assertConflict(t, repository.UpdateData(partner))
+
+	partners, page, err := repository.SearchData(query.Query{Sort: "-code", Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, partners, 2) {
+		assert.Equal(t, "gamma", partners[0].Code)
+		assert.Equal(t, "beta", partners[1].Code)
+		assert.Equal(t, 3, page.Total)
+	}
+	partners, page, err = repository.SearchData(query.Query{Sort: "-code", Limit: 2, Cursor: page.NextCursor})
+	if assert.NoError(t, err) && assert.Len(t, partners, 1) {
+		assert.Equal(t, "alpha", partners[0].Code)
+		assert.Empty(t, page.NextCursor)
+	}
+	partners, _, err = repository.SearchData(query.Query{Search: "PIC@BE"})
+	if assert.NoError(t, err) && assert.Len(t, partners, 1) {
+		assert.Equal(t, "beta", partners[0].Code)
+	}
+	_, _, err = repository.SearchData(query.Query{Sort: "secret_key"})
+	assert.EqualError(t, err, query.ErrInvalidSort)
+
+	assert.NoError(t, repository.DeleteData(partner.ID))
+	assert.EqualError(t, repository.DeleteData(partner.ID), ErrPartnerNotFound)
+	assert.Empty(t, repository.FindByCode("alpha").ID)
+	_, err = repository.ReadData(partner.ID)
+	assert.EqualError(t, err, ErrPartnerNotFound)
+	assert.EqualError(t, repository.UpdateData(read), ErrPartnerNotFound)
+	partners, err = repository.ListData()
+	if assert.NoError(t, err) {
+		assert.Len(t, partners, 2)
+	}
+
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrInvalidID)
+	assert.EqualError(t, repository.DeleteData("x"), ErrInvalidID)
+	assert.EqualError(t, repository.UpdateData(partnerPort.PartnerRepo{ID: missingID()}), ErrPartnerNotFound)
+}
+
+// PartnerIssuer checks a partner issuer repository
+func PartnerIssuer(t *testing.T, repository partnerIssuerPort.Repository) {
+	partnerId, issuerId, otherIssuerId := missingID(), missingID(), missingID()
+	_, err := repository.FindByPartnerIssuerID(partnerId, issuerId)
+	assert.EqualError(t, err, ErrPartnerIssuerNotFound)
+	require.NoError(t, repository.CreateData(partnerIssuerPort.PartnerIssuerRepo{PartnerId: partnerId, IssuerId: issuerId, Config: "{}", ReservedThread: 1, MaxThread: 4}))
+	require.NoError(t, repository.CreateData(partnerIssuerPort.PartnerIssuerRepo{PartnerId: partnerId, IssuerId: otherIssuerId}))
+	require.NoError(t, repository.CreateData(partnerIssuerPort.PartnerIssuerRepo{PartnerId: missingID(), IssuerId: issuerId}))
+
+	partnerIssuer, err := repository.FindByPartnerIssuerID(partnerId, issuerId)
+	require.NoError(t, err)
+	assert.NotEmpty(t, partnerIssuer.ID)
+	assert.Equal(t, "{}", partnerIssuer.Config)
+	assert.Equal(t, 1, partnerIssuer.ReservedThread)
+	assert.Equal(t, 4, partnerIssuer.MaxThread)
+	read, err := repository.ReadData(partnerIssuer.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, partnerIssuer.IssuerId, read.IssuerId)
+	}
+
+	partnerIssuer.MaxThread = 8
+	assert.NoError(t, repository.UpdateData(partnerIssuer))
+	read, err = repository.ReadData(partnerIssuer.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 8, read.MaxThread)
+		assert.Equal(t, 1, read.Version)
+	}
+	partnerIssuer.MaxThread = 2
+	assertConflict(t, repository.UpdateData(partnerIssuer))
+
+	partnerIssuers, page, err := repository.SearchData(query.Query{Filters: map[string]string{"partner_id": partnerId}})
+	if assert.NoError(t, err) {
+		assert.Len(t, partnerIssuers, 2)
+		assert.Equal(t, 2, page.Total)
+	}
+	partnerIssuers, _, err = repository.SearchData(query.Query{Sort: "created_at", Filters: map[string]string{"issuer_id": issuerId, "partner_id": ""}})
+	if assert.NoError(t, err) {
+		assert.Len(t, partnerIssuers, 2)
+	}
+	_, _, err = repository.SearchData(query.Query{Filters: map[string]string{"config": "{}"}})
+	assert.EqualError(t, err, query.ErrInvalidFilter)
+
+	assert.NoError(t, repository.DeleteData(partnerIssuer.ID))
+	assert.EqualError(t, repository.DeleteData(partnerIssuer.ID), ErrPartnerIssuerNotFound)
+	_, err = repository.FindByPartnerIssuerID(partnerId, issuerId)
+	assert.EqualError(t, err, ErrPartnerIssuerNotFound)
+	_, err = repository.ReadData(partnerIssuer.ID)
+	assert.EqualError(t, err, ErrPartnerIssuerNotFound)
+	assert.EqualError(t, repository.UpdateData(read), ErrPartnerIssuerNotFound)
+	partnerIssuers, err = repository.ListData()
+	if assert.NoError(t, err) {
+		assert.Len(t, partnerIssuers, 2)
+	}
+
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrInvalidID)
+	assert.EqualError(t, repository.UpdateData(partnerIssuerPort.PartnerIssuerRepo{ID: missingID()}), ErrPartnerIssuerNotFound)
+}
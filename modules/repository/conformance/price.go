@@ -0,0 +1,67 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrPriceNotFound = "Price not found"
+)
+
+// Price checks a price repository
+func Price(t *testing.T, repository pricePort.Repository) {
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, price := range []pricePort.PriceRepo{
+		{PartnerCode: "partner", ProductCode: "product", BasePrice: 9000, Margin: 500, EffectiveFrom: now.Add(-time.Hour), EffectiveUntil: now},
+		{PartnerCode: "partner", ProductCode: "product", BasePrice: 9100, MarginPercent: 2.5, Fee: 100, EffectiveFrom: now},
+		{PartnerCode: "partner", ProductCode: "other", BasePrice: 5000, SellingPrice: 5500, EffectiveFrom: now},
+	} {
+		require.NoError(t, repository.CreateData(price))
+	}
+
+	// latest effective first
+	prices, err := repository.FindByPartnerProduct("partner", "product")
+	require.NoError(t, err)
+	require.Len(t, prices, 2)
+	price := prices[0]
+	assert.Equal(t, int64(9100), price.BasePrice)
+	assert.Equal(t, 2.5, price.MarginPercent)
+	assert.Equal(t, int64(100), price.Fee)
+	assert.True(t, now.Equal(price.EffectiveFrom))
+	assert.True(t, now.Equal(prices[1].EffectiveUntil))
+
+	price.BasePrice = 9200
+	price.EffectiveUntil = now.Add(time.Hour)
+	assert.NoError(t, repository.UpdateData(price))
+	read, err := repository.ReadData(price.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(9200), read.BasePrice)
+		assert.True(t, now.Add(time.Hour).Equal(read.EffectiveUntil))
+	}
+
+	prices, err = repository.ListData()
+	if assert.NoError(t, err) && assert.Len(t, prices, 3) {
+		assert.Equal(t, "other", prices[0].ProductCode)
+		assert.Equal(t, price.ID, prices[1].ID)
+	}
+
+	assert.NoError(t, repository.DeleteData(price.ID))
+	_, err = repository.ReadData(price.ID)
+	assert.EqualError(t, err, ErrPriceNotFound)
+	assert.EqualError(t, repository.DeleteData(price.ID), ErrPriceNotFound)
+	prices, err = repository.FindByPartnerProduct("partner", "product")
+	if assert.NoError(t, err) {
+		assert.Len(t, prices, 1)
+	}
+
+	_, err = repository.ReadData(missingID())
+	assert.EqualError(t, err, ErrPriceNotFound)
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrInvalidID)
+	assert.EqualError(t, repository.DeleteData("x"), ErrInvalidID)
+}
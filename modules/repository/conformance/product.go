@@ -0,0 +1,69 @@
+package conformance
+
+import (
+	"testing"
+
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrProductNotFound = "Product not found"
+)
+
+// Product checks a product repository
+func Product(t *testing.T, repository productPort.Repository) {
+	assert.Empty(t, repository.FindByCode("TSEL10").ID)
+	for _, product := range []productPort.ProductRepo{
+		{Code: "TSEL10", Name: "Telkomsel 10k", Operator: "telkomsel", Type: productPort.TypePulsa, Denomination: 10000, Active: true,
+			Issuers: []productPort.IssuerProduct{{IssuerCode: "issuer", IssuerProductId: "S10"}}},
+		{Code: "TSEL5", Name: "Telkomsel 5k", Operator: "telkomsel", Type: productPort.TypePulsa, Denomination: 5000},
+		{Code: "ISAT5", Name: "Indosat 5k", Operator: "indosat", Type: productPort.TypePulsa, Denomination: 5000, Active: true},
+	} {
+		require.NoError(t, repository.CreateData(product))
+	}
+
+	product := repository.FindByCode("TSEL10")
+	require.NotEmpty(t, product.ID)
+	assert.Equal(t, "Telkomsel 10k", product.Name)
+	assert.True(t, product.Active)
+	assert.Equal(t, []productPort.IssuerProduct{{IssuerCode: "issuer", IssuerProductId: "S10"}}, product.Issuers)
+
+	product.Name = "Telkomsel 10.000"
+	product.Issuers = append(product.Issuers, productPort.IssuerProduct{IssuerCode: "backup", IssuerProductId: "T10"})
+	assert.NoError(t, repository.UpdateData(product))
+	read, err := repository.ReadData(product.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Telkomsel 10.000", read.Name)
+		assert.Len(t, read.Issuers, 2)
+	}
+
+	// sorted on operator, type and denomination
+	products, err := repository.ListData()
+	if assert.NoError(t, err) && assert.Len(t, products, 3) {
+		assert.Equal(t, "ISAT5", products[0].Code)
+		assert.Equal(t, "TSEL5", products[1].Code)
+		assert.Equal(t, "TSEL10", products[2].Code)
+	}
+	products, err = repository.ListActive()
+	if assert.NoError(t, err) {
+		assert.Len(t, products, 2)
+	}
+
+	assert.NoError(t, repository.DeleteData(product.ID))
+	_, err = repository.ReadData(product.ID)
+	assert.EqualError(t, err, ErrProductNotFound)
+	assert.EqualError(t, repository.DeleteData(product.ID), ErrProductNotFound)
+	assert.Empty(t, repository.FindByCode("TSEL10").ID)
+	products, err = repository.ListActive()
+	if assert.NoError(t, err) {
+		assert.Len(t, products, 1)
+	}
+
+	_, err = repository.ReadData(missingID())
+	assert.EqualError(t, err, ErrProductNotFound)
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrInvalidID)
+	assert.EqualError(t, repository.DeleteData("x"), ErrInvalidID)
+}
@@ -0,0 +1,56 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+	"github.com/stretchr/testify/assert"
+)
+
+// RateLimit checks a rate limit repository
+func RateLimit(t *testing.T, repository ratelimitPort.Repository) {
+	now := time.Date(2021, 9, 8, 10, 0, 0, 0, time.UTC)
+	limits := map[string]partnerPort.RateLimit{"partner": {Rate: 1, Burst: 2}}
+
+	for i := 0; i < 2; i++ {
+		allowed, _, err := repository.Take("partner", limits, now)
+		if assert.NoError(t, err) {
+			assert.True(t, allowed)
+		}
+	}
+	allowed, retryAfter, err := repository.Take("partner", limits, now)
+	if assert.NoError(t, err) {
+		assert.False(t, allowed)
+		assert.True(t, retryAfter > 0)
+	}
+
+	// buckets are kept per key and refill over time
+	allowed, _, err = repository.Take("other", limits, now)
+	if assert.NoError(t, err) {
+		assert.True(t, allowed)
+	}
+	allowed, _, err = repository.Take("partner", limits, now.Add(time.Second))
+	if assert.NoError(t, err) {
+		assert.True(t, allowed)
+	}
+
+	// buckets of a key are taken together, a request rejected by one bucket costs no token of the other
+	limits = map[string]partnerPort.RateLimit{
+		"partner":      {Rate: 1, Burst: 2},
+		"issuer:dummy": {Rate: 1, Burst: 1},
+	}
+	allowed, _, err = repository.Take("both", limits, now)
+	if assert.NoError(t, err) {
+		assert.True(t, allowed)
+	}
+	allowed, _, err = repository.Take("both", limits, now)
+	if assert.NoError(t, err) {
+		assert.False(t, allowed)
+	}
+	allowed, _, err = repository.Take("both", map[string]partnerPort.RateLimit{"partner": limits["partner"]}, now)
+	if assert.NoError(t, err) {
+		assert.True(t, allowed)
+	}
+}
@@ -0,0 +1,71 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrReconciliationNotFound = "Reconciliation not found"
+)
+
+// Reconciliation checks a reconciliation repository
+func Reconciliation(t *testing.T, repository reconciliationPort.Repository) {
+	date := time.Date(2021, 9, 8, 0, 0, 0, 0, time.UTC)
+	var runs []reconciliationPort.RunRepo
+	for _, issuerCode := range []string{"issuer", "other", "issuer"} {
+		run, err := repository.CreateRun(reconciliationPort.RunRepo{IssuerCode: issuerCode, FileName: issuerCode + ".csv", Date: date, Rows: 2, Matched: 1, AmountMismatch: 1})
+		require.NoError(t, err)
+		require.NotEmpty(t, run.ID)
+		assert.False(t, run.CreatedAt.IsZero())
+		runs = append(runs, run)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	run, err := repository.ReadRun(runs[0].ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "issuer.csv", run.FileName)
+		assert.True(t, date.Equal(run.Date))
+		assert.Equal(t, 1, run.AmountMismatch)
+	}
+
+	// newest first
+	list, err := repository.ListRuns("issuer")
+	if assert.NoError(t, err) && assert.Len(t, list, 2) {
+		assert.Equal(t, runs[2].ID, list[0].ID)
+		assert.Equal(t, runs[0].ID, list[1].ID)
+	}
+	list, err = repository.ListRuns("")
+	if assert.NoError(t, err) {
+		assert.Len(t, list, 3)
+	}
+
+	require.NoError(t, repository.CreateItems([]reconciliationPort.ItemRepo{
+		{RunId: runs[0].ID, Class: reconciliationPort.ClassMatched, Row: 1, TransactionId: "trx-1", Amount: 9000, IssuerAmount: 9000, Status: reconciliationPort.StatusSuccess, IssuerStatus: reconciliationPort.StatusSuccess},
+		{RunId: runs[0].ID, Class: reconciliationPort.ClassAmountMismatch, Row: 2, TransactionId: "trx-2", Amount: 9000, IssuerAmount: 9500},
+		{RunId: runs[1].ID, Class: reconciliationPort.ClassMatched, Row: 1, TransactionId: "trx-3"},
+	}))
+	items, err := repository.ListItems(runs[0].ID, "")
+	if assert.NoError(t, err) && assert.Len(t, items, 2) {
+		assert.Equal(t, "trx-1", items[0].TransactionId)
+		assert.Equal(t, "trx-2", items[1].TransactionId)
+		assert.Equal(t, int64(9500), items[1].IssuerAmount)
+	}
+	items, err = repository.ListItems(runs[0].ID, reconciliationPort.ClassAmountMismatch)
+	if assert.NoError(t, err) && assert.Len(t, items, 1) {
+		assert.Equal(t, 2, items[0].Row)
+	}
+	items, err = repository.ListItems(runs[2].ID, "")
+	if assert.NoError(t, err) {
+		assert.Empty(t, items)
+	}
+
+	_, err = repository.ReadRun(missingID())
+	assert.EqualError(t, err, ErrReconciliationNotFound)
+	_, err = repository.ReadRun("x")
+	assert.EqualError(t, err, ErrInvalidID)
+}
@@ -0,0 +1,63 @@
+package conformance
+
+import (
+	"testing"
+
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrRouteNotFound = "Route not found"
+)
+
+// Route checks a route repository
+func Route(t *testing.T, repository routePort.Repository) {
+	assert.Empty(t, repository.FindByProductCode("TSEL10").ID)
+	require.NoError(t, repository.CreateData(routePort.RouteRepo{
+		ProductCode: "TSEL10",
+		Candidates: []routePort.Candidate{
+			{IssuerCode: "issuer", IssuerProductId: "S10", Priority: 1, Weight: 70},
+			{IssuerCode: "backup", IssuerProductId: "T10", Priority: 1, Weight: 30},
+		},
+		FailoverRescodes: []string{"68"},
+	}))
+	require.NoError(t, repository.CreateData(routePort.RouteRepo{ProductCode: "ISAT5"}))
+
+	route := repository.FindByProductCode("TSEL10")
+	require.NotEmpty(t, route.ID)
+	assert.Len(t, route.Candidates, 2)
+	assert.Equal(t, routePort.Candidate{IssuerCode: "backup", IssuerProductId: "T10", Priority: 1, Weight: 30}, route.Candidates[1])
+	assert.Equal(t, []string{"68"}, route.FailoverRescodes)
+
+	route.Candidates = route.Candidates[:1]
+	route.FailoverRescodes = []string{"68", "06"}
+	assert.NoError(t, repository.UpdateData(route))
+	read, err := repository.ReadData(route.ID)
+	if assert.NoError(t, err) {
+		assert.Len(t, read.Candidates, 1)
+		assert.Equal(t, []string{"68", "06"}, read.FailoverRescodes)
+	}
+
+	routes, err := repository.ListData()
+	if assert.NoError(t, err) {
+		assert.Len(t, routes, 2)
+	}
+
+	assert.NoError(t, repository.DeleteData(route.ID))
+	_, err = repository.ReadData(route.ID)
+	assert.EqualError(t, err, ErrRouteNotFound)
+	assert.EqualError(t, repository.DeleteData(route.ID), ErrRouteNotFound)
+	assert.Empty(t, repository.FindByProductCode("TSEL10").ID)
+	routes, err = repository.ListData()
+	if assert.NoError(t, err) {
+		assert.Len(t, routes, 1)
+	}
+
+	_, err = repository.ReadData(missingID())
+	assert.EqualError(t, err, ErrRouteNotFound)
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrInvalidID)
+	assert.EqualError(t, repository.DeleteData("x"), ErrInvalidID)
+}
@@ -0,0 +1,162 @@
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	authPort "github.com/sepulsa/teleco/business/auth/port"
+	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ErrUserNotFound      = "user not found"
+	ErrUserInvalidID     = "invalid id"
+	ErrUserTokenNotFound = "Token not found"
+)
+
+// User checks a user repository
+func User(t *testing.T, repository userPort.Repository) {
+	assert.Empty(t, repository.FindByEmail("alice@example.com").ID)
+	for _, name := range []string{"bob", "alice", "carol"} {
+		require.NoError(t, repository.CreateData(userPort.UserRepo{
+			Email:    name + "@example.com",
+			Fullname: "User " + name,
+			Password: "hash-" + name,
+		}))
+	}
+
+	user := repository.FindByEmail("alice@example.com")
+	require.NotEmpty(t, user.ID)
+	assert.Equal(t, "User alice", user.Fullname)
+	assert.Equal(t, "hash-alice", user.Password)
+	read, err := repository.ReadData(user.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, user.Email, read.Email)
+	}
+
+	// a reset token is found until it expires or the password changes
+	require.NoError(t, repository.UpdateResetToken(user.ID, "token-hash", time.Now().Add(time.Hour)))
+	assert.Equal(t, user.ID, repository.FindByResetToken("token-hash").ID)
+	assert.Empty(t, repository.FindByResetToken("other-hash").ID)
+	// an update without password keeps the reset token
+	user.Fullname = "Alice"
+	user.Password = ""
+	assert.NoError(t, repository.UpdateData(user))
+	assert.Equal(t, user.ID, repository.FindByResetToken("token-hash").ID)
+	// the version read before the update is stale
+	user.Fullname = "Stale"
+	assertConflict(t, repository.UpdateData(user))
+	user, err = repository.ReadData(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, user.Version)
+	user.Password = "new-hash"
+	user.PasswordHistory = []string{"hash-alice"}
+	assert.NoError(t, repository.UpdateData(user))
+	assert.Empty(t, repository.FindByResetToken("token-hash").ID)
+	read, err = repository.ReadData(user.ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Alice", read.Fullname)
+		assert.Equal(t, "new-hash", read.Password)
+		assert.Equal(t, []string{"hash-alice"}, read.PasswordHistory)
+	}
+	require.NoError(t, repository.UpdateResetToken(user.ID, "expired-hash", time.Now().Add(-time.Hour)))
+	assert.Empty(t, repository.FindByResetToken("expired-hash").ID)
+
+	users, page, err := repository.SearchData(query.Query{Sort: "-email", Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, users, 2) {
+		assert.Equal(t, "carol@example.com", users[0].Email)
+		assert.Equal(t, "bob@example.com", users[1].Email)
+		assert.Empty(t, users[0].Password)
+		assert.Equal(t, 3, page.Total)
+		assert.NotEmpty(t, page.NextCursor)
+	}
+	users, page, err = repository.SearchData(query.Query{Sort: "-email", Limit: 2, Cursor: page.NextCursor})
+	if assert.NoError(t, err) && assert.Len(t, users, 1) {
+		assert.Equal(t, "alice@example.com", users[0].Email)
+		assert.Equal(t, 2, users[0].Version)
+		assert.Empty(t, page.NextCursor)
+	}
+	users, _, err = repository.SearchData(query.Query{Search: "CAROL"})
+	if assert.NoError(t, err) && assert.Len(t, users, 1) {
+		assert.Equal(t, "carol@example.com", users[0].Email)
+	}
+	_, _, err = repository.SearchData(query.Query{Sort: "password"})
+	assert.EqualError(t, err, query.ErrInvalidSort)
+
+	// listed users keep the version a later update is checked against
+	users, err = repository.ListData()
+	if assert.NoError(t, err) && assert.Len(t, users, 3) {
+		for _, listed := range users {
+			if listed.ID == user.ID {
+				assert.Equal(t, 2, listed.Version)
+			}
+		}
+	}
+
+	// deleted users are gone
+	assert.NoError(t, repository.DeleteData(user.ID))
+	_, err = repository.ReadData(user.ID)
+	assert.EqualError(t, err, ErrUserNotFound)
+	assert.EqualError(t, repository.DeleteData(user.ID), ErrUserNotFound)
+	assert.EqualError(t, repository.UpdateData(user), ErrUserNotFound)
+	assert.Empty(t, repository.FindByEmail("alice@example.com").ID)
+	users, err = repository.ListData()
+	if assert.NoError(t, err) {
+		assert.Len(t, users, 2)
+	}
+
+	_, err = repository.ReadData(missingID())
+	assert.EqualError(t, err, ErrUserNotFound)
+	assert.EqualError(t, repository.UpdateResetToken(missingID(), "x", time.Now()), ErrUserNotFound)
+	assert.EqualError(t, repository.UpdateData(userPort.UserRepo{ID: missingID()}), ErrUserNotFound)
+	_, err = repository.ReadData("x")
+	assert.EqualError(t, err, ErrUserInvalidID)
+	assert.EqualError(t, repository.DeleteData("x"), ErrUserInvalidID)
+}
+
+// UserToken checks a user token repository
+func UserToken(t *testing.T, repository authPort.Repository) {
+	assert.Empty(t, repository.FindByTokenID("token-1").TokenID)
+	expiredAt := time.Now().Add(time.Hour)
+	for _, userToken := range []authPort.UserTokenRepo{
+		{UserID: "user", TokenID: "token-1", IPAddress: "127.0.0.1", UserAgent: "agent", ExpiredAt: expiredAt},
+		{UserID: "user", TokenID: "token-2", ExpiredAt: expiredAt},
+		{UserID: "user", TokenID: "token-expired", ExpiredAt: time.Now().Add(-time.Hour)},
+		{UserID: "other", TokenID: "token-other", ExpiredAt: expiredAt},
+	} {
+		require.NoError(t, repository.CreateData(userToken))
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	userToken := repository.FindByTokenID("token-1")
+	assert.Equal(t, "user", userToken.UserID)
+	assert.Equal(t, "127.0.0.1", userToken.IPAddress)
+	assert.Equal(t, "agent", userToken.UserAgent)
+	assert.False(t, userToken.CreatedAt.IsZero())
+
+	// most recently used first, expired tokens left out
+	userTokens, err := repository.ListDataByUserID("user")
+	if assert.NoError(t, err) && assert.Len(t, userTokens, 2) {
+		assert.Equal(t, "token-2", userTokens[0].TokenID)
+	}
+	assert.NoError(t, repository.UpdateLastUsed("token-1"))
+	userTokens, err = repository.ListDataByUserID("user")
+	if assert.NoError(t, err) && assert.Len(t, userTokens, 2) {
+		assert.Equal(t, "token-1", userTokens[0].TokenID)
+	}
+
+	assert.NoError(t, repository.DeleteData("token-1"))
+	assert.Empty(t, repository.FindByTokenID("token-1").TokenID)
+	assert.EqualError(t, repository.DeleteData("token-1"), ErrUserTokenNotFound)
+	assert.EqualError(t, repository.UpdateLastUsed("token-1"), ErrUserTokenNotFound)
+
+	assert.NoError(t, repository.DeleteDataByUserID("user"))
+	userTokens, err = repository.ListDataByUserID("user")
+	if assert.NoError(t, err) {
+		assert.Empty(t, userTokens)
+	}
+	assert.Equal(t, "other", repository.FindByTokenID("token-other").UserID)
+}
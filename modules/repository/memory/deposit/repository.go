@@ -0,0 +1,190 @@
+package deposit
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+	"github.com/sepulsa/teleco/utils/apperror"
+)
+
+type (
+	// Repository keeps deposits in process memory, balances are changed under one lock
+	Repository struct {
+		mu       sync.Mutex
+		accounts map[string]*depositPort.AccountRepo
+		holds    []*depositPort.HoldRepo
+		entries  []depositPort.EntryRepo
+	}
+)
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrAccountNotFound = "Deposit account not found"
+	ErrHoldNotFound    = "Deposit hold not found"
+	ErrDuplicateHold   = "Transaction already holds deposit"
+)
+
+func New() *Repository {
+	return &Repository{
+		accounts: make(map[string]*depositPort.AccountRepo),
+	}
+}
+
+func (db *Repository) FindAccount(partnerCode string) (depositPort.AccountRepo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	account, found := db.accounts[partnerCode]
+	if !found {
+		return depositPort.AccountRepo{}, errors.New(ErrAccountNotFound)
+	}
+	return *account, nil
+}
+
+func (db *Repository) Topup(partnerCode string, amount int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	account, found := db.accounts[partnerCode]
+	if !found {
+		account = &depositPort.AccountRepo{PartnerCode: partnerCode}
+		db.accounts[partnerCode] = account
+	}
+	account.Balance += amount
+	account.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) HoldBalance(partnerCode string, amount int64) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	account, found := db.accounts[partnerCode]
+	if !found || account.Balance < amount {
+		return false, nil
+	}
+	account.Balance -= amount
+	account.Held += amount
+	account.UpdatedAt = time.Now()
+	return true, nil
+}
+
+func (db *Repository) MoveBalance(partnerCode string, balance int64, held int64) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	account, found := db.accounts[partnerCode]
+	if !found {
+		return errors.New(ErrAccountNotFound)
+	}
+	account.Balance += balance
+	account.Held += held
+	account.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) CreateHold(hold depositPort.HoldRepo) (depositPort.HoldRepo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	// one hold per partner transaction, like the unique index of the mongo repository
+	for _, data := range db.holds {
+		if data.PartnerCode == hold.PartnerCode && data.TransactionId == hold.TransactionId {
+			return hold, apperror.NewConflict(ErrDuplicateHold)
+		}
+	}
+	hold.ID = memory.NewID()
+	hold.CreatedAt = time.Now()
+	hold.UpdatedAt = hold.CreatedAt
+	// the issuer transaction id is only known once the hold is settled
+	data := hold
+	data.IssuerTransactionId = ""
+	db.holds = append(db.holds, &data)
+	return hold, nil
+}
+
+func (db *Repository) ReadHold(ID string) (depositPort.HoldRepo, error) {
+	if !memory.IsID(ID) {
+		return depositPort.HoldRepo{}, errors.New(ErrInvalidID)
+	}
+	return db.findHold(func(hold *depositPort.HoldRepo) bool {
+		return hold.ID == ID
+	})
+}
+
+func (db *Repository) FindHold(partnerCode string, transactionId string) (depositPort.HoldRepo, error) {
+	return db.findHold(func(hold *depositPort.HoldRepo) bool {
+		return hold.PartnerCode == partnerCode && hold.TransactionId == transactionId
+	})
+}
+
+func (db *Repository) FindHoldByIssuerTransaction(partnerCode string, issuerTransactionId string) (depositPort.HoldRepo, error) {
+	return db.findHold(func(hold *depositPort.HoldRepo) bool {
+		return hold.PartnerCode == partnerCode && hold.IssuerTransactionId == issuerTransactionId
+	})
+}
+
+func (db *Repository) findHold(match func(hold *depositPort.HoldRepo) bool) (depositPort.HoldRepo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, hold := range db.holds {
+		if match(hold) {
+			return *hold, nil
+		}
+	}
+	return depositPort.HoldRepo{}, errors.New(ErrHoldNotFound)
+}
+
+func (db *Repository) UpdateHoldStatus(ID string, from string, to string, issuerTransactionId string) (bool, error) {
+	if !memory.IsID(ID) {
+		return false, errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, hold := range db.holds {
+		if hold.ID == ID && hold.Status == from {
+			hold.Status = to
+			hold.UpdatedAt = time.Now()
+			if issuerTransactionId != "" {
+				hold.IssuerTransactionId = issuerTransactionId
+			}
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (db *Repository) CreateEntry(entry depositPort.EntryRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry.ID = memory.NewID()
+	entry.CreatedAt = time.Now()
+	db.entries = append(db.entries, entry)
+	return nil
+}
+
+func (db *Repository) ListEntries(partnerCode string) (entries []depositPort.EntryRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, entry := range db.entries {
+		if entry.PartnerCode == partnerCode {
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	return
+}
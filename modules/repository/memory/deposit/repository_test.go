@@ -0,0 +1,11 @@
+package deposit
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Deposit(t, New())
+}
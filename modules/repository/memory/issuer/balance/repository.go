@@ -0,0 +1,79 @@
+package balance
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+)
+
+type (
+	// Repository keeps issuer balances in process memory
+	Repository struct {
+		mu       sync.Mutex
+		balances map[string]*issuerPort.Balance
+	}
+)
+
+var (
+	ErrBalanceNotFound = "Issuer balance not reported yet"
+)
+
+func New() *Repository {
+	return &Repository{
+		balances: make(map[string]*issuerPort.Balance),
+	}
+}
+
+func (db *Repository) FindByIssuerCode(code string) (issuerPort.Balance, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	balance, found := db.balances[code]
+	if !found {
+		return issuerPort.Balance{}, errors.New(ErrBalanceNotFound)
+	}
+	return *balance, nil
+}
+
+func (db *Repository) SaveBalance(code string, balance int64, source string) (issuerPort.Balance, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data, found := db.balances[code]
+	if !found {
+		data = &issuerPort.Balance{IssuerCode: code}
+		db.balances[code] = data
+	}
+	data.Balance = balance
+	data.Source = source
+	data.UpdatedAt = time.Now()
+	return *data, nil
+}
+
+func (db *Repository) DeductBalance(code string, amount int64) (issuerPort.Balance, bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data, found := db.balances[code]
+	if !found {
+		return issuerPort.Balance{}, false, nil
+	}
+	data.Balance -= amount
+	data.Source = issuerPort.BalanceSourceEstimate
+	data.UpdatedAt = time.Now()
+	return *data, true, nil
+}
+
+func (db *Repository) UpdateAlerted(code string, alerted bool) (bool, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data, found := db.balances[code]
+	if !found || data.Alerted == alerted {
+		return false, nil
+	}
+	data.Alerted = alerted
+	return true, nil
+}
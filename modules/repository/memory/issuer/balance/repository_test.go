@@ -0,0 +1,11 @@
+package balance
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.IssuerBalance(t, New())
+}
@@ -0,0 +1,41 @@
+package circuit
+
+import (
+	"sync"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/circuitbreaker"
+)
+
+type (
+	// Repository keeps circuit states in process memory, states are per replica
+	Repository struct {
+		mu     sync.Mutex
+		states map[string]issuerPort.CircuitState
+	}
+)
+
+func New() *Repository {
+	return &Repository{
+		states: make(map[string]issuerPort.CircuitState),
+	}
+}
+
+func (db *Repository) SaveState(state issuerPort.CircuitState) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.states[state.IssuerCode] = state
+	return nil
+}
+
+func (db *Repository) FindByIssuerCode(code string) (issuerPort.CircuitState, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	state, found := db.states[code]
+	if !found {
+		return issuerPort.CircuitState{IssuerCode: code, State: circuitbreaker.StateClosed}, nil
+	}
+	return state, nil
+}
@@ -0,0 +1,189 @@
+package issuer
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+)
+
+type (
+	// Repository keeps issuers in process memory, deleted issuers are kept with their deletion time
+	Repository struct {
+		mu      sync.Mutex
+		issuers []*Issuer
+	}
+
+	Issuer struct {
+		issuerPort.IssuerRepo
+		CreatedAt time.Time
+		UpdatedAt time.Time
+		DeletedAt time.Time
+	}
+)
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrIssuerNotFound  = "Issuer not found"
+	ErrVersionConflict = "Issuer was updated since it was read, reload it and retry"
+
+	searchFields = memory.SearchFields{
+		Search: []string{"code", "label"},
+		Sort:   []string{"code", "label", "status", "created_at", "updated_at"},
+		Filter: []string{"status"},
+	}
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByCode(code string) (issuer issuerPort.IssuerRepo) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.issuers {
+		if data.Code == code && data.DeletedAt.IsZero() {
+			memory.Copy(data.IssuerRepo, &issuer)
+			return
+		}
+	}
+	return
+}
+
+func (db *Repository) CreateData(issuer issuerPort.IssuerRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &Issuer{CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	memory.Copy(issuer, &data.IssuerRepo)
+	data.ID = memory.NewID()
+	data.Version = 0
+	db.issuers = append(db.issuers, data)
+	return nil
+}
+
+func (db *Repository) ReadData(ID string) (issuer issuerPort.IssuerRepo, err error) {
+	if !memory.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		err = errors.New(ErrIssuerNotFound)
+		return
+	}
+	memory.Copy(data.IssuerRepo, &issuer)
+	return
+}
+
+func (db *Repository) UpdateData(issuer issuerPort.IssuerRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(issuer.ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrIssuerNotFound)
+	}
+	if data.Version != issuer.Version {
+		return apperror.NewConflict(ErrVersionConflict)
+	}
+	forced := data.CircuitForcedOpen
+	memory.Copy(issuer, &data.IssuerRepo)
+	data.CircuitForcedOpen = forced
+	data.Version++
+	data.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !memory.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrIssuerNotFound)
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) ListData() (issuers []issuerPort.IssuerRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.issuers {
+		if data.DeletedAt.IsZero() {
+			var issuer issuerPort.IssuerRepo
+			memory.Copy(data.IssuerRepo, &issuer)
+			issuers = append(issuers, issuer)
+		}
+	}
+	return
+}
+
+func (db *Repository) SearchData(listQuery query.Query) (issuers []issuerPort.IssuerRepo, page query.Page, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	datas := []*Issuer{}
+	documents := []memory.Document{}
+	for _, data := range db.issuers {
+		if data.DeletedAt.IsZero() {
+			datas = append(datas, data)
+			documents = append(documents, memory.Document{
+				"id":         data.ID,
+				"code":       data.Code,
+				"label":      data.Label,
+				"status":     data.Status,
+				"created_at": memory.Time(data.CreatedAt),
+				"updated_at": memory.Time(data.UpdatedAt),
+			})
+		}
+	}
+	indexes, page, err := memory.Search(documents, listQuery, searchFields)
+	for _, i := range indexes {
+		var issuer issuerPort.IssuerRepo
+		memory.Copy(datas[i].IssuerRepo, &issuer)
+		issuers = append(issuers, issuer)
+	}
+	return
+}
+
+func (db *Repository) UpdateCircuitForcedOpen(ID string, forced bool) error {
+	if !memory.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil {
+		return errors.New(ErrIssuerNotFound)
+	}
+	data.CircuitForcedOpen = forced
+	data.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) find(ID string) *Issuer {
+	for _, data := range db.issuers {
+		if data.ID == ID {
+			return data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// TimeLayout fixed width UTC layout, times of a Document sort and compare as text
+const TimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type (
+	// Document searchable fields of a record, the id field is required
+	Document map[string]string
+
+	// SearchFields fields of a Document open to a query.Query
+	SearchFields struct {
+		Search []string
+		Sort   []string
+		Filter []string
+	}
+
+	cursor struct {
+		Value string `json:"v"`
+		ID    string `json:"id"`
+	}
+)
+
+// NewID identifier in the format of the MongoDB repositories
+func NewID() string {
+	return bson.NewObjectId().Hex()
+}
+
+// IsID reports whether ID was made by NewID
+func IsID(ID string) bool {
+	return bson.IsObjectIdHex(ID)
+}
+
+// Time value of a time field of a Document
+func Time(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(TimeLayout)
+}
+
+// Copy deep copies src into dst through json, as the MongoDB repositories map their documents
+func Copy(src interface{}, dst interface{}) {
+	b, _ := json.Marshal(src)
+	json.Unmarshal(b, dst)
+}
+
+// Search selects a page of documents matching the query, the indexes of the selected documents are returned
+// in page order, documents are sorted by id when the query has no sort field
+func Search(documents []Document, q query.Query, fields SearchFields) (indexes []int, page query.Page, err error) {
+	q = q.Normalize()
+	page.Page = q.Page
+	page.Limit = q.Limit
+
+	for field, value := range q.Filters {
+		if value != "" && !contains(fields.Filter, field) {
+			return nil, page, errors.New(query.ErrInvalidFilter)
+		}
+	}
+	field, descending := q.SortField()
+	if field == "" {
+		field = "id"
+	} else if !contains(fields.Sort, field) {
+		return nil, page, errors.New(query.ErrInvalidSort)
+	}
+	var position cursor
+	if q.Cursor != "" {
+		if position, err = decodeCursor(q.Cursor); err != nil {
+			return nil, page, err
+		}
+	}
+
+	matches := []int{}
+	for i, document := range documents {
+		if match(document, q, fields) {
+			matches = append(matches, i)
+		}
+	}
+	page.Total = len(matches)
+
+	// the id breaks ties on the sort field
+	less := func(a Document, b Document) bool {
+		if a[field] != b[field] {
+			return (a[field] < b[field]) != descending
+		}
+		if a["id"] == b["id"] {
+			return false
+		}
+		return (a["id"] < b["id"]) != descending
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return less(documents[matches[i]], documents[matches[j]])
+	})
+
+	start := 0
+	if q.Cursor != "" {
+		after := Document{field: position.Value, "id": position.ID}
+		for start < len(matches) && !less(after, documents[matches[start]]) {
+			start++
+		}
+	} else {
+		start = (q.Page - 1) * q.Limit
+	}
+	if start > len(matches) {
+		start = len(matches)
+	}
+	indexes = matches[start:]
+	if len(indexes) > q.Limit {
+		indexes = indexes[:q.Limit]
+		last := documents[indexes[len(indexes)-1]]
+		page.NextCursor = encodeCursor(cursor{last[field], last["id"]})
+	}
+	return
+}
+
+func match(document Document, q query.Query, fields SearchFields) bool {
+	for field, value := range q.Filters {
+		if value != "" && document[field] != value {
+			return false
+		}
+	}
+	if q.Search == "" || len(fields.Search) == 0 {
+		return true
+	}
+	search := strings.ToLower(q.Search)
+	for _, field := range fields.Search {
+		if strings.Contains(strings.ToLower(document[field]), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeCursor keeps the sort position of a document, it is opaque to the caller
+func encodeCursor(position cursor) string {
+	b, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(value string) (position cursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(b, &position)
+	}
+	if err != nil || !IsID(position.ID) {
+		err = errors.New(query.ErrInvalidCursor)
+	}
+	return
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
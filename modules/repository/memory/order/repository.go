@@ -0,0 +1,220 @@
+package order
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+)
+
+type (
+	// Repository keeps the order log in process memory
+	Repository struct {
+		mu     sync.Mutex
+		orders []orderPort.OrderRepo
+	}
+)
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrOrderNotFound = "Order not found"
+	ErrInvalidCursor = "Invalid cursor"
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) CreateData(order orderPort.OrderRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	order.ID = memory.NewID()
+	order.CreatedAt = time.Now()
+	db.orders = append(db.orders, order)
+	return nil
+}
+
+func (db *Repository) ListPurchases(purchaseFilter orderPort.PurchaseFilter) (orders []orderPort.OrderRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, order := range db.orders {
+		if order.CommandType != orderPort.Purchase ||
+			order.CreatedAt.Before(purchaseFilter.From) || !order.CreatedAt.Before(purchaseFilter.Until) ||
+			(purchaseFilter.IssuerId != "" && order.IssuerId != purchaseFilter.IssuerId) ||
+			(purchaseFilter.PartnerId != "" && order.PartnerId != purchaseFilter.PartnerId) {
+			continue
+		}
+		orders = append(orders, order)
+	}
+	sortOrders(orders, false)
+	return
+}
+
+func (db *Repository) SearchData(orderFilter orderPort.OrderFilter) (orders []orderPort.OrderRepo, next string, err error) {
+	descending := orderFilter.Sort != orderPort.SortCreatedAt
+	var after orderPort.OrderRepo
+	if orderFilter.Cursor != "" {
+		if after.CreatedAt, after.ID, err = decodeCursor(orderFilter.Cursor); err != nil {
+			return
+		}
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, order := range db.orders {
+		if match(order, orderFilter) && (orderFilter.Cursor == "" || less(after, order, descending)) {
+			orders = append(orders, order)
+		}
+	}
+	sortOrders(orders, descending)
+	if len(orders) > orderFilter.Limit {
+		orders = orders[:orderFilter.Limit]
+		last := orders[len(orders)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return
+}
+
+func (db *Repository) ReadData(ID string) (order orderPort.OrderRepo, err error) {
+	if !memory.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, order := range db.orders {
+		if order.ID == ID {
+			return order, nil
+		}
+	}
+	err = errors.New(ErrOrderNotFound)
+	return
+}
+
+func (db *Repository) ListTimeline(partnerId string, transactionId string, issuerTransactionIds []string) (orders []orderPort.OrderRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, order := range db.orders {
+		if order.PartnerId != partnerId {
+			continue
+		}
+		if order.TransactionId == transactionId || contains(issuerTransactionIds, order.IssuerTransactionId) {
+			orders = append(orders, order)
+		}
+	}
+	sortOrders(orders, false)
+	return
+}
+
+func (db *Repository) RemoveData(IDs []string) (int, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	kept := db.orders[:0]
+	for _, order := range db.orders {
+		if !contains(IDs, order.ID) {
+			kept = append(kept, order)
+		}
+	}
+	removed := len(db.orders) - len(kept)
+	db.orders = kept
+	return removed, nil
+}
+
+func match(order orderPort.OrderRepo, orderFilter orderPort.OrderFilter) bool {
+	equal := func(value string, filter string) bool {
+		return filter == "" || value == filter
+	}
+	if !equal(order.PartnerId, orderFilter.PartnerId) ||
+		!equal(order.IssuerId, orderFilter.IssuerId) ||
+		!equal(order.CommandType, orderFilter.CommandType) ||
+		!equal(order.TransactionId, orderFilter.TransactionId) ||
+		!equal(order.IssuerTransactionId, orderFilter.IssuerTransactionId) ||
+		!equal(order.CustomerNumber, orderFilter.CustomerNumber) {
+		return false
+	}
+	pending := order.IssuerRescode == orderPort.RescodePending || order.IssuerRescode == ""
+	switch orderFilter.Status {
+	case orderPort.StatusSuccess:
+		if order.IssuerRescode != orderPort.RescodeSuccess {
+			return false
+		}
+	case orderPort.StatusPending:
+		if !pending {
+			return false
+		}
+	case orderPort.StatusFailed:
+		if pending || order.IssuerRescode == orderPort.RescodeSuccess {
+			return false
+		}
+	}
+	if !orderFilter.From.IsZero() && order.CreatedAt.Before(orderFilter.From) {
+		return false
+	}
+	if !orderFilter.Until.IsZero() && !order.CreatedAt.Before(orderFilter.Until) {
+		return false
+	}
+	return true
+}
+
+// less reports whether a comes before b, the id breaks ties on the same time
+func less(a orderPort.OrderRepo, b orderPort.OrderRepo, descending bool) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt) != descending
+	}
+	if a.ID == b.ID {
+		return false
+	}
+	return (a.ID < b.ID) != descending
+}
+
+func sortOrders(orders []orderPort.OrderRepo, descending bool) {
+	sort.SliceStable(orders, func(i, j int) bool {
+		return less(orders[i], orders[j], descending)
+	})
+}
+
+// encodeCursor keeps the sort position of a record, it is opaque to the caller
+func encodeCursor(createdAt time.Time, ID string) string {
+	position := fmt.Sprintf("%d:%s", createdAt.UnixNano(), ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+func decodeCursor(cursor string) (createdAt time.Time, ID string, err error) {
+	err = errors.New(ErrInvalidCursor)
+	position, decodeErr := base64.RawURLEncoding.DecodeString(cursor)
+	if decodeErr != nil {
+		return
+	}
+	parts := strings.SplitN(string(position), ":", 2)
+	if len(parts) != 2 || !memory.IsID(parts[1]) {
+		return
+	}
+	nano, parseErr := strconv.ParseInt(parts[0], 10, 64)
+	if parseErr != nil {
+		return
+	}
+	return time.Unix(0, nano), parts[1], nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,11 @@
+package order
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Order(t, New())
+}
@@ -0,0 +1,166 @@
+package issuer
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+)
+
+type (
+	// Repository keeps partner issuer mappings in process memory, deleted mappings are kept with their deletion time
+	Repository struct {
+		mu             sync.Mutex
+		partnerIssuers []*PartnerIssuer
+	}
+
+	PartnerIssuer struct {
+		partnerIssuerPort.PartnerIssuerRepo
+		DeletedAt time.Time
+	}
+)
+
+var (
+	ErrInvalidID             = "Invalid ID"
+	ErrPartnerIssuerNotFound = "Partner Issuer not found"
+	ErrVersionConflict       = "Partner Issuer was updated since it was read, reload it and retry"
+
+	searchFields = memory.SearchFields{
+		Sort:   []string{"created_at", "updated_at"},
+		Filter: []string{"partner_id", "issuer_id"},
+	}
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) CreateData(partnerIssuer partnerIssuerPort.PartnerIssuerRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &PartnerIssuer{PartnerIssuerRepo: partnerIssuer}
+	data.ID = memory.NewID()
+	data.Version = 0
+	data.CreatedAt = time.Now()
+	data.UpdatedAt = time.Now()
+	db.partnerIssuers = append(db.partnerIssuers, data)
+	return nil
+}
+
+func (db *Repository) FindByPartnerIssuerID(partnerId string, issuerId string) (partnerIssuer partnerIssuerPort.PartnerIssuerRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.partnerIssuers {
+		if data.PartnerId == partnerId && data.IssuerId == issuerId && data.DeletedAt.IsZero() {
+			return data.PartnerIssuerRepo, nil
+		}
+	}
+	err = errors.New(ErrPartnerIssuerNotFound)
+	return
+}
+
+func (db *Repository) ReadData(ID string) (partnerIssuer partnerIssuerPort.PartnerIssuerRepo, err error) {
+	if !memory.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		err = errors.New(ErrPartnerIssuerNotFound)
+		return
+	}
+	return data.PartnerIssuerRepo, nil
+}
+
+func (db *Repository) UpdateData(partnerIssuer partnerIssuerPort.PartnerIssuerRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(partnerIssuer.ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrPartnerIssuerNotFound)
+	}
+	if data.Version != partnerIssuer.Version {
+		return apperror.NewConflict(ErrVersionConflict)
+	}
+	data.PartnerId = partnerIssuer.PartnerId
+	data.IssuerId = partnerIssuer.IssuerId
+	data.Config = partnerIssuer.Config
+	data.ReservedThread = partnerIssuer.ReservedThread
+	data.MaxThread = partnerIssuer.MaxThread
+	data.Version++
+	data.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !memory.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrPartnerIssuerNotFound)
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) ListData() (partnerIssuers []partnerIssuerPort.PartnerIssuerRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.partnerIssuers {
+		if data.DeletedAt.IsZero() {
+			partnerIssuers = append(partnerIssuers, data.PartnerIssuerRepo)
+		}
+	}
+	return
+}
+
+func (db *Repository) SearchData(listQuery query.Query) (partnerIssuers []partnerIssuerPort.PartnerIssuerRepo, page query.Page, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	datas := []*PartnerIssuer{}
+	documents := []memory.Document{}
+	for _, data := range db.partnerIssuers {
+		if data.DeletedAt.IsZero() {
+			datas = append(datas, data)
+			documents = append(documents, memory.Document{
+				"id":         data.ID,
+				"partner_id": data.PartnerId,
+				"issuer_id":  data.IssuerId,
+				"created_at": memory.Time(data.CreatedAt),
+				"updated_at": memory.Time(data.UpdatedAt),
+			})
+		}
+	}
+	indexes, page, err := memory.Search(documents, listQuery, searchFields)
+	for _, i := range indexes {
+		partnerIssuers = append(partnerIssuers, datas[i].PartnerIssuerRepo)
+	}
+	return
+}
+
+func (db *Repository) find(ID string) *PartnerIssuer {
+	for _, data := range db.partnerIssuers {
+		if data.ID == ID {
+			return data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package issuer
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.PartnerIssuer(t, New())
+}
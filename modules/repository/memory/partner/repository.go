@@ -0,0 +1,174 @@
+package partner
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+)
+
+type (
+	// Repository keeps partners in process memory, deleted partners are kept with their deletion time
+	Repository struct {
+		mu       sync.Mutex
+		partners []*partnerPort.PartnerRepo
+	}
+)
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrPartnerNotFound = "Partner not found"
+	ErrVersionConflict = "Partner was updated since it was read, reload it and retry"
+
+	searchFields = memory.SearchFields{
+		Search: []string{"code", "name", "pic"},
+		Sort:   []string{"code", "name", "status", "created_at", "updated_at"},
+		Filter: []string{"status"},
+	}
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByCode(code string) (partner partnerPort.PartnerRepo) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.partners {
+		if data.Code == code && data.DeletedAt.IsZero() {
+			memory.Copy(data, &partner)
+			return
+		}
+	}
+	return
+}
+
+func (db *Repository) CreateData(partner partnerPort.PartnerRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &partnerPort.PartnerRepo{}
+	memory.Copy(partner, data)
+	data.ID = memory.NewID()
+	data.Version = 0
+	data.CreatedAt = time.Now()
+	data.UpdatedAt = time.Now()
+	data.DeletedAt = time.Time{}
+	db.partners = append(db.partners, data)
+	return nil
+}
+
+func (db *Repository) ReadData(ID string) (partner partnerPort.PartnerRepo, err error) {
+	if !memory.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		err = errors.New(ErrPartnerNotFound)
+		return
+	}
+	memory.Copy(data, &partner)
+	return
+}
+
+func (db *Repository) UpdateData(partner partnerPort.PartnerRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(partner.ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrPartnerNotFound)
+	}
+	if data.Version != partner.Version {
+		return apperror.NewConflict(ErrVersionConflict)
+	}
+	createdAt, deletedAt := data.CreatedAt, data.DeletedAt
+	*data = partnerPort.PartnerRepo{}
+	memory.Copy(partner, data)
+	if len(data.IssuerRateLimit) == 0 {
+		data.IssuerRateLimit = nil
+	}
+	data.Version++
+	data.CreatedAt = createdAt
+	data.UpdatedAt = time.Now()
+	data.DeletedAt = deletedAt
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !memory.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrPartnerNotFound)
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) ListData() (partners []partnerPort.PartnerRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.partners {
+		if data.DeletedAt.IsZero() {
+			var partner partnerPort.PartnerRepo
+			memory.Copy(data, &partner)
+			partners = append(partners, partner)
+		}
+	}
+	return
+}
+
+func (db *Repository) SearchData(listQuery query.Query) (partners []partnerPort.PartnerRepo, page query.Page, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	datas := []*partnerPort.PartnerRepo{}
+	documents := []memory.Document{}
+	for _, data := range db.partners {
+		if data.DeletedAt.IsZero() {
+			datas = append(datas, data)
+			documents = append(documents, memory.Document{
+				"id":         data.ID,
+				"code":       data.Code,
+				"name":       data.Name,
+				"pic":        data.Pic,
+				"status":     data.Status,
+				"created_at": memory.Time(data.CreatedAt),
+				"updated_at": memory.Time(data.UpdatedAt),
+			})
+		}
+	}
+	indexes, page, err := memory.Search(documents, listQuery, searchFields)
+	for _, i := range indexes {
+		var partner partnerPort.PartnerRepo
+		memory.Copy(datas[i], &partner)
+		partners = append(partners, partner)
+	}
+	return
+}
+
+func (db *Repository) find(ID string) *partnerPort.PartnerRepo {
+	for _, data := range db.partners {
+		if data.ID == ID {
+			return data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+package partner
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Partner(t, New())
+}
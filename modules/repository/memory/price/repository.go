@@ -0,0 +1,133 @@
+package price
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+)
+
+type (
+	// Repository keeps prices in process memory, deleted prices are kept with their deletion time
+	Repository struct {
+		mu     sync.Mutex
+		prices []*Price
+	}
+
+	Price struct {
+		pricePort.PriceRepo
+		DeletedAt time.Time
+	}
+)
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrPriceNotFound = "Price not found"
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByPartnerProduct(partnerCode string, productCode string) ([]pricePort.PriceRepo, error) {
+	return db.list(func(price *Price) bool {
+		return price.PartnerCode == partnerCode && price.ProductCode == productCode
+	})
+}
+
+func (db *Repository) CreateData(price pricePort.PriceRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &Price{PriceRepo: price}
+	data.ID = memory.NewID()
+	data.CreatedAt = time.Now()
+	data.UpdatedAt = time.Now()
+	db.prices = append(db.prices, data)
+	return nil
+}
+
+func (db *Repository) ReadData(ID string) (pricePort.PriceRepo, error) {
+	if !memory.IsID(ID) {
+		return pricePort.PriceRepo{}, errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return pricePort.PriceRepo{}, errors.New(ErrPriceNotFound)
+	}
+	return data.PriceRepo, nil
+}
+
+func (db *Repository) UpdateData(price pricePort.PriceRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(price.ID)
+	if data == nil {
+		return errors.New(ErrPriceNotFound)
+	}
+	createdAt := data.CreatedAt
+	data.PriceRepo = price
+	data.CreatedAt = createdAt
+	data.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !memory.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrPriceNotFound)
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) ListData() ([]pricePort.PriceRepo, error) {
+	return db.list(func(price *Price) bool {
+		return true
+	})
+}
+
+func (db *Repository) list(match func(price *Price) bool) (prices []pricePort.PriceRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.prices {
+		if data.DeletedAt.IsZero() && match(data) {
+			prices = append(prices, data.PriceRepo)
+		}
+	}
+	sort.SliceStable(prices, func(i, j int) bool {
+		if prices[i].PartnerCode != prices[j].PartnerCode {
+			return prices[i].PartnerCode < prices[j].PartnerCode
+		}
+		if prices[i].ProductCode != prices[j].ProductCode {
+			return prices[i].ProductCode < prices[j].ProductCode
+		}
+		return prices[i].EffectiveFrom.After(prices[j].EffectiveFrom)
+	})
+	return
+}
+
+func (db *Repository) find(ID string) *Price {
+	for _, data := range db.prices {
+		if data.ID == ID {
+			return data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package product
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+)
+
+type (
+	// Repository keeps products in process memory, deleted products are kept with their deletion time
+	Repository struct {
+		mu       sync.Mutex
+		products []*Product
+	}
+
+	Product struct {
+		productPort.ProductRepo
+		DeletedAt time.Time
+	}
+)
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrProductNotFound = "Product not found"
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByCode(code string) (product productPort.ProductRepo) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.products {
+		if data.Code == code && data.DeletedAt.IsZero() {
+			memory.Copy(data.ProductRepo, &product)
+			return
+		}
+	}
+	return
+}
+
+func (db *Repository) CreateData(product productPort.ProductRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &Product{}
+	memory.Copy(product, &data.ProductRepo)
+	data.ID = memory.NewID()
+	data.CreatedAt = time.Now()
+	data.UpdatedAt = time.Now()
+	db.products = append(db.products, data)
+	return nil
+}
+
+func (db *Repository) ReadData(ID string) (product productPort.ProductRepo, err error) {
+	if !memory.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		err = errors.New(ErrProductNotFound)
+		return
+	}
+	memory.Copy(data.ProductRepo, &product)
+	return
+}
+
+func (db *Repository) UpdateData(product productPort.ProductRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(product.ID)
+	if data == nil {
+		return errors.New(ErrProductNotFound)
+	}
+	createdAt := data.CreatedAt
+	data.ProductRepo = productPort.ProductRepo{}
+	memory.Copy(product, &data.ProductRepo)
+	data.CreatedAt = createdAt
+	data.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !memory.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrProductNotFound)
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) ListData() ([]productPort.ProductRepo, error) {
+	return db.list(func(product *Product) bool {
+		return true
+	})
+}
+
+func (db *Repository) ListActive() ([]productPort.ProductRepo, error) {
+	return db.list(func(product *Product) bool {
+		return product.Active
+	})
+}
+
+func (db *Repository) list(match func(product *Product) bool) (products []productPort.ProductRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.products {
+		if data.DeletedAt.IsZero() && match(data) {
+			var product productPort.ProductRepo
+			memory.Copy(data.ProductRepo, &product)
+			products = append(products, product)
+		}
+	}
+	sort.SliceStable(products, func(i, j int) bool {
+		if products[i].Operator != products[j].Operator {
+			return products[i].Operator < products[j].Operator
+		}
+		if products[i].Type != products[j].Type {
+			return products[i].Type < products[j].Type
+		}
+		return products[i].Denomination < products[j].Denomination
+	})
+	return
+}
+
+func (db *Repository) find(ID string) *Product {
+	for _, data := range db.products {
+		if data.ID == ID {
+			return data
+		}
+	}
+	return nil
+}
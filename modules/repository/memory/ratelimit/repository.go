@@ -0,0 +1,81 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/ratelimit"
+)
+
+type (
+	// Repository keeps buckets in process memory, limits are per replica
+	Repository struct {
+		mu        sync.Mutex
+		entries   map[string]*entry
+		lastSweep time.Time
+	}
+
+	// entry buckets of one key, with the time they are all full again
+	entry struct {
+		buckets map[string]*ratelimit.Bucket
+		fullAt  time.Time
+	}
+)
+
+var (
+	// SweepInterval how often entries back to full buckets are dropped, they are the same as new ones
+	SweepInterval = time.Minute
+)
+
+func New() *Repository {
+	return &Repository{
+		entries: make(map[string]*entry),
+	}
+}
+
+func (db *Repository) Take(key string, limits map[string]partnerPort.RateLimit, now time.Time) (bool, time.Duration, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.sweep(now)
+	e, found := db.entries[key]
+	if !found {
+		e = &entry{buckets: make(map[string]*ratelimit.Bucket, len(limits))}
+		db.entries[key] = e
+	}
+	bucketLimits := make(map[string]ratelimit.Limit, len(limits))
+	for name, limit := range limits {
+		bucketLimits[name] = ratelimit.Limit(limit)
+	}
+	allowed, retryAfter := ratelimit.TakeAll(e.buckets, bucketLimits, now)
+
+	e.fullAt = now
+	for name, limit := range limits {
+		if fullAt := e.buckets[name].FullAt(limit.Rate, limit.Burst); fullAt.After(e.fullAt) {
+			e.fullAt = fullAt
+		}
+	}
+
+	return allowed, retryAfter, nil
+}
+
+// Len number of keys kept
+func (db *Repository) Len() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return len(db.entries)
+}
+
+func (db *Repository) sweep(now time.Time) {
+	if now.Sub(db.lastSweep) < SweepInterval {
+		return
+	}
+	db.lastSweep = now
+	for key, e := range db.entries {
+		if !now.Before(e.fullAt) {
+			delete(db.entries, key)
+		}
+	}
+}
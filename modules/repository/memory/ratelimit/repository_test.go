@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RateLimit(t, New())
+}
+
+func TestSweep(t *testing.T) {
+	repository := New()
+	now := time.Date(2021, 9, 8, 10, 0, 0, 0, time.UTC)
+	limits := map[string]partnerPort.RateLimit{"partner": {Rate: 1, Burst: 2}}
+	slowLimits := map[string]partnerPort.RateLimit{"partner": {Rate: 0.01, Burst: 2}}
+
+	repository.Take("partner", limits, now)
+	repository.Take("other", slowLimits, now.Add(SweepInterval/2))
+	assert.Equal(t, 2, repository.Len())
+
+	// partner is full again and dropped, other still misses a token
+	repository.Take("other", slowLimits, now.Add(SweepInterval))
+	assert.Equal(t, 1, repository.Len())
+}
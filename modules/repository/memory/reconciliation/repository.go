@@ -0,0 +1,93 @@
+package reconciliation
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+)
+
+type (
+	// Repository keeps reconciliation runs and their items in process memory
+	Repository struct {
+		mu    sync.Mutex
+		runs  []reconciliationPort.RunRepo
+		items []reconciliationPort.ItemRepo
+	}
+)
+
+var (
+	ErrInvalidID              = "Invalid ID"
+	ErrReconciliationNotFound = "Reconciliation not found"
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) CreateRun(run reconciliationPort.RunRepo) (reconciliationPort.RunRepo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	run.ID = memory.NewID()
+	run.CreatedAt = time.Now()
+	db.runs = append(db.runs, run)
+	return run, nil
+}
+
+func (db *Repository) CreateItems(items []reconciliationPort.ItemRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, item := range items {
+		item.ID = memory.NewID()
+		db.items = append(db.items, item)
+	}
+	return nil
+}
+
+func (db *Repository) ReadRun(ID string) (reconciliationPort.RunRepo, error) {
+	if !memory.IsID(ID) {
+		return reconciliationPort.RunRepo{}, errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, run := range db.runs {
+		if run.ID == ID {
+			return run, nil
+		}
+	}
+	return reconciliationPort.RunRepo{}, errors.New(ErrReconciliationNotFound)
+}
+
+func (db *Repository) ListRuns(issuerCode string) (runs []reconciliationPort.RunRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, run := range db.runs {
+		if issuerCode == "" || run.IssuerCode == issuerCode {
+			runs = append(runs, run)
+		}
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].CreatedAt.After(runs[j].CreatedAt)
+	})
+	return
+}
+
+func (db *Repository) ListItems(runId string, class string) (items []reconciliationPort.ItemRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, item := range db.items {
+		if item.RunId == runId && (class == "" || item.Class == class) {
+			items = append(items, item)
+		}
+	}
+	return
+}
@@ -0,0 +1,126 @@
+package route
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+)
+
+type (
+	// Repository keeps routes in process memory, deleted routes are kept with their deletion time
+	Repository struct {
+		mu     sync.Mutex
+		routes []*Route
+	}
+
+	Route struct {
+		routePort.RouteRepo
+		DeletedAt time.Time
+	}
+)
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrRouteNotFound = "Route not found"
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByProductCode(productCode string) (route routePort.RouteRepo) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.routes {
+		if data.ProductCode == productCode && data.DeletedAt.IsZero() {
+			memory.Copy(data.RouteRepo, &route)
+			return
+		}
+	}
+	return
+}
+
+func (db *Repository) CreateData(route routePort.RouteRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &Route{}
+	memory.Copy(route, &data.RouteRepo)
+	data.ID = memory.NewID()
+	db.routes = append(db.routes, data)
+	return nil
+}
+
+func (db *Repository) ReadData(ID string) (route routePort.RouteRepo, err error) {
+	if !memory.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		err = errors.New(ErrRouteNotFound)
+		return
+	}
+	memory.Copy(data.RouteRepo, &route)
+	return
+}
+
+func (db *Repository) UpdateData(route routePort.RouteRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(route.ID)
+	if data == nil {
+		return errors.New(ErrRouteNotFound)
+	}
+	data.RouteRepo = routePort.RouteRepo{}
+	memory.Copy(route, &data.RouteRepo)
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !memory.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return errors.New(ErrRouteNotFound)
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) ListData() (routes []routePort.RouteRepo, err error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.routes {
+		if data.DeletedAt.IsZero() {
+			var route routePort.RouteRepo
+			memory.Copy(data.RouteRepo, &route)
+			routes = append(routes, route)
+		}
+	}
+	return
+}
+
+func (db *Repository) find(ID string) *Route {
+	for _, data := range db.routes {
+		if data.ID == ID {
+			return data
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package user
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/modules/repository/memory"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+)
+
+type (
+	// Repository keeps users in process memory, deleted users are kept with their deletion time
+	Repository struct {
+		mu    sync.Mutex
+		users []*User
+	}
+
+	User struct {
+		userPort.UserRepo
+		ResetToken string
+		CreatedAt  time.Time
+		UpdatedAt  time.Time
+		DeletedAt  time.Time
+	}
+)
+
+var (
+	ErrUserNotFound    error = errors.New("user not found")
+	ErrInvalidID       error = errors.New("invalid id")
+	ErrVersionConflict error = apperror.NewConflict("user was updated since it was read, reload it and retry")
+
+	searchFields = memory.SearchFields{
+		Search: []string{"email", "fullname"},
+		Sort:   []string{"email", "fullname", "created_at", "updated_at"},
+	}
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByEmail(email string) userPort.UserRepo {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.users {
+		if data.Email == email && data.DeletedAt.IsZero() {
+			return toUserRepo(data)
+		}
+	}
+	return userPort.UserRepo{}
+}
+
+func (db *Repository) FindByResetToken(tokenHash string) userPort.UserRepo {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.users {
+		if data.ResetToken != "" && data.ResetToken == tokenHash && data.ResetTokenExpiredAt.After(time.Now()) && data.DeletedAt.IsZero() {
+			return toUserRepo(data)
+		}
+	}
+	return userPort.UserRepo{}
+}
+
+func (db *Repository) ReadData(ID string) (userPort.UserRepo, error) {
+	if !memory.IsID(ID) {
+		return userPort.UserRepo{}, ErrInvalidID
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return userPort.UserRepo{}, ErrUserNotFound
+	}
+	return toUserRepo(data), nil
+}
+
+func (db *Repository) CreateData(user userPort.UserRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &User{CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	data.ID = memory.NewID()
+	data.Email = user.Email
+	data.Fullname = user.Fullname
+	data.Password = user.Password
+	db.users = append(db.users, data)
+	return nil
+}
+
+func (db *Repository) UpdateData(user userPort.UserRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(user.ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return ErrUserNotFound
+	}
+	if data.Version != user.Version {
+		return ErrVersionConflict
+	}
+	data.Email = user.Email
+	data.Fullname = user.Fullname
+	data.Version++
+	data.UpdatedAt = time.Now()
+	if user.Password != "" {
+		data.Password = user.Password
+		data.PasswordHistory = append([]string(nil), user.PasswordHistory...)
+		data.ResetToken = ""
+		data.ResetTokenExpiredAt = time.Time{}
+	}
+	return nil
+}
+
+func (db *Repository) UpdateResetToken(ID string, tokenHash string, expiredAt time.Time) error {
+	if !memory.IsID(ID) {
+		return ErrInvalidID
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil {
+		return ErrUserNotFound
+	}
+	data.ResetToken = tokenHash
+	data.ResetTokenExpiredAt = expiredAt
+	data.UpdatedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !memory.IsID(ID) {
+		return ErrInvalidID
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(ID)
+	if data == nil || !data.DeletedAt.IsZero() {
+		return ErrUserNotFound
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) ListData() ([]userPort.UserRepo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	users := make([]userPort.UserRepo, 0)
+	for _, data := range db.users {
+		if data.DeletedAt.IsZero() {
+			users = append(users, userPort.UserRepo{
+				ID:       data.ID,
+				Email:    data.Email,
+				Fullname: data.Fullname,
+				Version:  data.Version,
+			})
+		}
+	}
+	return users, nil
+}
+
+func (db *Repository) SearchData(listQuery query.Query) ([]userPort.UserRepo, query.Page, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	datas := []*User{}
+	documents := []memory.Document{}
+	for _, data := range db.users {
+		if data.DeletedAt.IsZero() {
+			datas = append(datas, data)
+			documents = append(documents, memory.Document{
+				"id":         data.ID,
+				"email":      data.Email,
+				"fullname":   data.Fullname,
+				"created_at": memory.Time(data.CreatedAt),
+				"updated_at": memory.Time(data.UpdatedAt),
+			})
+		}
+	}
+	users := make([]userPort.UserRepo, 0)
+	indexes, page, err := memory.Search(documents, listQuery, searchFields)
+	for _, i := range indexes {
+		users = append(users, userPort.UserRepo{
+			ID:       datas[i].ID,
+			Email:    datas[i].Email,
+			Fullname: datas[i].Fullname,
+			Version:  datas[i].Version,
+		})
+	}
+	return users, page, err
+}
+
+func (db *Repository) find(ID string) *User {
+	for _, data := range db.users {
+		if data.ID == ID {
+			return data
+		}
+	}
+	return nil
+}
+
+func toUserRepo(data *User) userPort.UserRepo {
+	user := data.UserRepo
+	user.PasswordHistory = append([]string(nil), data.PasswordHistory...)
+	return user
+}
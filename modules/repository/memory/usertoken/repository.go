@@ -0,0 +1,117 @@
+package usertoken
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	authPort "github.com/sepulsa/teleco/business/auth/port"
+)
+
+type (
+	// Repository keeps user tokens in process memory, revoked tokens are kept with their deletion time
+	Repository struct {
+		mu         sync.Mutex
+		userTokens []*UserToken
+	}
+
+	UserToken struct {
+		authPort.UserTokenRepo
+		DeletedAt time.Time
+	}
+)
+
+var (
+	ErrInvalidID         = "Invalid ID"
+	ErrUserTokenNotFound = "Token not found"
+)
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) CreateData(jwt authPort.UserTokenRepo) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := &UserToken{UserTokenRepo: jwt}
+	data.CreatedAt = time.Now()
+	data.LastUsedAt = data.CreatedAt
+	db.userTokens = append(db.userTokens, data)
+	return nil
+}
+
+func (db *Repository) FindByTokenID(tokenID string) authPort.UserTokenRepo {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if data := db.find(tokenID); data != nil {
+		return data.UserTokenRepo
+	}
+	return authPort.UserTokenRepo{}
+}
+
+func (db *Repository) ListDataByUserID(userID string) ([]authPort.UserTokenRepo, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	userTokens := make([]authPort.UserTokenRepo, 0)
+	now := time.Now()
+	for _, data := range db.userTokens {
+		// tokens created before expiry tracking have no expired_at
+		if data.UserID == userID && data.DeletedAt.IsZero() && (data.ExpiredAt.IsZero() || data.ExpiredAt.After(now)) {
+			userTokens = append(userTokens, data.UserTokenRepo)
+		}
+	}
+	sort.SliceStable(userTokens, func(i, j int) bool {
+		return userTokens[i].LastUsedAt.After(userTokens[j].LastUsedAt)
+	})
+	return userTokens, nil
+}
+
+func (db *Repository) UpdateLastUsed(tokenID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(tokenID)
+	if data == nil {
+		return errors.New(ErrUserTokenNotFound)
+	}
+	data.LastUsedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) DeleteData(tokenID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	data := db.find(tokenID)
+	if data == nil {
+		return errors.New(ErrUserTokenNotFound)
+	}
+	data.DeletedAt = time.Now()
+	return nil
+}
+
+func (db *Repository) DeleteDataByUserID(userID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, data := range db.userTokens {
+		if data.UserID == userID && data.DeletedAt.IsZero() {
+			data.DeletedAt = time.Now()
+		}
+	}
+	return nil
+}
+
+// find the first token not revoked yet
+func (db *Repository) find(tokenID string) *UserToken {
+	for _, data := range db.userTokens {
+		if data.TokenID == tokenID && data.DeletedAt.IsZero() {
+			return data
+		}
+	}
+	return nil
+}
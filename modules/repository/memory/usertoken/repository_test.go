@@ -0,0 +1,11 @@
+package usertoken
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.UserToken(t, New())
+}
@@ -0,0 +1,70 @@
+package deposit
+
+import (
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindAccount(partnerCode string) (depositPort.AccountRepo, error) {
+	result := db.Called(partnerCode)
+	return result.Get(0).(depositPort.AccountRepo), result.Error(1)
+}
+
+func (db *Repository) Topup(partnerCode string, amount int64) error {
+	result := db.Called(partnerCode, amount)
+	return result.Error(0)
+}
+
+func (db *Repository) HoldBalance(partnerCode string, amount int64) (bool, error) {
+	result := db.Called(partnerCode, amount)
+	return result.Bool(0), result.Error(1)
+}
+
+func (db *Repository) MoveBalance(partnerCode string, balance int64, held int64) error {
+	result := db.Called(partnerCode, balance, held)
+	return result.Error(0)
+}
+
+func (db *Repository) CreateHold(hold depositPort.HoldRepo) (depositPort.HoldRepo, error) {
+	result := db.Called(hold)
+	return result.Get(0).(depositPort.HoldRepo), result.Error(1)
+}
+
+func (db *Repository) ReadHold(ID string) (depositPort.HoldRepo, error) {
+	result := db.Called(ID)
+	return result.Get(0).(depositPort.HoldRepo), result.Error(1)
+}
+
+func (db *Repository) FindHold(partnerCode string, transactionId string) (depositPort.HoldRepo, error) {
+	result := db.Called(partnerCode, transactionId)
+	return result.Get(0).(depositPort.HoldRepo), result.Error(1)
+}
+
+func (db *Repository) FindHoldByIssuerTransaction(partnerCode string, issuerTransactionId string) (depositPort.HoldRepo, error) {
+	result := db.Called(partnerCode, issuerTransactionId)
+	return result.Get(0).(depositPort.HoldRepo), result.Error(1)
+}
+
+func (db *Repository) UpdateHoldStatus(ID string, from string, to string, issuerTransactionId string) (bool, error) {
+	result := db.Called(ID, from, to, issuerTransactionId)
+	return result.Bool(0), result.Error(1)
+}
+
+func (db *Repository) CreateEntry(entry depositPort.EntryRepo) error {
+	result := db.Called(entry)
+	return result.Error(0)
+}
+
+func (db *Repository) ListEntries(partnerCode string) ([]depositPort.EntryRepo, error) {
+	result := db.Called(partnerCode)
+	return result.Get(0).([]depositPort.EntryRepo), result.Error(1)
+}
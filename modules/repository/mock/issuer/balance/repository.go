@@ -0,0 +1,35 @@
+package balance
+
+import (
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByIssuerCode(code string) (issuerPort.Balance, error) {
+	result := db.Called(code)
+	return result.Get(0).(issuerPort.Balance), result.Error(1)
+}
+
+func (db *Repository) SaveBalance(code string, balance int64, source string) (issuerPort.Balance, error) {
+	result := db.Called(code, balance, source)
+	return result.Get(0).(issuerPort.Balance), result.Error(1)
+}
+
+func (db *Repository) DeductBalance(code string, amount int64) (issuerPort.Balance, bool, error) {
+	result := db.Called(code, amount)
+	return result.Get(0).(issuerPort.Balance), result.Bool(1), result.Error(2)
+}
+
+func (db *Repository) UpdateAlerted(code string, alerted bool) (bool, error) {
+	result := db.Called(code, alerted)
+	return result.Bool(0), result.Error(1)
+}
@@ -0,0 +1,25 @@
+package circuit
+
+import (
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) SaveState(state issuerPort.CircuitState) error {
+	result := db.Called(state)
+	return result.Error(0)
+}
+
+func (db *Repository) FindByIssuerCode(code string) (issuerPort.CircuitState, error) {
+	result := db.Called(code)
+	return result.Get(0).(issuerPort.CircuitState), result.Error(1)
+}
@@ -2,6 +2,7 @@ package issuer
 
 import (
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -43,3 +44,13 @@ func (db *Repository) ListData() ([]issuerPort.IssuerRepo, error) {
 	result := db.Called()
 	return result.Get(0).([]issuerPort.IssuerRepo), result.Error(1)
 }
+
+func (db *Repository) UpdateCircuitForcedOpen(ID string, forced bool) error {
+	result := db.Called(ID, forced)
+	return result.Error(0)
+}
+
+func (db *Repository) SearchData(listQuery query.Query) ([]issuerPort.IssuerRepo, query.Page, error) {
+	result := db.Called(listQuery)
+	return result.Get(0).([]issuerPort.IssuerRepo), result.Get(1).(query.Page), result.Error(2)
+}
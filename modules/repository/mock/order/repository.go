@@ -23,3 +23,28 @@ func (db *Repository) UpdateData(order orderPort.OrderRepo) error {
 	result := db.Called(order)
 	return result.Error(0)
 }
+
+func (db *Repository) ListPurchases(filter orderPort.PurchaseFilter) ([]orderPort.OrderRepo, error) {
+	result := db.Called(filter)
+	return result.Get(0).([]orderPort.OrderRepo), result.Error(1)
+}
+
+func (db *Repository) SearchData(filter orderPort.OrderFilter) ([]orderPort.OrderRepo, string, error) {
+	result := db.Called(filter)
+	return result.Get(0).([]orderPort.OrderRepo), result.String(1), result.Error(2)
+}
+
+func (db *Repository) ReadData(ID string) (orderPort.OrderRepo, error) {
+	result := db.Called(ID)
+	return result.Get(0).(orderPort.OrderRepo), result.Error(1)
+}
+
+func (db *Repository) ListTimeline(partnerId string, transactionId string, issuerTransactionIds []string) ([]orderPort.OrderRepo, error) {
+	result := db.Called(partnerId, transactionId, issuerTransactionIds)
+	return result.Get(0).([]orderPort.OrderRepo), result.Error(1)
+}
+
+func (db *Repository) RemoveData(IDs []string) (int, error) {
+	result := db.Called(IDs)
+	return result.Int(0), result.Error(1)
+}
@@ -2,6 +2,7 @@ package issuer
 
 import (
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -43,3 +44,8 @@ func (db *Repository) ListData() ([]partnerIssuerPort.PartnerIssuerRepo, error)
 	result := db.Called()
 	return result.Get(0).([]partnerIssuerPort.PartnerIssuerRepo), result.Error(1)
 }
+
+func (db *Repository) SearchData(listQuery query.Query) ([]partnerIssuerPort.PartnerIssuerRepo, query.Page, error) {
+	result := db.Called(listQuery)
+	return result.Get(0).([]partnerIssuerPort.PartnerIssuerRepo), result.Get(1).(query.Page), result.Error(2)
+}
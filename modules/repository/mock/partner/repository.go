@@ -2,6 +2,7 @@ package partner
 
 import (
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -43,3 +44,8 @@ func (db *Repository) ListData() ([]partnerPort.PartnerRepo, error) {
 	result := db.Called()
 	return result.Get(0).([]partnerPort.PartnerRepo), result.Error(1)
 }
+
+func (db *Repository) SearchData(listQuery query.Query) ([]partnerPort.PartnerRepo, query.Page, error) {
+	result := db.Called(listQuery)
+	return result.Get(0).([]partnerPort.PartnerRepo), result.Get(1).(query.Page), result.Error(2)
+}
@@ -0,0 +1,45 @@
+package price
+
+import (
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByPartnerProduct(partnerCode string, productCode string) ([]pricePort.PriceRepo, error) {
+	result := db.Called(partnerCode, productCode)
+	return result.Get(0).([]pricePort.PriceRepo), result.Error(1)
+}
+
+func (db *Repository) CreateData(price pricePort.PriceRepo) error {
+	result := db.Called(price)
+	return result.Error(0)
+}
+
+func (db *Repository) ReadData(ID string) (pricePort.PriceRepo, error) {
+	result := db.Called(ID)
+	return result.Get(0).(pricePort.PriceRepo), result.Error(1)
+}
+
+func (db *Repository) UpdateData(price pricePort.PriceRepo) error {
+	result := db.Called(price)
+	return result.Error(0)
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	result := db.Called(ID)
+	return result.Error(0)
+}
+
+func (db *Repository) ListData() ([]pricePort.PriceRepo, error) {
+	result := db.Called()
+	return result.Get(0).([]pricePort.PriceRepo), result.Error(1)
+}
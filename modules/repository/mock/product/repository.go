@@ -0,0 +1,50 @@
+package product
+
+import (
+	productPort "github.com/sepulsa/teleco/business/product/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByCode(code string) productPort.ProductRepo {
+	result := db.Called(code)
+	return result.Get(0).(productPort.ProductRepo)
+}
+
+func (db *Repository) CreateData(product productPort.ProductRepo) error {
+	result := db.Called(product)
+	return result.Error(0)
+}
+
+func (db *Repository) ReadData(ID string) (productPort.ProductRepo, error) {
+	result := db.Called(ID)
+	return result.Get(0).(productPort.ProductRepo), result.Error(1)
+}
+
+func (db *Repository) UpdateData(product productPort.ProductRepo) error {
+	result := db.Called(product)
+	return result.Error(0)
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	result := db.Called(ID)
+	return result.Error(0)
+}
+
+func (db *Repository) ListData() ([]productPort.ProductRepo, error) {
+	result := db.Called()
+	return result.Get(0).([]productPort.ProductRepo), result.Error(1)
+}
+
+func (db *Repository) ListActive() ([]productPort.ProductRepo, error) {
+	result := db.Called()
+	return result.Get(0).([]productPort.ProductRepo), result.Error(1)
+}
@@ -0,0 +1,22 @@
+package ratelimit
+
+import (
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) Take(key string, limits map[string]partnerPort.RateLimit, now time.Time) (bool, time.Duration, error) {
+	result := db.Called(key, limits, now)
+	return result.Bool(0), result.Get(1).(time.Duration), result.Error(2)
+}
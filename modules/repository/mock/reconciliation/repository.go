@@ -0,0 +1,40 @@
+package reconciliation
+
+import (
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) CreateRun(run reconciliationPort.RunRepo) (reconciliationPort.RunRepo, error) {
+	result := db.Called(run)
+	return result.Get(0).(reconciliationPort.RunRepo), result.Error(1)
+}
+
+func (db *Repository) CreateItems(items []reconciliationPort.ItemRepo) error {
+	result := db.Called(items)
+	return result.Error(0)
+}
+
+func (db *Repository) ReadRun(ID string) (reconciliationPort.RunRepo, error) {
+	result := db.Called(ID)
+	return result.Get(0).(reconciliationPort.RunRepo), result.Error(1)
+}
+
+func (db *Repository) ListRuns(issuerCode string) ([]reconciliationPort.RunRepo, error) {
+	result := db.Called(issuerCode)
+	return result.Get(0).([]reconciliationPort.RunRepo), result.Error(1)
+}
+
+func (db *Repository) ListItems(runId string, class string) ([]reconciliationPort.ItemRepo, error) {
+	result := db.Called(runId, class)
+	return result.Get(0).([]reconciliationPort.ItemRepo), result.Error(1)
+}
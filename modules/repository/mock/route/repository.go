@@ -0,0 +1,45 @@
+package route
+
+import (
+	routePort "github.com/sepulsa/teleco/business/route/port"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type Repository struct {
+	mock.Mock
+}
+
+func New() *Repository {
+	return &Repository{}
+}
+
+func (db *Repository) FindByProductCode(productCode string) routePort.RouteRepo {
+	result := db.Called(productCode)
+	return result.Get(0).(routePort.RouteRepo)
+}
+
+func (db *Repository) CreateData(route routePort.RouteRepo) error {
+	result := db.Called(route)
+	return result.Error(0)
+}
+
+func (db *Repository) ReadData(ID string) (routePort.RouteRepo, error) {
+	result := db.Called(ID)
+	return result.Get(0).(routePort.RouteRepo), result.Error(1)
+}
+
+func (db *Repository) UpdateData(route routePort.RouteRepo) error {
+	result := db.Called(route)
+	return result.Error(0)
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	result := db.Called(ID)
+	return result.Error(0)
+}
+
+func (db *Repository) ListData() ([]routePort.RouteRepo, error) {
+	result := db.Called()
+	return result.Get(0).([]routePort.RouteRepo), result.Error(1)
+}
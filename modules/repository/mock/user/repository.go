@@ -1,7 +1,10 @@
 package user
 
 import (
+	"time"
+
 	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/query"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -19,6 +22,16 @@ func (db *Repository) FindByEmail(email string) userPort.UserRepo {
 	return result.Get(0).(userPort.UserRepo)
 }
 
+func (db *Repository) FindByResetToken(tokenHash string) userPort.UserRepo {
+	result := db.Called(tokenHash)
+	return result.Get(0).(userPort.UserRepo)
+}
+
+func (db *Repository) UpdateResetToken(ID string, tokenHash string, expiredAt time.Time) error {
+	result := db.Called(ID, tokenHash, expiredAt)
+	return result.Error(0)
+}
+
 func (db *Repository) CreateData(user userPort.UserRepo) error {
 	result := db.Called(user)
 	return result.Error(0)
@@ -43,3 +56,8 @@ func (db *Repository) ListData() ([]userPort.UserRepo, error) {
 	result := db.Called()
 	return result.Get(0).([]userPort.UserRepo), result.Error(1)
 }
+
+func (db *Repository) SearchData(listQuery query.Query) ([]userPort.UserRepo, query.Page, error) {
+	result := db.Called(listQuery)
+	return result.Get(0).([]userPort.UserRepo), result.Get(1).(query.Page), result.Error(2)
+}
@@ -19,6 +19,16 @@ func (db *Repository) FindByTokenID(tokenID string) authPort.UserTokenRepo {
 	return result.Get(0).(authPort.UserTokenRepo)
 }
 
+func (db *Repository) ListDataByUserID(userID string) ([]authPort.UserTokenRepo, error) {
+	result := db.Called(userID)
+	return result.Get(0).([]authPort.UserTokenRepo), result.Error(1)
+}
+
+func (db *Repository) UpdateLastUsed(tokenID string) error {
+	result := db.Called(tokenID)
+	return result.Error(0)
+}
+
 func (db *Repository) CreateData(userToken authPort.UserTokenRepo) error {
 	result := db.Called(userToken)
 	return result.Error(0)
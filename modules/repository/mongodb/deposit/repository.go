@@ -0,0 +1,240 @@
+package deposit
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	// Repository balances are only changed with $inc so concurrent updates never overwrite each other
+	Repository struct {
+		accounts mongo.Collection
+		holds    mongo.Collection
+		entries  mongo.Collection
+	}
+
+	Account struct {
+		PartnerCode string    `bson:"_id" json:"partner_code"`
+		Balance     int64     `bson:"balance" json:"balance"`
+		Held        int64     `bson:"held" json:"held"`
+		UpdatedAt   time.Time `bson:"updated_at" json:"updated_at"`
+	}
+
+	Hold struct {
+		ID                  bson.ObjectId `bson:"_id,omitempty" json:"id"`
+		PartnerCode         string        `bson:"partner_code" json:"partner_code"`
+		TransactionId       string        `bson:"transaction_id" json:"transaction_id"`
+		IssuerTransactionId string        `bson:"issuer_transaction_id" json:"issuer_transaction_id"`
+		Amount              int64         `bson:"amount" json:"amount"`
+		Status              string        `bson:"status" json:"status"`
+		CreatedAt           time.Time     `bson:"created_at" json:"created_at"`
+		UpdatedAt           time.Time     `bson:"updated_at" json:"updated_at"`
+	}
+
+	Entry struct {
+		ID            bson.ObjectId `bson:"_id,omitempty" json:"id"`
+		PartnerCode   string        `bson:"partner_code" json:"partner_code"`
+		Type          string        `bson:"type" json:"type"`
+		DebitAccount  string        `bson:"debit_account" json:"debit_account"`
+		CreditAccount string        `bson:"credit_account" json:"credit_account"`
+		Amount        int64         `bson:"amount" json:"amount"`
+		Reference     string        `bson:"reference" json:"reference"`
+		CreatedAt     time.Time     `bson:"created_at" json:"created_at"`
+	}
+)
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrAccountNotFound = "Deposit account not found"
+	ErrHoldNotFound    = "Deposit hold not found"
+	ErrDuplicateHold   = "Transaction already holds deposit"
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		accounts: Mgo.C("deposit_account"),
+		holds:    Mgo.C("deposit_hold"),
+		entries:  Mgo.C("deposit_journal"),
+	}
+}
+
+func (db *Repository) FindAccount(partnerCode string) (account depositPort.AccountRepo, err error) {
+	var data Account
+	if err = db.accounts.Find(bson.M{"_id": partnerCode}).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrAccountNotFound)
+		}
+		return
+	}
+	return depositPort.AccountRepo(data), nil
+}
+
+func (db *Repository) Topup(partnerCode string, amount int64) error {
+	update := bson.M{
+		"$inc": bson.M{
+			"balance": amount,
+		},
+		"$set": bson.M{
+			"updated_at": time.Now(),
+		},
+	}
+	_, err := db.accounts.Upsert(bson.M{"_id": partnerCode}, update)
+	return err
+}
+
+func (db *Repository) HoldBalance(partnerCode string, amount int64) (bool, error) {
+	filter := bson.M{
+		"_id": partnerCode,
+		"balance": bson.M{
+			"$gte": amount,
+		},
+	}
+	update := bson.M{
+		"$inc": bson.M{
+			"balance": -amount,
+			"held":    amount,
+		},
+		"$set": bson.M{
+			"updated_at": time.Now(),
+		},
+	}
+	if err := db.accounts.Update(filter, update); err != nil {
+		if err == mgo.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (db *Repository) MoveBalance(partnerCode string, balance int64, held int64) error {
+	update := bson.M{
+		"$inc": bson.M{
+			"balance": balance,
+			"held":    held,
+		},
+		"$set": bson.M{
+			"updated_at": time.Now(),
+		},
+	}
+	return db.accounts.Update(bson.M{"_id": partnerCode}, update)
+}
+
+func (db *Repository) CreateHold(hold depositPort.HoldRepo) (depositPort.HoldRepo, error) {
+	data := Hold{
+		ID:            bson.NewObjectId(),
+		PartnerCode:   hold.PartnerCode,
+		TransactionId: hold.TransactionId,
+		Amount:        hold.Amount,
+		Status:        hold.Status,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+	if err := db.holds.Insert(data); err != nil {
+		// unique on partner code and transaction id
+		if mgo.IsDup(err) {
+			err = apperror.NewConflict(ErrDuplicateHold)
+		}
+		return hold, err
+	}
+	hold.ID = data.ID.Hex()
+	hold.CreatedAt = data.CreatedAt
+	hold.UpdatedAt = data.UpdatedAt
+	return hold, nil
+}
+
+func (db *Repository) ReadHold(ID string) (hold depositPort.HoldRepo, err error) {
+	if !bson.IsObjectIdHex(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+	return db.findHold(bson.M{"_id": bson.ObjectIdHex(ID)})
+}
+
+func (db *Repository) FindHold(partnerCode string, transactionId string) (depositPort.HoldRepo, error) {
+	return db.findHold(bson.M{
+		"partner_code":   partnerCode,
+		"transaction_id": transactionId,
+	})
+}
+
+func (db *Repository) FindHoldByIssuerTransaction(partnerCode string, issuerTransactionId string) (depositPort.HoldRepo, error) {
+	return db.findHold(bson.M{
+		"partner_code":          partnerCode,
+		"issuer_transaction_id": issuerTransactionId,
+	})
+}
+
+func (db *Repository) findHold(filter bson.M) (hold depositPort.HoldRepo, err error) {
+	var data Hold
+	if err = db.holds.Find(filter).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrHoldNotFound)
+		}
+		return
+	}
+	b, _ := json.Marshal(data)
+	json.Unmarshal(b, &hold)
+
+	return
+}
+
+func (db *Repository) UpdateHoldStatus(ID string, from string, to string, issuerTransactionId string) (bool, error) {
+	if !bson.IsObjectIdHex(ID) {
+		return false, errors.New(ErrInvalidID)
+	}
+
+	filter := bson.M{
+		"_id":    bson.ObjectIdHex(ID),
+		"status": from,
+	}
+	data := bson.M{
+		"status":     to,
+		"updated_at": time.Now(),
+	}
+	if issuerTransactionId != "" {
+		data["issuer_transaction_id"] = issuerTransactionId
+	}
+	if err := db.holds.Update(filter, bson.M{"$set": data}); err != nil {
+		if err == mgo.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (db *Repository) CreateEntry(entry depositPort.EntryRepo) error {
+	data := Entry{
+		PartnerCode:   entry.PartnerCode,
+		Type:          entry.Type,
+		DebitAccount:  entry.DebitAccount,
+		CreditAccount: entry.CreditAccount,
+		Amount:        entry.Amount,
+		Reference:     entry.Reference,
+		CreatedAt:     time.Now(),
+	}
+	return db.entries.Insert(data)
+}
+
+func (db *Repository) ListEntries(partnerCode string) (entries []depositPort.EntryRepo, err error) {
+	var data []Entry
+	if err = db.entries.Find(bson.M{"partner_code": partnerCode}).Sort("-created_at", "-_id").All(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &entries)
+
+	return
+}
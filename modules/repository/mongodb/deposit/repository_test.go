@@ -0,0 +1,17 @@
+package deposit
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+	"gopkg.in/mgo.v2"
+)
+
+func TestConformance(t *testing.T) {
+	db := conformance.MongoDB(t)
+	// one hold per partner transaction relies on the index of the migrations
+	if err := db.C("deposit_hold").EnsureIndex(mgo.Index{Key: []string{"partner_code", "transaction_id"}, Unique: true}); err != nil {
+		t.Fatal(err)
+	}
+	conformance.Deposit(t, New(db))
+}
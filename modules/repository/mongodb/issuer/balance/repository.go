@@ -0,0 +1,96 @@
+package balance
+
+import (
+	"errors"
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	Repository struct {
+		mongo.Collection
+	}
+
+	Balance struct {
+		IssuerCode string    `bson:"_id"`
+		Balance    int64     `bson:"balance"`
+		Source     string    `bson:"source"`
+		Alerted    bool      `bson:"alerted"`
+		UpdatedAt  time.Time `bson:"updated_at"`
+	}
+)
+
+var (
+	ErrBalanceNotFound = "Issuer balance not reported yet"
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		Mgo.C("issuer_balance"),
+	}
+}
+
+func (db *Repository) FindByIssuerCode(code string) (balance issuerPort.Balance, err error) {
+	var data Balance
+	if err = db.Find(bson.M{"_id": code}).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrBalanceNotFound)
+		}
+		return
+	}
+	return issuerPort.Balance(data), nil
+}
+
+func (db *Repository) SaveBalance(code string, balance int64, source string) (issuerPort.Balance, error) {
+	update := bson.M{
+		"$set": bson.M{
+			"balance":    balance,
+			"source":     source,
+			"updated_at": time.Now(),
+		},
+	}
+	if _, err := db.Upsert(bson.M{"_id": code}, update); err != nil {
+		return issuerPort.Balance{}, err
+	}
+	return db.FindByIssuerCode(code)
+}
+
+func (db *Repository) DeductBalance(code string, amount int64) (issuerPort.Balance, bool, error) {
+	update := bson.M{
+		"$inc": bson.M{
+			"balance": -amount,
+		},
+		"$set": bson.M{
+			"source":     issuerPort.BalanceSourceEstimate,
+			"updated_at": time.Now(),
+		},
+	}
+	if err := db.Update(bson.M{"_id": code}, update); err != nil {
+		if err == mgo.ErrNotFound {
+			return issuerPort.Balance{}, false, nil
+		}
+		return issuerPort.Balance{}, false, err
+	}
+	balance, err := db.FindByIssuerCode(code)
+	return balance, err == nil, err
+}
+
+func (db *Repository) UpdateAlerted(code string, alerted bool) (bool, error) {
+	filter := bson.M{
+		"_id": code,
+		"alerted": bson.M{
+			"$ne": alerted,
+		},
+	}
+	if err := db.Update(filter, bson.M{"$set": bson.M{"alerted": alerted}}); err != nil {
+		if err == mgo.ErrNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
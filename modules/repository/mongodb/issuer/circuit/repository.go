@@ -0,0 +1,50 @@
+package circuit
+
+import (
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/circuitbreaker"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	Repository struct {
+		mongo.Collection
+	}
+
+	Circuit struct {
+		IssuerCode string    `bson:"_id"`
+		State      string    `bson:"state"`
+		Calls      int       `bson:"calls"`
+		Failures   int       `bson:"failures"`
+		SlowCalls  int       `bson:"slow_calls"`
+		OpenedAt   time.Time `bson:"opened_at"`
+		UpdatedAt  time.Time `bson:"updated_at"`
+	}
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		Mgo.C("issuer_circuit"),
+	}
+}
+
+func (db *Repository) SaveState(state issuerPort.CircuitState) error {
+	data := Circuit(state)
+	_, err := db.Upsert(bson.M{"_id": state.IssuerCode}, data)
+	return err
+}
+
+func (db *Repository) FindByIssuerCode(code string) (state issuerPort.CircuitState, err error) {
+	var data Circuit
+	if err = db.Find(bson.M{"_id": code}).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			return issuerPort.CircuitState{IssuerCode: code, State: circuitbreaker.StateClosed}, nil
+		}
+		return
+	}
+	return issuerPort.CircuitState(data), nil
+}
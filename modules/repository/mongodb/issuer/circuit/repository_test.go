@@ -0,0 +1,11 @@
+package circuit
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.IssuerCircuit(t, New(conformance.MongoDB(t)))
+}
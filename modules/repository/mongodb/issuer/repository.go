@@ -6,7 +6,9 @@ import (
 	"time"
 
 	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/apperror"
 	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/sepulsa/teleco/utils/query"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -24,15 +26,51 @@ type (
 		ThreadNum        int           `bson:"thread_num" json:"thread_num"`
 		ThreadTimeout    int           `bson:"thread_timeout" json:"thread_timeout"`
 		QueueWorkerLimit int           `bson:"queue_worker_limit" json:"queue_worker_limit"`
-		CreatedAt        time.Time     `bson:"created_at"`
-		UpdatedAt        time.Time     `bson:"updated_at"`
-		DeletedAt        time.Time     `bson:"-,omitempty"`
+		Status           string        `bson:"status" json:"status"`
+
+		CircuitBreaker    CircuitBreaker `bson:"circuit_breaker" json:"circuit_breaker"`
+		CircuitForcedOpen bool           `bson:"circuit_forced_open" json:"circuit_forced_open"`
+
+		LowBalanceThreshold int64             `bson:"low_balance_threshold" json:"low_balance_threshold"`
+		SettlementMapping   SettlementMapping `bson:"settlement_mapping" json:"settlement_mapping"`
+
+		Version   int       `bson:"version" json:"version"`
+		CreatedAt time.Time `bson:"created_at"`
+		UpdatedAt time.Time `bson:"updated_at"`
+		DeletedAt time.Time `bson:"-,omitempty"`
+	}
+
+	CircuitBreaker struct {
+		ErrorRate        float64 `bson:"error_rate" json:"error_rate"`
+		SlowCallRate     float64 `bson:"slow_call_rate" json:"slow_call_rate"`
+		SlowCallDuration int     `bson:"slow_call_duration" json:"slow_call_duration"`
+		MinimumCalls     int     `bson:"minimum_calls" json:"minimum_calls"`
+		Window           int     `bson:"window" json:"window"`
+		OpenDuration     int     `bson:"open_duration" json:"open_duration"`
+		HalfOpenCalls    int     `bson:"half_open_calls" json:"half_open_calls"`
+	}
+
+	SettlementMapping struct {
+		TransactionId       string   `bson:"transaction_id" json:"transaction_id"`
+		IssuerTransactionId string   `bson:"issuer_transaction_id" json:"issuer_transaction_id"`
+		Amount              string   `bson:"amount" json:"amount"`
+		Status              string   `bson:"status" json:"status"`
+		SuccessStatus       []string `bson:"success_status" json:"success_status"`
+		Delimiter           string   `bson:"delimiter" json:"delimiter"`
 	}
 )
 
 var (
-	ErrInvalidID      = "Invalid ID"
-	ErrIssuerNotFound = "Issuer not found"
+	ErrInvalidID       = "Invalid ID"
+	ErrIssuerNotFound  = "Issuer not found"
+	ErrVersionConflict = "Issuer was updated since it was read, reload it and retry"
+	ErrDuplicateCode   = "Code already in use"
+
+	searchFields = mongo.SearchFields{
+		Search: []string{"code", "label"},
+		Sort:   []string{"code", "label", "status", "created_at", "updated_at"},
+		Filter: []string{"status"},
+	}
 )
 
 func New(Mgo *mongo.MongoDatabase) *Repository {
@@ -66,11 +104,24 @@ func (db *Repository) CreateData(issuer issuerPort.IssuerRepo) error {
 		ThreadNum:        issuer.ThreadNum,
 		ThreadTimeout:    issuer.ThreadTimeout,
 		QueueWorkerLimit: issuer.QueueWorkerLimit,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+		Status:           issuer.Status,
+		CircuitBreaker:   CircuitBreaker(issuer.CircuitBreaker),
+
+		LowBalanceThreshold: issuer.LowBalanceThreshold,
+		SettlementMapping:   SettlementMapping(issuer.SettlementMapping),
+
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
 	}
 
-	return db.Insert(data)
+	if err := db.Insert(data); err != nil {
+		// the code is unique among the issuers not deleted, the service check can race
+		if mgo.IsDup(err) {
+			return apperror.NewConflict(ErrDuplicateCode)
+		}
+		return err
+	}
+	return nil
 }
 
 func (db *Repository) ReadData(ID string) (issuer issuerPort.IssuerRepo, err error) {
@@ -99,6 +150,10 @@ func (db *Repository) ReadData(ID string) (issuer issuerPort.IssuerRepo, err err
 }
 
 func (db *Repository) UpdateData(issuer issuerPort.IssuerRepo) error {
+	if !bson.IsObjectIdHex(issuer.ID) {
+		return errors.New(ErrInvalidID)
+	}
+
 	data := bson.M{
 		"code":               issuer.Code,
 		"label":              issuer.Label,
@@ -106,9 +161,24 @@ func (db *Repository) UpdateData(issuer issuerPort.IssuerRepo) error {
 		"thread_num":         issuer.ThreadNum,
 		"thread_timeout":     issuer.ThreadTimeout,
 		"queue_worker_limit": issuer.QueueWorkerLimit,
-		"updated_at":         time.Now(),
+		"status":             issuer.Status,
+		"circuit_breaker":    CircuitBreaker(issuer.CircuitBreaker),
+
+		"low_balance_threshold": issuer.LowBalanceThreshold,
+		"settlement_mapping":    SettlementMapping(issuer.SettlementMapping),
+		"updated_at":            time.Now(),
+	}
+	switch err := mongo.UpdateVersion(db.Collection, bson.ObjectIdHex(issuer.ID), issuer.Version, bson.M{"$set": data}); err {
+	case mgo.ErrNotFound:
+		return errors.New(ErrIssuerNotFound)
+	case mongo.ErrVersionConflict:
+		return apperror.NewConflict(ErrVersionConflict)
+	default:
+		if mgo.IsDup(err) {
+			return apperror.NewConflict(ErrDuplicateCode)
+		}
+		return err
 	}
-	return db.Update(bson.M{"_id": bson.ObjectIdHex(issuer.ID)}, bson.M{"$set": data})
 }
 
 func (db *Repository) DeleteData(ID string) error {
@@ -154,3 +224,39 @@ func (db *Repository) ListData() (issuers []issuerPort.IssuerRepo, err error) {
 
 	return
 }
+
+func (db *Repository) SearchData(listQuery query.Query) (issuers []issuerPort.IssuerRepo, page query.Page, err error) {
+	var data []Issuer
+
+	filter := bson.M{
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if page, err = mongo.Search(db.Collection, filter, listQuery, searchFields, &data); err != nil {
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &issuers)
+
+	return
+}
+
+func (db *Repository) UpdateCircuitForcedOpen(ID string, forced bool) error {
+	if !bson.IsObjectIdHex(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	data := bson.M{
+		"circuit_forced_open": forced,
+		"updated_at":          time.Now(),
+	}
+	if err := db.Update(bson.M{"_id": bson.ObjectIdHex(ID)}, bson.M{"$set": data}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrIssuerNotFound)
+		}
+		return err
+	}
+	return nil
+}
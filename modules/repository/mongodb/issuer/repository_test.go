@@ -0,0 +1,28 @@
+package issuer
+
+import (
+	"testing"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Issuer(t, New(conformance.MongoDB(t)))
+}
+
+func TestDuplicateCode(t *testing.T) {
+	db := conformance.MongoDB(t)
+	// the code is unique through the index of the migrations
+	if err := db.C("issuer").EnsureIndex(mgo.Index{Key: []string{"code", "deleted_at"}, Unique: true}); err != nil {
+		t.Fatal(err)
+	}
+	repository := New(db)
+
+	assert.NoError(t, repository.CreateData(issuerPort.IssuerRepo{Code: "alpha"}))
+	err := repository.CreateData(issuerPort.IssuerRepo{Code: "alpha"})
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+}
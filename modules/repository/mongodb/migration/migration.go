@@ -0,0 +1,180 @@
+package migration
+
+import (
+	"errors"
+	"time"
+
+	"github.com/sepulsa/teleco/utils/config"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// Migrations indexes and backfills of the MongoDB repositories, append new versions, never edit an applied one.
+// Unique keys of soft deleted collections include deleted_at, it is missing on every live document so
+// the key is unique among them while deleted documents keep their value.
+var Migrations = []mongo.Migration{
+	{
+		Version:     1,
+		Description: "index issuer",
+		Up: mongo.EnsureIndexes("issuer",
+			mgo.Index{Key: []string{"code", "deleted_at"}, Unique: true},
+		),
+	},
+	{
+		Version:     2,
+		Description: "index partner",
+		Up: mongo.EnsureIndexes("partner",
+			mgo.Index{Key: []string{"code", "deleted_at"}, Unique: true},
+		),
+	},
+	{
+		Version:     3,
+		Description: "index partner issuer",
+		Up: mongo.EnsureIndexes("partnerIssuer",
+			mgo.Index{Key: []string{"partner_id", "issuer_id", "deleted_at"}, Unique: true},
+			mgo.Index{Key: []string{"issuer_id"}},
+		),
+	},
+	{
+		Version:     4,
+		Description: "index order",
+		Up: mongo.EnsureIndexes("order",
+			mgo.Index{Key: []string{"partner_id", "transaction_id"}},
+			mgo.Index{Key: []string{"issuer_id", "issuer_transaction_id"}},
+			mgo.Index{Key: []string{"command_type", "created_at"}},
+			mgo.Index{Key: []string{"created_at", "_id"}},
+			mgo.Index{Key: []string{"customer_number"}},
+		),
+	},
+	{
+		Version:     5,
+		Description: "index user",
+		Up: mongo.EnsureIndexes("user",
+			mgo.Index{Key: []string{"email", "deleted_at"}, Unique: true},
+			mgo.Index{Key: []string{"reset_token"}, Sparse: true},
+		),
+	},
+	{
+		Version:     6,
+		Description: "index user token",
+		Up: mongo.EnsureIndexes("usertoken",
+			mgo.Index{Key: []string{"token_id"}},
+			mgo.Index{Key: []string{"user_id", "last_used_at"}},
+		),
+	},
+	{
+		Version:     7,
+		Description: "index product",
+		Up: mongo.EnsureIndexes("product",
+			mgo.Index{Key: []string{"code", "deleted_at"}, Unique: true},
+		),
+	},
+	{
+		Version:     8,
+		Description: "index price",
+		Up: mongo.EnsureIndexes("price",
+			mgo.Index{Key: []string{"partner_code", "product_code", "effective_from"}},
+		),
+	},
+	{
+		Version:     9,
+		Description: "index route",
+		Up: mongo.EnsureIndexes("route",
+			mgo.Index{Key: []string{"product_code", "deleted_at"}, Unique: true},
+		),
+	},
+	{
+		Version:     10,
+		Description: "index deposit hold",
+		Up: mongo.EnsureIndexes("deposit_hold",
+			mgo.Index{Key: []string{"partner_code", "transaction_id"}},
+			mgo.Index{Key: []string{"partner_code", "issuer_transaction_id"}},
+		),
+	},
+	{
+		Version:     11,
+		Description: "index deposit journal",
+		Up: mongo.EnsureIndexes("deposit_journal",
+			mgo.Index{Key: []string{"partner_code", "created_at"}},
+		),
+	},
+	{
+		Version:     12,
+		Description: "index reconciliation run",
+		Up: mongo.EnsureIndexes("reconciliation_run",
+			mgo.Index{Key: []string{"issuer_code", "created_at"}},
+		),
+	},
+	{
+		Version:     13,
+		Description: "index reconciliation item",
+		Up: mongo.EnsureIndexes("reconciliation_item",
+			mgo.Index{Key: []string{"run_id", "class"}},
+		),
+	},
+	{
+		Version:     14,
+		Description: "backfill user token expired_at",
+		Up:          backfillTokenExpiredAt,
+	},
+	{
+		Version:     15,
+		Description: "backfill version of issuer, partner, partner issuer and user",
+		Up:          backfillVersion("issuer", "partner", "partnerIssuer", "user"),
+	},
+	{
+		Version:     16,
+		Description: "unique deposit hold transaction",
+		Up:          uniqueDepositHold,
+	},
+}
+
+// backfillTokenExpiredAt ends the sessions created before expiry tracking after the refresh token lifetime,
+// they never expired until now
+func backfillTokenExpiredAt(db mongo.DataLayer) error {
+	lifetime := time.Duration(config.GetJWTExpiredRefreshToken()) * time.Hour
+	if lifetime <= 0 {
+		return errors.New("jwt.expired_refresh_token is not set")
+	}
+
+	var tokens []struct {
+		ID        bson.ObjectId `bson:"_id"`
+		CreatedAt time.Time     `bson:"created_at"`
+	}
+	c := db.C("usertoken")
+	if err := c.Find(bson.M{"expired_at": bson.M{"$exists": false}}).All(&tokens); err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		update := bson.M{"$set": bson.M{"expired_at": token.CreatedAt.Add(lifetime)}}
+		if err := c.Update(bson.M{"_id": token.ID}, update); err != nil && err != mgo.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillVersion sets version 0 on the documents created before optimistic concurrency, an update only
+// matches the document carrying the version it read
+func backfillVersion(collections ...string) func(db mongo.DataLayer) error {
+	return func(db mongo.DataLayer) error {
+		for _, collection := range collections {
+			missing := bson.M{"version": bson.M{"$exists": false}}
+			if _, err := db.C(collection).UpdateAll(missing, bson.M{"$set": bson.M{"version": 0}}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// uniqueDepositHold replaces the partner transaction index of the deposit holds by a unique one, so concurrent
+// orders of a transaction can not both hold deposit
+func uniqueDepositHold(db mongo.DataLayer) error {
+	c := db.C("deposit_hold")
+	if err := c.DropIndexName("partner_code_1_transaction_id_1"); err != nil {
+		return err
+	}
+	return c.EnsureIndex(mgo.Index{Key: []string{"partner_code", "transaction_id"}, Unique: true})
+}
@@ -0,0 +1,42 @@
+package order
+
+import (
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ArchiveCollection collection of the records removed from the order collection
+const ArchiveCollection = "order_archive"
+
+type (
+	// Archive keeps the records removed by the retention in ArchiveCollection, with their id
+	Archive struct {
+		mongo.Collection
+	}
+
+	ArchivedOrder struct {
+		Order      `bson:",inline"`
+		ArchivedAt time.Time `bson:"archived_at"`
+	}
+)
+
+func NewArchive(Mgo *mongo.MongoDatabase) *Archive {
+	return &Archive{
+		Mgo.C(ArchiveCollection),
+	}
+}
+
+// Write upserts on the id, records archived again after an interrupted run are not duplicated
+func (db *Archive) Write(runAt time.Time, orders []orderPort.OrderRepo) (string, error) {
+	for _, order := range orders {
+		data := ArchivedOrder{toOrder(order), runAt}
+		data.UpdatedAt = data.CreatedAt
+		if _, err := db.Upsert(bson.M{"_id": data.ID}, data); err != nil {
+			return "", err
+		}
+	}
+	return ArchiveCollection, nil
+}
@@ -1,10 +1,16 @@
 package order
 
 import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	orderPort "github.com/sepulsa/teleco/business/order/port"
 	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
 
@@ -26,15 +32,28 @@ type (
 		ResponseData         string        `bson:"response_data" json:"response_data"`
 		CallbackRequestData  string        `bson:"callback_request_data"`
 		CallbackResponseData string        `bson:"callback_response_data"`
+		ProductCode          string        `bson:"product_code,omitempty" json:"product_code"`
+		Route                int           `bson:"route,omitempty" json:"route"`
+		Price                Price         `bson:"price,omitempty" json:"price"`
+		IssuerRescode        string        `bson:"issuer_rescode,omitempty" json:"issuer_rescode"`
+		SerialNumber         string        `bson:"serial_number,omitempty" json:"serial_number"`
 		CreatedAt            time.Time     `bson:"created_at" json:"created_at"`
 		UpdatedAt            time.Time     `bson:"updated_at" json:"update_id"`
 		DeletedAt            time.Time     `bson:"-,omitempty" json:"deleted_at"`
 	}
+
+	Price struct {
+		PriceId      string `bson:"price_id" json:"price_id"`
+		BasePrice    int64  `bson:"base_price" json:"base_price"`
+		SellingPrice int64  `bson:"selling_price" json:"selling_price"`
+		Fee          int64  `bson:"fee" json:"fee"`
+	}
 )
 
 var (
 	ErrInvalidID     = "Invalid ID"
-	ErrOrderNotFound = "Partner Issuer not found"
+	ErrOrderNotFound = "Order not found"
+	ErrInvalidCursor = "Invalid cursor"
 )
 
 func New(Mgo *mongo.MongoDatabase) *Repository {
@@ -44,6 +63,188 @@ func New(Mgo *mongo.MongoDatabase) *Repository {
 }
 
 func (db *Repository) CreateData(order orderPort.OrderRepo) error {
+	data := toOrder(order)
+	data.CreatedAt = time.Now()
+	data.UpdatedAt = time.Now()
+	if err := db.Insert(data); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (db *Repository) ListPurchases(purchaseFilter orderPort.PurchaseFilter) (orders []orderPort.OrderRepo, err error) {
+	var datas []Order
+	filter := bson.M{
+		"command_type": orderPort.Purchase,
+		"created_at": bson.M{
+			"$gte": purchaseFilter.From,
+			"$lt":  purchaseFilter.Until,
+		},
+	}
+	if purchaseFilter.IssuerId != "" {
+		filter["issuer_id"] = purchaseFilter.IssuerId
+	}
+	if purchaseFilter.PartnerId != "" {
+		filter["partner_id"] = purchaseFilter.PartnerId
+	}
+	if err = db.Find(filter).Sort("created_at").All(&datas); err != nil {
+		return
+	}
+	for _, data := range datas {
+		orders = append(orders, toOrderRepo(data))
+	}
+	return
+}
+
+func (db *Repository) SearchData(orderFilter orderPort.OrderFilter) (orders []orderPort.OrderRepo, next string, err error) {
+	filter := bson.M{}
+	if orderFilter.PartnerId != "" {
+		filter["partner_id"] = orderFilter.PartnerId
+	}
+	if orderFilter.IssuerId != "" {
+		filter["issuer_id"] = orderFilter.IssuerId
+	}
+	if orderFilter.CommandType != "" {
+		filter["command_type"] = orderFilter.CommandType
+	}
+	if orderFilter.TransactionId != "" {
+		filter["transaction_id"] = orderFilter.TransactionId
+	}
+	if orderFilter.IssuerTransactionId != "" {
+		filter["issuer_transaction_id"] = orderFilter.IssuerTransactionId
+	}
+	if orderFilter.CustomerNumber != "" {
+		filter["customer_number"] = orderFilter.CustomerNumber
+	}
+	pending := []interface{}{orderPort.RescodePending, "", nil}
+	switch orderFilter.Status {
+	case orderPort.StatusSuccess:
+		filter["issuer_rescode"] = orderPort.RescodeSuccess
+	case orderPort.StatusPending:
+		filter["issuer_rescode"] = bson.M{"$in": pending}
+	case orderPort.StatusFailed:
+		filter["issuer_rescode"] = bson.M{"$nin": append(pending, orderPort.RescodeSuccess)}
+	}
+	createdAt := bson.M{}
+	if !orderFilter.From.IsZero() {
+		createdAt["$gte"] = orderFilter.From
+	}
+	if !orderFilter.Until.IsZero() {
+		createdAt["$lt"] = orderFilter.Until
+	}
+	if len(createdAt) > 0 {
+		filter["created_at"] = createdAt
+	}
+
+	ascending := orderFilter.Sort == orderPort.SortCreatedAt
+	sort, op := []string{"-created_at", "-_id"}, "$lt"
+	if ascending {
+		sort, op = []string{"created_at", "_id"}, "$gt"
+	}
+	if orderFilter.Cursor != "" {
+		createdAt, ID, err := decodeCursor(orderFilter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		// records after the cursor, the id breaks ties on the same time
+		filter["$or"] = []bson.M{
+			{"created_at": bson.M{op: createdAt}},
+			{"created_at": createdAt, "_id": bson.M{op: ID}},
+		}
+	}
+
+	var datas []Order
+	if err = db.Find(filter).Sort(sort...).Limit(orderFilter.Limit + 1).All(&datas); err != nil {
+		return
+	}
+	if len(datas) > orderFilter.Limit {
+		datas = datas[:orderFilter.Limit]
+		last := datas[len(datas)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	for _, data := range datas {
+		orders = append(orders, toOrderRepo(data))
+	}
+	return
+}
+
+func (db *Repository) ReadData(ID string) (order orderPort.OrderRepo, err error) {
+	if !bson.IsObjectIdHex(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	var data Order
+	if err = db.Find(bson.M{"_id": bson.ObjectIdHex(ID)}).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrOrderNotFound)
+		}
+		return
+	}
+	order = toOrderRepo(data)
+	return
+}
+
+func (db *Repository) ListTimeline(partnerId string, transactionId string, issuerTransactionIds []string) (orders []orderPort.OrderRepo, err error) {
+	var datas []Order
+	filter := bson.M{
+		"partner_id": partnerId,
+		"$or": []bson.M{
+			{"transaction_id": transactionId},
+			{"issuer_transaction_id": bson.M{"$in": issuerTransactionIds}},
+		},
+	}
+	if err = db.Find(filter).Sort("created_at", "_id").All(&datas); err != nil {
+		return
+	}
+	for _, data := range datas {
+		orders = append(orders, toOrderRepo(data))
+	}
+	return
+}
+
+func (db *Repository) RemoveData(IDs []string) (int, error) {
+	objectIds := make([]bson.ObjectId, 0, len(IDs))
+	for _, ID := range IDs {
+		if bson.IsObjectIdHex(ID) {
+			objectIds = append(objectIds, bson.ObjectIdHex(ID))
+		}
+	}
+	if len(objectIds) == 0 {
+		return 0, nil
+	}
+	info, err := db.RemoveAll(bson.M{"_id": bson.M{"$in": objectIds}})
+	if err != nil {
+		return 0, err
+	}
+	return info.Removed, nil
+}
+
+// encodeCursor keeps the sort position of a record, it is opaque to the caller
+func encodeCursor(createdAt time.Time, ID bson.ObjectId) string {
+	position := fmt.Sprintf("%d:%s", createdAt.UnixNano(), ID.Hex())
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+func decodeCursor(cursor string) (createdAt time.Time, ID bson.ObjectId, err error) {
+	err = errors.New(ErrInvalidCursor)
+	position, decodeErr := base64.RawURLEncoding.DecodeString(cursor)
+	if decodeErr != nil {
+		return
+	}
+	parts := strings.SplitN(string(position), ":", 2)
+	if len(parts) != 2 || !bson.IsObjectIdHex(parts[1]) {
+		return
+	}
+	nano, parseErr := strconv.ParseInt(parts[0], 10, 64)
+	if parseErr != nil {
+		return
+	}
+	return time.Unix(0, nano), bson.ObjectIdHex(parts[1]), nil
+}
+
+// toOrder record of the collection, the id and times are only kept when set
+func toOrder(order orderPort.OrderRepo) Order {
 	data := Order{
 		CommandType:          order.CommandType,
 		IssuerTransactionId:  order.IssuerTransactionId,
@@ -56,11 +257,38 @@ func (db *Repository) CreateData(order orderPort.OrderRepo) error {
 		ResponseData:         order.ResponseData,
 		CallbackRequestData:  order.CallbackRequestData,
 		CallbackResponseData: order.CallbackResponseData,
-		CreatedAt:            time.Now(),
-		UpdatedAt:            time.Now(),
+		ProductCode:          order.ProductCode,
+		Route:                order.Route,
+		Price:                Price(order.Price),
+		IssuerRescode:        order.IssuerRescode,
+		SerialNumber:         order.SerialNumber,
+		CreatedAt:            order.CreatedAt,
 	}
-	if err := db.Insert(data); err != nil {
-		return err
+	if bson.IsObjectIdHex(order.ID) {
+		data.ID = bson.ObjectIdHex(order.ID)
+	}
+	return data
+}
+
+func toOrderRepo(data Order) orderPort.OrderRepo {
+	return orderPort.OrderRepo{
+		ID:                   data.ID.Hex(),
+		CommandType:          data.CommandType,
+		TransactionId:        data.TransactionId,
+		IssuerProductId:      data.IssuerProductId,
+		CustomerNumber:       data.CustomerNumber,
+		PartnerId:            data.PartnerId,
+		IssuerId:             data.IssuerId,
+		IssuerTransactionId:  data.IssuerTransactionId,
+		RequestData:          data.RequestData,
+		ResponseData:         data.ResponseData,
+		CallbackRequestData:  data.CallbackRequestData,
+		CallbackResponseData: data.CallbackResponseData,
+		ProductCode:          data.ProductCode,
+		Route:                data.Route,
+		Price:                orderPort.OrderPrice(data.Price),
+		IssuerRescode:        data.IssuerRescode,
+		SerialNumber:         data.SerialNumber,
+		CreatedAt:            data.CreatedAt,
 	}
-	return nil
 }
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/utils/apperror"
 	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/sepulsa/teleco/utils/query"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -21,15 +23,27 @@ type (
 		PartnerId string        `bson:"partner_id" json:"partner_id"`
 		IssuerId  string        `bson:"issuer_id" json:"issuer_id"`
 		Config    string        `bson:"config" json:"config"`
-		CreatedAt time.Time     `bson:"created_at" json:"created_at"`
-		UpdatedAt time.Time     `bson:"updated_at" json:"update_id"`
-		DeletedAt time.Time     `bson:"-,omitempty" json:"deleted_at"`
+
+		ReservedThread int       `bson:"reserved_thread" json:"reserved_thread"`
+		MaxThread      int       `bson:"max_thread" json:"max_thread"`
+		Version        int       `bson:"version" json:"version"`
+		CreatedAt      time.Time `bson:"created_at" json:"created_at"`
+		UpdatedAt      time.Time `bson:"updated_at" json:"update_id"`
+		DeletedAt      time.Time `bson:"-,omitempty" json:"deleted_at"`
 	}
 )
 
 var (
 	ErrInvalidID             = "Invalid ID"
 	ErrPartnerIssuerNotFound = "Partner Issuer not found"
+	ErrVersionConflict       = "Partner Issuer was updated since it was read, reload it and retry"
+	ErrDuplicatePair         = "Partner already mapped to the issuer"
+
+	searchFields = mongo.SearchFields{
+		Search: nil,
+		Sort:   []string{"created_at", "updated_at"},
+		Filter: []string{"partner_id", "issuer_id"},
+	}
 )
 
 func New(Mgo *mongo.MongoDatabase) *Repository {
@@ -43,10 +57,17 @@ func (db *Repository) CreateData(partnerIssuer partnerIssuerPort.PartnerIssuerRe
 		PartnerId: partnerIssuer.PartnerId,
 		IssuerId:  partnerIssuer.IssuerId,
 		Config:    partnerIssuer.Config,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+
+		ReservedThread: partnerIssuer.ReservedThread,
+		MaxThread:      partnerIssuer.MaxThread,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
 	}
 	if err := db.Insert(data); err != nil {
+		// the pair is unique among the records not deleted, the service check can race
+		if mgo.IsDup(err) {
+			return apperror.NewConflict(ErrDuplicatePair)
+		}
 		return err
 	}
 	return nil
@@ -99,16 +120,29 @@ func (db *Repository) ReadData(ID string) (partnerIssuer partnerIssuerPort.Partn
 }
 
 func (db *Repository) UpdateData(partnerIssuer partnerIssuerPort.PartnerIssuerRepo) error {
-	data := bson.M{
-		"partner_id": partnerIssuer.PartnerId,
-		"issuer_id":  partnerIssuer.IssuerId,
-		"config":     partnerIssuer.Config,
-		"updated_at": time.Now(),
+	if !bson.IsObjectIdHex(partnerIssuer.ID) {
+		return errors.New(ErrInvalidID)
 	}
-	if err := db.Update(bson.M{"_id": bson.ObjectIdHex(partnerIssuer.ID)}, bson.M{"$set": data}); err != nil {
+
+	data := bson.M{
+		"partner_id":      partnerIssuer.PartnerId,
+		"issuer_id":       partnerIssuer.IssuerId,
+		"config":          partnerIssuer.Config,
+		"reserved_thread": partnerIssuer.ReservedThread,
+		"max_thread":      partnerIssuer.MaxThread,
+		"updated_at":      time.Now(),
+	}
+	switch err := mongo.UpdateVersion(db.Collection, bson.ObjectIdHex(partnerIssuer.ID), partnerIssuer.Version, bson.M{"$set": data}); err {
+	case mgo.ErrNotFound:
+		return errors.New(ErrPartnerIssuerNotFound)
+	case mongo.ErrVersionConflict:
+		return apperror.NewConflict(ErrVersionConflict)
+	default:
+		if mgo.IsDup(err) {
+			return apperror.NewConflict(ErrDuplicatePair)
+		}
 		return err
 	}
-	return nil
 }
 
 func (db *Repository) DeleteData(ID string) error {
@@ -154,3 +188,21 @@ func (db *Repository) ListData() (partnerIssuers []partnerIssuerPort.PartnerIssu
 
 	return
 }
+
+func (db *Repository) SearchData(listQuery query.Query) (partnerIssuers []partnerIssuerPort.PartnerIssuerRepo, page query.Page, err error) {
+	var data []PartnerIssuer
+
+	filter := bson.M{
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if page, err = mongo.Search(db.Collection, filter, listQuery, searchFields, &data); err != nil {
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &partnerIssuers)
+
+	return
+}
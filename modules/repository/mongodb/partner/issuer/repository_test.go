@@ -0,0 +1,29 @@
+package issuer
+
+import (
+	"testing"
+
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.PartnerIssuer(t, New(conformance.MongoDB(t)))
+}
+
+func TestDuplicatePair(t *testing.T) {
+	db := conformance.MongoDB(t)
+	// the pair is unique through the index of the migrations
+	if err := db.C("partnerIssuer").EnsureIndex(mgo.Index{Key: []string{"partner_id", "issuer_id", "deleted_at"}, Unique: true}); err != nil {
+		t.Fatal(err)
+	}
+	repository := New(db)
+
+	pair := partnerIssuerPort.PartnerIssuerRepo{PartnerId: "partner", IssuerId: "issuer"}
+	assert.NoError(t, repository.CreateData(pair))
+	err := repository.CreateData(pair)
+	assert.Equal(t, apperror.Conflict, apperror.KindOf(err))
+}
@@ -6,7 +6,9 @@ import (
 	"time"
 
 	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/apperror"
 	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/sepulsa/teleco/utils/query"
 	"gopkg.in/mgo.v2"
 	"gopkg.in/mgo.v2/bson"
 )
@@ -26,15 +28,32 @@ type (
 		IpWhitelist []string      `json:"ip_whitelist" bson:"ip_whitelist"`
 		Status      string        `bson:"status"`
 		SecretKey   string        `json:"secret_key" bson:"secret_key"`
-		CreatedAt   time.Time     `bson:"created_at"`
-		UpdatedAt   time.Time     `bson:"updated_at"`
-		DeletedAt   time.Time     `bson:"-,omitempty"`
+
+		RateLimit       RateLimit            `json:"rate_limit" bson:"rate_limit"`
+		IssuerRateLimit map[string]RateLimit `json:"issuer_rate_limit" bson:"issuer_rate_limit,omitempty"`
+		Version         int                  `json:"version" bson:"version"`
+		CreatedAt       time.Time            `bson:"created_at"`
+		UpdatedAt       time.Time            `bson:"updated_at"`
+		DeletedAt       time.Time            `bson:"-,omitempty"`
+	}
+
+	RateLimit struct {
+		Rate  float64 `json:"rate" bson:"rate"`
+		Burst int     `json:"burst" bson:"burst"`
 	}
 )
 
 var (
 	ErrInvalidID       = "Invalid ID"
 	ErrPartnerNotFound = "Partner not found"
+	ErrVersionConflict = "Partner was updated since it was read, reload it and retry"
+	ErrDuplicateCode   = "Code already in use"
+
+	searchFields = mongo.SearchFields{
+		Search: []string{"code", "name", "pic"},
+		Sort:   []string{"code", "name", "status", "created_at", "updated_at"},
+		Filter: []string{"status"},
+	}
 )
 
 func New(Mgo *mongo.MongoDatabase) *Repository {
@@ -70,10 +89,17 @@ func (db *Repository) CreateData(partner partnerPort.PartnerRepo) error {
 		IpWhitelist: partner.IpWhitelist,
 		Status:      partner.Status,
 		SecretKey:   partner.SecretKey,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+
+		RateLimit:       RateLimit(partner.RateLimit),
+		IssuerRateLimit: toRateLimits(partner.IssuerRateLimit),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
 	}
 	if err := db.Insert(insertData); err != nil {
+		// the code is unique among the partners not deleted, the service check can race
+		if mgo.IsDup(err) {
+			return apperror.NewConflict(ErrDuplicateCode)
+		}
 		return err
 	}
 	return nil
@@ -105,6 +131,10 @@ func (db *Repository) ReadData(ID string) (partner partnerPort.PartnerRepo, err
 }
 
 func (db *Repository) UpdateData(partner partnerPort.PartnerRepo) error {
+	if !bson.IsObjectIdHex(partner.ID) {
+		return errors.New(ErrInvalidID)
+	}
+
 	data := bson.M{
 		"code":         partner.Code,
 		"name":         partner.Name,
@@ -114,12 +144,26 @@ func (db *Repository) UpdateData(partner partnerPort.PartnerRepo) error {
 		"ip_whitelist": partner.IpWhitelist,
 		"status":       partner.Status,
 		"secret_key":   partner.SecretKey,
+		"rate_limit":   RateLimit(partner.RateLimit),
 		"updated_at":   time.Now(),
 	}
-	if err := db.Update(bson.M{"_id": bson.ObjectIdHex(partner.ID)}, bson.M{"$set": data}); err != nil {
+	update := bson.M{"$set": data}
+	if len(partner.IssuerRateLimit) > 0 {
+		data["issuer_rate_limit"] = toRateLimits(partner.IssuerRateLimit)
+	} else {
+		update["$unset"] = bson.M{"issuer_rate_limit": ""}
+	}
+	switch err := mongo.UpdateVersion(db.Collection, bson.ObjectIdHex(partner.ID), partner.Version, update); err {
+	case mgo.ErrNotFound:
+		return errors.New(ErrPartnerNotFound)
+	case mongo.ErrVersionConflict:
+		return apperror.NewConflict(ErrVersionConflict)
+	default:
+		if mgo.IsDup(err) {
+			return apperror.NewConflict(ErrDuplicateCode)
+		}
 		return err
 	}
-	return nil
 }
 
 func (db *Repository) DeleteData(ID string) error {
@@ -165,3 +209,32 @@ func (db *Repository) ListData() (partners []partnerPort.PartnerRepo, err error)
 
 	return
 }
+
+func (db *Repository) SearchData(listQuery query.Query) (partners []partnerPort.PartnerRepo, page query.Page, err error) {
+	var data []Partner
+
+	filter := bson.M{
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if page, err = mongo.Search(db.Collection, filter, listQuery, searchFields, &data); err != nil {
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &partners)
+
+	return
+}
+
+func toRateLimits(limits map[string]partnerPort.RateLimit) map[string]RateLimit {
+	if len(limits) == 0 {
+		return nil
+	}
+	data := make(map[string]RateLimit, len(limits))
+	for issuerCode, limit := range limits {
+		data[issuerCode] = RateLimit(limit)
+	}
+	return data
+}
@@ -0,0 +1,160 @@
+package price
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	Repository struct {
+		mongo.Collection
+	}
+
+	Price struct {
+		ID             bson.ObjectId `bson:"_id,omitempty"`
+		PartnerCode    string        `bson:"partner_code" json:"partner_code"`
+		ProductCode    string        `bson:"product_code" json:"product_code"`
+		BasePrice      int64         `bson:"base_price" json:"base_price"`
+		SellingPrice   int64         `bson:"selling_price" json:"selling_price"`
+		Margin         int64         `bson:"margin" json:"margin"`
+		MarginPercent  float64       `bson:"margin_percent" json:"margin_percent"`
+		Fee            int64         `bson:"fee" json:"fee"`
+		EffectiveFrom  time.Time     `bson:"effective_from" json:"effective_from"`
+		EffectiveUntil time.Time     `bson:"effective_until" json:"effective_until"`
+		CreatedAt      time.Time     `bson:"created_at" json:"created_at"`
+		UpdatedAt      time.Time     `bson:"updated_at" json:"updated_at"`
+		DeletedAt      time.Time     `bson:"-,omitempty"`
+	}
+)
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrPriceNotFound = "Price not found"
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		Mgo.C("price"),
+	}
+}
+
+func (db *Repository) FindByPartnerProduct(partnerCode string, productCode string) (prices []pricePort.PriceRepo, err error) {
+	return db.list(bson.M{
+		"partner_code": partnerCode,
+		"product_code": productCode,
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	})
+}
+
+func (db *Repository) CreateData(price pricePort.PriceRepo) error {
+	data := Price{
+		PartnerCode:    price.PartnerCode,
+		ProductCode:    price.ProductCode,
+		BasePrice:      price.BasePrice,
+		SellingPrice:   price.SellingPrice,
+		Margin:         price.Margin,
+		MarginPercent:  price.MarginPercent,
+		Fee:            price.Fee,
+		EffectiveFrom:  price.EffectiveFrom,
+		EffectiveUntil: price.EffectiveUntil,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+
+	return db.Insert(data)
+}
+
+func (db *Repository) ReadData(ID string) (price pricePort.PriceRepo, err error) {
+	if !bson.IsObjectIdHex(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	var data Price
+	filterByID := bson.M{
+		"_id": bson.ObjectIdHex(ID),
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if err = db.Find(filterByID).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrPriceNotFound)
+		}
+		return
+	}
+	b, _ := json.Marshal(data)
+	json.Unmarshal(b, &price)
+
+	return
+}
+
+func (db *Repository) UpdateData(price pricePort.PriceRepo) error {
+	data := bson.M{
+		"partner_code":    price.PartnerCode,
+		"product_code":    price.ProductCode,
+		"base_price":      price.BasePrice,
+		"selling_price":   price.SellingPrice,
+		"margin":          price.Margin,
+		"margin_percent":  price.MarginPercent,
+		"fee":             price.Fee,
+		"effective_from":  price.EffectiveFrom,
+		"effective_until": price.EffectiveUntil,
+		"updated_at":      time.Now(),
+	}
+	return db.Update(bson.M{"_id": bson.ObjectIdHex(price.ID)}, bson.M{"$set": data})
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !bson.IsObjectIdHex(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	filter := bson.M{
+		"_id": bson.ObjectIdHex(ID),
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	data := bson.M{
+		"deleted_at": time.Now(),
+	}
+	if err := db.Update(filter, bson.M{"$set": data}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrPriceNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (db *Repository) ListData() ([]pricePort.PriceRepo, error) {
+	return db.list(bson.M{
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	})
+}
+
+func (db *Repository) list(filter bson.M) (prices []pricePort.PriceRepo, err error) {
+	var data []Price
+	if err = db.Find(filter).Sort("partner_code", "product_code", "-effective_from").All(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &prices)
+
+	return
+}
@@ -0,0 +1,11 @@
+package price
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Price(t, New(conformance.MongoDB(t)))
+}
@@ -0,0 +1,183 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	Repository struct {
+		mongo.Collection
+	}
+
+	Product struct {
+		ID           bson.ObjectId   `bson:"_id,omitempty"`
+		Code         string          `bson:"code" json:"code"`
+		Name         string          `bson:"name" json:"name"`
+		Operator     string          `bson:"operator" json:"operator"`
+		Type         string          `bson:"type" json:"type"`
+		Denomination int64           `bson:"denomination" json:"denomination"`
+		Active       bool            `bson:"active" json:"active"`
+		Issuers      []IssuerProduct `bson:"issuers" json:"issuers"`
+		CreatedAt    time.Time       `bson:"created_at" json:"created_at"`
+		UpdatedAt    time.Time       `bson:"updated_at" json:"updated_at"`
+		DeletedAt    time.Time       `bson:"-,omitempty"`
+	}
+
+	IssuerProduct struct {
+		IssuerCode      string `bson:"issuer_code" json:"issuer_code"`
+		IssuerProductId string `bson:"issuer_product_id" json:"issuer_product_id"`
+	}
+)
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrProductNotFound = "Product not found"
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		Mgo.C("product"),
+	}
+}
+
+func toIssuerProducts(issuers []productPort.IssuerProduct) []IssuerProduct {
+	data := make([]IssuerProduct, 0, len(issuers))
+	for _, issuer := range issuers {
+		data = append(data, IssuerProduct(issuer))
+	}
+	return data
+}
+
+func (db *Repository) FindByCode(code string) (product productPort.ProductRepo) {
+	var data Product
+	filterByCode := bson.M{
+		"code": code,
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if err := db.Find(filterByCode).One(&data); err != nil {
+		return
+	}
+	b, _ := json.Marshal(data)
+	json.Unmarshal(b, &product)
+
+	return
+}
+
+func (db *Repository) CreateData(product productPort.ProductRepo) error {
+	data := Product{
+		Code:         product.Code,
+		Name:         product.Name,
+		Operator:     product.Operator,
+		Type:         product.Type,
+		Denomination: product.Denomination,
+		Active:       product.Active,
+		Issuers:      toIssuerProducts(product.Issuers),
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	return db.Insert(data)
+}
+
+func (db *Repository) ReadData(ID string) (product productPort.ProductRepo, err error) {
+	if !bson.IsObjectIdHex(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	var data Product
+	filterByID := bson.M{
+		"_id": bson.ObjectIdHex(ID),
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if err = db.Find(filterByID).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrProductNotFound)
+		}
+		return
+	}
+	b, _ := json.Marshal(data)
+	json.Unmarshal(b, &product)
+
+	return
+}
+
+func (db *Repository) UpdateData(product productPort.ProductRepo) error {
+	data := bson.M{
+		"code":         product.Code,
+		"name":         product.Name,
+		"operator":     product.Operator,
+		"type":         product.Type,
+		"denomination": product.Denomination,
+		"active":       product.Active,
+		"issuers":      toIssuerProducts(product.Issuers),
+		"updated_at":   time.Now(),
+	}
+	return db.Update(bson.M{"_id": bson.ObjectIdHex(product.ID)}, bson.M{"$set": data})
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !bson.IsObjectIdHex(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	filter := bson.M{
+		"_id": bson.ObjectIdHex(ID),
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	data := bson.M{
+		"deleted_at": time.Now(),
+	}
+	if err := db.Update(filter, bson.M{"$set": data}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrProductNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (db *Repository) ListData() ([]productPort.ProductRepo, error) {
+	return db.list(bson.M{
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	})
+}
+
+func (db *Repository) ListActive() ([]productPort.ProductRepo, error) {
+	return db.list(bson.M{
+		"active": true,
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	})
+}
+
+func (db *Repository) list(filter bson.M) (products []productPort.ProductRepo, err error) {
+	var data []Product
+	if err = db.Find(filter).Sort("operator", "type", "denomination").All(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &products)
+
+	return
+}
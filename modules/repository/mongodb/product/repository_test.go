@@ -0,0 +1,11 @@
+package product
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Product(t, New(conformance.MongoDB(t)))
+}
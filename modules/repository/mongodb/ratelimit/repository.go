@@ -0,0 +1,100 @@
+package ratelimit
+
+import (
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/sepulsa/teleco/utils/ratelimit"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	// Repository shares buckets between replicas, the buckets of a key are one document
+	// so they are updated together, updates are compare-and-swap on version
+	Repository struct {
+		mongo.Collection
+	}
+
+	Buckets struct {
+		Key       string            `bson:"_id"`
+		Buckets   map[string]Bucket `bson:"buckets"`
+		Version   int64             `bson:"version"`
+		UpdatedAt time.Time         `bson:"updated_at"`
+	}
+
+	Bucket struct {
+		Tokens    float64   `bson:"tokens"`
+		UpdatedAt time.Time `bson:"updated_at"`
+	}
+)
+
+var (
+	ErrConflict = "Rate limit bucket update conflict"
+
+	// MaxRetry attempts before giving up on a contended bucket, the caller rejects the request then
+	MaxRetry = 5
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		Mgo.C("rate_limit"),
+	}
+}
+
+func (db *Repository) Take(key string, limits map[string]partnerPort.RateLimit, now time.Time) (bool, time.Duration, error) {
+	for i := 0; i < MaxRetry; i++ {
+		var data Buckets
+		err := db.Find(bson.M{"_id": key}).One(&data)
+		if err != nil && err != mgo.ErrNotFound {
+			return false, 0, err
+		}
+		exists := err == nil
+
+		buckets := make(map[string]*ratelimit.Bucket, len(limits))
+		bucketLimits := make(map[string]ratelimit.Limit, len(limits))
+		for name, limit := range limits {
+			if bucket, found := data.Buckets[name]; found {
+				buckets[name] = &ratelimit.Bucket{Tokens: bucket.Tokens, UpdatedAt: bucket.UpdatedAt}
+			}
+			bucketLimits[name] = ratelimit.Limit(limit)
+		}
+		allowed, retryAfter := ratelimit.TakeAll(buckets, bucketLimits, now)
+
+		if !exists {
+			data = Buckets{Key: key, Buckets: make(map[string]Bucket, len(buckets)), Version: 1, UpdatedAt: now}
+			for name, bucket := range buckets {
+				data.Buckets[name] = Bucket(*bucket)
+			}
+			err = db.Insert(data)
+			if mgo.IsDup(err) {
+				continue
+			}
+			return allowed, retryAfter, err
+		}
+
+		set := bson.M{"updated_at": now}
+		for name, bucket := range buckets {
+			set["buckets."+name] = Bucket(*bucket)
+		}
+		filter := bson.M{
+			"_id":     key,
+			"version": data.Version,
+		}
+		update := bson.M{
+			"$set": set,
+			"$inc": bson.M{
+				"version": 1,
+			},
+		}
+		err = db.Update(filter, update)
+		if err == mgo.ErrNotFound {
+			continue
+		}
+		return allowed, retryAfter, err
+	}
+
+	return false, 0, apperror.NewConflict(ErrConflict)
+}
@@ -0,0 +1,11 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.RateLimit(t, New(conformance.MongoDB(t)))
+}
@@ -0,0 +1,157 @@
+package reconciliation
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	Repository struct {
+		runs  mongo.Collection
+		items mongo.Collection
+	}
+
+	Run struct {
+		ID             bson.ObjectId `bson:"_id,omitempty" json:"id"`
+		IssuerCode     string        `bson:"issuer_code" json:"issuer_code"`
+		FileName       string        `bson:"file_name" json:"file_name"`
+		Date           time.Time     `bson:"date" json:"date"`
+		Rows           int           `bson:"rows" json:"rows"`
+		Matched        int           `bson:"matched" json:"matched"`
+		MissingOurs    int           `bson:"missing_ours" json:"missing_ours"`
+		MissingIssuer  int           `bson:"missing_issuer" json:"missing_issuer"`
+		AmountMismatch int           `bson:"amount_mismatch" json:"amount_mismatch"`
+		StatusMismatch int           `bson:"status_mismatch" json:"status_mismatch"`
+		Duplicate      int           `bson:"duplicate" json:"duplicate"`
+		CreatedAt      time.Time     `bson:"created_at" json:"created_at"`
+	}
+
+	Item struct {
+		ID                  bson.ObjectId `bson:"_id,omitempty" json:"id"`
+		RunId               string        `bson:"run_id" json:"run_id"`
+		Class               string        `bson:"class" json:"class"`
+		Row                 int           `bson:"row" json:"row"`
+		TransactionId       string        `bson:"transaction_id" json:"transaction_id"`
+		IssuerTransactionId string        `bson:"issuer_transaction_id" json:"issuer_transaction_id"`
+		Amount              int64         `bson:"amount" json:"amount"`
+		IssuerAmount        int64         `bson:"issuer_amount" json:"issuer_amount"`
+		Status              string        `bson:"status" json:"status"`
+		IssuerStatus        string        `bson:"issuer_status" json:"issuer_status"`
+	}
+)
+
+var (
+	ErrInvalidID              = "Invalid ID"
+	ErrReconciliationNotFound = "Reconciliation not found"
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		runs:  Mgo.C("reconciliation_run"),
+		items: Mgo.C("reconciliation_item"),
+	}
+}
+
+func (db *Repository) CreateRun(run reconciliationPort.RunRepo) (reconciliationPort.RunRepo, error) {
+	data := Run{
+		ID:             bson.NewObjectId(),
+		IssuerCode:     run.IssuerCode,
+		FileName:       run.FileName,
+		Date:           run.Date,
+		Rows:           run.Rows,
+		Matched:        run.Matched,
+		MissingOurs:    run.MissingOurs,
+		MissingIssuer:  run.MissingIssuer,
+		AmountMismatch: run.AmountMismatch,
+		StatusMismatch: run.StatusMismatch,
+		Duplicate:      run.Duplicate,
+		CreatedAt:      time.Now(),
+	}
+	if err := db.runs.Insert(data); err != nil {
+		return run, err
+	}
+	run.ID = data.ID.Hex()
+	run.CreatedAt = data.CreatedAt
+	return run, nil
+}
+
+func (db *Repository) CreateItems(items []reconciliationPort.ItemRepo) error {
+	docs := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		docs = append(docs, Item{
+			RunId:               item.RunId,
+			Class:               item.Class,
+			Row:                 item.Row,
+			TransactionId:       item.TransactionId,
+			IssuerTransactionId: item.IssuerTransactionId,
+			Amount:              item.Amount,
+			IssuerAmount:        item.IssuerAmount,
+			Status:              item.Status,
+			IssuerStatus:        item.IssuerStatus,
+		})
+	}
+	return db.items.Insert(docs...)
+}
+
+func (db *Repository) ReadRun(ID string) (run reconciliationPort.RunRepo, err error) {
+	if !bson.IsObjectIdHex(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	var data Run
+	if err = db.runs.Find(bson.M{"_id": bson.ObjectIdHex(ID)}).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrReconciliationNotFound)
+		}
+		return
+	}
+	b, _ := json.Marshal(data)
+	json.Unmarshal(b, &run)
+
+	return
+}
+
+func (db *Repository) ListRuns(issuerCode string) (runs []reconciliationPort.RunRepo, err error) {
+	var data []Run
+	filter := bson.M{}
+	if issuerCode != "" {
+		filter["issuer_code"] = issuerCode
+	}
+	if err = db.runs.Find(filter).Sort("-created_at").All(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &runs)
+
+	return
+}
+
+func (db *Repository) ListItems(runId string, class string) (items []reconciliationPort.ItemRepo, err error) {
+	var data []Item
+	filter := bson.M{"run_id": runId}
+	if class != "" {
+		filter["class"] = class
+	}
+	if err = db.items.Find(filter).Sort("_id").All(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &items)
+
+	return
+}
@@ -0,0 +1,11 @@
+package reconciliation
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Reconciliation(t, New(conformance.MongoDB(t)))
+}
@@ -0,0 +1,162 @@
+package route
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	Repository struct {
+		mongo.Collection
+	}
+
+	Route struct {
+		ID               bson.ObjectId `bson:"_id,omitempty"`
+		ProductCode      string        `bson:"product_code" json:"product_code"`
+		Candidates       []Candidate   `bson:"candidates" json:"candidates"`
+		FailoverRescodes []string      `bson:"failover_rescodes" json:"failover_rescodes"`
+		CreatedAt        time.Time     `bson:"created_at"`
+		UpdatedAt        time.Time     `bson:"updated_at"`
+		DeletedAt        time.Time     `bson:"-,omitempty"`
+	}
+
+	Candidate struct {
+		IssuerCode      string `bson:"issuer_code" json:"issuer_code"`
+		IssuerProductId string `bson:"issuer_product_id" json:"issuer_product_id"`
+		Priority        int    `bson:"priority" json:"priority"`
+		Weight          int    `bson:"weight" json:"weight"`
+	}
+)
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrRouteNotFound = "Route not found"
+)
+
+func New(Mgo *mongo.MongoDatabase) *Repository {
+	return &Repository{
+		Mgo.C("route"),
+	}
+}
+
+func toCandidates(candidates []routePort.Candidate) []Candidate {
+	data := make([]Candidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		data = append(data, Candidate(candidate))
+	}
+	return data
+}
+
+func (db *Repository) FindByProductCode(productCode string) (route routePort.RouteRepo) {
+	var data Route
+	filterByProductCode := bson.M{
+		"product_code": productCode,
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if err := db.Find(filterByProductCode).One(&data); err != nil {
+		return
+	}
+	b, _ := json.Marshal(data)
+	json.Unmarshal(b, &route)
+
+	return
+}
+
+func (db *Repository) CreateData(route routePort.RouteRepo) error {
+	data := Route{
+		ProductCode:      route.ProductCode,
+		Candidates:       toCandidates(route.Candidates),
+		FailoverRescodes: route.FailoverRescodes,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	return db.Insert(data)
+}
+
+func (db *Repository) ReadData(ID string) (route routePort.RouteRepo, err error) {
+	if !bson.IsObjectIdHex(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	var data Route
+	filterByID := bson.M{
+		"_id": bson.ObjectIdHex(ID),
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if err = db.Find(filterByID).One(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrRouteNotFound)
+		}
+		return
+	}
+	b, _ := json.Marshal(data)
+	json.Unmarshal(b, &route)
+
+	return
+}
+
+func (db *Repository) UpdateData(route routePort.RouteRepo) error {
+	data := bson.M{
+		"product_code":      route.ProductCode,
+		"candidates":        toCandidates(route.Candidates),
+		"failover_rescodes": route.FailoverRescodes,
+		"updated_at":        time.Now(),
+	}
+	return db.Update(bson.M{"_id": bson.ObjectIdHex(route.ID)}, bson.M{"$set": data})
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !bson.IsObjectIdHex(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	filter := bson.M{
+		"_id": bson.ObjectIdHex(ID),
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	data := bson.M{
+		"deleted_at": time.Now(),
+	}
+	if err := db.Update(filter, bson.M{"$set": data}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrRouteNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
+func (db *Repository) ListData() (routes []routePort.RouteRepo, err error) {
+	var data []Route
+
+	filter := bson.M{
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if err = db.Find(filter).All(&data); err != nil {
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		return
+	}
+
+	d, _ := json.Marshal(data)
+	json.Unmarshal(d, &routes)
+
+	return
+}
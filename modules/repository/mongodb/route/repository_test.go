@@ -0,0 +1,11 @@
+package route
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.Route(t, New(conformance.MongoDB(t)))
+}
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/apperror"
 	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/sepulsa/teleco/utils/query"
 	"gopkg.in/mgo.v2/bson"
 
 	"gopkg.in/mgo.v2"
@@ -17,19 +19,29 @@ type (
 	}
 
 	User struct {
-		ID        bson.ObjectId `bson:"_id,omitempty"`
-		Email     string        `bson:"email"`
-		Password  string        `bson:"password"`
-		Fullname  string        `bson:"fullname"`
-		CreatedAt time.Time     `bson:"created_at"`
-		UpdatedAt time.Time     `bson:"updated_at"`
-		DeletedAt time.Time     `bson:"-,omitempty"`
+		ID                  bson.ObjectId `bson:"_id,omitempty"`
+		Email               string        `bson:"email"`
+		Password            string        `bson:"password"`
+		PasswordHistory     []string      `bson:"password_history,omitempty"`
+		ResetToken          string        `bson:"reset_token,omitempty"`
+		ResetTokenExpiredAt time.Time     `bson:"reset_token_expired_at,omitempty"`
+		Fullname            string        `bson:"fullname"`
+		Version             int           `bson:"version"`
+		CreatedAt           time.Time     `bson:"created_at"`
+		UpdatedAt           time.Time     `bson:"updated_at"`
+		DeletedAt           time.Time     `bson:"-,omitempty"`
 	}
 )
 
 var (
-	ErrUserNotFound error = errors.New("user not found")
-	ErrInvalidID    error = errors.New("invalid id")
+	ErrUserNotFound    error = errors.New("user not found")
+	ErrInvalidID       error = errors.New("invalid id")
+	ErrVersionConflict error = apperror.NewConflict("user was updated since it was read, reload it and retry")
+
+	searchFields = mongo.SearchFields{
+		Search: []string{"email", "fullname"},
+		Sort:   []string{"email", "fullname", "created_at", "updated_at"},
+	}
 )
 
 func New(Mgo *mongo.MongoDatabase) *Repository {
@@ -52,12 +64,27 @@ func (db *Repository) FindByEmail(email string) userPort.UserRepo {
 		return user
 	}
 
-	user.ID = data.ID.Hex()
-	user.Email = data.Email
-	user.Fullname = data.Fullname
-	user.Password = data.Password
+	return toUserRepo(data)
+}
+
+func (db *Repository) FindByResetToken(tokenHash string) userPort.UserRepo {
+	var data User
+	var user userPort.UserRepo
+
+	filterByResetToken := bson.M{
+		"reset_token": tokenHash,
+		"reset_token_expired_at": bson.M{
+			"$gt": time.Now(),
+		},
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	if err := db.Find(filterByResetToken).One(&data); err != nil {
+		return user
+	}
 
-	return user
+	return toUserRepo(data)
 }
 
 func (db *Repository) ReadData(ID string) (userPort.UserRepo, error) {
@@ -81,12 +108,7 @@ func (db *Repository) ReadData(ID string) (userPort.UserRepo, error) {
 		return user, err
 	}
 
-	user.ID = data.ID.Hex()
-	user.Email = data.Email
-	user.Fullname = data.Fullname
-	user.Password = data.Password
-
-	return user, nil
+	return toUserRepo(data), nil
 }
 
 func (db *Repository) CreateData(user userPort.UserRepo) error {
@@ -101,16 +123,52 @@ func (db *Repository) CreateData(user userPort.UserRepo) error {
 }
 
 func (db *Repository) UpdateData(user userPort.UserRepo) error {
+	if !bson.IsObjectIdHex(user.ID) {
+		return ErrInvalidID
+	}
+
 	data := make(bson.M)
 	data["email"] = user.Email
 	data["fullname"] = user.Fullname
 	data["updated_at"] = time.Now()
 
+	update := bson.M{"$set": data}
 	if user.Password != "" {
 		data["password"] = user.Password
+		data["password_history"] = user.PasswordHistory
+		update["$unset"] = bson.M{
+			"reset_token":            "",
+			"reset_token_expired_at": "",
+		}
 	}
 
-	return db.Update(bson.M{"_id": bson.ObjectIdHex(user.ID)}, bson.M{"$set": data})
+	switch err := mongo.UpdateVersion(db.Collection, bson.ObjectIdHex(user.ID), user.Version, update); err {
+	case mgo.ErrNotFound:
+		return ErrUserNotFound
+	case mongo.ErrVersionConflict:
+		return ErrVersionConflict
+	default:
+		return err
+	}
+}
+
+func (db *Repository) UpdateResetToken(ID string, tokenHash string, expiredAt time.Time) error {
+	if !bson.IsObjectIdHex(ID) {
+		return ErrInvalidID
+	}
+
+	data := bson.M{
+		"reset_token":            tokenHash,
+		"reset_token_expired_at": expiredAt,
+		"updated_at":             time.Now(),
+	}
+	if err := db.Update(bson.M{"_id": bson.ObjectIdHex(ID)}, bson.M{"$set": data}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = ErrUserNotFound
+		}
+		return err
+	}
+	return nil
 }
 
 func (db *Repository) DeleteData(ID string) error {
@@ -158,8 +216,47 @@ func (db *Repository) ListData() ([]userPort.UserRepo, error) {
 		user.ID = datas[i].ID.Hex()
 		user.Email = datas[i].Email
 		user.Fullname = datas[i].Fullname
+		user.Version = datas[i].Version
 		users = append(users, user)
 	}
 
 	return users, nil
 }
+
+func (db *Repository) SearchData(listQuery query.Query) ([]userPort.UserRepo, query.Page, error) {
+	var datas []User
+	users := make([]userPort.UserRepo, 0)
+
+	filter := bson.M{
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	page, err := mongo.Search(db.Collection, filter, listQuery, searchFields, &datas)
+	if err != nil {
+		return users, page, err
+	}
+
+	for i := range datas {
+		users = append(users, userPort.UserRepo{
+			ID:       datas[i].ID.Hex(),
+			Email:    datas[i].Email,
+			Fullname: datas[i].Fullname,
+			Version:  datas[i].Version,
+		})
+	}
+
+	return users, page, nil
+}
+
+func toUserRepo(data User) userPort.UserRepo {
+	return userPort.UserRepo{
+		ID:                  data.ID.Hex(),
+		Email:               data.Email,
+		Fullname:            data.Fullname,
+		Password:            data.Password,
+		PasswordHistory:     data.PasswordHistory,
+		ResetTokenExpiredAt: data.ResetTokenExpiredAt,
+		Version:             data.Version,
+	}
+}
@@ -0,0 +1,11 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+)
+
+func TestConformance(t *testing.T) {
+	conformance.User(t, New(conformance.MongoDB(t)))
+}
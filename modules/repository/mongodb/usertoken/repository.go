@@ -16,12 +16,16 @@ type (
 	}
 
 	UserToken struct {
-		ID        bson.ObjectId `bson:"_id,omitempty"`
-		UserID    string        `bson:"user_id"`
-		TokenID   string        `bson:"token_id"`
-		CreatedAt time.Time     `bson:"created_at"`
-		UpdatedAt time.Time     `bson:"updated_at"`
-		DeletedAt time.Time     `bson:"-,omitempty"`
+		ID         bson.ObjectId `bson:"_id,omitempty"`
+		UserID     string        `bson:"user_id"`
+		TokenID    string        `bson:"token_id"`
+		IPAddress  string        `bson:"ip_address"`
+		UserAgent  string        `bson:"user_agent"`
+		LastUsedAt time.Time     `bson:"last_used_at"`
+		ExpiredAt  time.Time     `bson:"expired_at,omitempty"`
+		CreatedAt  time.Time     `bson:"created_at"`
+		UpdatedAt  time.Time     `bson:"updated_at"`
+		DeletedAt  time.Time     `bson:"-,omitempty"`
 	}
 )
 
@@ -40,8 +44,12 @@ func (db *Repository) CreateData(jwt authPort.UserTokenRepo) error {
 	var data UserToken
 	data.UserID = jwt.UserID
 	data.TokenID = jwt.TokenID
+	data.IPAddress = jwt.IPAddress
+	data.UserAgent = jwt.UserAgent
+	data.ExpiredAt = jwt.ExpiredAt
 	data.CreatedAt = time.Now()
 	data.UpdatedAt = time.Now()
+	data.LastUsedAt = data.CreatedAt
 
 	return db.Insert(data)
 }
@@ -56,13 +64,60 @@ func (db *Repository) FindByTokenID(tokenID string) authPort.UserTokenRepo {
 		},
 	}
 	if err := db.Find(filterByTokenID).One(&data); err == nil {
-		userToken.UserID = data.UserID
-		userToken.TokenID = data.TokenID
+		userToken = toUserTokenRepo(data)
 	}
 
 	return userToken
 }
 
+func (db *Repository) ListDataByUserID(userID string) ([]authPort.UserTokenRepo, error) {
+	var datas []UserToken
+	userTokens := make([]authPort.UserTokenRepo, 0)
+
+	filter := bson.M{
+		"user_id": userID,
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+		// tokens created before expiry tracking have no expired_at
+		"$or": []bson.M{
+			{"expired_at": bson.M{"$gt": time.Now()}},
+			{"expired_at": bson.M{"$exists": false}},
+		},
+	}
+	if err := db.Find(filter).Sort("-last_used_at").All(&datas); err != nil {
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		return userTokens, err
+	}
+
+	for i := range datas {
+		userTokens = append(userTokens, toUserTokenRepo(datas[i]))
+	}
+
+	return userTokens, nil
+}
+
+func (db *Repository) UpdateLastUsed(tokenID string) error {
+	filter := bson.M{
+		"token_id": tokenID,
+		"deleted_at": bson.M{
+			"$exists": false,
+		},
+	}
+	data := bson.M{
+		"last_used_at": time.Now(),
+	}
+	if err := db.Update(filter, bson.M{"$set": data}); err != nil {
+		if err == mgo.ErrNotFound {
+			err = errors.New(ErrUserTokenNotFound)
+		}
+		return err
+	}
+	return nil
+}
+
 func (db *Repository) DeleteData(tokenID string) error {
 	filter := bson.M{
 		"token_id": tokenID,
@@ -100,3 +155,15 @@ func (db *Repository) DeleteDataByUserID(userID string) error {
 	}
 	return nil
 }
+
+func toUserTokenRepo(data UserToken) authPort.UserTokenRepo {
+	return authPort.UserTokenRepo{
+		UserID:     data.UserID,
+		TokenID:    data.TokenID,
+		IPAddress:  data.IPAddress,
+		UserAgent:  data.UserAgent,
+		LastUsedAt: data.LastUsedAt,
+		ExpiredAt:  data.ExpiredAt,
+		CreatedAt:  data.CreatedAt,
+	}
+}
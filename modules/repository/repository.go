@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"errors"
+	"sync"
+
+	authPort "github.com/sepulsa/teleco/business/auth/port"
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	ratelimitPort "github.com/sepulsa/teleco/business/ratelimit/port"
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	userPort "github.com/sepulsa/teleco/business/user/port"
+	ratelimitMemoryRepository "github.com/sepulsa/teleco/modules/repository/memory/ratelimit"
+	depositRepository "github.com/sepulsa/teleco/modules/repository/mongodb/deposit"
+	issuerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/issuer"
+	issuerBalanceRepository "github.com/sepulsa/teleco/modules/repository/mongodb/issuer/balance"
+	issuerCircuitRepository "github.com/sepulsa/teleco/modules/repository/mongodb/issuer/circuit"
+	mongoMigration "github.com/sepulsa/teleco/modules/repository/mongodb/migration"
+	orderRepository "github.com/sepulsa/teleco/modules/repository/mongodb/order"
+	partnerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/partner"
+	partnerIssuerRepository "github.com/sepulsa/teleco/modules/repository/mongodb/partner/issuer"
+	priceRepository "github.com/sepulsa/teleco/modules/repository/mongodb/price"
+	productRepository "github.com/sepulsa/teleco/modules/repository/mongodb/product"
+	ratelimitRepository "github.com/sepulsa/teleco/modules/repository/mongodb/ratelimit"
+	reconciliationRepository "github.com/sepulsa/teleco/modules/repository/mongodb/reconciliation"
+	routeRepository "github.com/sepulsa/teleco/modules/repository/mongodb/route"
+	userRepository "github.com/sepulsa/teleco/modules/repository/mongodb/user"
+	userTokenRepository "github.com/sepulsa/teleco/modules/repository/mongodb/usertoken"
+	sqliteDepositRepository "github.com/sepulsa/teleco/modules/repository/sqlite/deposit"
+	sqliteIssuerRepository "github.com/sepulsa/teleco/modules/repository/sqlite/issuer"
+	sqliteIssuerBalanceRepository "github.com/sepulsa/teleco/modules/repository/sqlite/issuer/balance"
+	sqliteIssuerCircuitRepository "github.com/sepulsa/teleco/modules/repository/sqlite/issuer/circuit"
+	"github.com/sepulsa/teleco/modules/repository/sqlite/migration"
+	sqliteOrderRepository "github.com/sepulsa/teleco/modules/repository/sqlite/order"
+	sqlitePartnerRepository "github.com/sepulsa/teleco/modules/repository/sqlite/partner"
+	sqlitePartnerIssuerRepository "github.com/sepulsa/teleco/modules/repository/sqlite/partner/issuer"
+	sqlitePriceRepository "github.com/sepulsa/teleco/modules/repository/sqlite/price"
+	sqliteProductRepository "github.com/sepulsa/teleco/modules/repository/sqlite/product"
+	sqliteReconciliationRepository "github.com/sepulsa/teleco/modules/repository/sqlite/reconciliation"
+	sqliteRouteRepository "github.com/sepulsa/teleco/modules/repository/sqlite/route"
+	sqliteUserRepository "github.com/sepulsa/teleco/modules/repository/sqlite/user"
+	sqliteUserTokenRepository "github.com/sepulsa/teleco/modules/repository/sqlite/usertoken"
+	"github.com/sepulsa/teleco/utils/config"
+	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+var (
+	migrate sync.Once
+
+	ErrMongoDBDriver  = "MongoDB migrations need the mongodb database driver"
+	ErrRateLimitStore = "rate_limit.store mongo needs the mongodb database driver"
+)
+
+// sqliteDB reports whether the sqlite driver is selected, the schema is migrated on first use
+func sqliteDB() bool {
+	if config.GetDatabaseDriver() != config.DatabaseDriverSQLite {
+		return false
+	}
+	migrate.Do(func() {
+		if err := sqlite.Migrate(config.SQLite, migration.Migrations); err != nil {
+			panic(err)
+		}
+	})
+	return true
+}
+
+// MigrateMongoDB applies the MongoDB indexes and backfills not applied yet
+func MigrateMongoDB() error {
+	if config.GetDatabaseDriver() != config.DatabaseDriverMongoDB {
+		return errors.New(ErrMongoDBDriver)
+	}
+	return mongo.Migrate(config.Mgo, mongoMigration.Migrations)
+}
+
+// MigrateMongoDBOnStartup applies the MongoDB migrations when database.mongo.migrate is set
+func MigrateMongoDBOnStartup() {
+	if !config.GetMongoMigrate() || config.Mgo == nil {
+		return
+	}
+	if err := MigrateMongoDB(); err != nil {
+		panic(err)
+	}
+}
+
+// NewIssuer issuer repository of the configured database driver
+func NewIssuer() issuerPort.Repository {
+	if sqliteDB() {
+		return sqliteIssuerRepository.New(config.SQLite)
+	}
+	return issuerRepository.New(config.Mgo)
+}
+
+// NewPartner partner repository of the configured database driver
+func NewPartner() partnerPort.Repository {
+	if sqliteDB() {
+		return sqlitePartnerRepository.New(config.SQLite)
+	}
+	return partnerRepository.New(config.Mgo)
+}
+
+// NewPartnerIssuer partner issuer repository of the configured database driver
+func NewPartnerIssuer() partnerIssuerPort.Repository {
+	if sqliteDB() {
+		return sqlitePartnerIssuerRepository.New(config.SQLite)
+	}
+	return partnerIssuerRepository.New(config.Mgo)
+}
+
+// NewOrder order repository of the configured database driver
+func NewOrder() orderPort.Repository {
+	if sqliteDB() {
+		return sqliteOrderRepository.New(config.SQLite)
+	}
+	return orderRepository.New(config.Mgo)
+}
+
+// NewUser user repository of the configured database driver
+func NewUser() userPort.Repository {
+	if sqliteDB() {
+		return sqliteUserRepository.New(config.SQLite)
+	}
+	return userRepository.New(config.Mgo)
+}
+
+// NewUserToken user token repository of the configured database driver
+func NewUserToken() authPort.Repository {
+	if sqliteDB() {
+		return sqliteUserTokenRepository.New(config.SQLite)
+	}
+	return userTokenRepository.New(config.Mgo)
+}
+
+// NewProduct product repository of the configured database driver
+func NewProduct() productPort.Repository {
+	if sqliteDB() {
+		return sqliteProductRepository.New(config.SQLite)
+	}
+	return productRepository.New(config.Mgo)
+}
+
+// NewRoute route repository of the configured database driver
+func NewRoute() routePort.Repository {
+	if sqliteDB() {
+		return sqliteRouteRepository.New(config.SQLite)
+	}
+	return routeRepository.New(config.Mgo)
+}
+
+// NewPrice price repository of the configured database driver
+func NewPrice() pricePort.Repository {
+	if sqliteDB() {
+		return sqlitePriceRepository.New(config.SQLite)
+	}
+	return priceRepository.New(config.Mgo)
+}
+
+// NewDeposit deposit repository of the configured database driver
+func NewDeposit() depositPort.Repository {
+	if sqliteDB() {
+		return sqliteDepositRepository.New(config.SQLite)
+	}
+	return depositRepository.New(config.Mgo)
+}
+
+// NewIssuerCircuit issuer circuit repository of the configured database driver
+func NewIssuerCircuit() issuerPort.CircuitRepository {
+	if sqliteDB() {
+		return sqliteIssuerCircuitRepository.New(config.SQLite)
+	}
+	return issuerCircuitRepository.New(config.Mgo)
+}
+
+// NewIssuerBalance issuer balance repository of the configured database driver
+func NewIssuerBalance() issuerPort.BalanceRepository {
+	if sqliteDB() {
+		return sqliteIssuerBalanceRepository.New(config.SQLite)
+	}
+	return issuerBalanceRepository.New(config.Mgo)
+}
+
+// NewReconciliation reconciliation repository of the configured database driver
+func NewReconciliation() reconciliationPort.Repository {
+	if sqliteDB() {
+		return sqliteReconciliationRepository.New(config.SQLite)
+	}
+	return reconciliationRepository.New(config.Mgo)
+}
+
+// NewRateLimit token bucket repository of rate_limit.store, buckets shared through MongoDB need the mongodb driver
+func NewRateLimit() ratelimitPort.Repository {
+	if config.GetRateLimitStore() != config.RateLimitStoreMongo {
+		return ratelimitMemoryRepository.New()
+	}
+	if config.GetDatabaseDriver() != config.DatabaseDriverMongoDB {
+		panic(errors.New(ErrRateLimitStore))
+	}
+	return ratelimitRepository.New(config.Mgo)
+}
@@ -0,0 +1,205 @@
+package deposit
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	depositPort "github.com/sepulsa/teleco/business/deposit/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	// Repository balances are only changed by relative updates so concurrent updates never overwrite each other
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const holdColumns = `partner_code, transaction_id, issuer_transaction_id, amount, status, created_at, updated_at`
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrAccountNotFound = "Deposit account not found"
+	ErrHoldNotFound    = "Deposit hold not found"
+	ErrDuplicateHold   = "Transaction already holds deposit"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindAccount(partnerCode string) (account depositPort.AccountRepo, err error) {
+	var updatedAt sql.NullString
+	row := db.QueryRow(`SELECT partner_code, balance, held, updated_at FROM deposit_account WHERE partner_code = ?`, partnerCode)
+	if err = row.Scan(&account.PartnerCode, &account.Balance, &account.Held, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New(ErrAccountNotFound)
+		}
+		return depositPort.AccountRepo{}, err
+	}
+	account.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
+
+func (db *Repository) Topup(partnerCode string, amount int64) error {
+	_, err := db.Exec(`INSERT INTO deposit_account (partner_code, balance, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (partner_code) DO UPDATE SET balance = balance + excluded.balance, updated_at = excluded.updated_at`,
+		partnerCode, amount, sqlite.Time(time.Now()))
+	return err
+}
+
+func (db *Repository) HoldBalance(partnerCode string, amount int64) (bool, error) {
+	result, err := db.Exec(`UPDATE deposit_account SET balance = balance - ?, held = held + ?, updated_at = ?
+		WHERE partner_code = ? AND balance >= ?`, amount, amount, sqlite.Time(time.Now()), partnerCode, amount)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+func (db *Repository) MoveBalance(partnerCode string, balance int64, held int64) error {
+	result, err := db.Exec(`UPDATE deposit_account SET balance = balance + ?, held = held + ?, updated_at = ? WHERE partner_code = ?`,
+		balance, held, sqlite.Time(time.Now()), partnerCode)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrAccountNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) CreateHold(hold depositPort.HoldRepo) (depositPort.HoldRepo, error) {
+	ID := sqlite.NewID()
+	now := time.Now()
+	_, err := db.Exec(`INSERT INTO deposit_hold (id, `+holdColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		ID,
+		hold.PartnerCode,
+		hold.TransactionId,
+		"",
+		hold.Amount,
+		hold.Status,
+		sqlite.Time(now),
+		sqlite.Time(now),
+	)
+	if err != nil {
+		// unique on partner code and transaction id
+		if sqlite.IsDup(err) {
+			err = apperror.NewConflict(ErrDuplicateHold)
+		}
+		return hold, err
+	}
+	hold.ID = ID
+	hold.CreatedAt = now
+	hold.UpdatedAt = now
+	return hold, nil
+}
+
+func (db *Repository) ReadHold(ID string) (depositPort.HoldRepo, error) {
+	if !sqlite.IsID(ID) {
+		return depositPort.HoldRepo{}, errors.New(ErrInvalidID)
+	}
+	return db.findHold(`id = ?`, ID)
+}
+
+func (db *Repository) FindHold(partnerCode string, transactionId string) (depositPort.HoldRepo, error) {
+	return db.findHold(`partner_code = ? AND transaction_id = ?`, partnerCode, transactionId)
+}
+
+func (db *Repository) FindHoldByIssuerTransaction(partnerCode string, issuerTransactionId string) (depositPort.HoldRepo, error) {
+	return db.findHold(`partner_code = ? AND issuer_transaction_id = ?`, partnerCode, issuerTransactionId)
+}
+
+func (db *Repository) findHold(where string, args ...interface{}) (hold depositPort.HoldRepo, err error) {
+	row := db.QueryRow(`SELECT id, `+holdColumns+` FROM deposit_hold WHERE `+where+` ORDER BY id LIMIT 1`, args...)
+	if hold, err = scanHold(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrHoldNotFound)
+	}
+	return
+}
+
+func (db *Repository) UpdateHoldStatus(ID string, from string, to string, issuerTransactionId string) (bool, error) {
+	if !sqlite.IsID(ID) {
+		return false, errors.New(ErrInvalidID)
+	}
+
+	// the issuer transaction id is kept when none is given
+	result, err := db.Exec(`UPDATE deposit_hold SET status = ?, updated_at = ?,
+		issuer_transaction_id = CASE WHEN ? = '' THEN issuer_transaction_id ELSE ? END
+		WHERE id = ? AND status = ?`,
+		to, sqlite.Time(time.Now()), issuerTransactionId, issuerTransactionId, ID, from)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+func (db *Repository) CreateEntry(entry depositPort.EntryRepo) error {
+	_, err := db.Exec(`INSERT INTO deposit_journal (id, partner_code, type, debit_account, credit_account, amount, reference, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		entry.PartnerCode,
+		entry.Type,
+		entry.DebitAccount,
+		entry.CreditAccount,
+		entry.Amount,
+		entry.Reference,
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) ListEntries(partnerCode string) (entries []depositPort.EntryRepo, err error) {
+	rows, err := db.Query(`SELECT id, partner_code, type, debit_account, credit_account, amount, reference, created_at
+		FROM deposit_journal WHERE partner_code = ? ORDER BY created_at DESC, id DESC`, partnerCode)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry depositPort.EntryRepo
+		var createdAt sql.NullString
+		if err = rows.Scan(
+			&entry.ID,
+			&entry.PartnerCode,
+			&entry.Type,
+			&entry.DebitAccount,
+			&entry.CreditAccount,
+			&entry.Amount,
+			&entry.Reference,
+			&createdAt,
+		); err != nil {
+			return nil, err
+		}
+		entry.CreatedAt = sqlite.ParseTime(createdAt)
+		entries = append(entries, entry)
+	}
+	err = rows.Err()
+	return
+}
+
+func scanHold(scan sqlite.Scan) (hold depositPort.HoldRepo, err error) {
+	var createdAt, updatedAt sql.NullString
+	if err = scan(
+		&hold.ID,
+		&hold.PartnerCode,
+		&hold.TransactionId,
+		&hold.IssuerTransactionId,
+		&hold.Amount,
+		&hold.Status,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return depositPort.HoldRepo{}, err
+	}
+	hold.CreatedAt = sqlite.ParseTime(createdAt)
+	hold.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
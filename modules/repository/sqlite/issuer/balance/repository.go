@@ -0,0 +1,72 @@
+package balance
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+var (
+	ErrBalanceNotFound = "Issuer balance not reported yet"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindByIssuerCode(code string) (balance issuerPort.Balance, err error) {
+	var updatedAt sql.NullString
+	row := db.QueryRow(`SELECT issuer_code, balance, source, alerted, updated_at FROM issuer_balance WHERE issuer_code = ?`, code)
+	if err = row.Scan(&balance.IssuerCode, &balance.Balance, &balance.Source, &balance.Alerted, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			err = errors.New(ErrBalanceNotFound)
+		}
+		return issuerPort.Balance{}, err
+	}
+	balance.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
+
+func (db *Repository) SaveBalance(code string, balance int64, source string) (issuerPort.Balance, error) {
+	// the alert flag of a stored balance is kept
+	_, err := db.Exec(`INSERT INTO issuer_balance (issuer_code, balance, source, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (issuer_code) DO UPDATE SET balance = excluded.balance, source = excluded.source, updated_at = excluded.updated_at`,
+		code, balance, source, sqlite.Time(time.Now()))
+	if err != nil {
+		return issuerPort.Balance{}, err
+	}
+	return db.FindByIssuerCode(code)
+}
+
+func (db *Repository) DeductBalance(code string, amount int64) (issuerPort.Balance, bool, error) {
+	result, err := db.Exec(`UPDATE issuer_balance SET balance = balance - ?, source = ?, updated_at = ? WHERE issuer_code = ?`,
+		amount, issuerPort.BalanceSourceEstimate, sqlite.Time(time.Now()), code)
+	if err != nil {
+		return issuerPort.Balance{}, false, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return issuerPort.Balance{}, false, nil
+	}
+	balance, err := db.FindByIssuerCode(code)
+	return balance, err == nil, err
+}
+
+func (db *Repository) UpdateAlerted(code string, alerted bool) (bool, error) {
+	result, err := db.Exec(`UPDATE issuer_balance SET alerted = ? WHERE issuer_code = ? AND alerted != ?`, alerted, code, alerted)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
@@ -0,0 +1,19 @@
+package balance
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+	"github.com/sepulsa/teleco/modules/repository/sqlite/migration"
+	"github.com/sepulsa/teleco/utils/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConformance(t *testing.T) {
+	db, err := sqlite.Open(":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, sqlite.Migrate(db, migration.Migrations))
+
+	conformance.IssuerBalance(t, New(db))
+}
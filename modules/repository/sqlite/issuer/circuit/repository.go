@@ -0,0 +1,49 @@
+package circuit
+
+import (
+	"database/sql"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/circuitbreaker"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) SaveState(state issuerPort.CircuitState) error {
+	_, err := db.Exec(`INSERT OR REPLACE INTO issuer_circuit (issuer_code, state, calls, failures, slow_calls, opened_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		state.IssuerCode,
+		state.State,
+		state.Calls,
+		state.Failures,
+		state.SlowCalls,
+		sqlite.Time(state.OpenedAt),
+		sqlite.Time(state.UpdatedAt),
+	)
+	return err
+}
+
+func (db *Repository) FindByIssuerCode(code string) (state issuerPort.CircuitState, err error) {
+	var openedAt, updatedAt sql.NullString
+	row := db.QueryRow(`SELECT issuer_code, state, calls, failures, slow_calls, opened_at, updated_at FROM issuer_circuit WHERE issuer_code = ?`, code)
+	if err = row.Scan(&state.IssuerCode, &state.State, &state.Calls, &state.Failures, &state.SlowCalls, &openedAt, &updatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return issuerPort.CircuitState{IssuerCode: code, State: circuitbreaker.StateClosed}, nil
+		}
+		return issuerPort.CircuitState{}, err
+	}
+	state.OpenedAt = sqlite.ParseTime(openedAt)
+	state.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
@@ -0,0 +1,186 @@
+package issuer
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	issuerPort "github.com/sepulsa/teleco/business/issuer/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `code, label, config, thread_num, thread_timeout, queue_worker_limit, status,
+	circuit_breaker, circuit_forced_open, low_balance_threshold, settlement_mapping, version`
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrIssuerNotFound  = "Issuer not found"
+	ErrVersionConflict = "Issuer was updated since it was read, reload it and retry"
+
+	searchFields = sqlite.SearchFields{
+		Search: []string{"code", "label"},
+		Sort:   []string{"code", "label", "status", "created_at", "updated_at"},
+		Filter: []string{"status"},
+	}
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindByCode(code string) (issuer issuerPort.IssuerRepo) {
+	row := db.QueryRow(`SELECT id, `+columns+` FROM issuer WHERE code = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, code)
+	issuer, _ = scanIssuer(row.Scan)
+	return
+}
+
+func (db *Repository) CreateData(issuer issuerPort.IssuerRepo) error {
+	_, err := db.Exec(`INSERT INTO issuer (id, `+columns+`, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		issuer.Code,
+		issuer.Label,
+		issuer.Config,
+		issuer.ThreadNum,
+		issuer.ThreadTimeout,
+		issuer.QueueWorkerLimit,
+		issuer.Status,
+		sqlite.JSON(issuer.CircuitBreaker),
+		issuer.CircuitForcedOpen,
+		issuer.LowBalanceThreshold,
+		sqlite.JSON(issuer.SettlementMapping),
+		0,
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) ReadData(ID string) (issuer issuerPort.IssuerRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT id, `+columns+` FROM issuer WHERE id = ? AND deleted_at IS NULL`, ID)
+	if issuer, err = scanIssuer(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrIssuerNotFound)
+	}
+	return
+}
+
+func (db *Repository) UpdateData(issuer issuerPort.IssuerRepo) error {
+	err := sqlite.UpdateVersion(db.DB, "issuer", `code = ?, label = ?, config = ?, thread_num = ?, thread_timeout = ?,
+		queue_worker_limit = ?, status = ?, circuit_breaker = ?, low_balance_threshold = ?, settlement_mapping = ?,
+		updated_at = ?`, issuer.ID, issuer.Version,
+		issuer.Code,
+		issuer.Label,
+		issuer.Config,
+		issuer.ThreadNum,
+		issuer.ThreadTimeout,
+		issuer.QueueWorkerLimit,
+		issuer.Status,
+		sqlite.JSON(issuer.CircuitBreaker),
+		issuer.LowBalanceThreshold,
+		sqlite.JSON(issuer.SettlementMapping),
+		sqlite.Time(time.Now()),
+	)
+	switch err {
+	case sql.ErrNoRows:
+		return errors.New(ErrIssuerNotFound)
+	case sqlite.ErrVersionConflict:
+		return apperror.NewConflict(ErrVersionConflict)
+	default:
+		return err
+	}
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !sqlite.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	result, err := db.Exec(`UPDATE issuer SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrIssuerNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) ListData() (issuers []issuerPort.IssuerRepo, err error) {
+	rows, err := db.Query(`SELECT id, ` + columns + ` FROM issuer WHERE deleted_at IS NULL ORDER BY id`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		issuer, err := scanIssuer(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		issuers = append(issuers, issuer)
+	}
+	err = rows.Err()
+	return
+}
+
+func (db *Repository) SearchData(listQuery query.Query) (issuers []issuerPort.IssuerRepo, page query.Page, err error) {
+	page, err = sqlite.Search(db.DB, "issuer", "id, "+columns, "deleted_at IS NULL", nil, listQuery, searchFields, func(scan sqlite.Scan) error {
+		issuer, err := scanIssuer(scan)
+		issuers = append(issuers, issuer)
+		return err
+	})
+	return
+}
+
+func (db *Repository) UpdateCircuitForcedOpen(ID string, forced bool) error {
+	if !sqlite.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	result, err := db.Exec(`UPDATE issuer SET circuit_forced_open = ?, updated_at = ? WHERE id = ?`, forced, sqlite.Time(time.Now()), ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrIssuerNotFound)
+	}
+	return nil
+}
+
+func scanIssuer(scan sqlite.Scan) (issuer issuerPort.IssuerRepo, err error) {
+	var circuitBreaker, settlementMapping string
+	if err = scan(
+		&issuer.ID,
+		&issuer.Code,
+		&issuer.Label,
+		&issuer.Config,
+		&issuer.ThreadNum,
+		&issuer.ThreadTimeout,
+		&issuer.QueueWorkerLimit,
+		&issuer.Status,
+		&circuitBreaker,
+		&issuer.CircuitForcedOpen,
+		&issuer.LowBalanceThreshold,
+		&settlementMapping,
+		&issuer.Version,
+	); err != nil {
+		return issuerPort.IssuerRepo{}, err
+	}
+	sqlite.ParseJSON(circuitBreaker, &issuer.CircuitBreaker)
+	sqlite.ParseJSON(settlementMapping, &issuer.SettlementMapping)
+	return
+}
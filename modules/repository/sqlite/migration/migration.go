@@ -0,0 +1,305 @@
+package migration
+
+import "github.com/sepulsa/teleco/utils/sqlite"
+
+// Migrations schema of the SQLite repositories, append new versions, never edit an applied one
+var Migrations = []sqlite.Migration{
+	{
+		Version:     1,
+		Description: "create issuer",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS issuer (
+				id TEXT PRIMARY KEY,
+				code TEXT NOT NULL DEFAULT '',
+				label TEXT NOT NULL DEFAULT '',
+				config TEXT NOT NULL DEFAULT '',
+				thread_num INTEGER NOT NULL DEFAULT 0,
+				thread_timeout INTEGER NOT NULL DEFAULT 0,
+				queue_worker_limit INTEGER NOT NULL DEFAULT 0,
+				status TEXT NOT NULL DEFAULT '',
+				circuit_breaker TEXT NOT NULL DEFAULT '{}',
+				circuit_forced_open INTEGER NOT NULL DEFAULT 0,
+				low_balance_threshold INTEGER NOT NULL DEFAULT 0,
+				settlement_mapping TEXT NOT NULL DEFAULT '{}',
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS issuer_code ON issuer (code)`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "create partner",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS partner (
+				id TEXT PRIMARY KEY,
+				code TEXT NOT NULL DEFAULT '',
+				name TEXT NOT NULL DEFAULT '',
+				pic TEXT NOT NULL DEFAULT '',
+				address TEXT NOT NULL DEFAULT '',
+				callback_url TEXT NOT NULL DEFAULT '',
+				ip_whitelist TEXT NOT NULL DEFAULT '[]',
+				status TEXT NOT NULL DEFAULT '',
+				secret_key TEXT NOT NULL DEFAULT '',
+				rate_limit TEXT NOT NULL DEFAULT '{}',
+				issuer_rate_limit TEXT NOT NULL DEFAULT '{}',
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS partner_code ON partner (code)`,
+		},
+	},
+	{
+		Version:     3,
+		Description: "create partner_issuer",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS partner_issuer (
+				id TEXT PRIMARY KEY,
+				partner_id TEXT NOT NULL DEFAULT '',
+				issuer_id TEXT NOT NULL DEFAULT '',
+				config TEXT NOT NULL DEFAULT '',
+				reserved_thread INTEGER NOT NULL DEFAULT 0,
+				max_thread INTEGER NOT NULL DEFAULT 0,
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS partner_issuer_partner_issuer ON partner_issuer (partner_id, issuer_id)`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "create orders",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS orders (
+				id TEXT PRIMARY KEY,
+				command_type TEXT NOT NULL DEFAULT '',
+				transaction_id TEXT NOT NULL DEFAULT '',
+				issuer_product_id TEXT NOT NULL DEFAULT '',
+				customer_number TEXT NOT NULL DEFAULT '',
+				partner_id TEXT NOT NULL DEFAULT '',
+				issuer_id TEXT NOT NULL DEFAULT '',
+				issuer_transaction_id TEXT NOT NULL DEFAULT '',
+				request_data TEXT NOT NULL DEFAULT '',
+				response_data TEXT NOT NULL DEFAULT '',
+				callback_request_data TEXT NOT NULL DEFAULT '',
+				callback_response_data TEXT NOT NULL DEFAULT '',
+				product_code TEXT NOT NULL DEFAULT '',
+				route INTEGER NOT NULL DEFAULT 0,
+				price_id TEXT NOT NULL DEFAULT '',
+				base_price INTEGER NOT NULL DEFAULT 0,
+				selling_price INTEGER NOT NULL DEFAULT 0,
+				fee INTEGER NOT NULL DEFAULT 0,
+				issuer_rescode TEXT NOT NULL DEFAULT '',
+				serial_number TEXT NOT NULL DEFAULT '',
+				created_at TEXT NOT NULL,
+				updated_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS orders_created_at ON orders (created_at, id)`,
+			`CREATE INDEX IF NOT EXISTS orders_partner_transaction ON orders (partner_id, transaction_id)`,
+			`CREATE INDEX IF NOT EXISTS orders_issuer_transaction ON orders (issuer_transaction_id)`,
+			`CREATE INDEX IF NOT EXISTS orders_issuer_created_at ON orders (issuer_id, created_at)`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "create users",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS users (
+				id TEXT PRIMARY KEY,
+				email TEXT NOT NULL DEFAULT '',
+				password TEXT NOT NULL DEFAULT '',
+				password_history TEXT NOT NULL DEFAULT '[]',
+				reset_token TEXT,
+				reset_token_expired_at TEXT,
+				fullname TEXT NOT NULL DEFAULT '',
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS users_email ON users (email)`,
+			`CREATE INDEX IF NOT EXISTS users_reset_token ON users (reset_token)`,
+		},
+	},
+	{
+		Version:     6,
+		Description: "create user_tokens",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS user_tokens (
+				id TEXT PRIMARY KEY,
+				user_id TEXT NOT NULL DEFAULT '',
+				token_id TEXT NOT NULL DEFAULT '',
+				ip_address TEXT NOT NULL DEFAULT '',
+				user_agent TEXT NOT NULL DEFAULT '',
+				last_used_at TEXT,
+				expired_at TEXT,
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS user_tokens_token_id ON user_tokens (token_id)`,
+			`CREATE INDEX IF NOT EXISTS user_tokens_user_id ON user_tokens (user_id)`,
+		},
+	},
+	{
+		Version:     7,
+		Description: "add version to issuer, partner, partner_issuer and users",
+		Statements: []string{
+			`ALTER TABLE issuer ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE partner ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE partner_issuer ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+			`ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 0`,
+		},
+	},
+	{
+		Version:     8,
+		Description: "create product",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS product (
+				id TEXT PRIMARY KEY,
+				code TEXT NOT NULL DEFAULT '',
+				name TEXT NOT NULL DEFAULT '',
+				operator TEXT NOT NULL DEFAULT '',
+				type TEXT NOT NULL DEFAULT '',
+				denomination INTEGER NOT NULL DEFAULT 0,
+				active INTEGER NOT NULL DEFAULT 0,
+				issuers TEXT NOT NULL DEFAULT '[]',
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS product_code ON product (code)`,
+		},
+	},
+	{
+		Version:     9,
+		Description: "create route",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS route (
+				id TEXT PRIMARY KEY,
+				product_code TEXT NOT NULL DEFAULT '',
+				candidates TEXT NOT NULL DEFAULT '[]',
+				failover_rescodes TEXT NOT NULL DEFAULT '[]',
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS route_product_code ON route (product_code)`,
+		},
+	},
+	{
+		Version:     10,
+		Description: "create price",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS price (
+				id TEXT PRIMARY KEY,
+				partner_code TEXT NOT NULL DEFAULT '',
+				product_code TEXT NOT NULL DEFAULT '',
+				base_price INTEGER NOT NULL DEFAULT 0,
+				selling_price INTEGER NOT NULL DEFAULT 0,
+				margin INTEGER NOT NULL DEFAULT 0,
+				margin_percent REAL NOT NULL DEFAULT 0,
+				fee INTEGER NOT NULL DEFAULT 0,
+				effective_from TEXT,
+				effective_until TEXT,
+				created_at TEXT,
+				updated_at TEXT,
+				deleted_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS price_partner_product ON price (partner_code, product_code, effective_from)`,
+		},
+	},
+	{
+		Version:     11,
+		Description: "create deposit_account, deposit_hold and deposit_journal",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS deposit_account (
+				partner_code TEXT PRIMARY KEY,
+				balance INTEGER NOT NULL DEFAULT 0,
+				held INTEGER NOT NULL DEFAULT 0,
+				updated_at TEXT
+			)`,
+			`CREATE TABLE IF NOT EXISTS deposit_hold (
+				id TEXT PRIMARY KEY,
+				partner_code TEXT NOT NULL DEFAULT '',
+				transaction_id TEXT NOT NULL DEFAULT '',
+				issuer_transaction_id TEXT NOT NULL DEFAULT '',
+				amount INTEGER NOT NULL DEFAULT 0,
+				status TEXT NOT NULL DEFAULT '',
+				created_at TEXT,
+				updated_at TEXT
+			)`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS deposit_hold_partner_transaction ON deposit_hold (partner_code, transaction_id)`,
+			`CREATE INDEX IF NOT EXISTS deposit_hold_partner_issuer_transaction ON deposit_hold (partner_code, issuer_transaction_id)`,
+			`CREATE TABLE IF NOT EXISTS deposit_journal (
+				id TEXT PRIMARY KEY,
+				partner_code TEXT NOT NULL DEFAULT '',
+				type TEXT NOT NULL DEFAULT '',
+				debit_account TEXT NOT NULL DEFAULT '',
+				credit_account TEXT NOT NULL DEFAULT '',
+				amount INTEGER NOT NULL DEFAULT 0,
+				reference TEXT NOT NULL DEFAULT '',
+				created_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS deposit_journal_partner_created_at ON deposit_journal (partner_code, created_at)`,
+		},
+	},
+	{
+		Version:     12,
+		Description: "create issuer_circuit and issuer_balance",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS issuer_circuit (
+				issuer_code TEXT PRIMARY KEY,
+				state TEXT NOT NULL DEFAULT '',
+				calls INTEGER NOT NULL DEFAULT 0,
+				failures INTEGER NOT NULL DEFAULT 0,
+				slow_calls INTEGER NOT NULL DEFAULT 0,
+				opened_at TEXT,
+				updated_at TEXT
+			)`,
+			`CREATE TABLE IF NOT EXISTS issuer_balance (
+				issuer_code TEXT PRIMARY KEY,
+				balance INTEGER NOT NULL DEFAULT 0,
+				source TEXT NOT NULL DEFAULT '',
+				alerted INTEGER NOT NULL DEFAULT 0,
+				updated_at TEXT
+			)`,
+		},
+	},
+	{
+		Version:     13,
+		Description: "create reconciliation_run and reconciliation_item",
+		Statements: []string{
+			`CREATE TABLE IF NOT EXISTS reconciliation_run (
+				id TEXT PRIMARY KEY,
+				issuer_code TEXT NOT NULL DEFAULT '',
+				file_name TEXT NOT NULL DEFAULT '',
+				date TEXT,
+				rows INTEGER NOT NULL DEFAULT 0,
+				matched INTEGER NOT NULL DEFAULT 0,
+				missing_ours INTEGER NOT NULL DEFAULT 0,
+				missing_issuer INTEGER NOT NULL DEFAULT 0,
+				amount_mismatch INTEGER NOT NULL DEFAULT 0,
+				status_mismatch INTEGER NOT NULL DEFAULT 0,
+				duplicate INTEGER NOT NULL DEFAULT 0,
+				created_at TEXT
+			)`,
+			`CREATE INDEX IF NOT EXISTS reconciliation_run_issuer_created_at ON reconciliation_run (issuer_code, created_at)`,
+			`CREATE TABLE IF NOT EXISTS reconciliation_item (
+				id TEXT PRIMARY KEY,
+				run_id TEXT NOT NULL DEFAULT '',
+				class TEXT NOT NULL DEFAULT '',
+				row INTEGER NOT NULL DEFAULT 0,
+				transaction_id TEXT NOT NULL DEFAULT '',
+				issuer_transaction_id TEXT NOT NULL DEFAULT '',
+				amount INTEGER NOT NULL DEFAULT 0,
+				issuer_amount INTEGER NOT NULL DEFAULT 0,
+				status TEXT NOT NULL DEFAULT '',
+				issuer_status TEXT NOT NULL DEFAULT ''
+			)`,
+			`CREATE INDEX IF NOT EXISTS reconciliation_item_run_class ON reconciliation_item (run_id, class)`,
+		},
+	},
+}
@@ -0,0 +1,257 @@
+package order
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `id, command_type, transaction_id, issuer_product_id, customer_number, partner_id, issuer_id,
+	issuer_transaction_id, request_data, response_data, callback_request_data, callback_response_data, product_code,
+	route, price_id, base_price, selling_price, fee, issuer_rescode, serial_number, created_at`
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrOrderNotFound = "Order not found"
+	ErrInvalidCursor = "Invalid cursor"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) CreateData(order orderPort.OrderRepo) error {
+	_, err := db.Exec(`INSERT INTO orders (`+columns+`, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		order.CommandType,
+		order.TransactionId,
+		order.IssuerProductId,
+		order.CustomerNumber,
+		order.PartnerId,
+		order.IssuerId,
+		order.IssuerTransactionId,
+		order.RequestData,
+		order.ResponseData,
+		order.CallbackRequestData,
+		order.CallbackResponseData,
+		order.ProductCode,
+		order.Route,
+		order.Price.PriceId,
+		order.Price.BasePrice,
+		order.Price.SellingPrice,
+		order.Price.Fee,
+		order.IssuerRescode,
+		order.SerialNumber,
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) ListPurchases(purchaseFilter orderPort.PurchaseFilter) ([]orderPort.OrderRepo, error) {
+	conditions := []string{"command_type = ?", "created_at >= ?", "created_at < ?"}
+	args := []interface{}{orderPort.Purchase, sqlite.Time(purchaseFilter.From), sqlite.Time(purchaseFilter.Until)}
+	if purchaseFilter.IssuerId != "" {
+		conditions = append(conditions, "issuer_id = ?")
+		args = append(args, purchaseFilter.IssuerId)
+	}
+	if purchaseFilter.PartnerId != "" {
+		conditions = append(conditions, "partner_id = ?")
+		args = append(args, purchaseFilter.PartnerId)
+	}
+	return db.list(`SELECT `+columns+` FROM orders WHERE `+strings.Join(conditions, " AND ")+` ORDER BY created_at, id`, args...)
+}
+
+func (db *Repository) SearchData(orderFilter orderPort.OrderFilter) (orders []orderPort.OrderRepo, next string, err error) {
+	conditions := []string{}
+	args := []interface{}{}
+	equal := func(column string, value string) {
+		if value != "" {
+			conditions = append(conditions, column+" = ?")
+			args = append(args, value)
+		}
+	}
+	equal("partner_id", orderFilter.PartnerId)
+	equal("issuer_id", orderFilter.IssuerId)
+	equal("command_type", orderFilter.CommandType)
+	equal("transaction_id", orderFilter.TransactionId)
+	equal("issuer_transaction_id", orderFilter.IssuerTransactionId)
+	equal("customer_number", orderFilter.CustomerNumber)
+	switch orderFilter.Status {
+	case orderPort.StatusSuccess:
+		equal("issuer_rescode", orderPort.RescodeSuccess)
+	case orderPort.StatusPending:
+		conditions = append(conditions, "issuer_rescode IN (?, '')")
+		args = append(args, orderPort.RescodePending)
+	case orderPort.StatusFailed:
+		conditions = append(conditions, "issuer_rescode NOT IN (?, ?, '')")
+		args = append(args, orderPort.RescodePending, orderPort.RescodeSuccess)
+	}
+	if !orderFilter.From.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, sqlite.Time(orderFilter.From))
+	}
+	if !orderFilter.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, sqlite.Time(orderFilter.Until))
+	}
+
+	ascending := orderFilter.Sort == orderPort.SortCreatedAt
+	order, op := "DESC", "<"
+	if ascending {
+		order, op = "ASC", ">"
+	}
+	if orderFilter.Cursor != "" {
+		createdAt, ID, err := decodeCursor(orderFilter.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		// records after the cursor, the id breaks ties on the same time
+		conditions = append(conditions, "(created_at "+op+" ? OR (created_at = ? AND id "+op+" ?))")
+		args = append(args, sqlite.Time(createdAt), sqlite.Time(createdAt), ID)
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, orderFilter.Limit+1)
+
+	if orders, err = db.list(`SELECT `+columns+` FROM orders`+where+` ORDER BY created_at `+order+`, id `+order+` LIMIT ?`, args...); err != nil {
+		return
+	}
+	if len(orders) > orderFilter.Limit {
+		orders = orders[:orderFilter.Limit]
+		last := orders[len(orders)-1]
+		next = encodeCursor(last.CreatedAt, last.ID)
+	}
+	return
+}
+
+func (db *Repository) ReadData(ID string) (order orderPort.OrderRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT `+columns+` FROM orders WHERE id = ?`, ID)
+	if order, err = scanOrder(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrOrderNotFound)
+	}
+	return
+}
+
+func (db *Repository) ListTimeline(partnerId string, transactionId string, issuerTransactionIds []string) ([]orderPort.OrderRepo, error) {
+	match := "transaction_id = ?"
+	args := []interface{}{partnerId, transactionId}
+	if len(issuerTransactionIds) > 0 {
+		match += " OR issuer_transaction_id IN (?" + strings.Repeat(", ?", len(issuerTransactionIds)-1) + ")"
+		for _, issuerTransactionId := range issuerTransactionIds {
+			args = append(args, issuerTransactionId)
+		}
+	}
+	return db.list(`SELECT `+columns+` FROM orders WHERE partner_id = ? AND (`+match+`) ORDER BY created_at, id`, args...)
+}
+
+func (db *Repository) RemoveData(IDs []string) (int, error) {
+	if len(IDs) == 0 {
+		return 0, nil
+	}
+	args := make([]interface{}, 0, len(IDs))
+	for _, ID := range IDs {
+		args = append(args, ID)
+	}
+	result, err := db.Exec(`DELETE FROM orders WHERE id IN (?`+strings.Repeat(", ?", len(IDs)-1)+`)`, args...)
+	if err != nil {
+		return 0, err
+	}
+	removed, err := result.RowsAffected()
+	return int(removed), err
+}
+
+func (db *Repository) list(query string, args ...interface{}) (orders []orderPort.OrderRepo, err error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		order, err := scanOrder(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+	err = rows.Err()
+	return
+}
+
+// encodeCursor keeps the sort position of a record, it is opaque to the caller
+func encodeCursor(createdAt time.Time, ID string) string {
+	position := fmt.Sprintf("%d:%s", createdAt.UnixNano(), ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(position))
+}
+
+func decodeCursor(cursor string) (createdAt time.Time, ID string, err error) {
+	err = errors.New(ErrInvalidCursor)
+	position, decodeErr := base64.RawURLEncoding.DecodeString(cursor)
+	if decodeErr != nil {
+		return
+	}
+	parts := strings.SplitN(string(position), ":", 2)
+	if len(parts) != 2 || !sqlite.IsID(parts[1]) {
+		return
+	}
+	nano, parseErr := strconv.ParseInt(parts[0], 10, 64)
+	if parseErr != nil {
+		return
+	}
+	return time.Unix(0, nano), parts[1], nil
+}
+
+func scanOrder(scan sqlite.Scan) (order orderPort.OrderRepo, err error) {
+	var createdAt sql.NullString
+	if err = scan(
+		&order.ID,
+		&order.CommandType,
+		&order.TransactionId,
+		&order.IssuerProductId,
+		&order.CustomerNumber,
+		&order.PartnerId,
+		&order.IssuerId,
+		&order.IssuerTransactionId,
+		&order.RequestData,
+		&order.ResponseData,
+		&order.CallbackRequestData,
+		&order.CallbackResponseData,
+		&order.ProductCode,
+		&order.Route,
+		&order.Price.PriceId,
+		&order.Price.BasePrice,
+		&order.Price.SellingPrice,
+		&order.Price.Fee,
+		&order.IssuerRescode,
+		&order.SerialNumber,
+		&createdAt,
+	); err != nil {
+		return orderPort.OrderRepo{}, err
+	}
+	order.CreatedAt = sqlite.ParseTime(createdAt)
+	return
+}
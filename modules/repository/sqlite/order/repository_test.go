@@ -0,0 +1,94 @@
+package order
+
+import (
+	"testing"
+	"time"
+
+	orderPort "github.com/sepulsa/teleco/business/order/port"
+	"github.com/sepulsa/teleco/modules/repository/conformance"
+	"github.com/sepulsa/teleco/modules/repository/sqlite/migration"
+	"github.com/sepulsa/teleco/utils/sqlite"
+	"github.com/stretchr/testify/assert"
+)
+
+func newRepository(t *testing.T) *Repository {
+	db, err := sqlite.Open(":memory:")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, sqlite.Migrate(db, migration.Migrations)) {
+		t.FailNow()
+	}
+	return New(db)
+}
+
+func TestSearchData(t *testing.T) {
+	repository := newRepository(t)
+	defer repository.Close()
+	for _, rescode := range []string{orderPort.RescodeSuccess, orderPort.RescodePending, "14"} {
+		assert.NoError(t, repository.CreateData(orderPort.OrderRepo{
+			CommandType:   orderPort.Purchase,
+			TransactionId: "trx-" + rescode,
+			PartnerId:     "partner",
+			IssuerRescode: rescode,
+			Price:         orderPort.OrderPrice{PriceId: "price", SellingPrice: 10000},
+		}))
+	}
+
+	orders, next, err := repository.SearchData(orderPort.OrderFilter{Sort: orderPort.SortCreatedAt, Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, orders, 2) {
+		assert.Equal(t, "trx-00", orders[0].TransactionId)
+		assert.Equal(t, int64(10000), orders[0].Price.SellingPrice)
+		assert.NotEmpty(t, next)
+	}
+	orders, next, err = repository.SearchData(orderPort.OrderFilter{Sort: orderPort.SortCreatedAt, Limit: 2, Cursor: next})
+	if assert.NoError(t, err) && assert.Len(t, orders, 1) {
+		assert.Equal(t, "trx-14", orders[0].TransactionId)
+		assert.Empty(t, next)
+	}
+
+	orders, _, err = repository.SearchData(orderPort.OrderFilter{Status: orderPort.StatusFailed, Limit: 20})
+	if assert.NoError(t, err) && assert.Len(t, orders, 1) {
+		assert.Equal(t, "14", orders[0].IssuerRescode)
+	}
+	orders, _, err = repository.SearchData(orderPort.OrderFilter{Until: time.Now().Add(-time.Hour), Limit: 20})
+	if assert.NoError(t, err) {
+		assert.Empty(t, orders)
+	}
+
+	_, _, err = repository.SearchData(orderPort.OrderFilter{Cursor: "x", Limit: 20})
+	assert.Equal(t, ErrInvalidCursor, err.Error())
+}
+
+func TestReadData(t *testing.T) {
+	repository := newRepository(t)
+	defer repository.Close()
+	repository.CreateData(orderPort.OrderRepo{CommandType: orderPort.Purchase, TransactionId: "trx", PartnerId: "partner", IssuerTransactionId: "issuer-trx"})
+	repository.CreateData(orderPort.OrderRepo{CommandType: orderPort.Advise, TransactionId: "advise", PartnerId: "partner", IssuerTransactionId: "issuer-trx"})
+	repository.CreateData(orderPort.OrderRepo{CommandType: orderPort.Purchase, TransactionId: "trx", PartnerId: "other"})
+
+	orders, _, _ := repository.SearchData(orderPort.OrderFilter{PartnerId: "partner", Sort: orderPort.SortCreatedAt, Limit: 20})
+	if !assert.Len(t, orders, 2) {
+		return
+	}
+	order, err := repository.ReadData(orders[0].ID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, orders[0], order)
+	}
+	timeline, err := repository.ListTimeline(order.PartnerId, order.TransactionId, []string{order.IssuerTransactionId})
+	if assert.NoError(t, err) {
+		assert.Equal(t, orders, timeline)
+	}
+
+	_, err = repository.ReadData(sqlite.NewID())
+	assert.Equal(t, ErrOrderNotFound, err.Error())
+	_, err = repository.ReadData("x")
+	assert.Equal(t, ErrInvalidID, err.Error())
+}
+
+func TestConformance(t *testing.T) {
+	repository := newRepository(t)
+	defer repository.Close()
+
+	conformance.Order(t, repository)
+}
@@ -0,0 +1,155 @@
+package issuer
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	partnerIssuerPort "github.com/sepulsa/teleco/business/partner/issuer/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `partner_id, issuer_id, config, reserved_thread, max_thread, version, created_at, updated_at`
+
+var (
+	ErrInvalidID             = "Invalid ID"
+	ErrPartnerIssuerNotFound = "Partner Issuer not found"
+	ErrVersionConflict       = "Partner Issuer was updated since it was read, reload it and retry"
+
+	searchFields = sqlite.SearchFields{
+		Sort:   []string{"created_at", "updated_at"},
+		Filter: []string{"partner_id", "issuer_id"},
+	}
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) CreateData(partnerIssuer partnerIssuerPort.PartnerIssuerRepo) error {
+	_, err := db.Exec(`INSERT INTO partner_issuer (id, `+columns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		partnerIssuer.PartnerId,
+		partnerIssuer.IssuerId,
+		partnerIssuer.Config,
+		partnerIssuer.ReservedThread,
+		partnerIssuer.MaxThread,
+		0,
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) FindByPartnerIssuerID(partnerId string, issuerId string) (partnerIssuer partnerIssuerPort.PartnerIssuerRepo, err error) {
+	row := db.QueryRow(`SELECT id, `+columns+` FROM partner_issuer WHERE partner_id = ? AND issuer_id = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, partnerId, issuerId)
+	if partnerIssuer, err = scanPartnerIssuer(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrPartnerIssuerNotFound)
+	}
+	return
+}
+
+func (db *Repository) ReadData(ID string) (partnerIssuer partnerIssuerPort.PartnerIssuerRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT id, `+columns+` FROM partner_issuer WHERE id = ? AND deleted_at IS NULL`, ID)
+	if partnerIssuer, err = scanPartnerIssuer(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrPartnerIssuerNotFound)
+	}
+	return
+}
+
+func (db *Repository) UpdateData(partnerIssuer partnerIssuerPort.PartnerIssuerRepo) error {
+	err := sqlite.UpdateVersion(db.DB, "partner_issuer", `partner_id = ?, issuer_id = ?, config = ?, reserved_thread = ?, max_thread = ?,
+		updated_at = ?`, partnerIssuer.ID, partnerIssuer.Version,
+		partnerIssuer.PartnerId,
+		partnerIssuer.IssuerId,
+		partnerIssuer.Config,
+		partnerIssuer.ReservedThread,
+		partnerIssuer.MaxThread,
+		sqlite.Time(time.Now()),
+	)
+	switch err {
+	case sql.ErrNoRows:
+		return errors.New(ErrPartnerIssuerNotFound)
+	case sqlite.ErrVersionConflict:
+		return apperror.NewConflict(ErrVersionConflict)
+	default:
+		return err
+	}
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !sqlite.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	result, err := db.Exec(`UPDATE partner_issuer SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrPartnerIssuerNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) ListData() (partnerIssuers []partnerIssuerPort.PartnerIssuerRepo, err error) {
+	rows, err := db.Query(`SELECT id, ` + columns + ` FROM partner_issuer WHERE deleted_at IS NULL ORDER BY id`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		partnerIssuer, err := scanPartnerIssuer(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		partnerIssuers = append(partnerIssuers, partnerIssuer)
+	}
+	err = rows.Err()
+	return
+}
+
+func (db *Repository) SearchData(listQuery query.Query) (partnerIssuers []partnerIssuerPort.PartnerIssuerRepo, page query.Page, err error) {
+	page, err = sqlite.Search(db.DB, "partner_issuer", "id, "+columns, "deleted_at IS NULL", nil, listQuery, searchFields, func(scan sqlite.Scan) error {
+		partnerIssuer, err := scanPartnerIssuer(scan)
+		partnerIssuers = append(partnerIssuers, partnerIssuer)
+		return err
+	})
+	return
+}
+
+func scanPartnerIssuer(scan sqlite.Scan) (partnerIssuer partnerIssuerPort.PartnerIssuerRepo, err error) {
+	var createdAt, updatedAt sql.NullString
+	if err = scan(
+		&partnerIssuer.ID,
+		&partnerIssuer.PartnerId,
+		&partnerIssuer.IssuerId,
+		&partnerIssuer.Config,
+		&partnerIssuer.ReservedThread,
+		&partnerIssuer.MaxThread,
+		&partnerIssuer.Version,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return partnerIssuerPort.PartnerIssuerRepo{}, err
+	}
+	partnerIssuer.CreatedAt = sqlite.ParseTime(createdAt)
+	partnerIssuer.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
@@ -0,0 +1,174 @@
+package partner
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	partnerPort "github.com/sepulsa/teleco/business/partner/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `code, name, pic, address, callback_url, ip_whitelist, status, secret_key,
+	rate_limit, issuer_rate_limit, version, created_at, updated_at`
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrPartnerNotFound = "Partner not found"
+	ErrVersionConflict = "Partner was updated since it was read, reload it and retry"
+
+	searchFields = sqlite.SearchFields{
+		Search: []string{"code", "name", "pic"},
+		Sort:   []string{"code", "name", "status", "created_at", "updated_at"},
+		Filter: []string{"status"},
+	}
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindByCode(code string) (partner partnerPort.PartnerRepo) {
+	row := db.QueryRow(`SELECT id, `+columns+` FROM partner WHERE code = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, code)
+	partner, _ = scanPartner(row.Scan)
+	return
+}
+
+func (db *Repository) CreateData(partner partnerPort.PartnerRepo) error {
+	_, err := db.Exec(`INSERT INTO partner (id, `+columns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		partner.Code,
+		partner.Name,
+		partner.Pic,
+		partner.Address,
+		partner.CallbackUrl,
+		sqlite.JSON(partner.IpWhitelist),
+		partner.Status,
+		partner.SecretKey,
+		sqlite.JSON(partner.RateLimit),
+		sqlite.JSON(partner.IssuerRateLimit),
+		0,
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) ReadData(ID string) (partner partnerPort.PartnerRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT id, `+columns+` FROM partner WHERE id = ? AND deleted_at IS NULL`, ID)
+	if partner, err = scanPartner(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrPartnerNotFound)
+	}
+	return
+}
+
+func (db *Repository) UpdateData(partner partnerPort.PartnerRepo) error {
+	err := sqlite.UpdateVersion(db.DB, "partner", `code = ?, name = ?, pic = ?, address = ?, callback_url = ?, ip_whitelist = ?,
+		status = ?, secret_key = ?, rate_limit = ?, issuer_rate_limit = ?, updated_at = ?`, partner.ID, partner.Version,
+		partner.Code,
+		partner.Name,
+		partner.Pic,
+		partner.Address,
+		partner.CallbackUrl,
+		sqlite.JSON(partner.IpWhitelist),
+		partner.Status,
+		partner.SecretKey,
+		sqlite.JSON(partner.RateLimit),
+		sqlite.JSON(partner.IssuerRateLimit),
+		sqlite.Time(time.Now()),
+	)
+	switch err {
+	case sql.ErrNoRows:
+		return errors.New(ErrPartnerNotFound)
+	case sqlite.ErrVersionConflict:
+		return apperror.NewConflict(ErrVersionConflict)
+	default:
+		return err
+	}
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !sqlite.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	result, err := db.Exec(`UPDATE partner SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrPartnerNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) ListData() (partners []partnerPort.PartnerRepo, err error) {
+	rows, err := db.Query(`SELECT id, ` + columns + ` FROM partner WHERE deleted_at IS NULL ORDER BY id`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		partner, err := scanPartner(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		partners = append(partners, partner)
+	}
+	err = rows.Err()
+	return
+}
+
+func (db *Repository) SearchData(listQuery query.Query) (partners []partnerPort.PartnerRepo, page query.Page, err error) {
+	page, err = sqlite.Search(db.DB, "partner", "id, "+columns, "deleted_at IS NULL", nil, listQuery, searchFields, func(scan sqlite.Scan) error {
+		partner, err := scanPartner(scan)
+		partners = append(partners, partner)
+		return err
+	})
+	return
+}
+
+func scanPartner(scan sqlite.Scan) (partner partnerPort.PartnerRepo, err error) {
+	var ipWhitelist, rateLimit, issuerRateLimit string
+	var createdAt, updatedAt sql.NullString
+	if err = scan(
+		&partner.ID,
+		&partner.Code,
+		&partner.Name,
+		&partner.Pic,
+		&partner.Address,
+		&partner.CallbackUrl,
+		&ipWhitelist,
+		&partner.Status,
+		&partner.SecretKey,
+		&rateLimit,
+		&issuerRateLimit,
+		&partner.Version,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return partnerPort.PartnerRepo{}, err
+	}
+	sqlite.ParseJSON(ipWhitelist, &partner.IpWhitelist)
+	sqlite.ParseJSON(rateLimit, &partner.RateLimit)
+	sqlite.ParseJSON(issuerRateLimit, &partner.IssuerRateLimit)
+	partner.CreatedAt = sqlite.ParseTime(createdAt)
+	partner.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
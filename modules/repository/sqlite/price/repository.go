@@ -0,0 +1,151 @@
+package price
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	pricePort "github.com/sepulsa/teleco/business/price/port"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `partner_code, product_code, base_price, selling_price, margin, margin_percent, fee,
+	effective_from, effective_until, created_at, updated_at`
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrPriceNotFound = "Price not found"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindByPartnerProduct(partnerCode string, productCode string) ([]pricePort.PriceRepo, error) {
+	return db.list(`deleted_at IS NULL AND partner_code = ? AND product_code = ?`, partnerCode, productCode)
+}
+
+func (db *Repository) CreateData(price pricePort.PriceRepo) error {
+	_, err := db.Exec(`INSERT INTO price (id, `+columns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		price.PartnerCode,
+		price.ProductCode,
+		price.BasePrice,
+		price.SellingPrice,
+		price.Margin,
+		price.MarginPercent,
+		price.Fee,
+		sqlite.Time(price.EffectiveFrom),
+		sqlite.Time(price.EffectiveUntil),
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) ReadData(ID string) (price pricePort.PriceRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT id, `+columns+` FROM price WHERE id = ? AND deleted_at IS NULL`, ID)
+	if price, err = scanPrice(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrPriceNotFound)
+	}
+	return
+}
+
+func (db *Repository) UpdateData(price pricePort.PriceRepo) error {
+	result, err := db.Exec(`UPDATE price SET partner_code = ?, product_code = ?, base_price = ?, selling_price = ?, margin = ?,
+		margin_percent = ?, fee = ?, effective_from = ?, effective_until = ?, updated_at = ? WHERE id = ?`,
+		price.PartnerCode,
+		price.ProductCode,
+		price.BasePrice,
+		price.SellingPrice,
+		price.Margin,
+		price.MarginPercent,
+		price.Fee,
+		sqlite.Time(price.EffectiveFrom),
+		sqlite.Time(price.EffectiveUntil),
+		sqlite.Time(time.Now()),
+		price.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrPriceNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !sqlite.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	result, err := db.Exec(`UPDATE price SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrPriceNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) ListData() ([]pricePort.PriceRepo, error) {
+	return db.list(`deleted_at IS NULL`)
+}
+
+func (db *Repository) list(where string, args ...interface{}) (prices []pricePort.PriceRepo, err error) {
+	rows, err := db.Query(`SELECT id, `+columns+` FROM price WHERE `+where+` ORDER BY partner_code, product_code, effective_from DESC, id`, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		price, err := scanPrice(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, price)
+	}
+	err = rows.Err()
+	return
+}
+
+func scanPrice(scan sqlite.Scan) (price pricePort.PriceRepo, err error) {
+	var effectiveFrom, effectiveUntil, createdAt, updatedAt sql.NullString
+	if err = scan(
+		&price.ID,
+		&price.PartnerCode,
+		&price.ProductCode,
+		&price.BasePrice,
+		&price.SellingPrice,
+		&price.Margin,
+		&price.MarginPercent,
+		&price.Fee,
+		&effectiveFrom,
+		&effectiveUntil,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return pricePort.PriceRepo{}, err
+	}
+	price.EffectiveFrom = sqlite.ParseTime(effectiveFrom)
+	price.EffectiveUntil = sqlite.ParseTime(effectiveUntil)
+	price.CreatedAt = sqlite.ParseTime(createdAt)
+	price.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
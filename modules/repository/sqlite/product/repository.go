@@ -0,0 +1,150 @@
+package product
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	productPort "github.com/sepulsa/teleco/business/product/port"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `code, name, operator, type, denomination, active, issuers, created_at, updated_at`
+
+var (
+	ErrInvalidID       = "Invalid ID"
+	ErrProductNotFound = "Product not found"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindByCode(code string) (product productPort.ProductRepo) {
+	row := db.QueryRow(`SELECT id, `+columns+` FROM product WHERE code = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, code)
+	product, _ = scanProduct(row.Scan)
+	return
+}
+
+func (db *Repository) CreateData(product productPort.ProductRepo) error {
+	_, err := db.Exec(`INSERT INTO product (id, `+columns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		product.Code,
+		product.Name,
+		product.Operator,
+		product.Type,
+		product.Denomination,
+		product.Active,
+		sqlite.JSON(product.Issuers),
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) ReadData(ID string) (product productPort.ProductRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT id, `+columns+` FROM product WHERE id = ? AND deleted_at IS NULL`, ID)
+	if product, err = scanProduct(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrProductNotFound)
+	}
+	return
+}
+
+func (db *Repository) UpdateData(product productPort.ProductRepo) error {
+	result, err := db.Exec(`UPDATE product SET code = ?, name = ?, operator = ?, type = ?, denomination = ?, active = ?,
+		issuers = ?, updated_at = ? WHERE id = ?`,
+		product.Code,
+		product.Name,
+		product.Operator,
+		product.Type,
+		product.Denomination,
+		product.Active,
+		sqlite.JSON(product.Issuers),
+		sqlite.Time(time.Now()),
+		product.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrProductNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !sqlite.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	result, err := db.Exec(`UPDATE product SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrProductNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) ListData() ([]productPort.ProductRepo, error) {
+	return db.list(`deleted_at IS NULL`)
+}
+
+func (db *Repository) ListActive() ([]productPort.ProductRepo, error) {
+	return db.list(`deleted_at IS NULL AND active = 1`)
+}
+
+func (db *Repository) list(where string) (products []productPort.ProductRepo, err error) {
+	rows, err := db.Query(`SELECT id, ` + columns + ` FROM product WHERE ` + where + ` ORDER BY operator, type, denomination, id`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		product, err := scanProduct(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	err = rows.Err()
+	return
+}
+
+func scanProduct(scan sqlite.Scan) (product productPort.ProductRepo, err error) {
+	var issuers string
+	var createdAt, updatedAt sql.NullString
+	if err = scan(
+		&product.ID,
+		&product.Code,
+		&product.Name,
+		&product.Operator,
+		&product.Type,
+		&product.Denomination,
+		&product.Active,
+		&issuers,
+		&createdAt,
+		&updatedAt,
+	); err != nil {
+		return productPort.ProductRepo{}, err
+	}
+	sqlite.ParseJSON(issuers, &product.Issuers)
+	product.CreatedAt = sqlite.ParseTime(createdAt)
+	product.UpdatedAt = sqlite.ParseTime(updatedAt)
+	return
+}
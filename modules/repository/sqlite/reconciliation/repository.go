@@ -0,0 +1,169 @@
+package reconciliation
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	reconciliationPort "github.com/sepulsa/teleco/business/reconciliation/port"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const (
+	runColumns = `issuer_code, file_name, date, rows, matched, missing_ours, missing_issuer, amount_mismatch,
+	status_mismatch, duplicate, created_at`
+	itemColumns = `run_id, class, row, transaction_id, issuer_transaction_id, amount, issuer_amount, status, issuer_status`
+)
+
+var (
+	ErrInvalidID              = "Invalid ID"
+	ErrReconciliationNotFound = "Reconciliation not found"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) CreateRun(run reconciliationPort.RunRepo) (reconciliationPort.RunRepo, error) {
+	ID := sqlite.NewID()
+	createdAt := time.Now()
+	_, err := db.Exec(`INSERT INTO reconciliation_run (id, `+runColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		ID,
+		run.IssuerCode,
+		run.FileName,
+		sqlite.Time(run.Date),
+		run.Rows,
+		run.Matched,
+		run.MissingOurs,
+		run.MissingIssuer,
+		run.AmountMismatch,
+		run.StatusMismatch,
+		run.Duplicate,
+		sqlite.Time(createdAt),
+	)
+	if err != nil {
+		return run, err
+	}
+	run.ID = ID
+	run.CreatedAt = createdAt
+	return run, nil
+}
+
+// CreateItems inserts the items of a run in one transaction, none is kept when one fails
+func (db *Repository) CreateItems(items []reconciliationPort.ItemRepo) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if _, err = tx.Exec(`INSERT INTO reconciliation_item (id, `+itemColumns+`) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			sqlite.NewID(),
+			item.RunId,
+			item.Class,
+			item.Row,
+			item.TransactionId,
+			item.IssuerTransactionId,
+			item.Amount,
+			item.IssuerAmount,
+			item.Status,
+			item.IssuerStatus,
+		); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (db *Repository) ReadRun(ID string) (run reconciliationPort.RunRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT id, `+runColumns+` FROM reconciliation_run WHERE id = ?`, ID)
+	if run, err = scanRun(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrReconciliationNotFound)
+	}
+	return
+}
+
+func (db *Repository) ListRuns(issuerCode string) (runs []reconciliationPort.RunRepo, err error) {
+	rows, err := db.Query(`SELECT id, `+runColumns+` FROM reconciliation_run WHERE ? = '' OR issuer_code = ?
+		ORDER BY created_at DESC, id DESC`, issuerCode, issuerCode)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		run, err := scanRun(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	err = rows.Err()
+	return
+}
+
+func (db *Repository) ListItems(runId string, class string) (items []reconciliationPort.ItemRepo, err error) {
+	rows, err := db.Query(`SELECT id, `+itemColumns+` FROM reconciliation_item WHERE run_id = ? AND (? = '' OR class = ?)
+		ORDER BY id`, runId, class, class)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item reconciliationPort.ItemRepo
+		if err = rows.Scan(
+			&item.ID,
+			&item.RunId,
+			&item.Class,
+			&item.Row,
+			&item.TransactionId,
+			&item.IssuerTransactionId,
+			&item.Amount,
+			&item.IssuerAmount,
+			&item.Status,
+			&item.IssuerStatus,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	err = rows.Err()
+	return
+}
+
+func scanRun(scan sqlite.Scan) (run reconciliationPort.RunRepo, err error) {
+	var date, createdAt sql.NullString
+	if err = scan(
+		&run.ID,
+		&run.IssuerCode,
+		&run.FileName,
+		&date,
+		&run.Rows,
+		&run.Matched,
+		&run.MissingOurs,
+		&run.MissingIssuer,
+		&run.AmountMismatch,
+		&run.StatusMismatch,
+		&run.Duplicate,
+		&createdAt,
+	); err != nil {
+		return reconciliationPort.RunRepo{}, err
+	}
+	run.Date = sqlite.ParseTime(date)
+	run.CreatedAt = sqlite.ParseTime(createdAt)
+	return
+}
@@ -0,0 +1,125 @@
+package route
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	routePort "github.com/sepulsa/teleco/business/route/port"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `product_code, candidates, failover_rescodes`
+
+var (
+	ErrInvalidID     = "Invalid ID"
+	ErrRouteNotFound = "Route not found"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindByProductCode(productCode string) (route routePort.RouteRepo) {
+	row := db.QueryRow(`SELECT id, `+columns+` FROM route WHERE product_code = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, productCode)
+	route, _ = scanRoute(row.Scan)
+	return
+}
+
+func (db *Repository) CreateData(route routePort.RouteRepo) error {
+	_, err := db.Exec(`INSERT INTO route (id, `+columns+`, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		route.ProductCode,
+		sqlite.JSON(route.Candidates),
+		sqlite.JSON(route.FailoverRescodes),
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) ReadData(ID string) (route routePort.RouteRepo, err error) {
+	if !sqlite.IsID(ID) {
+		err = errors.New(ErrInvalidID)
+		return
+	}
+
+	row := db.QueryRow(`SELECT id, `+columns+` FROM route WHERE id = ? AND deleted_at IS NULL`, ID)
+	if route, err = scanRoute(row.Scan); err == sql.ErrNoRows {
+		err = errors.New(ErrRouteNotFound)
+	}
+	return
+}
+
+func (db *Repository) UpdateData(route routePort.RouteRepo) error {
+	result, err := db.Exec(`UPDATE route SET product_code = ?, candidates = ?, failover_rescodes = ?, updated_at = ? WHERE id = ?`,
+		route.ProductCode,
+		sqlite.JSON(route.Candidates),
+		sqlite.JSON(route.FailoverRescodes),
+		sqlite.Time(time.Now()),
+		route.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrRouteNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !sqlite.IsID(ID) {
+		return errors.New(ErrInvalidID)
+	}
+
+	result, err := db.Exec(`UPDATE route SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), ID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrRouteNotFound)
+	}
+	return nil
+}
+
+func (db *Repository) ListData() (routes []routePort.RouteRepo, err error) {
+	rows, err := db.Query(`SELECT id, ` + columns + ` FROM route WHERE deleted_at IS NULL ORDER BY id`)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		route, err := scanRoute(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, route)
+	}
+	err = rows.Err()
+	return
+}
+
+func scanRoute(scan sqlite.Scan) (route routePort.RouteRepo, err error) {
+	var candidates, failoverRescodes string
+	if err = scan(
+		&route.ID,
+		&route.ProductCode,
+		&candidates,
+		&failoverRescodes,
+	); err != nil {
+		return routePort.RouteRepo{}, err
+	}
+	sqlite.ParseJSON(candidates, &route.Candidates)
+	sqlite.ParseJSON(failoverRescodes, &route.FailoverRescodes)
+	return
+}
@@ -0,0 +1,185 @@
+package user
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	userPort "github.com/sepulsa/teleco/business/user/port"
+	"github.com/sepulsa/teleco/utils/apperror"
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `email, fullname, password, password_history, reset_token_expired_at, version`
+
+var (
+	ErrUserNotFound    error = errors.New("user not found")
+	ErrInvalidID       error = errors.New("invalid id")
+	ErrVersionConflict error = apperror.NewConflict("user was updated since it was read, reload it and retry")
+
+	searchFields = sqlite.SearchFields{
+		Search: []string{"email", "fullname"},
+		Sort:   []string{"email", "fullname", "created_at", "updated_at"},
+	}
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) FindByEmail(email string) userPort.UserRepo {
+	row := db.QueryRow(`SELECT id, `+columns+` FROM users WHERE email = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, email)
+	user, _ := scanUser(row.Scan)
+	return user
+}
+
+func (db *Repository) FindByResetToken(tokenHash string) userPort.UserRepo {
+	row := db.QueryRow(`SELECT id, `+columns+` FROM users WHERE reset_token = ? AND reset_token_expired_at > ? AND deleted_at IS NULL
+		ORDER BY id LIMIT 1`, tokenHash, sqlite.Time(time.Now()))
+	user, _ := scanUser(row.Scan)
+	return user
+}
+
+func (db *Repository) ReadData(ID string) (userPort.UserRepo, error) {
+	if !sqlite.IsID(ID) {
+		return userPort.UserRepo{}, ErrInvalidID
+	}
+
+	row := db.QueryRow(`SELECT id, `+columns+` FROM users WHERE id = ? AND deleted_at IS NULL`, ID)
+	user, err := scanUser(row.Scan)
+	if err == sql.ErrNoRows {
+		err = ErrUserNotFound
+	}
+	return user, err
+}
+
+func (db *Repository) CreateData(user userPort.UserRepo) error {
+	_, err := db.Exec(`INSERT INTO users (id, email, fullname, password, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		user.Email,
+		user.Fullname,
+		user.Password,
+		sqlite.Time(time.Now()),
+		sqlite.Time(time.Now()),
+	)
+	return err
+}
+
+func (db *Repository) UpdateData(user userPort.UserRepo) error {
+	if !sqlite.IsID(user.ID) {
+		return ErrInvalidID
+	}
+
+	set := `email = ?, fullname = ?, updated_at = ?`
+	args := []interface{}{user.Email, user.Fullname, sqlite.Time(time.Now())}
+	if user.Password != "" {
+		set += `, password = ?, password_history = ?, reset_token = NULL, reset_token_expired_at = NULL`
+		args = append(args, user.Password, sqlite.JSON(user.PasswordHistory))
+	}
+	switch err := sqlite.UpdateVersion(db.DB, "users", set, user.ID, user.Version, args...); err {
+	case sql.ErrNoRows:
+		return ErrUserNotFound
+	case sqlite.ErrVersionConflict:
+		return ErrVersionConflict
+	default:
+		return err
+	}
+}
+
+func (db *Repository) UpdateResetToken(ID string, tokenHash string, expiredAt time.Time) error {
+	if !sqlite.IsID(ID) {
+		return ErrInvalidID
+	}
+
+	return db.affect(`UPDATE users SET reset_token = ?, reset_token_expired_at = ?, updated_at = ? WHERE id = ?`,
+		tokenHash, sqlite.Time(expiredAt), sqlite.Time(time.Now()), ID)
+}
+
+func (db *Repository) DeleteData(ID string) error {
+	if !sqlite.IsID(ID) {
+		return ErrInvalidID
+	}
+
+	return db.affect(`UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), ID)
+}
+
+func (db *Repository) ListData() ([]userPort.UserRepo, error) {
+	users := make([]userPort.UserRepo, 0)
+
+	rows, err := db.Query(`SELECT id, ` + columns + ` FROM users WHERE deleted_at IS NULL ORDER BY id`)
+	if err != nil {
+		return users, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		user, err := scanUser(rows.Scan)
+		if err != nil {
+			return users, err
+		}
+		users = append(users, userPort.UserRepo{
+			ID:       user.ID,
+			Email:    user.Email,
+			Fullname: user.Fullname,
+			Version:  user.Version,
+		})
+	}
+
+	return users, rows.Err()
+}
+
+func (db *Repository) SearchData(listQuery query.Query) ([]userPort.UserRepo, query.Page, error) {
+	users := make([]userPort.UserRepo, 0)
+
+	page, err := sqlite.Search(db.DB, "users", "id, "+columns, "deleted_at IS NULL", nil, listQuery, searchFields, func(scan sqlite.Scan) error {
+		user, err := scanUser(scan)
+		users = append(users, userPort.UserRepo{
+			ID:       user.ID,
+			Email:    user.Email,
+			Fullname: user.Fullname,
+			Version:  user.Version,
+		})
+		return err
+	})
+	return users, page, err
+}
+
+// affect runs an update, a missing user is ErrUserNotFound
+func (db *Repository) affect(statement string, args ...interface{}) error {
+	result, err := db.Exec(statement, args...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func scanUser(scan sqlite.Scan) (user userPort.UserRepo, err error) {
+	var passwordHistory string
+	var resetTokenExpiredAt sql.NullString
+	if err = scan(
+		&user.ID,
+		&user.Email,
+		&user.Fullname,
+		&user.Password,
+		&passwordHistory,
+		&resetTokenExpiredAt,
+		&user.Version,
+	); err != nil {
+		return userPort.UserRepo{}, err
+	}
+	sqlite.ParseJSON(passwordHistory, &user.PasswordHistory)
+	user.ResetTokenExpiredAt = sqlite.ParseTime(resetTokenExpiredAt)
+	return
+}
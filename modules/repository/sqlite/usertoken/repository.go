@@ -0,0 +1,120 @@
+package usertoken
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	authPort "github.com/sepulsa/teleco/business/auth/port"
+	"github.com/sepulsa/teleco/utils/sqlite"
+)
+
+type (
+	Repository struct {
+		*sql.DB
+	}
+)
+
+const columns = `user_id, token_id, ip_address, user_agent, last_used_at, expired_at, created_at`
+
+var (
+	ErrInvalidID         = "Invalid ID"
+	ErrUserTokenNotFound = "Token not found"
+)
+
+func New(db *sql.DB) *Repository {
+	return &Repository{
+		db,
+	}
+}
+
+func (db *Repository) CreateData(jwt authPort.UserTokenRepo) error {
+	now := time.Now()
+	_, err := db.Exec(`INSERT INTO user_tokens (id, `+columns+`, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sqlite.NewID(),
+		jwt.UserID,
+		jwt.TokenID,
+		jwt.IPAddress,
+		jwt.UserAgent,
+		sqlite.Time(now),
+		sqlite.Time(jwt.ExpiredAt),
+		sqlite.Time(now),
+		sqlite.Time(now),
+	)
+	return err
+}
+
+func (db *Repository) FindByTokenID(tokenID string) authPort.UserTokenRepo {
+	row := db.QueryRow(`SELECT `+columns+` FROM user_tokens WHERE token_id = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`, tokenID)
+	userToken, _ := scanUserToken(row.Scan)
+	return userToken
+}
+
+func (db *Repository) ListDataByUserID(userID string) ([]authPort.UserTokenRepo, error) {
+	userTokens := make([]authPort.UserTokenRepo, 0)
+
+	// tokens created before expiry tracking have no expired_at
+	rows, err := db.Query(`SELECT `+columns+` FROM user_tokens WHERE user_id = ? AND deleted_at IS NULL
+		AND (expired_at > ? OR expired_at IS NULL) ORDER BY last_used_at DESC`, userID, sqlite.Time(time.Now()))
+	if err != nil {
+		return userTokens, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		userToken, err := scanUserToken(rows.Scan)
+		if err != nil {
+			return userTokens, err
+		}
+		userTokens = append(userTokens, userToken)
+	}
+
+	return userTokens, rows.Err()
+}
+
+func (db *Repository) UpdateLastUsed(tokenID string) error {
+	// only the first matching token is updated, as a single document update would
+	return db.affect(`UPDATE user_tokens SET last_used_at = ? WHERE id = (
+		SELECT id FROM user_tokens WHERE token_id = ? AND deleted_at IS NULL ORDER BY id LIMIT 1)`, sqlite.Time(time.Now()), tokenID)
+}
+
+func (db *Repository) DeleteData(tokenID string) error {
+	return db.affect(`UPDATE user_tokens SET deleted_at = ? WHERE id = (
+		SELECT id FROM user_tokens WHERE token_id = ? AND deleted_at IS NULL ORDER BY id LIMIT 1)`, sqlite.Time(time.Now()), tokenID)
+}
+
+func (db *Repository) DeleteDataByUserID(userID string) error {
+	_, err := db.Exec(`UPDATE user_tokens SET deleted_at = ? WHERE user_id = ? AND deleted_at IS NULL`, sqlite.Time(time.Now()), userID)
+	return err
+}
+
+// affect runs an update, a missing token is ErrUserTokenNotFound
+func (db *Repository) affect(statement string, args ...interface{}) error {
+	result, err := db.Exec(statement, args...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return errors.New(ErrUserTokenNotFound)
+	}
+	return nil
+}
+
+func scanUserToken(scan sqlite.Scan) (userToken authPort.UserTokenRepo, err error) {
+	var lastUsedAt, expiredAt, createdAt sql.NullString
+	if err = scan(
+		&userToken.UserID,
+		&userToken.TokenID,
+		&userToken.IPAddress,
+		&userToken.UserAgent,
+		&lastUsedAt,
+		&expiredAt,
+		&createdAt,
+	); err != nil {
+		return authPort.UserTokenRepo{}, err
+	}
+	userToken.LastUsedAt = sqlite.ParseTime(lastUsedAt)
+	userToken.ExpiredAt = sqlite.ParseTime(expiredAt)
+	userToken.CreatedAt = sqlite.ParseTime(createdAt)
+	return
+}
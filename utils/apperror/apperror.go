@@ -0,0 +1,52 @@
+package apperror
+
+import "errors"
+
+// Kind of a broken business rule, the controllers map it to an HTTP status
+type Kind int
+
+const (
+	// Conflict the request conflicts with a stored record, a duplicate or a record still in use
+	Conflict Kind = iota + 1
+	// Invalid the request refers to records that do not exist
+	Invalid
+	// NotFound the looked up record does not exist, callers may fall back on a default
+	NotFound
+)
+
+type (
+	// Error message of a broken business rule, the message is kept as is so that callers
+	// comparing err.Error() keep working
+	Error struct {
+		Kind    Kind
+		Message string
+	}
+)
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NewConflict error of a request conflicting with a stored record
+func NewConflict(message string) error {
+	return &Error{Conflict, message}
+}
+
+// NewInvalid error of a request referring to missing records
+func NewInvalid(message string) error {
+	return &Error{Invalid, message}
+}
+
+// NewNotFound error of a looked up record that does not exist
+func NewNotFound(message string) error {
+	return &Error{NotFound, message}
+}
+
+// KindOf kind of err, zero when err is not an *Error
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+	return 0
+}
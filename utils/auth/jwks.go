@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+type (
+	signingKey struct {
+		ID      string
+		Method  jwt.SigningMethod
+		Private crypto.PrivateKey
+		Public  crypto.PublicKey
+	}
+
+	// JSONWebKey public key in RFC 7517 format
+	JSONWebKey struct {
+		KeyType   string `json:"kty"`
+		KeyID     string `json:"kid"`
+		Use       string `json:"use"`
+		Algorithm string `json:"alg"`
+		N         string `json:"n,omitempty"`
+		E         string `json:"e,omitempty"`
+		Curve     string `json:"crv,omitempty"`
+		X         string `json:"x,omitempty"`
+		Y         string `json:"y,omitempty"`
+	}
+
+	// JSONWebKeySet set of public keys accepted for token verification
+	JSONWebKeySet struct {
+		Keys []JSONWebKey `json:"keys"`
+	}
+)
+
+func loadSigningKey(conf JWTKeyConfig) (signingKey, error) {
+	key := signingKey{ID: conf.KeyID}
+	if conf.KeyID == "" {
+		return key, fmt.Errorf("jwt key id is required")
+	}
+	if conf.PrivateKeyFile == "" && conf.PublicKeyFile == "" {
+		return key, fmt.Errorf("jwt key %s has no key file", conf.KeyID)
+	}
+
+	switch method := jwt.GetSigningMethod(conf.Algorithm).(type) {
+	case *jwt.SigningMethodRSA:
+		key.Method = method
+		if conf.PrivateKeyFile != "" {
+			pem, err := ioutil.ReadFile(conf.PrivateKeyFile)
+			if err != nil {
+				return key, err
+			}
+			private, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return key, fmt.Errorf("jwt key %s: %s", conf.KeyID, err)
+			}
+			key.Private, key.Public = private, &private.PublicKey
+		} else {
+			pem, err := ioutil.ReadFile(conf.PublicKeyFile)
+			if err != nil {
+				return key, err
+			}
+			public, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+			if err != nil {
+				return key, fmt.Errorf("jwt key %s: %s", conf.KeyID, err)
+			}
+			key.Public = public
+		}
+	case *jwt.SigningMethodECDSA:
+		key.Method = method
+		if conf.PrivateKeyFile != "" {
+			pem, err := ioutil.ReadFile(conf.PrivateKeyFile)
+			if err != nil {
+				return key, err
+			}
+			private, err := jwt.ParseECPrivateKeyFromPEM(pem)
+			if err != nil {
+				return key, fmt.Errorf("jwt key %s: %s", conf.KeyID, err)
+			}
+			key.Private, key.Public = private, &private.PublicKey
+		} else {
+			pem, err := ioutil.ReadFile(conf.PublicKeyFile)
+			if err != nil {
+				return key, err
+			}
+			public, err := jwt.ParseECPublicKeyFromPEM(pem)
+			if err != nil {
+				return key, fmt.Errorf("jwt key %s: %s", conf.KeyID, err)
+			}
+			key.Public = public
+		}
+		if public := key.Public.(*ecdsa.PublicKey); public.Curve.Params().BitSize != method.CurveBits {
+			return key, fmt.Errorf("jwt key %s curve does not match %s", conf.KeyID, conf.Algorithm)
+		}
+	default:
+		return key, fmt.Errorf("jwt key %s has unsupported algorithm %s", conf.KeyID, conf.Algorithm)
+	}
+
+	return key, nil
+}
+
+// JWKS public keys of the active and retired keys, HMAC secrets are never published
+func (j *JWTConf) JWKS() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(j.keys))}
+	for _, key := range j.keys {
+		jwk := JSONWebKey{
+			KeyID:     key.ID,
+			Use:       "sig",
+			Algorithm: key.Method.Alg(),
+		}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case *ecdsa.PublicKey:
+			size := (public.Curve.Params().BitSize + 7) / 8
+			jwk.KeyType = "EC"
+			jwk.Curve = public.Curve.Params().Name
+			jwk.X = base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size)))
+			jwk.Y = base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size)))
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	sort.Slice(set.Keys, func(a, b int) bool {
+		return set.Keys[a].KeyID < set.Keys[b].KeyID
+	})
+
+	return set
+}
@@ -0,0 +1,163 @@
+package auth_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sepulsa/teleco/utils/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	TestSignKey    = []byte("jwt-secret")
+	TestClaimsID   = "6328402206636078"
+	TestOldKeyID   = "2021-04"
+	TestNewKeyID   = "2021-10"
+	TestWrongKeyID = "2020-01"
+)
+
+func writePEM(t *testing.T, dir string, name string, blockType string, der []byte) string {
+	path := filepath.Join(dir, name)
+	err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600)
+	assert.Nil(t, err)
+	return path
+}
+
+func setupKeys(t *testing.T) (dir string, rsaPrivate string, ecPrivate string, ecPublic string) {
+	dir, err := ioutil.TempDir("", "jwt")
+	assert.Nil(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	rsaPrivate = writePEM(t, dir, "rsa.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey))
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	der, err := x509.MarshalECPrivateKey(ecKey)
+	assert.Nil(t, err)
+	ecPrivate = writePEM(t, dir, "ec.pem", "EC PRIVATE KEY", der)
+	der, err = x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	assert.Nil(t, err)
+	ecPublic = writePEM(t, dir, "ec.pub.pem", "PUBLIC KEY", der)
+
+	return
+}
+
+func TestKeyRotation(t *testing.T) {
+	dir, rsaPrivate, ecPrivate, ecPublic := setupKeys(t)
+	defer os.RemoveAll(dir)
+
+	legacy, err := auth.NewJWTWithConfig(auth.JWTConfig{SignKey: TestSignKey, ExpiredToken: 1})
+	assert.Nil(t, err)
+	old, err := auth.NewJWTWithConfig(auth.JWTConfig{
+		ExpiredToken: 1,
+		ActiveKeyID:  TestOldKeyID,
+		Keys: []auth.JWTKeyConfig{
+			{KeyID: TestOldKeyID, Algorithm: "ES256", PrivateKeyFile: ecPrivate},
+		},
+	})
+	assert.Nil(t, err)
+	current, err := auth.NewJWTWithConfig(auth.JWTConfig{
+		SignKey:      TestSignKey,
+		ExpiredToken: 1,
+		ActiveKeyID:  TestNewKeyID,
+		Keys: []auth.JWTKeyConfig{
+			{KeyID: TestNewKeyID, Algorithm: "RS256", PrivateKeyFile: rsaPrivate},
+			{KeyID: TestOldKeyID, Algorithm: "ES256", PublicKeyFile: ecPublic},
+		},
+	})
+	assert.Nil(t, err)
+
+	t.Run("Expect sign with active key", func(t *testing.T) {
+		token, err := current.CreateToken(auth.JWTClaims{ID: TestClaimsID})
+		assert.Nil(t, err)
+
+		_, claims, err := current.ParseToken(token)
+		assert.Nil(t, err)
+		assert.Equal(t, TestClaimsID, claims.ID)
+
+		_, _, err = old.ParseToken(token)
+		assert.Equal(t, auth.ErrInvalidJWT, err)
+	})
+
+	t.Run("Expect retired key still verify", func(t *testing.T) {
+		token, err := old.CreateToken(auth.JWTClaims{ID: TestClaimsID})
+		assert.Nil(t, err)
+
+		_, claims, err := current.ParseToken(token)
+		assert.Nil(t, err)
+		assert.Equal(t, TestClaimsID, claims.ID)
+	})
+
+	t.Run("Expect legacy HMAC token verify while secret is configured", func(t *testing.T) {
+		token, err := legacy.CreateToken(auth.JWTClaims{ID: TestClaimsID})
+		assert.Nil(t, err)
+
+		_, _, err = current.ParseToken(token)
+		assert.Nil(t, err)
+
+		_, _, err = old.ParseToken(token)
+		assert.Equal(t, auth.ErrInvalidJWT, err)
+	})
+
+	t.Run("Expect JWKS publish public keys only", func(t *testing.T) {
+		jwks := current.JWKS()
+		assert.Len(t, jwks.Keys, 2)
+		assert.Equal(t, TestOldKeyID, jwks.Keys[0].KeyID)
+		assert.Equal(t, "EC", jwks.Keys[0].KeyType)
+		assert.Equal(t, "P-256", jwks.Keys[0].Curve)
+		assert.Equal(t, TestNewKeyID, jwks.Keys[1].KeyID)
+		assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+
+		assert.Len(t, legacy.JWKS().Keys, 0)
+	})
+}
+
+func TestNewJWTWithConfig(t *testing.T) {
+	dir, rsaPrivate, _, ecPublic := setupKeys(t)
+	defer os.RemoveAll(dir)
+
+	t.Run("Expect active key must have private key", func(t *testing.T) {
+		_, err := auth.NewJWTWithConfig(auth.JWTConfig{
+			ActiveKeyID: TestOldKeyID,
+			Keys: []auth.JWTKeyConfig{
+				{KeyID: TestOldKeyID, Algorithm: "ES256", PublicKeyFile: ecPublic},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+	t.Run("Expect unknown active key", func(t *testing.T) {
+		_, err := auth.NewJWTWithConfig(auth.JWTConfig{
+			ActiveKeyID: TestWrongKeyID,
+			Keys: []auth.JWTKeyConfig{
+				{KeyID: TestNewKeyID, Algorithm: "RS256", PrivateKeyFile: rsaPrivate},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+	t.Run("Expect algorithm match key type", func(t *testing.T) {
+		_, err := auth.NewJWTWithConfig(auth.JWTConfig{
+			Keys: []auth.JWTKeyConfig{
+				{KeyID: TestNewKeyID, Algorithm: "ES256", PrivateKeyFile: rsaPrivate},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+	t.Run("Expect HMAC not allowed as key", func(t *testing.T) {
+		_, err := auth.NewJWTWithConfig(auth.JWTConfig{
+			Keys: []auth.JWTKeyConfig{
+				{KeyID: TestNewKeyID, Algorithm: "HS256", PrivateKeyFile: rsaPrivate},
+			},
+		})
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,204 @@
+package circuitbreaker
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+var (
+	DefaultMinimumCalls  = 10
+	DefaultWindow        = 60 * time.Second
+	DefaultOpenDuration  = 30 * time.Second
+	DefaultHalfOpenCalls = 1
+)
+
+// Settings thresholds of a breaker, a rate of zero disables its check
+type Settings struct {
+	// ErrorRate ratio of failed calls in the window that opens the circuit
+	ErrorRate float64
+	// SlowCallRate ratio of calls slower than SlowCall in the window that opens the circuit
+	SlowCallRate float64
+	SlowCall     time.Duration
+	// MinimumCalls in the window before the rates are evaluated
+	MinimumCalls int
+	Window       time.Duration
+	// OpenDuration before probe calls are let through
+	OpenDuration time.Duration
+	// HalfOpenCalls probes that must succeed to close the circuit
+	HalfOpenCalls int
+}
+
+// Enabled breaker has at least one threshold
+func (s Settings) Enabled() bool {
+	return s.ErrorRate > 0 || (s.SlowCallRate > 0 && s.SlowCall > 0)
+}
+
+func (s Settings) withDefaults() Settings {
+	if s.MinimumCalls <= 0 {
+		s.MinimumCalls = DefaultMinimumCalls
+	}
+	if s.Window <= 0 {
+		s.Window = DefaultWindow
+	}
+	if s.OpenDuration <= 0 {
+		s.OpenDuration = DefaultOpenDuration
+	}
+	if s.HalfOpenCalls <= 0 {
+		s.HalfOpenCalls = DefaultHalfOpenCalls
+	}
+	return s
+}
+
+// Snapshot state and counters of the current window
+type Snapshot struct {
+	State     string
+	Calls     int
+	Failures  int
+	SlowCalls int
+	OpenedAt  time.Time
+	UpdatedAt time.Time
+}
+
+// Breaker closed, open and half open state machine over a fixed window of calls,
+// settings are passed on every call so they can change while the breaker is live
+type Breaker struct {
+	mu          sync.Mutex
+	state       string
+	windowStart time.Time
+	calls       int
+	failures    int
+	slowCalls   int
+	openedAt    time.Time
+	updatedAt   time.Time
+	probes      int
+	succeeded   int
+}
+
+// New closed breaker
+func New() *Breaker {
+	return &Breaker{state: StateClosed}
+}
+
+// Allow whether a call may go through, every allowed call must be followed by Done or Cancel
+func (b *Breaker) Allow(now time.Time, settings Settings) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !settings.Enabled() {
+		if b.state != StateClosed {
+			b.reset(now, StateClosed)
+		}
+		return true
+	}
+	settings = settings.withDefaults()
+
+	switch b.state {
+	case StateOpen:
+		if now.Sub(b.openedAt) < settings.OpenDuration {
+			return false
+		}
+		b.reset(now, StateHalfOpen)
+		fallthrough
+	case StateHalfOpen:
+		if b.probes >= settings.HalfOpenCalls {
+			return false
+		}
+		b.probes++
+	}
+	return true
+}
+
+// Cancel give back an allowed call that did not reach the issuer
+func (b *Breaker) Cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// Done record the outcome of an allowed call and report whether the state changed
+func (b *Breaker) Done(now time.Time, settings Settings, failed bool, elapsed time.Duration) (changed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !settings.Enabled() {
+		return false
+	}
+	settings = settings.withDefaults()
+	slow := settings.SlowCall > 0 && elapsed >= settings.SlowCall
+
+	switch b.state {
+	case StateHalfOpen:
+		if failed || (slow && settings.SlowCallRate > 0) {
+			b.reset(now, StateOpen)
+			return true
+		}
+		b.succeeded++
+		if b.succeeded >= settings.HalfOpenCalls {
+			b.reset(now, StateClosed)
+			return true
+		}
+		return false
+	case StateOpen:
+		// a late call started before the circuit opened
+		return false
+	}
+
+	if now.Sub(b.windowStart) >= settings.Window {
+		b.windowStart = now
+		b.calls, b.failures, b.slowCalls = 0, 0, 0
+	}
+	b.calls++
+	if failed {
+		b.failures++
+	}
+	if slow {
+		b.slowCalls++
+	}
+	b.updatedAt = now
+	if b.calls < settings.MinimumCalls {
+		return false
+	}
+	errorRate := float64(b.failures) / float64(b.calls)
+	slowCallRate := float64(b.slowCalls) / float64(b.calls)
+	if (settings.ErrorRate > 0 && errorRate >= settings.ErrorRate) ||
+		(settings.SlowCallRate > 0 && slowCallRate >= settings.SlowCallRate) {
+		b.reset(now, StateOpen)
+		return true
+	}
+	return false
+}
+
+// reset move to state with fresh counters, the counters at the moment are kept for Snapshot
+func (b *Breaker) reset(now time.Time, state string) {
+	if state == StateOpen {
+		b.openedAt = now
+	}
+	if state == StateClosed {
+		b.windowStart = now
+		b.calls, b.failures, b.slowCalls = 0, 0, 0
+	}
+	b.state = state
+	b.probes = 0
+	b.succeeded = 0
+	b.updatedAt = now
+}
+
+// Snapshot current state and counters
+func (b *Breaker) Snapshot() Snapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return Snapshot{
+		State:     b.state,
+		Calls:     b.calls,
+		Failures:  b.failures,
+		SlowCalls: b.slowCalls,
+		OpenedAt:  b.openedAt,
+		UpdatedAt: b.updatedAt,
+	}
+}
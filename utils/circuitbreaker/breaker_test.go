@@ -0,0 +1,76 @@
+package circuitbreaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreakerErrorRate(t *testing.T) {
+	settings := Settings{ErrorRate: 0.5, MinimumCalls: 4, Window: time.Minute, OpenDuration: time.Second, HalfOpenCalls: 1}
+	now := time.Now()
+	b := New()
+
+	// below minimum calls nothing opens
+	for i := 0; i < 3; i++ {
+		assert.True(t, b.Allow(now, settings))
+		assert.False(t, b.Done(now, settings, true, 0))
+	}
+	assert.True(t, b.Allow(now, settings))
+	assert.True(t, b.Done(now, settings, false, 0))
+	assert.Equal(t, StateOpen, b.Snapshot().State)
+
+	// fail fast while open
+	assert.False(t, b.Allow(now.Add(500*time.Millisecond), settings))
+
+	// one probe in half open, failure opens again
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow(now, settings))
+	assert.False(t, b.Allow(now, settings))
+	assert.True(t, b.Done(now, settings, true, 0))
+	assert.Equal(t, StateOpen, b.Snapshot().State)
+
+	// cancelled probe is given back, successful probe closes
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow(now, settings))
+	b.Cancel()
+	assert.True(t, b.Allow(now, settings))
+	assert.True(t, b.Done(now, settings, false, 0))
+	snapshot := b.Snapshot()
+	assert.Equal(t, StateClosed, snapshot.State)
+	assert.Equal(t, 0, snapshot.Calls)
+}
+
+func TestBreakerSlowCall(t *testing.T) {
+	settings := Settings{SlowCallRate: 1, SlowCall: time.Second, MinimumCalls: 2, Window: time.Minute}
+	now := time.Now()
+	b := New()
+
+	assert.True(t, b.Allow(now, settings))
+	assert.False(t, b.Done(now, settings, false, 2*time.Second))
+	assert.True(t, b.Allow(now, settings))
+	assert.True(t, b.Done(now, settings, false, 2*time.Second))
+	assert.Equal(t, StateOpen, b.Snapshot().State)
+
+	// disabling thresholds closes the circuit
+	assert.True(t, b.Allow(now, Settings{}))
+	assert.Equal(t, StateClosed, b.Snapshot().State)
+}
+
+func TestBreakerWindow(t *testing.T) {
+	settings := Settings{ErrorRate: 0.5, MinimumCalls: 2, Window: time.Second}
+	now := time.Now()
+	b := New()
+
+	assert.True(t, b.Allow(now, settings))
+	assert.False(t, b.Done(now, settings, true, 0))
+
+	// previous failure expired with its window
+	now = now.Add(time.Second)
+	assert.True(t, b.Allow(now, settings))
+	assert.False(t, b.Done(now, settings, false, 0))
+	assert.True(t, b.Allow(now, settings))
+	assert.False(t, b.Done(now, settings, false, 0))
+	assert.Equal(t, StateClosed, b.Snapshot().State)
+}
@@ -0,0 +1,10 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// GetAdminEmails internal users allowed to manage other users, the internal API has no roles yet
+func GetAdminEmails() []string {
+	return viper.GetStringSlice("admin.emails")
+}
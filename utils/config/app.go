@@ -6,14 +6,13 @@ import (
 	"strings"
 
 	"github.com/spf13/viper"
-	//driver
-	_ "github.com/mattn/go-sqlite3"
 )
 
 func init() {
 	// set config based on env
 	LoadEnvVars()
 	MongoConnect()
+	SQLiteConnect()
 }
 
 func LoadEnvVars() {
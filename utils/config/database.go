@@ -1,17 +1,26 @@
 package config
 
 import (
+	"database/sql"
+
 	"github.com/spf13/viper"
 
 	//driver
 	"gopkg.in/mgo.v2"
 
 	mongo "github.com/sepulsa/teleco/utils/mgo"
+	"github.com/sepulsa/teleco/utils/sqlite"
 )
 
 var (
 	//Mgo Mgo
 	Mgo *mongo.MongoDatabase
+	// SQLite database of the sqlite driver, nil with the mongodb driver
+	SQLite *sql.DB
+
+	DatabaseDriverMongoDB = "mongodb"
+	DatabaseDriverSQLite  = "sqlite"
+	DefaultSQLitePath     = "teleco.db"
 )
 
 //Database Database
@@ -41,9 +50,9 @@ func LoadDBConfig(name string) Database {
 	return conf
 }
 
-//MongoConnect MongoConnect
+//MongoConnect dial MongoDB when the mongodb driver is selected, outside of the tests
 func MongoConnect() {
-	if viper.Get("env") != "testing" {
+	if viper.Get("env") != "testing" && GetDatabaseDriver() == DatabaseDriverMongoDB {
 		conf := LoadDBConfig("mongo")
 		mongoConf := &mgo.DialInfo{
 			Addrs:    []string{conf.Host},
@@ -59,3 +68,71 @@ func MongoConnect() {
 		Mgo = &mongo.MongoDatabase{Database: s.Session.DB(conf.DBName)}
 	}
 }
+
+// GetDatabaseDriver where the repositories are kept, the MongoDB rate limit store and archive collection
+// need the mongodb driver
+func GetDatabaseDriver() string {
+	if driver := viper.GetString("database.driver"); driver != "" {
+		return driver
+	}
+	return DatabaseDriverMongoDB
+}
+
+// GetMongoMigrate reports whether the MongoDB migrations are applied at startup
+func GetMongoMigrate() bool {
+	return viper.GetBool("database.mongo.migrate")
+}
+
+// GetSQLitePath database file of the sqlite driver, ":memory:" keeps it in memory
+func GetSQLitePath() string {
+	if path := viper.GetString("database.sqlite.path"); path != "" {
+		return path
+	}
+	return DefaultSQLitePath
+}
+
+// SQLiteConnect open the database file when the sqlite driver is selected
+func SQLiteConnect() {
+	if GetDatabaseDriver() != DatabaseDriverSQLite {
+		return
+	}
+	db, err := sqlite.Open(GetSQLitePath())
+	if err != nil {
+		panic(err)
+	}
+	SQLite = db
+}
+
+// ArchiveConfig where the retention keeps the archived order records
+type ArchiveConfig struct {
+	// Target ArchiveTargetFile or ArchiveTargetCollection
+	Target string
+	// Path directory of the archive files
+	Path string
+}
+
+var (
+	ArchiveTargetFile       = "file"
+	ArchiveTargetCollection = "collection"
+	DefaultArchivePath      = "archive"
+)
+
+// GetMongoMaxAge max age of the order records, zero keeps them forever
+func GetMongoMaxAge() mongo.MaxAge {
+	var maxAge mongo.MaxAge
+	viper.UnmarshalKey("database.mongo.max_age", &maxAge)
+	return maxAge
+}
+
+// GetMongoArchive archive of the retention, files under DefaultArchivePath by default
+func GetMongoArchive() ArchiveConfig {
+	var archive ArchiveConfig
+	viper.UnmarshalKey("database.mongo.archive", &archive)
+	if archive.Target == "" {
+		archive.Target = ArchiveTargetFile
+	}
+	if archive.Path == "" {
+		archive.Path = DefaultArchivePath
+	}
+	return archive
+}
@@ -1,18 +1,24 @@
 package config
 
 import (
+	"fmt"
+
 	"github.com/spf13/viper"
+
+	"github.com/sepulsa/teleco/utils/auth"
 )
 
-type (
-	// JWTConfig JWTConfig
-	JWTConfig struct {
-		SignKey             []byte
-		RefreshSignKey      []byte
-		ExpiredToken        int
-		ExpiredRefreshToken int
+// GetJWTConfig secrets and keys of the jwt section
+func GetJWTConfig() auth.JWTConfig {
+	return auth.JWTConfig{
+		SignKey:             GetJWTSigningKey(),
+		RefreshSignKey:      GetJWTRefreshSigningKey(),
+		ExpiredToken:        GetJWTExpiredToken(),
+		ExpiredRefreshToken: GetJWTExpiredRefreshToken(),
+		ActiveKeyID:         GetJWTActiveKeyID(),
+		Keys:                GetJWTKeys(),
 	}
-)
+}
 
 func GetJWTSigningKey() []byte {
 	return []byte(viper.GetString("jwt.secret_token"))
@@ -28,3 +34,17 @@ func GetJWTExpiredToken() int {
 func GetJWTExpiredRefreshToken() int {
 	return viper.GetInt("jwt.expired_refresh_token")
 }
+
+// GetJWTActiveKeyID kid of the key used to sign new tokens, empty means HS256 with jwt.secret_token
+func GetJWTActiveKeyID() string {
+	return viper.GetString("jwt.active_kid")
+}
+
+// GetJWTKeys active and retired asymmetric keys
+func GetJWTKeys() []auth.JWTKeyConfig {
+	var keys []auth.JWTKeyConfig
+	if err := viper.UnmarshalKey("jwt.keys", &keys); err != nil {
+		panic(fmt.Errorf("Fatal error jwt keys config: %s", err))
+	}
+	return keys
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+type (
+	// SMTPConfig mail server the low balance alerts and password reset tokens are sent through, disabled while host is empty
+	SMTPConfig struct {
+		Host     string   `mapstructure:"host"`
+		Port     int      `mapstructure:"port"`
+		Username string   `mapstructure:"username"`
+		Password string   `mapstructure:"password"`
+		From     string   `mapstructure:"from"`
+		To       []string `mapstructure:"to"`
+	}
+)
+
+// GetNotifierWebhookUrl url the low balance alerts are posted to as json, disabled while empty
+func GetNotifierWebhookUrl() string {
+	return viper.GetString("notifier.webhook.url")
+}
+
+// GetNotifierSMTP mail server of the low balance alerts and password reset tokens
+func GetNotifierSMTP() SMTPConfig {
+	var smtp SMTPConfig
+	viper.UnmarshalKey("notifier.smtp", &smtp)
+	return smtp
+}
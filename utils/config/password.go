@@ -0,0 +1,31 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+
+	"github.com/sepulsa/teleco/utils/validator"
+)
+
+var (
+	DefaultPasswordResetExpired = 60
+)
+
+// GetPasswordPolicy load password policy, missing keys disable the rule
+func GetPasswordPolicy() validator.PasswordPolicy {
+	return validator.PasswordPolicy{
+		MinLength:     viper.GetInt("password_policy.min_length"),
+		RequireUpper:  viper.GetBool("password_policy.require_upper"),
+		RequireLower:  viper.GetBool("password_policy.require_lower"),
+		RequireDigit:  viper.GetBool("password_policy.require_digit"),
+		RequireSymbol: viper.GetBool("password_policy.require_symbol"),
+		History:       viper.GetInt("password_policy.history"),
+	}
+}
+
+// GetPasswordResetExpired lifetime of password reset token in minutes
+func GetPasswordResetExpired() int {
+	if expired := viper.GetInt("password_policy.reset_token_expired"); expired > 0 {
+		return expired
+	}
+	return DefaultPasswordResetExpired
+}
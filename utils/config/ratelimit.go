@@ -0,0 +1,18 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+var (
+	RateLimitStoreMemory = "memory"
+	RateLimitStoreMongo  = "mongo"
+)
+
+// GetRateLimitStore where token buckets are kept, use mongo when running more than one replica
+func GetRateLimitStore() string {
+	if store := viper.GetString("rate_limit.store"); store != "" {
+		return store
+	}
+	return RateLimitStoreMemory
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+var (
+	DefaultShutdownDrainTimeout = 30
+)
+
+// GetShutdownDrainTimeout seconds a stopping process waits for in-flight requests, tasks and queue messages
+func GetShutdownDrainTimeout() int {
+	if timeout := viper.GetInt("shutdown.drain_timeout"); timeout > 0 {
+		return timeout
+	}
+	return DefaultShutdownDrainTimeout
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+var (
+	DefaultStatementDownloadUrl = "/api/v1/statement/download"
+	DefaultStatementLinkExpired = 24 * time.Hour
+)
+
+// GetStatementDownloadUrl external API url of the statement download, the signed query is appended to it
+func GetStatementDownloadUrl() string {
+	if url := viper.GetString("statement.download_url"); url != "" {
+		return url
+	}
+	return DefaultStatementDownloadUrl
+}
+
+// GetStatementLinkExpired validity of a statement download link, statement.link_expired is in minutes
+func GetStatementLinkExpired() time.Duration {
+	if minutes := viper.GetInt("statement.link_expired"); minutes > 0 {
+		return time.Duration(minutes) * time.Minute
+	}
+	return DefaultStatementLinkExpired
+}
@@ -0,0 +1,17 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+var (
+	DefaultThreadPoolCallTimeout = 60
+)
+
+// GetThreadPoolCallTimeout seconds an issuer call may keep running after the pool timeout returned a pending result
+func GetThreadPoolCallTimeout() int {
+	if timeout := viper.GetInt("threadpool.call_timeout"); timeout > 0 {
+		return timeout
+	}
+	return DefaultThreadPoolCallTimeout
+}
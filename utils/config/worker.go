@@ -0,0 +1,27 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+var (
+	DefaultWorkerReconcileInterval = 30
+)
+
+// GetWorkerReconcileInterval seconds between two syncs of the queue consumers with the issuer collection
+func GetWorkerReconcileInterval() int {
+	if interval := viper.GetInt("worker.reconcile_interval"); interval > 0 {
+		return interval
+	}
+	return DefaultWorkerReconcileInterval
+}
+
+// GetWorkerBalanceInquiryInterval seconds between two balance inquiries to every active issuer, zero disables them
+func GetWorkerBalanceInquiryInterval() int {
+	return viper.GetInt("worker.balance_inquiry_interval")
+}
+
+// GetWorkerRetentionInterval seconds between two retention runs of the order records, zero disables them
+func GetWorkerRetentionInterval() int {
+	return viper.GetInt("worker.retention_interval")
+}
@@ -75,6 +75,16 @@ func init() {
 	Logger = zerolog.New(appLog).With().Timestamp().Logger()
 }
 
+// Close flush and close the log files, call it last while the process stops.
+func Close() {
+	if appLog != nil {
+		appLog.Close()
+	}
+	if MiddlewareLog != nil {
+		MiddlewareLog.Close()
+	}
+}
+
 // Output duplicates the global logger and sets w as its output.
 func Output(w io.Writer) zerolog.Logger {
 	return Logger.Output(w)
@@ -11,6 +11,8 @@ type (
 		One(result interface{}) error
 		Sort(fields ...string) Query
 		Limit(n int) Query
+		Skip(n int) Query
+		Count() (int, error)
 	}
 
 	// Collection is an interface to access to the collection struct.
@@ -19,7 +21,9 @@ type (
 		Insert(docs ...interface{}) error
 		Update(selector interface{}, update interface{}) error
 		UpdateAll(selector interface{}, update interface{}) (*mgo.ChangeInfo, error)
+		Upsert(selector interface{}, update interface{}) (*mgo.ChangeInfo, error)
 		Remove(selector interface{}) error
+		RemoveAll(selector interface{}) (*mgo.ChangeInfo, error)
 		DropIndexName(name string) error
 		EnsureIndex(index mgo.Index) error
 		EnsureIndexKey(key ...string) error
@@ -74,6 +78,14 @@ func (q MongoQuery) Limit(n int) Query {
 	return MongoQuery{Query: q.Query.Limit(n)}
 }
 
+func (q MongoQuery) Skip(n int) Query {
+	return MongoQuery{Query: q.Query.Skip(n)}
+}
+
+func (q MongoQuery) Count() (int, error) {
+	return q.Query.Count()
+}
+
 // Find shadows *mgo.Collection to returns a Query interface instead of *mgo.Query.
 func (c MongoCollection) Find(query interface{}) Query {
 	return MongoQuery{Query: c.Collection.Find(query)}
@@ -91,10 +103,18 @@ func (c MongoCollection) UpdateAll(selector interface{}, update interface{}) (*m
 	return c.Collection.UpdateAll(selector, update)
 }
 
+func (c MongoCollection) Upsert(selector interface{}, update interface{}) (*mgo.ChangeInfo, error) {
+	return c.Collection.Upsert(selector, update)
+}
+
 func (c MongoCollection) Remove(selector interface{}) error {
 	return c.Collection.Remove(selector)
 }
 
+func (c MongoCollection) RemoveAll(selector interface{}) (*mgo.ChangeInfo, error) {
+	return c.Collection.RemoveAll(selector)
+}
+
 func (c MongoCollection) DropIndexName(name string) error {
 	return c.Collection.DropIndexName(name)
 }
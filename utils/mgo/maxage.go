@@ -0,0 +1,20 @@
+package mgo
+
+import "time"
+
+// MaxAge age of the records kept in a collection, older records are archived by the retention
+type MaxAge struct {
+	Days   int
+	Months int
+	Years  int
+}
+
+// IsZero reports whether records are kept forever
+func (a MaxAge) IsZero() bool {
+	return a.Days <= 0 && a.Months <= 0 && a.Years <= 0
+}
+
+// Cutoff creation time before which records are past the max age
+func (a MaxAge) Cutoff(now time.Time) time.Time {
+	return now.AddDate(-a.Years, -a.Months, -a.Days)
+}
@@ -0,0 +1,64 @@
+package mgo
+
+import (
+	"time"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// MigrationCollection records the applied migrations
+const MigrationCollection = "schema_migrations"
+
+type (
+	// Migration schema change applied once, in Version order, Up must be safe to run again
+	// as it is recorded only once it succeeded
+	Migration struct {
+		Version     int
+		Description string
+		Up          func(db DataLayer) error
+	}
+
+	// AppliedMigration record of a migration in MigrationCollection
+	AppliedMigration struct {
+		Version     int       `bson:"_id"`
+		Description string    `bson:"description"`
+		AppliedAt   time.Time `bson:"applied_at"`
+	}
+)
+
+// Migrate applies the migrations not recorded yet in MigrationCollection
+func Migrate(db DataLayer, migrations []Migration) error {
+	applied := db.C(MigrationCollection)
+	for _, migration := range migrations {
+		count, err := applied.Find(bson.M{"_id": migration.Version}).Count()
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if err = migration.Up(db); err != nil {
+			return err
+		}
+		// another replica may have applied it at the same time
+		err = applied.Insert(AppliedMigration{migration.Version, migration.Description, time.Now()})
+		if err != nil && !mgo.IsDup(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureIndexes migration step creating the indexes of a collection
+func EnsureIndexes(collection string, indexes ...mgo.Index) func(db DataLayer) error {
+	return func(db DataLayer) error {
+		c := db.C(collection)
+		for _, index := range indexes {
+			if err := c.EnsureIndex(index); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
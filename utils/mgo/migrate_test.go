@@ -0,0 +1,79 @@
+package mgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	migrationDatabase struct {
+		applied map[int]bool
+		indexes map[string][]mgo.Index
+	}
+
+	migrationCollection struct {
+		MockCollection
+		db   *migrationDatabase
+		name string
+	}
+
+	migrationQuery struct {
+		MockQuery
+		found bool
+	}
+)
+
+func (db *migrationDatabase) C(name string) Collection {
+	return migrationCollection{db: db, name: name}
+}
+
+func (c migrationCollection) Find(query interface{}) Query {
+	version, _ := query.(bson.M)["_id"].(int)
+	return migrationQuery{found: c.db.applied[version]}
+}
+
+func (c migrationCollection) Insert(docs ...interface{}) error {
+	c.db.applied[docs[0].(AppliedMigration).Version] = true
+	return nil
+}
+
+func (c migrationCollection) EnsureIndex(index mgo.Index) error {
+	c.db.indexes[c.name] = append(c.db.indexes[c.name], index)
+	return nil
+}
+
+func (q migrationQuery) Count() (int, error) {
+	if q.found {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func TestMigrate(t *testing.T) {
+	db := &migrationDatabase{applied: map[int]bool{1: true}, indexes: map[string][]mgo.Index{}}
+	runs := 0
+	migrations := []Migration{
+		{1, "applied before", func(DataLayer) error { runs++; return nil }},
+		{2, "code index", EnsureIndexes("issuer", mgo.Index{Key: []string{"code"}, Unique: true})},
+		{3, "backfill", func(DataLayer) error { runs++; return nil }},
+	}
+
+	if assert.NoError(t, Migrate(db, migrations)) {
+		assert.Equal(t, 1, runs)
+		assert.Equal(t, map[int]bool{1: true, 2: true, 3: true}, db.applied)
+		assert.Equal(t, []mgo.Index{{Key: []string{"code"}, Unique: true}}, db.indexes["issuer"])
+	}
+
+	// applied migrations are not run again
+	assert.NoError(t, Migrate(db, migrations))
+	assert.Equal(t, 1, runs)
+
+	// a failed migration is not recorded
+	failed := append(migrations, Migration{4, "failed", func(DataLayer) error { return errors.New("failed") }})
+	assert.EqualError(t, Migrate(db, failed), "failed")
+	assert.False(t, db.applied[4])
+}
@@ -34,6 +34,15 @@ func (fq MockQuery) Limit(n int) Query {
 	return fq
 }
 
+func (fq MockQuery) Skip(n int) Query {
+	return fq
+}
+
+// Count mock.
+func (fq MockQuery) Count() (int, error) {
+	return 0, nil
+}
+
 // Find mock.
 func (fc MockCollection) Find(query interface{}) Query {
 	return MockQuery{}
@@ -53,10 +62,18 @@ func (fc MockCollection) UpdateAll(selector interface{}, update interface{}) (*m
 	return nil, nil
 }
 
+func (fc MockCollection) Upsert(selector interface{}, update interface{}) (*mgo.ChangeInfo, error) {
+	return nil, nil
+}
+
 func (fc MockCollection) Remove(selector interface{}) error {
 	return nil
 }
 
+func (fc MockCollection) RemoveAll(selector interface{}) (*mgo.ChangeInfo, error) {
+	return &mgo.ChangeInfo{}, nil
+}
+
 func (fc MockCollection) DropIndexName(name string) error {
 	return nil
 }
@@ -0,0 +1,146 @@
+package mgo
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"regexp"
+
+	"github.com/sepulsa/teleco/utils/query"
+	"gopkg.in/mgo.v2/bson"
+)
+
+type (
+	// SearchFields fields of a collection open to a query.Query
+	SearchFields struct {
+		Search []string
+		Sort   []string
+		Filter []string
+	}
+
+	cursor struct {
+		Value interface{}   `bson:"v"`
+		ID    bson.ObjectId `bson:"id"`
+	}
+)
+
+// Search reads a page of the documents matching filter and the query into result, a pointer to a slice,
+// documents are sorted by _id when the query has no sort field
+func Search(c Collection, filter bson.M, q query.Query, fields SearchFields, result interface{}) (page query.Page, err error) {
+	q = q.Normalize()
+	page.Page = q.Page
+	page.Limit = q.Limit
+
+	for field, value := range q.Filters {
+		if value == "" {
+			continue
+		}
+		if !contains(fields.Filter, field) {
+			return page, errors.New(query.ErrInvalidFilter)
+		}
+		filter[field] = value
+	}
+	if q.Search != "" && len(fields.Search) > 0 {
+		search := []bson.M{}
+		for _, field := range fields.Search {
+			search = append(search, bson.M{field: bson.RegEx{Pattern: regexp.QuoteMeta(q.Search), Options: "i"}})
+		}
+		filter["$or"] = search
+	}
+	if page.Total, err = c.Find(filter).Count(); err != nil {
+		return
+	}
+
+	field, descending := q.SortField()
+	if field == "" {
+		field = "_id"
+	} else if !contains(fields.Sort, field) {
+		return page, errors.New(query.ErrInvalidSort)
+	}
+	order, op := "", "$gt"
+	if descending {
+		order, op = "-", "$lt"
+	}
+	sort := []string{order + field}
+	if field != "_id" {
+		sort = append(sort, order+"_id")
+	}
+
+	selector := filter
+	if q.Cursor != "" {
+		position, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return page, err
+		}
+		// documents after the cursor, the id breaks ties on the sort field
+		after := []bson.M{{"_id": bson.M{op: position.ID}}}
+		if field != "_id" {
+			after = []bson.M{
+				{field: bson.M{op: position.Value}},
+				{field: position.Value, "_id": bson.M{op: position.ID}},
+			}
+		}
+		selector = bson.M{"$and": []bson.M{filter, {"$or": after}}}
+	}
+	find := c.Find(selector).Sort(sort...).Limit(q.Limit + 1)
+	if q.Cursor == "" {
+		find = find.Skip((q.Page - 1) * q.Limit)
+	}
+
+	var raws []bson.Raw
+	if err = find.All(&raws); err != nil {
+		return
+	}
+	if len(raws) > q.Limit {
+		raws = raws[:q.Limit]
+		if page.NextCursor, err = encodeCursor(raws[len(raws)-1], field); err != nil {
+			return
+		}
+	}
+
+	documents := reflect.ValueOf(result).Elem()
+	slice := reflect.MakeSlice(documents.Type(), 0, len(raws))
+	for _, raw := range raws {
+		document := reflect.New(documents.Type().Elem())
+		if err = raw.Unmarshal(document.Interface()); err != nil {
+			return
+		}
+		slice = reflect.Append(slice, document.Elem())
+	}
+	documents.Set(slice)
+	return
+}
+
+// encodeCursor keeps the sort position of a document, it is opaque to the caller
+func encodeCursor(raw bson.Raw, field string) (string, error) {
+	var document bson.M
+	if err := raw.Unmarshal(&document); err != nil {
+		return "", err
+	}
+	ID, _ := document["_id"].(bson.ObjectId)
+	b, err := bson.Marshal(cursor{document[field], ID})
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeCursor(value string) (position cursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = bson.Unmarshal(b, &position)
+	}
+	if err != nil || !position.ID.Valid() {
+		err = errors.New(query.ErrInvalidCursor)
+	}
+	return
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package mgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/mgo.v2/bson"
+)
+
+func TestCursor(t *testing.T) {
+	ID := bson.NewObjectId()
+	createdAt := time.Date(2021, 9, 8, 10, 0, 0, 0, time.UTC)
+	b, _ := bson.Marshal(bson.M{"_id": ID, "code": "dummy", "created_at": createdAt})
+
+	value, err := encodeCursor(bson.Raw{Kind: 0x03, Data: b}, "created_at")
+	if assert.NoError(t, err) {
+		position, err := decodeCursor(value)
+		if assert.NoError(t, err) {
+			assert.Equal(t, ID, position.ID)
+			assert.True(t, createdAt.Equal(position.Value.(time.Time)))
+		}
+	}
+
+	_, err = decodeCursor("x")
+	assert.Equal(t, query.ErrInvalidCursor, err.Error())
+}
+
+func TestSearch(t *testing.T) {
+	var documents []bson.M
+	fields := SearchFields{Search: []string{"code"}, Sort: []string{"code"}, Filter: []string{"status"}}
+
+	page, err := Search(MockCollection{}, bson.M{}, query.Query{Sort: "-code", Search: "dum", Filters: map[string]string{"status": "active"}}, fields, &documents)
+	if assert.NoError(t, err) {
+		assert.Equal(t, query.Page{Page: 1, Limit: query.DefaultLimit}, page)
+		assert.Empty(t, documents)
+	}
+
+	_, err = Search(MockCollection{}, bson.M{}, query.Query{Sort: "secret_key"}, fields, &documents)
+	assert.Equal(t, query.ErrInvalidSort, err.Error())
+
+	_, err = Search(MockCollection{}, bson.M{}, query.Query{Filters: map[string]string{"secret_key": "x"}}, fields, &documents)
+	assert.Equal(t, query.ErrInvalidFilter, err.Error())
+
+	_, err = Search(MockCollection{}, bson.M{}, query.Query{Cursor: "x"}, fields, &documents)
+	assert.Equal(t, query.ErrInvalidCursor, err.Error())
+}
@@ -0,0 +1,35 @@
+package mgo
+
+import (
+	"errors"
+
+	"gopkg.in/mgo.v2"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// ErrVersionConflict the live document has another version than the update
+var ErrVersionConflict = errors.New("version conflict")
+
+// UpdateVersion applies update to the live document of ID as long as it still has version and increments it,
+// mgo.ErrNotFound when there is no live document of ID and ErrVersionConflict when it has another version
+func UpdateVersion(c Collection, ID bson.ObjectId, version int, update bson.M) error {
+	filter := bson.M{
+		"_id":        ID,
+		"deleted_at": bson.M{"$exists": false},
+		"version":    version,
+	}
+	update["$inc"] = bson.M{"version": 1}
+	err := c.Update(filter, update)
+	if err != mgo.ErrNotFound {
+		return err
+	}
+
+	count, err := c.Find(bson.M{"_id": ID, "deleted_at": bson.M{"$exists": false}}).Count()
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrVersionConflict
+	}
+	return mgo.ErrNotFound
+}
@@ -0,0 +1,50 @@
+package etag
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Header names missing from echo
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+var (
+	ErrVersionRequired = "version or If-Match header is required"
+	ErrInvalidIfMatch  = "If-Match header must be the ETag returned by GET"
+)
+
+// Format ETag of a record version
+func Format(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// Set ETag header of the response to the record version
+func Set(c echo.Context, version int) {
+	c.Response().Header().Set(HeaderETag, Format(version))
+}
+
+// Version the update is based on, the If-Match header takes precedence over the version of the request body
+func Version(c echo.Context, version *int) (int, error) {
+	ifMatch := strings.TrimSpace(c.Request().Header.Get(HeaderIfMatch))
+	if ifMatch == "" {
+		if version == nil {
+			return 0, errors.New(ErrVersionRequired)
+		}
+		return *version, nil
+	}
+
+	if len(ifMatch) < 3 || !strings.HasPrefix(ifMatch, `"`) || !strings.HasSuffix(ifMatch, `"`) {
+		return 0, errors.New(ErrInvalidIfMatch)
+	}
+	parsed, err := strconv.Atoi(ifMatch[1 : len(ifMatch)-1])
+	if err != nil || parsed < 0 {
+		return 0, errors.New(ErrInvalidIfMatch)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,52 @@
+package etag
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersion(t *testing.T) {
+	e := echo.New()
+	context := func(ifMatch string) echo.Context {
+		req := httptest.NewRequest(http.MethodPut, "/", nil)
+		if ifMatch != "" {
+			req.Header.Set(HeaderIfMatch, ifMatch)
+		}
+		return e.NewContext(req, httptest.NewRecorder())
+	}
+	body := 3
+
+	// version of the body
+	version, err := Version(context(""), &body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, version)
+	}
+
+	// If-Match takes precedence
+	version, err = Version(context(Format(5)), &body)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 5, version)
+	}
+
+	// missing
+	_, err = Version(context(""), nil)
+	assert.EqualError(t, err, ErrVersionRequired)
+
+	// not an ETag of this API
+	for _, ifMatch := range []string{"*", `W/"5"`, "5", `""`, `"-1"`, `"five"`} {
+		_, err = Version(context(ifMatch), nil)
+		assert.EqualError(t, err, ErrInvalidIfMatch, ifMatch)
+	}
+}
+
+func TestSet(t *testing.T) {
+	rec := httptest.NewRecorder()
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+
+	Set(c, 7)
+	assert.Equal(t, `"7"`, rec.Header().Get(HeaderETag))
+}
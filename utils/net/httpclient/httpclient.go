@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"context"
 	"crypto/tls"
 	"io"
 	"net/http"
@@ -13,6 +14,7 @@ import (
 //HttpDoer HttpDoer
 type HttpDoer interface {
 	HttpDo() (*http.Response, error)
+	HttpDoContext(ctx context.Context) (*http.Response, error)
 }
 
 //HttpParam HttpParam
@@ -31,28 +33,33 @@ type HttpParam struct {
 
 //HttpDo HttpDo
 func (httpParam *HttpParam) HttpDo() (*http.Response, error) {
+	return httpParam.HttpDoContext(context.Background())
+}
+
+//HttpDoContext HttpDo aborted when ctx is done, whichever comes first of ctx deadline and Timeout applies
+func (httpParam *HttpParam) HttpDoContext(ctx context.Context) (*http.Response, error) {
 	headers := makeHeader(httpParam.Header)
 	timeout := time.Duration(httpParam.Timeout) * time.Second
 	switch httpParam.Method {
 	case "get":
-		return get(httpParam.Url, headers, timeout)
+		return get(ctx, httpParam.Url, headers, timeout)
 	case "post":
-		return post(httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
+		return post(ctx, httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
 	case "put":
-		return put(httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
+		return put(ctx, httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
 	case "patch":
-		return patch(httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
+		return patch(ctx, httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
 	case "delete":
-		return delete(httpParam.Url, headers, timeout)
+		return delete(ctx, httpParam.Url, headers, timeout)
 	default:
-		return post(httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
+		return post(ctx, httpParam.Url, strings.NewReader(httpParam.Body), headers, timeout)
 	}
 }
 
 // Get makes a HTTP GET request to provided URL
-func get(url string, headers http.Header, timeout time.Duration) (*http.Response, error) {
+func get(ctx context.Context, url string, headers http.Header, timeout time.Duration) (*http.Response, error) {
 	var response *http.Response
-	request, err := http.NewRequest(http.MethodGet, url, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return response, errors.Wrap(err, "GET - request creation failed")
 	}
@@ -63,9 +70,9 @@ func get(url string, headers http.Header, timeout time.Duration) (*http.Response
 }
 
 // Post makes a HTTP POST request to provided URL and requestBody
-func post(url string, body io.Reader, headers http.Header, timeout time.Duration) (*http.Response, error) {
+func post(ctx context.Context, url string, body io.Reader, headers http.Header, timeout time.Duration) (*http.Response, error) {
 	var response *http.Response
-	request, err := http.NewRequest(http.MethodPost, url, body)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, body)
 	if err != nil {
 		return response, errors.Wrap(err, "POST - request creation failed")
 	}
@@ -76,9 +83,9 @@ func post(url string, body io.Reader, headers http.Header, timeout time.Duration
 }
 
 // Put makes a HTTP PUT request to provided URL and requestBody
-func put(url string, body io.Reader, headers http.Header, timeout time.Duration) (*http.Response, error) {
+func put(ctx context.Context, url string, body io.Reader, headers http.Header, timeout time.Duration) (*http.Response, error) {
 	var response *http.Response
-	request, err := http.NewRequest(http.MethodPut, url, body)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, url, body)
 	if err != nil {
 		return response, errors.Wrap(err, "PUT - request creation failed")
 	}
@@ -89,9 +96,9 @@ func put(url string, body io.Reader, headers http.Header, timeout time.Duration)
 }
 
 // Patch makes a HTTP PATCH request to provided URL and requestBody
-func patch(url string, body io.Reader, headers http.Header, timeout time.Duration) (*http.Response, error) {
+func patch(ctx context.Context, url string, body io.Reader, headers http.Header, timeout time.Duration) (*http.Response, error) {
 	var response *http.Response
-	request, err := http.NewRequest(http.MethodPatch, url, body)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPatch, url, body)
 	if err != nil {
 		return response, errors.Wrap(err, "PATCH - request creation failed")
 	}
@@ -102,9 +109,9 @@ func patch(url string, body io.Reader, headers http.Header, timeout time.Duratio
 }
 
 // Delete makes a HTTP DELETE request with provided URL
-func delete(url string, headers http.Header, timeout time.Duration) (*http.Response, error) {
+func delete(ctx context.Context, url string, headers http.Header, timeout time.Duration) (*http.Response, error) {
 	var response *http.Response
-	request, err := http.NewRequest(http.MethodDelete, url, nil)
+	request, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
 	if err != nil {
 		return response, errors.Wrap(err, "DELETE - request creation failed")
 	}
@@ -1,11 +1,14 @@
 package httpclient
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -198,6 +201,26 @@ func TestHttpDoDefault(t *testing.T) {
 	assert.Equal(t, httpParam.Body, string(body))
 }
 
+func TestHttpDoContext(t *testing.T) {
+	var httpParam HttpParam
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	httpParam.Url = server.URL
+	httpParam.Method = "get"
+	httpParam.Timeout = 30
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := httpParam.HttpDoContext(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(start) < time.Second)
+}
+
 func TestMakeHeader(t *testing.T) {
 	var httpParam HttpParam
 
@@ -2,13 +2,20 @@ package httperror
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/sepulsa/teleco/utils/apperror"
 )
 
 // New example
 func NewValidationError(c echo.Context, status int, err error) error {
+	return c.JSON(status, echo.HTTPError{Message: ValidationMessage(err)})
+}
+
+// ValidationMessage message of the first failed validation
+func ValidationMessage(err error) string {
 	var errMsg string
 	if castedObject, ok := err.(validator.ValidationErrors); ok {
 		for _, err := range castedObject {
@@ -27,6 +34,8 @@ func NewValidationError(c echo.Context, status int, err error) error {
 				errMsg = fmt.Sprintf("%s value must be greater than %s", err.Field(), err.Param())
 			case "lte":
 				errMsg = fmt.Sprintf("%s value must be lower than %s", err.Field(), err.Param())
+			case "oneof":
+				errMsg = fmt.Sprintf("%s must be one of %s", err.Field(), err.Param())
 			}
 
 			if errMsg != "" {
@@ -35,5 +44,13 @@ func NewValidationError(c echo.Context, status int, err error) error {
 		}
 	}
 
-	return c.JSON(status, echo.HTTPError{Message: errMsg})
+	return errMsg
+}
+
+// Status HTTP status of a service error, 409 for a conflict and 422 otherwise
+func Status(err error) int {
+	if apperror.KindOf(err) == apperror.Conflict {
+		return http.StatusConflict
+	}
+	return http.StatusUnprocessableEntity
 }
@@ -0,0 +1,58 @@
+package query
+
+import "strings"
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidCursor = "Invalid cursor"
+	ErrInvalidSort   = "Invalid sort field"
+	ErrInvalidFilter = "Invalid filter"
+)
+
+type (
+	// Query list parameters shared by the repositories, Cursor takes over Page when set
+	Query struct {
+		Page   int
+		Limit  int
+		Cursor string
+		// Sort field name, descending with a "-" prefix
+		Sort string
+		// Search text matched, case insensitive, on the searchable fields of the repository
+		Search string
+		// Filters exact value of a field, empty values are left out
+		Filters map[string]string
+	}
+
+	// Page pagination metadata of a list, Page is zero when read with a cursor
+	Page struct {
+		Total      int    `json:"total"`
+		Page       int    `json:"page"`
+		Limit      int    `json:"limit"`
+		NextCursor string `json:"next_cursor"`
+	}
+)
+
+// Normalize sets the default page and limit, the page is dropped for a cursor
+func (q Query) Normalize() Query {
+	if q.Limit <= 0 {
+		q.Limit = DefaultLimit
+	}
+	if q.Limit > MaxLimit {
+		q.Limit = MaxLimit
+	}
+	if q.Cursor != "" {
+		q.Page = 0
+	} else if q.Page <= 0 {
+		q.Page = 1
+	}
+	return q
+}
+
+// SortField name of the sort field and its direction
+func (q Query) SortField() (field string, descending bool) {
+	return strings.TrimPrefix(q.Sort, "-"), strings.HasPrefix(q.Sort, "-")
+}
@@ -0,0 +1,32 @@
+package query_test
+
+import (
+	"testing"
+
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	q := query.Query{}.Normalize()
+	assert.Equal(t, 1, q.Page)
+	assert.Equal(t, query.DefaultLimit, q.Limit)
+
+	q = query.Query{Page: 3, Limit: 1000}.Normalize()
+	assert.Equal(t, 3, q.Page)
+	assert.Equal(t, query.MaxLimit, q.Limit)
+
+	// cursor reads from its own position
+	q = query.Query{Page: 3, Cursor: "x"}.Normalize()
+	assert.Equal(t, 0, q.Page)
+}
+
+func TestSortField(t *testing.T) {
+	field, descending := query.Query{Sort: "-code"}.SortField()
+	assert.Equal(t, "code", field)
+	assert.True(t, descending)
+
+	field, descending = query.Query{Sort: "code"}.SortField()
+	assert.Equal(t, "code", field)
+	assert.False(t, descending)
+}
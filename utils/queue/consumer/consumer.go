@@ -1,7 +1,10 @@
 package consumer
 
 import (
+	"context"
+	"errors"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/gofort/dispatcher"
@@ -24,16 +27,30 @@ type AMQPWorker struct {
 // AMQPConsumer ..
 type AMQPConsumer struct {
 	Consumer map[string]AMQPWorker
+
+	mu sync.Mutex
 }
 
 // Consumer ..
 var (
-	Consumer   AMQPConsumer = AMQPConsumer{make(map[string]AMQPWorker)}
+	Consumer   AMQPConsumer = AMQPConsumer{Consumer: make(map[string]AMQPWorker)}
 	packageLog              = "teleco/utils/queue/consumer"
+
+	DefaultLimit = 5
+
+	ErrCreateServer = "create amqp server failed"
 )
 
 // CreateServer ..
 func (cons *AMQPConsumer) CreateServer(queueName string) {
+	cons.mu.Lock()
+	defer cons.mu.Unlock()
+	if err := cons.createServer(queueName); err != nil {
+		log.Fatal().Str("event", "createserver.error").Str("package", packageLog).Msgf("Error Create Worker: %s", err.Error())
+	}
+}
+
+func (cons *AMQPConsumer) createServer(queueName string) error {
 	conf := config.LoadDBConfig("amqp")
 	amqpURL := "amqp://" + conf.User + ":" + conf.Password + "@" + conf.Host + ":" + strconv.Itoa(conf.Port)
 	cfg := dispatcher.ServerConfig{
@@ -55,47 +72,70 @@ func (cons *AMQPConsumer) CreateServer(queueName string) {
 	// This function creates new server (server consists of AMQP connection and publisher which sends tasks)
 	server, _, err := dispatcher.NewServer(&cfg)
 	if err != nil {
-		log.Fatal().Str("event", "createserver.error").Str("package", packageLog).Msgf("Error Create Worker: %s", err.Error())
-		return
+		return err
 	}
 	cons.Consumer[queueName] = AMQPWorker{queueName, server, nil, 0, false, "SHUTDOWN", "default"}
+	return nil
 }
 
-func (cons *AMQPConsumer) getServer(queueName string) *dispatcher.Server {
+func (cons *AMQPConsumer) getServer(queueName string) (*dispatcher.Server, error) {
 	server := cons.Consumer[queueName].Server
 	if server == nil {
-		cons.CreateServer(queueName)
+		if err := cons.createServer(queueName); err != nil {
+			return nil, err
+		}
 		server = cons.Consumer[queueName].Server
 	}
-	return server
+	if server == nil {
+		return nil, errors.New(ErrCreateServer)
+	}
+	return server, nil
 }
 
 // StartWorker ..
 func (cons *AMQPConsumer) StartWorker(queueName string, limit int, task Runnable) {
-	server := cons.getServer(queueName)
+	cons.mu.Lock()
+	defer cons.mu.Unlock()
+	if err := cons.startWorker(queueName, limit, task); err != nil {
+		log.Fatal().Str("event", "startworker.error").Str("package", packageLog).Msgf("Error Start Worker: %s", err.Error())
+	}
+}
+
+// startWorker start the queue consumer, a running consumer with another limit is replaced
+// by a new one and closed after the new one started so messages keep flowing. Until the previous
+// consumer drained its in-flight tasks both consume, so the issuer may briefly get up to the old
+// plus the new limit of concurrent tasks
+func (cons *AMQPConsumer) startWorker(queueName string, limit int, task Runnable) error {
+	server, err := cons.getServer(queueName)
+	if err != nil {
+		return err
+	}
 	if limit <= 0 {
-		limit = 5
+		limit = DefaultLimit
 	}
 
 	// check existing worker
 	consumer := cons.Consumer[queueName]
+	if consumer.IsActive && limit == consumer.Limit {
+		return nil
+	}
 	if limit == consumer.Limit {
 		if worker, err := server.GetWorkerByName(consumer.WorkerName); err == nil {
 			if err := worker.Start(server); err != nil {
-				log.Fatal().Str("event", "startworker.error").Str("package", packageLog).Msgf("Error Start Worker: %s", err.Error())
-				return
+				return err
 			}
 			consumer.Limit = limit
 			consumer.Worker = worker
 			consumer.IsActive = true
 			consumer.Status = "RUNNING"
 			cons.Consumer[queueName] = consumer
-			return
+			return nil
 		}
 	}
+	previous := consumer
 
 	// Basic worker configuration
-	workerName := "worker_" + queueName + "_" + time.Now().Format("0102150405")
+	workerName := "worker_" + queueName + "_" + time.Now().Format("0102150405.000")
 	workercfg := dispatcher.WorkerConfig{
 		Queue: queueName,
 		Name:  workerName,
@@ -113,24 +153,119 @@ func (cons *AMQPConsumer) StartWorker(queueName string, limit int, task Runnable
 	// This function creates worker, but he won't start to consume messages here
 	worker, err := server.NewWorker(&workercfg, tasks)
 	if err != nil {
-		log.Fatal().Str("event", "startworker.error").Str("package", packageLog).Msgf("Error Start Worker: %s", err.Error())
-		return
+		return err
 	}
-	consumer = cons.Consumer[queueName]
 	consumer.WorkerName = workerName
 	consumer.Limit = limit
 	consumer.Worker = worker
+
+	// Here we start worker consuming
+	if err := worker.Start(server); err != nil {
+		return err
+	}
 	consumer.IsActive = true
 	consumer.Status = "RUNNING"
 	cons.Consumer[queueName] = consumer
 
-	// Here we start worker consuming
-	if err := cons.Consumer[queueName].Worker.Start(server); err != nil {
-		consumer.IsActive = false
-		consumer.Status = "SHUTDOWN"
-		cons.Consumer[queueName] = consumer
-		log.Fatal().Str("event", "startworker.error").Str("package", packageLog).Msgf("Error Start Worker: %s", err.Error())
-		return
+	// the previous worker finishes its in-flight tasks in the background, unacknowledged
+	// messages are requeued when its channel closes
+	if previous.IsActive && previous.Worker != nil {
+		go previous.Worker.Close()
+	}
+	log.Info().Str("event", "startworker.info").Str("package", packageLog).Msgf("Worker: %s Started with limit %s", queueName, strconv.Itoa(limit))
+	return nil
+}
+
+// StopWorker close the queue consumer and its connection after in-flight tasks finished
+func (cons *AMQPConsumer) StopWorker(queueName string) {
+	cons.mu.Lock()
+	consumer, found := cons.removeWorker(queueName)
+	cons.mu.Unlock()
+	if found {
+		closeWorker(queueName, consumer)
+	}
+}
+
+// removeWorker take the queue consumer out of the running ones, the caller closes it
+// without holding the lock since closing waits for in-flight tasks
+func (cons *AMQPConsumer) removeWorker(queueName string) (AMQPWorker, bool) {
+	consumer, found := cons.Consumer[queueName]
+	if found {
+		delete(cons.Consumer, queueName)
+	}
+	return consumer, found
+}
+
+func closeWorker(queueName string, consumer AMQPWorker) {
+	if consumer.IsActive && consumer.Worker != nil {
+		consumer.Worker.Close()
+	}
+	if consumer.Server != nil {
+		consumer.Server.Close()
+	}
+	log.Info().Str("event", "stopworker.info").Str("package", packageLog).Msgf("Worker: %s Stopped", queueName)
+}
+
+// Shutdown stop consuming every queue, in-flight tasks are finished and acknowledged while
+// prefetched messages are requeued when the channels close, ctx bounds the wait
+func (cons *AMQPConsumer) Shutdown(ctx context.Context) error {
+	cons.mu.Lock()
+	consumers := cons.Consumer
+	cons.Consumer = make(map[string]AMQPWorker)
+	cons.mu.Unlock()
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		var wg sync.WaitGroup
+		for queueName, consumer := range consumers {
+			wg.Add(1)
+			go func(queueName string, consumer AMQPWorker) {
+				defer wg.Done()
+				closeWorker(queueName, consumer)
+			}(queueName, consumer)
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reconcile make the running consumers match desired, a map of queue name to limit:
+// missing consumers are started, changed limits are applied and the others are stopped,
+// stopped consumers finish their in-flight tasks in the background
+func (cons *AMQPConsumer) Reconcile(desired map[string]int, task Runnable) {
+	cons.mu.Lock()
+	defer cons.mu.Unlock()
+
+	for queueName := range cons.Consumer {
+		if _, found := desired[queueName]; !found {
+			if consumer, removed := cons.removeWorker(queueName); removed {
+				go closeWorker(queueName, consumer)
+			}
+		}
+	}
+	for queueName, limit := range desired {
+		if err := cons.startWorker(queueName, limit, task); err != nil {
+			log.Error().Str("event", "reconcile.error").Str("package", packageLog).Msgf("Error Start Worker %s: %s", queueName, err.Error())
+		}
+	}
+}
+
+// Workers snapshot of running queue names and their limit
+func (cons *AMQPConsumer) Workers() map[string]int {
+	cons.mu.Lock()
+	defer cons.mu.Unlock()
+	workers := make(map[string]int, len(cons.Consumer))
+	for queueName, consumer := range cons.Consumer {
+		if consumer.IsActive {
+			workers[queueName] = consumer.Limit
+		}
 	}
-	log.Info().Str("event", "startworker.info").Str("package", packageLog).Msgf("Worker: %s Started with limit %s", queueName, strconv.Itoa(cons.Consumer[queueName].Limit))
+	return workers
 }
@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+type (
+	// Bucket token bucket state, a new bucket starts full
+	Bucket struct {
+		Tokens    float64
+		UpdatedAt time.Time
+	}
+
+	// Limit refill rate in tokens per second and capacity of a bucket
+	Limit struct {
+		Rate  float64
+		Burst int
+	}
+)
+
+// Take refill the bucket with rate tokens per second up to burst and consume one token,
+// when the bucket is empty it returns how long until the next token is available
+func (b *Bucket) Take(now time.Time, rate float64, burst int) (allowed bool, retryAfter time.Duration) {
+	if retryAfter = b.refill(now, rate, burst); retryAfter > 0 {
+		return false, retryAfter
+	}
+	b.Tokens--
+	return true, 0
+}
+
+// FullAt time the bucket is back to burst tokens, from then on it is the same as a new bucket
+func (b *Bucket) FullAt(rate float64, burst int) time.Time {
+	missing := math.Max(float64(burst), 1) - b.Tokens
+	if missing <= 0 || rate <= 0 {
+		return b.UpdatedAt
+	}
+	return b.UpdatedAt.Add(time.Duration(missing / rate * float64(time.Second)))
+}
+
+// refill the bucket up to now and return how long until it has a token, 0 when it has one
+func (b *Bucket) refill(now time.Time, rate float64, burst int) time.Duration {
+	capacity := math.Max(float64(burst), 1)
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = capacity
+	} else if elapsed := now.Sub(b.UpdatedAt).Seconds(); elapsed > 0 {
+		b.Tokens = math.Min(capacity, b.Tokens+elapsed*rate)
+	}
+	b.UpdatedAt = now
+
+	if b.Tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.Tokens) / rate * float64(time.Second))
+}
+
+// TakeAll consume one token of every bucket of limits, or none when one of them is empty, missing buckets
+// are added full, when not allowed it returns how long until every bucket has a token
+func TakeAll(buckets map[string]*Bucket, limits map[string]Limit, now time.Time) (allowed bool, retryAfter time.Duration) {
+	for name, limit := range limits {
+		bucket, found := buckets[name]
+		if !found {
+			bucket = new(Bucket)
+			buckets[name] = bucket
+		}
+		if wait := bucket.refill(now, limit.Rate, limit.Burst); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+
+	for name := range limits {
+		buckets[name].Tokens--
+	}
+	return true, 0
+}
@@ -0,0 +1,58 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sepulsa/teleco/utils/ratelimit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketTake(t *testing.T) {
+	now := time.Now()
+	bucket := new(ratelimit.Bucket)
+
+	// new bucket starts full
+	for i := 0; i < 3; i++ {
+		allowed, _ := bucket.Take(now, 2, 3)
+		assert.True(t, allowed)
+	}
+
+	// empty, next token in half a second
+	allowed, retryAfter := bucket.Take(now, 2, 3)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	// refilled
+	allowed, _ = bucket.Take(now.Add(500*time.Millisecond), 2, 3)
+	assert.True(t, allowed)
+
+	// refill never exceeds burst
+	bucket.Take(now.Add(time.Hour), 2, 3)
+	assert.Equal(t, float64(2), bucket.Tokens)
+}
+
+func TestTakeAll(t *testing.T) {
+	now := time.Now()
+	buckets := map[string]*ratelimit.Bucket{}
+	limits := map[string]ratelimit.Limit{
+		"partner": {Rate: 10, Burst: 3},
+		"issuer":  {Rate: 1, Burst: 1},
+	}
+
+	// missing buckets start full
+	allowed, _ := ratelimit.TakeAll(buckets, limits, now)
+	assert.True(t, allowed)
+	assert.Equal(t, float64(2), buckets["partner"].Tokens)
+	assert.Equal(t, float64(0), buckets["issuer"].Tokens)
+
+	// issuer bucket empty, the partner token is not consumed
+	allowed, retryAfter := ratelimit.TakeAll(buckets, limits, now)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+	assert.Equal(t, float64(2), buckets["partner"].Tokens)
+
+	// full again after burst tokens at rate
+	assert.Equal(t, now.Add(100*time.Millisecond), buckets["partner"].FullAt(10, 3))
+	assert.Equal(t, now.Add(time.Second), buckets["issuer"].FullAt(1, 1))
+}
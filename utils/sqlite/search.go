@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/sepulsa/teleco/utils/query"
+)
+
+type (
+	// SearchFields columns of a table open to a query.Query
+	SearchFields struct {
+		Search []string
+		Sort   []string
+		Filter []string
+	}
+
+	// Scan reads the selected columns of a row into dest
+	Scan func(dest ...interface{}) error
+
+	cursor struct {
+		Value interface{} `json:"v"`
+		ID    string      `json:"id"`
+	}
+)
+
+// Search reads a page of the rows of table matching where and the query, rows are sorted by id when the
+// query has no sort field, collect is called for each row with a Scan over columns
+func Search(db *sql.DB, table string, columns string, where string, args []interface{}, q query.Query, fields SearchFields, collect func(scan Scan) error) (page query.Page, err error) {
+	q = q.Normalize()
+	page.Page = q.Page
+	page.Limit = q.Limit
+
+	conditions := []string{}
+	if where != "" {
+		conditions = append(conditions, where)
+	}
+	for field, value := range q.Filters {
+		if value == "" {
+			continue
+		}
+		if !contains(fields.Filter, field) {
+			return page, errors.New(query.ErrInvalidFilter)
+		}
+		conditions = append(conditions, field+" = ?")
+		args = append(args, value)
+	}
+	if q.Search != "" && len(fields.Search) > 0 {
+		search := make([]string, 0, len(fields.Search))
+		for _, field := range fields.Search {
+			search = append(search, field+` LIKE ? ESCAPE '\'`)
+			args = append(args, "%"+escapeLike(q.Search)+"%")
+		}
+		conditions = append(conditions, "("+strings.Join(search, " OR ")+")")
+	}
+	if err = db.QueryRow("SELECT COUNT(*) FROM "+table+whereClause(conditions), args...).Scan(&page.Total); err != nil {
+		return
+	}
+
+	field, descending := q.SortField()
+	if field == "" {
+		field = "id"
+	} else if !contains(fields.Sort, field) {
+		return page, errors.New(query.ErrInvalidSort)
+	}
+	order, op := "ASC", ">"
+	if descending {
+		order, op = "DESC", "<"
+	}
+	orderBy := " ORDER BY " + field + " " + order
+	if field != "id" {
+		orderBy += ", id " + order
+	}
+
+	limit := " LIMIT ?"
+	if q.Cursor != "" {
+		position, err := decodeCursor(q.Cursor)
+		if err != nil {
+			return page, err
+		}
+		// rows after the cursor, the id breaks ties on the sort field
+		if field == "id" {
+			conditions = append(conditions, "id "+op+" ?")
+			args = append(args, position.ID)
+		} else {
+			conditions = append(conditions, "("+field+" "+op+" ? OR ("+field+" = ? AND id "+op+" ?))")
+			args = append(args, position.Value, position.Value, position.ID)
+		}
+		args = append(args, q.Limit+1)
+	} else {
+		limit += " OFFSET ?"
+		args = append(args, q.Limit+1, (q.Page-1)*q.Limit)
+	}
+
+	rows, err := db.Query("SELECT "+field+", id, "+columns+" FROM "+table+whereClause(conditions)+orderBy+limit, args...)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	var last cursor
+	for count := 0; rows.Next(); count++ {
+		if count == q.Limit {
+			page.NextCursor = encodeCursor(last)
+			break
+		}
+		if err = collect(func(dest ...interface{}) error {
+			return rows.Scan(append([]interface{}{&last.Value, &last.ID}, dest...)...)
+		}); err != nil {
+			return
+		}
+	}
+	err = rows.Err()
+	return
+}
+
+// encodeCursor keeps the sort position of a row, it is opaque to the caller
+func encodeCursor(position cursor) string {
+	if b, ok := position.Value.([]byte); ok {
+		position.Value = string(b)
+	}
+	b, _ := json.Marshal(position)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(value string) (position cursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err == nil {
+		err = json.Unmarshal(b, &position)
+	}
+	if err != nil || !IsID(position.ID) {
+		err = errors.New(query.ErrInvalidCursor)
+	}
+	return
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+func contains(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gopkg.in/mgo.v2/bson"
+)
+
+// TimeLayout fixed width UTC layout, stored times sort and compare as text
+const TimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type (
+	// Migration schema change applied once, in Version order
+	Migration struct {
+		Version     int
+		Description string
+		Statements  []string
+	}
+)
+
+// Open connects the database file, a single connection is kept so that writes are serialized
+// and an in-memory database is shared
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", "file:"+path+"?_busy_timeout=5000&_foreign_keys=on")
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate applies the migrations not recorded yet in schema_migrations
+func Migrate(db *sql.DB, migrations []Migration) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	)`); err != nil {
+		return err
+	}
+	for _, migration := range migrations {
+		var applied int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, migration.Version).Scan(&applied); err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		for _, statement := range migration.Statements {
+			if _, err = tx.Exec(statement); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		if _, err = tx.Exec(`INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`, migration.Version, migration.Description, Time(time.Now())); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err = tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewID identifier in the format of the MongoDB repositories
+func NewID() string {
+	return bson.NewObjectId().Hex()
+}
+
+// IsID reports whether ID was made by NewID
+func IsID(ID string) bool {
+	return bson.IsObjectIdHex(ID)
+}
+
+// Time value of a time column, NULL for the zero time
+func Time(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(TimeLayout)
+}
+
+// ParseTime reads a time column, the zero time for NULL
+func ParseTime(value sql.NullString) time.Time {
+	if !value.Valid {
+		return time.Time{}
+	}
+	t, _ := time.Parse(TimeLayout, value.String)
+	return t.Local()
+}
+
+// JSON value of a json column
+func JSON(v interface{}) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// ParseJSON reads a json column into v
+func ParseJSON(value string, v interface{}) {
+	if value != "" {
+		json.Unmarshal([]byte(value), v)
+	}
+}
+
+// IsDup reports whether err is the violation of a unique index or primary key
+func IsDup(err error) bool {
+	sqliteErr, ok := err.(sqlite3.Error)
+	return ok && (sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey)
+}
@@ -0,0 +1,120 @@
+package sqlite
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sepulsa/teleco/utils/query"
+	"github.com/stretchr/testify/assert"
+)
+
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "create item",
+		Statements: []string{
+			`CREATE TABLE item (id TEXT PRIMARY KEY, code TEXT NOT NULL, status TEXT NOT NULL, created_at TEXT)`,
+		},
+	},
+}
+
+func open(t *testing.T) *sql.DB {
+	db, err := Open(":memory:")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, Migrate(db, migrations)) {
+		t.FailNow()
+	}
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	db := open(t)
+	defer db.Close()
+
+	// applied migrations are skipped
+	assert.NoError(t, Migrate(db, migrations))
+	var count int
+	db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	assert.Equal(t, 1, count)
+
+	// a failed migration is not recorded
+	err := Migrate(db, append(migrations, Migration{Version: 2, Statements: []string{`CREATE TABLE item (id TEXT)`}}))
+	assert.Error(t, err)
+	db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+	assert.Equal(t, 1, count)
+}
+
+func TestTime(t *testing.T) {
+	createdAt := time.Date(2021, 9, 8, 10, 0, 0, 5, time.UTC)
+	assert.Nil(t, Time(time.Time{}))
+	assert.True(t, createdAt.Equal(ParseTime(sql.NullString{String: Time(createdAt).(string), Valid: true})))
+	assert.True(t, ParseTime(sql.NullString{}).IsZero())
+}
+
+func TestIsDup(t *testing.T) {
+	db := open(t)
+	defer db.Close()
+
+	_, err := db.Exec(`INSERT INTO item (id, code, status) VALUES (?, ?, ?)`, NewID(), "dummy", "active")
+	assert.NoError(t, err)
+	assert.False(t, IsDup(err))
+	_, err = db.Exec(`INSERT INTO item (id, code, status) SELECT id, code, status FROM item`)
+	assert.True(t, IsDup(err))
+	_, err = db.Exec(`INSERT INTO item (id) VALUES (?)`, NewID())
+	assert.Error(t, err)
+	assert.False(t, IsDup(err))
+}
+
+func TestSearch(t *testing.T) {
+	db := open(t)
+	defer db.Close()
+	for _, code := range []string{"dummy_a", "dummy_b", "dummy_c", "other%"} {
+		db.Exec(`INSERT INTO item (id, code, status, created_at) VALUES (?, ?, ?, ?)`, NewID(), code, "active", Time(time.Now()))
+	}
+	fields := SearchFields{Search: []string{"code"}, Sort: []string{"code"}, Filter: []string{"status"}}
+	search := func(q query.Query) (codes []string, page query.Page, err error) {
+		page, err = Search(db, "item", "code", "", nil, q, fields, func(scan Scan) error {
+			var code string
+			err := scan(&code)
+			codes = append(codes, code)
+			return err
+		})
+		return
+	}
+
+	codes, page, err := search(query.Query{Sort: "-code", Search: "dum", Limit: 2, Filters: map[string]string{"status": "active"}})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"dummy_c", "dummy_b"}, codes)
+		assert.Equal(t, 3, page.Total)
+		assert.NotEmpty(t, page.NextCursor)
+	}
+	codes, page, err = search(query.Query{Sort: "-code", Search: "dum", Limit: 2, Cursor: page.NextCursor})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"dummy_a"}, codes)
+		assert.Empty(t, page.NextCursor)
+	}
+
+	// like wildcards are searched literally
+	codes, _, err = search(query.Query{Search: "%"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"other%"}, codes)
+	}
+
+	codes, page, err = search(query.Query{Sort: "code", Page: 2, Limit: 3})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"other%"}, codes)
+		assert.Equal(t, 4, page.Total)
+	}
+
+	_, _, err = search(query.Query{Sort: "secret_key"})
+	assert.Equal(t, query.ErrInvalidSort, err.Error())
+
+	_, _, err = search(query.Query{Filters: map[string]string{"secret_key": "x"}})
+	assert.Equal(t, query.ErrInvalidFilter, err.Error())
+
+	_, _, err = search(query.Query{Cursor: "x"})
+	assert.Equal(t, query.ErrInvalidCursor, err.Error())
+}
@@ -0,0 +1,32 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// ErrVersionConflict the live row has another version than the update
+var ErrVersionConflict = errors.New("version conflict")
+
+// UpdateVersion applies the SET clause set, bound to args, to the live row of ID as long as it still has version
+// and increments it, sql.ErrNoRows when there is no live row of ID and ErrVersionConflict when it has another version
+func UpdateVersion(db *sql.DB, table string, set string, ID string, version int, args ...interface{}) error {
+	args = append(args, ID, version)
+	result, err := db.Exec(`UPDATE `+table+` SET `+set+`, version = version + 1
+		WHERE id = ? AND deleted_at IS NULL AND version = ?`, args...)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return nil
+	}
+
+	var count int
+	if err = db.QueryRow(`SELECT COUNT(*) FROM `+table+` WHERE id = ? AND deleted_at IS NULL`, ID).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrVersionConflict
+	}
+	return sql.ErrNoRows
+}
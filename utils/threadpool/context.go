@@ -0,0 +1,27 @@
+package threadpool
+
+import (
+	"context"
+	"time"
+)
+
+// detached context keeping the values of its parent but not its deadline nor cancellation
+type detached struct {
+	parent context.Context
+}
+
+func (detached) Deadline() (deadline time.Time, ok bool) {
+	return
+}
+
+func (detached) Done() <-chan struct{} {
+	return nil
+}
+
+func (detached) Err() error {
+	return nil
+}
+
+func (d detached) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
@@ -0,0 +1,56 @@
+package threadpool
+
+import (
+	"context"
+	"sync"
+)
+
+// tracker count jobs and their after timeout handlers still running in background
+type tracker struct {
+	mu      sync.Mutex
+	running int
+	idle    chan struct{}
+}
+
+var inflight = &tracker{}
+
+func (t *tracker) add() {
+	t.mu.Lock()
+	if t.running == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.running++
+	t.mu.Unlock()
+}
+
+func (t *tracker) done() {
+	t.mu.Lock()
+	t.running--
+	if t.running == 0 {
+		close(t.idle)
+	}
+	t.mu.Unlock()
+}
+
+func (t *tracker) wait(ctx context.Context) error {
+	t.mu.Lock()
+	if t.running == 0 {
+		t.mu.Unlock()
+		return nil
+	}
+	idle := t.idle
+	t.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Drain wait until every job and RunAfterTimeout handler of every pool finished,
+// call it after new tasks are no longer accepted, ctx bounds the wait
+func Drain(ctx context.Context) error {
+	return inflight.wait(ctx)
+}
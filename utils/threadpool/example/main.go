@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"time"
@@ -12,7 +13,8 @@ func main() {
 
 	for i := 1; i <= 5; i++ {
 		task := &myTask{ID: i}
-		threadpool.Run("test", 1, 2, task)
+		result := threadpool.Run(context.Background(), "test", 1, 2, task)
+		fmt.Println("Result ", result)
 	}
 
 }
@@ -21,20 +23,27 @@ type myTask struct {
 	ID int
 }
 
-func (m *myTask) Run() {
+func (m *myTask) Run(ctx context.Context) interface{} {
 	fmt.Println("Running my task ", m.ID)
 	sleep := rand.Intn(6)
-	time.Sleep(time.Duration(sleep) * time.Second)
+	select {
+	case <-time.After(time.Duration(sleep) * time.Second):
+		return "done"
+	case <-ctx.Done():
+		return "cancelled"
+	}
 }
 
-func (m *myTask) RunWhenTimeout() {
+func (m *myTask) RunWhenTimeout() interface{} {
 	fmt.Println("Running when Timeout my task ", m.ID)
+	return "timeout"
 }
 
-func (m *myTask) RunAfterTimeout() {
-	fmt.Println("Running after Timeout my task ", m.ID)
+func (m *myTask) RunAfterTimeout(result interface{}) {
+	fmt.Println("Running after Timeout my task ", m.ID, result)
 }
 
-func (m *myTask) RunWhenFull() {
+func (m *myTask) RunWhenFull() interface{} {
 	fmt.Println("Running when Full my task ", m.ID)
+	return "full"
 }
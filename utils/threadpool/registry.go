@@ -0,0 +1,70 @@
+package threadpool
+
+import (
+	"sort"
+	"sync"
+)
+
+type (
+	// Stats counters of one pool, Timeouts and Overflows count since the process started
+	Stats struct {
+		Name      string `json:"name"`
+		Capacity  int    `json:"capacity"`
+		Timeout   int    `json:"timeout"`
+		InUse     int    `json:"in_use"`
+		Waiting   int    `json:"waiting"`
+		Timeouts  int64  `json:"timeouts"`
+		Overflows int64  `json:"overflows"`
+	}
+
+	registry struct {
+		mu    sync.Mutex
+		pools map[string]*ThreadPool
+	}
+)
+
+var pools = &registry{pools: make(map[string]*ThreadPool)}
+
+// get the pool by name, an existing pool is resized in place when its config changed
+func (r *registry) get(name string, maxThread int, timeout int) *ThreadPool {
+	r.mu.Lock()
+	tp, found := r.pools[name]
+	if !found {
+		tp = &ThreadPool{Name: name, MaxThread: maxThread, Timeout: timeout}
+		tp.initPool()
+		r.pools[name] = tp
+	}
+	r.mu.Unlock()
+
+	if found {
+		tp.Resize(maxThread, timeout)
+	}
+	return tp
+}
+
+// GetPool return the pool by name
+func GetPool(name string) (*ThreadPool, bool) {
+	pools.mu.Lock()
+	defer pools.mu.Unlock()
+	tp, found := pools.pools[name]
+	return tp, found
+}
+
+// GetStats list stats of every pool sorted by name
+func GetStats() []Stats {
+	pools.mu.Lock()
+	list := make([]*ThreadPool, 0, len(pools.pools))
+	for _, tp := range pools.pools {
+		list = append(list, tp)
+	}
+	pools.mu.Unlock()
+
+	stats := make([]Stats, 0, len(list))
+	for _, tp := range list {
+		stats = append(stats, tp.Stats())
+	}
+	sort.Slice(stats, func(a, b int) bool {
+		return stats[a].Name < stats[b].Name
+	})
+	return stats
+}
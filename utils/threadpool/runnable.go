@@ -1,9 +1,16 @@
 package threadpool
 
-// Runnable is interface for the jobs that will be executed by the threadpool
+import "context"
+
+// Runnable is interface for the jobs that will be executed by the threadpool,
+// results are passed by value so the caller never shares state with a late job
 type Runnable interface {
-	Run()
-	RunWhenTimeout()
-	RunAfterTimeout()
-	RunWhenFull()
+	// Run execute the job and return its result, it should give up when ctx is done
+	Run(ctx context.Context) interface{}
+	// RunWhenTimeout result handed back when the job is still running at the pool timeout
+	RunWhenTimeout() interface{}
+	// RunAfterTimeout receive the result of a job that finished after RunWhenTimeout
+	RunAfterTimeout(result interface{})
+	// RunWhenFull result handed back when no slot is available before the pool timeout
+	RunWhenFull() interface{}
 }
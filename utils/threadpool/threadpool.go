@@ -1,87 +1,305 @@
 package threadpool
 
 import (
+	"context"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/sepulsa/teleco/utils/logger"
+	"github.com/rs/zerolog"
 )
 
 // ThreadPool ...
 type ThreadPool struct {
-	Name           string
-	MaxThread      int
-	RoutineProcess chan struct{}
-	Timeout        int
+	Name      string
+	MaxThread int
+	Timeout   int
+
+	mu        sync.Mutex
+	inUse     int
+	waiting   int
+	timeouts  int64
+	overflows int64
+	members   map[string]*member
+	released  chan struct{}
+}
+
+// Quota share of a pool for one member (partner), reserved slots can not be taken by other
+// members, Max caps the slots the member may hold at once, zero means no cap
+type Quota struct {
+	Key      string
+	Reserved int
+	Max      int
+}
+
+type member struct {
+	inUse    int
+	wanted   int
+	reserved int
+	max      int
+	lastSeen time.Time
 }
 
 var (
 	package_name_log     = "teleco/utils/threadpool"
 	DefaultThreadNum     = 5
 	DefaultThreadTimeout = 30
+	// CallTimeout seconds a job may keep running after the caller stopped waiting, set by the apps from threadpool.call_timeout
+	CallTimeout = 60
+	// MemberIdleTimeout members without a slot in use nor an order for that long are dropped with their reservation
+	MemberIdleTimeout = 10 * time.Minute
+	// Logger of the pool events, set by the apps to the app logger, nothing is logged otherwise
+	Logger = zerolog.Nop()
 )
 
-var thread map[string]*ThreadPool = make(map[string]*ThreadPool)
-
 func (tp *ThreadPool) initPool() {
-	tp.RoutineProcess = make(chan struct{}, tp.MaxThread)
-	// Fill the dummy channel with maxThread empty struct.
-	for i := 0; i < tp.MaxThread; i++ {
-		tp.RoutineProcess <- struct{}{}
-	}
-	logger.Info().
+	tp.members = make(map[string]*member)
+	tp.released = make(chan struct{})
+	Logger.Info().
 		Str("event", "initPool").
 		Str("package", package_name_log).
 		Msgf("Init Thread Pool: %v, %v, %v", tp.Name, tp.MaxThread, tp.Timeout)
 }
 
-func Run(name string, maxThread int, timeout int, task Runnable) {
-	tp, found := thread[name]
+// register apply the member quota and drop idle members, reservations are granted again
+// whenever a quota changes or a member is dropped, caller must hold the lock
+func (tp *ThreadPool) register(quota Quota) *member {
+	now := time.Now()
+	changed := false
+	for key, m := range tp.members {
+		if m.inUse == 0 && now.Sub(m.lastSeen) > MemberIdleTimeout {
+			delete(tp.members, key)
+			changed = changed || m.wanted > 0
+		}
+	}
+
+	var m *member
+	if quota.Key != "" {
+		var found bool
+		if m, found = tp.members[quota.Key]; !found {
+			m = new(member)
+			tp.members[quota.Key] = m
+		}
+		changed = changed || m.wanted != quota.Reserved
+		m.wanted = quota.Reserved
+		m.max = quota.Max
+		m.lastSeen = now
+	}
+
+	if changed {
+		tp.allocate()
+		tp.wakeUp()
+	}
+	return m
+}
+
+// allocate grant the wanted reservations in member key order, never exceeding the pool capacity
+// in total, caller must hold the lock
+func (tp *ThreadPool) allocate() {
+	keys := make([]string, 0, len(tp.members))
+	for key := range tp.members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	available := tp.MaxThread
+	for _, key := range keys {
+		m := tp.members[key]
+		m.reserved = m.wanted
+		if m.reserved > available {
+			m.reserved = available
+		}
+		if m.reserved < 0 {
+			m.reserved = 0
+		}
+		available -= m.reserved
+	}
+}
+
+// unusedReserved slots held back for members below their reservation
+func (tp *ThreadPool) unusedReserved() (total int) {
+	for _, m := range tp.members {
+		if m.inUse < m.reserved {
+			total += m.reserved - m.inUse
+		}
+	}
+	return
+}
+
+func (tp *ThreadPool) tryAcquire(m *member) bool {
+	if tp.inUse >= tp.MaxThread {
+		return false
+	}
+	if m != nil {
+		if m.max > 0 && m.inUse >= m.max {
+			return false
+		}
+		if m.inUse < m.reserved {
+			return true
+		}
+	}
+	return tp.MaxThread-tp.inUse-tp.unusedReserved() > 0
+}
+
+// acquire wait for a slot until deadline or ctx is done
+func (tp *ThreadPool) acquire(ctx context.Context, quota Quota, deadline time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	waiting := false
+	defer func() {
+		if waiting {
+			tp.mu.Lock()
+			tp.waiting--
+			tp.mu.Unlock()
+		}
+	}()
+
+	for {
+		tp.mu.Lock()
+		m := tp.register(quota)
+		if tp.tryAcquire(m) {
+			tp.inUse++
+			if m != nil {
+				m.inUse++
+			}
+			tp.mu.Unlock()
+			return true
+		}
+		released := tp.released
+		if !waiting {
+			waiting = true
+			tp.waiting++
+		}
+		tp.mu.Unlock()
+
+		select {
+		case <-released:
+		case <-timer.C:
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+func (tp *ThreadPool) release(quota Quota) {
+	tp.mu.Lock()
+	tp.inUse--
+	if m, found := tp.members[quota.Key]; found && quota.Key != "" {
+		m.inUse--
+	}
+	tp.wakeUp()
+	tp.mu.Unlock()
+}
+
+// wakeUp every waiter, each one checks its own quota again, caller must hold the lock
+func (tp *ThreadPool) wakeUp() {
+	close(tp.released)
+	tp.released = make(chan struct{})
+}
+
+// Resize change capacity and timeout of a live pool, slots in use stay accounted and
+// when shrinking no new slot is handed out until usage drops below the new capacity
+func (tp *ThreadPool) Resize(maxThread int, timeout int) {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	if tp.MaxThread == maxThread && tp.Timeout == timeout {
+		return
+	}
+	tp.MaxThread = maxThread
+	tp.Timeout = timeout
+
+	// keep reservations within the new capacity
+	tp.allocate()
+	tp.wakeUp()
+
+	Logger.Info().
+		Str("event", "resizePool").
+		Str("package", package_name_log).
+		Msgf("Resize Thread Pool: %v, %v, %v", tp.Name, tp.MaxThread, tp.Timeout)
+}
+
+// Stats snapshot of the pool counters
+func (tp *ThreadPool) Stats() Stats {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	return Stats{
+		Name:      tp.Name,
+		Capacity:  tp.MaxThread,
+		Timeout:   tp.Timeout,
+		InUse:     tp.inUse,
+		Waiting:   tp.waiting,
+		Timeouts:  tp.timeouts,
+		Overflows: tp.overflows,
+	}
+}
+
+func (tp *ThreadPool) timeout() time.Duration {
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	return time.Duration(tp.Timeout) * time.Second
+}
+
+func (tp *ThreadPool) count(counter *int64) {
+	tp.mu.Lock()
+	*counter++
+	tp.mu.Unlock()
+}
+
+func Run(ctx context.Context, name string, maxThread int, timeout int, task Runnable) interface{} {
+	return RunWithQuota(ctx, name, maxThread, timeout, Quota{}, task)
+}
+
+// RunWithQuota run task in the pool respecting the quota of its member and return the job result,
+// or the RunWhenTimeout result when the job does not finish before the timeout or ctx is done
+func RunWithQuota(ctx context.Context, name string, maxThread int, timeout int, quota Quota, task Runnable) interface{} {
 	if maxThread == 0 {
 		maxThread = DefaultThreadNum
 	}
 	if timeout == 0 {
 		timeout = DefaultThreadTimeout
 	}
-	if !found || tp.MaxThread != maxThread || tp.Timeout != timeout {
-		threadPool := &ThreadPool{Name: name, MaxThread: maxThread, Timeout: timeout}
-		threadPool.initPool()
-		thread[name] = threadPool
-		tp = thread[name]
-	}
+	tp := pools.get(name, maxThread, timeout)
 
-	done := make(chan bool)
-	waitJobs := make(chan bool)
+	// Wait for a slot allowed by the quota. Otherwise, it will block the execution
+	// until an execution spot is available or the timeout is reached.
+	poolTimeout := tp.timeout()
+	deadline := time.Now().Add(poolTimeout)
+	if !tp.acquire(ctx, quota, deadline) {
+		tp.count(&tp.overflows)
+		return task.RunWhenFull()
+	}
 
+	// The job keeps the caller values but not its cancellation, a caller giving up must not abort
+	// an issuer call that may already be done. It runs up to its own deadline for RunAfterTimeout.
+	callTimeout := time.Duration(CallTimeout) * time.Second
+	if callTimeout < poolTimeout {
+		callTimeout = poolTimeout
+	}
+	jobCtx, cancel := context.WithTimeout(detached{ctx}, callTimeout)
+	finished := make(chan interface{}, 1)
+	// done once the caller got the result or RunAfterTimeout handled it
+	inflight.add()
 	go func() {
-		<-done
-		// Say that another goroutine can now start.
-		tp.RoutineProcess <- struct{}{}
-		waitJobs <- true
+		defer tp.release(quota)
+		defer cancel()
+		finished <- task.Run(jobCtx)
 	}()
 
-	// Try to receive from the routineProcess channel. When we have something,
-	// it means we can start a new goroutine because another one finished.
-	// Otherwise, it will block the execution until an execution spot is available.
-	var isTimeout bool = false
-	currentTime := time.Now()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
 	select {
-	case <-tp.RoutineProcess:
-		duration := currentTime.Add(time.Duration(tp.Timeout) * time.Second).Sub(time.Now())
-		go func(t Runnable) {
-			t.Run()
-			if isTimeout {
-				t.RunAfterTimeout()
-			}
-			done <- true
-		}(task)
-		select {
-		case <-waitJobs:
-			// Not do anything, just waiting channel signal sync process finished.
-		case <-time.After(duration):
-			task.RunWhenTimeout()
-			isTimeout = true
-		}
-	case <-time.After(time.Duration(tp.Timeout) * time.Second):
-		task.RunWhenFull()
+	case result := <-finished:
+		inflight.done()
+		return result
+	case <-timer.C:
+	case <-ctx.Done():
 	}
+
+	tp.count(&tp.timeouts)
+	go func() {
+		defer inflight.done()
+		task.RunAfterTimeout(<-finished)
+	}()
+	return task.RunWhenTimeout()
 }
@@ -0,0 +1,220 @@
+package threadpool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuota(t *testing.T) {
+	tp := &ThreadPool{Name: "test", MaxThread: 4, Timeout: 1}
+	tp.initPool()
+
+	reserved := Quota{Key: "p1", Reserved: 2}
+	shared := Quota{Key: "p2"}
+	capped := Quota{Key: "p3", Max: 1}
+	expired := time.Now()
+	ctx := context.Background()
+
+	// reservation is known to the pool after the first order of the member
+	assert.True(t, tp.acquire(ctx, reserved, expired))
+	tp.release(reserved)
+
+	// other members only share the unreserved slots
+	assert.True(t, tp.acquire(ctx, shared, expired))
+	assert.True(t, tp.acquire(ctx, shared, expired))
+	assert.False(t, tp.acquire(ctx, shared, expired))
+	assert.False(t, tp.acquire(ctx, capped, expired))
+
+	// reserved slots stay available
+	assert.True(t, tp.acquire(ctx, reserved, expired))
+	assert.True(t, tp.acquire(ctx, reserved, expired))
+	assert.False(t, tp.acquire(ctx, reserved, expired))
+
+	// max caps the member even when slots are free
+	tp.release(shared)
+	tp.release(shared)
+	assert.True(t, tp.acquire(ctx, capped, expired))
+	assert.False(t, tp.acquire(ctx, capped, expired))
+
+	assert.True(t, tp.acquire(ctx, shared, expired))
+
+	// a slot released by a reserved member stays with that member
+	tp.release(reserved)
+	assert.False(t, tp.acquire(ctx, shared, expired))
+
+	// a waiter gets the slot once it is released
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		tp.release(capped)
+	}()
+	assert.True(t, tp.acquire(ctx, shared, time.Now().Add(time.Second)))
+}
+
+func TestQuotaReservationCapped(t *testing.T) {
+	tp := &ThreadPool{Name: "test", MaxThread: 2, Timeout: 1}
+	tp.initPool()
+
+	tp.mu.Lock()
+	first := tp.register(Quota{Key: "p1", Reserved: 2})
+	second := tp.register(Quota{Key: "p2", Reserved: 2})
+	tp.mu.Unlock()
+
+	assert.Equal(t, 2, first.reserved)
+	assert.Equal(t, 0, second.reserved)
+}
+
+type testTask struct {
+	sleep time.Duration
+	late  chan interface{}
+	key   interface{}
+}
+
+func (t *testTask) Run(ctx context.Context) interface{} {
+	select {
+	case <-time.After(t.sleep):
+		if t.key != nil {
+			return "done " + ctx.Value(t.key).(string)
+		}
+		return "done"
+	case <-ctx.Done():
+		return "cancelled"
+	}
+}
+
+func (t *testTask) RunWhenTimeout() interface{} {
+	return "timeout"
+}
+
+func (t *testTask) RunAfterTimeout(result interface{}) {
+	t.late <- result
+}
+
+func (t *testTask) RunWhenFull() interface{} {
+	return "full"
+}
+
+func TestRun(t *testing.T) {
+	// finished in time
+	task := &testTask{late: make(chan interface{}, 1)}
+	assert.Equal(t, "done", Run(context.Background(), "run", 1, 1, task))
+
+	// pool timeout, late result is delivered to RunAfterTimeout
+	task = &testTask{sleep: 1100 * time.Millisecond, late: make(chan interface{}, 1)}
+	assert.Equal(t, "timeout", Run(context.Background(), "run", 1, 1, task))
+	assert.Equal(t, "done", <-task.late)
+
+	// caller gives up, the job keeps running with the caller values
+	type key struct{}
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), key{}, "value"), 10*time.Millisecond)
+	defer cancel()
+	task = &testTask{sleep: 50 * time.Millisecond, late: make(chan interface{}, 1), key: key{}}
+	assert.Equal(t, "timeout", Run(ctx, "run", 1, 1, task))
+	assert.Equal(t, "done value", <-task.late)
+
+	// no slot before the caller gives up
+	busy := &testTask{sleep: 100 * time.Millisecond, late: make(chan interface{}, 1)}
+	go Run(context.Background(), "run", 1, 1, busy)
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, "full", Run(ctx, "run", 1, 1, task))
+}
+
+func TestResize(t *testing.T) {
+	ctx := context.Background()
+	expired := time.Now()
+	tp := pools.get("resize", 2, 1)
+
+	assert.True(t, tp.acquire(ctx, Quota{}, expired))
+	assert.True(t, tp.acquire(ctx, Quota{}, expired))
+
+	// shrink keeps slots in use accounted
+	assert.Equal(t, tp, pools.get("resize", 1, 1))
+	tp.release(Quota{})
+	assert.False(t, tp.acquire(ctx, Quota{}, expired))
+	assert.Equal(t, 1, tp.Stats().InUse)
+
+	// grow wakes up waiters
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		pools.get("resize", 3, 1)
+	}()
+	assert.True(t, tp.acquire(ctx, Quota{}, time.Now().Add(time.Second)))
+
+	stats := tp.Stats()
+	assert.Equal(t, 3, stats.Capacity)
+	assert.Equal(t, 2, stats.InUse)
+	assert.Equal(t, 0, stats.Waiting)
+}
+
+func TestStats(t *testing.T) {
+	task := &testTask{sleep: 50 * time.Millisecond, late: make(chan interface{}, 1)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	Run(ctx, "stats", 1, 1, task)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	Run(ctx, "stats", 1, 1, task)
+	<-task.late
+
+	tp, found := GetPool("stats")
+	if assert.True(t, found) {
+		stats := tp.Stats()
+		assert.Equal(t, int64(1), stats.Timeouts)
+		assert.Equal(t, int64(1), stats.Overflows)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	// settle jobs left by other tests
+	settle, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, Drain(settle))
+
+	task := &testTask{sleep: 50 * time.Millisecond, late: make(chan interface{}, 1)}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, "timeout", Run(ctx, "drain", 1, 1, task))
+
+	// RunAfterTimeout is still pending
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, Drain(ctx))
+
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.Nil(t, Drain(ctx))
+	assert.Equal(t, "done", <-task.late)
+}
+
+func TestQuotaChanged(t *testing.T) {
+	tp := &ThreadPool{Name: "test", MaxThread: 2, Timeout: 1}
+	tp.initPool()
+
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+	first := tp.register(Quota{Key: "p1", Reserved: 2})
+	second := tp.register(Quota{Key: "p2", Reserved: 2})
+	assert.Equal(t, 0, second.reserved)
+
+	// a lowered quota hands its slots to the other members
+	tp.register(Quota{Key: "p1", Reserved: 1})
+	assert.Equal(t, 1, first.reserved)
+	assert.Equal(t, 1, second.reserved)
+
+	// an idle member is dropped with its reservation
+	first.lastSeen = time.Now().Add(-MemberIdleTimeout - time.Second)
+	tp.register(Quota{Key: "p2", Reserved: 2})
+	assert.Equal(t, 2, second.reserved)
+	assert.Len(t, tp.members, 1)
+
+	// unless it still holds a slot
+	second.inUse = 1
+	second.lastSeen = time.Now().Add(-MemberIdleTimeout - time.Second)
+	tp.register(Quota{Key: "p3"})
+	assert.Len(t, tp.members, 2)
+}
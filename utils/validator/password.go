@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+type (
+	// PasswordPolicy rules a new password must satisfy, zero value means no rule
+	PasswordPolicy struct {
+		MinLength     int
+		RequireUpper  bool
+		RequireLower  bool
+		RequireDigit  bool
+		RequireSymbol bool
+		// History number of last passwords which can't be reused
+		History int
+	}
+)
+
+var (
+	ErrPasswordTooShort     = "password length too short (min=%d)"
+	ErrPasswordNoUpper      = "password must contain an uppercase letter"
+	ErrPasswordNoLower      = "password must contain a lowercase letter"
+	ErrPasswordNoDigit      = "password must contain a digit"
+	ErrPasswordNoSymbol     = "password must contain a symbol"
+	ErrPasswordNotPrintable = "password must only contain printable characters"
+)
+
+// Verify check password against the policy
+func (p PasswordPolicy) Verify(password string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf(ErrPasswordTooShort, p.MinLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case !unicode.IsPrint(r):
+			return errors.New(ErrPasswordNotPrintable)
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	switch {
+	case p.RequireUpper && !hasUpper:
+		return errors.New(ErrPasswordNoUpper)
+	case p.RequireLower && !hasLower:
+		return errors.New(ErrPasswordNoLower)
+	case p.RequireDigit && !hasDigit:
+		return errors.New(ErrPasswordNoDigit)
+	case p.RequireSymbol && !hasSymbol:
+		return errors.New(ErrPasswordNoSymbol)
+	}
+
+	return nil
+}